@@ -0,0 +1,74 @@
+package glow
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// imageDecoders maps lower-case file extensions to their decoder.
+var imageDecoders = map[string]func(io.Reader) (image.Image, error){
+	".png":  png.Decode,
+	".jpg":  jpeg.Decode,
+	".jpeg": jpeg.Decode,
+	".bmp":  decodeBMP,
+}
+
+// LoadSprites loads every PNG, JPEG and BMP file in fsys matching pattern
+// (see fs.Glob) and returns them keyed by file name without extension.
+// Matching files with other extensions are skipped. It works with any
+// fs.FS, including an embed.FS:
+//
+//	//go:embed assets/*.png
+//	var assets embed.FS
+//
+//	sprites, err := glow.LoadSprites(assets, "assets/*.png")
+//	player := sprites["player"]
+func LoadSprites(fsys fs.FS, pattern string) (map[string]*Sprite, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	sprites := make(map[string]*Sprite)
+	for _, name := range matches {
+		ext := strings.ToLower(path.Ext(name))
+		decode, ok := imageDecoders[ext]
+		if !ok {
+			continue
+		}
+
+		key := strings.TrimSuffix(path.Base(name), path.Ext(name))
+		if _, dup := sprites[key]; dup {
+			return nil, fmt.Errorf("glow: LoadSprites: more than one asset named %q", key)
+		}
+
+		sprite, err := loadSpriteFS(fsys, name, decode)
+		if err != nil {
+			return nil, fmt.Errorf("glow: LoadSprites: %s: %w", name, err)
+		}
+		sprites[key] = sprite
+	}
+
+	return sprites, nil
+}
+
+// loadSpriteFS decodes a single file from fsys into a Sprite.
+func loadSpriteFS(fsys fs.FS, name string, decode func(io.Reader) (image.Image, error)) (*Sprite, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpriteFromImage(img), nil
+}
@@ -0,0 +1,164 @@
+package glow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// maxAtlasSize caps each side of a packed atlas; larger sprites are
+// rarely worth batching and huge allocations are usually a mistake.
+const maxAtlasSize = 8192
+
+// Atlas is a set of named sprites packed into one backing sprite.
+// Each name maps to the rectangle its pixels occupy in Sprite.
+type Atlas struct {
+	Sprite  *Sprite
+	regions map[string]Rect
+}
+
+// Region returns where the named sprite lies in the atlas.
+func (a *Atlas) Region(name string) (Rect, bool) {
+	r, ok := a.regions[name]
+	return r, ok
+}
+
+// Names returns the names of the packed sprites, sorted.
+func (a *Atlas) Names() []string {
+	names := make([]string, 0, len(a.regions))
+	for name := range a.regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DrawAtlasSprite draws the named sprite from an atlas at (x, y).
+// It returns false, drawing nothing, if the atlas has no such sprite.
+func (c *Canvas) DrawAtlasSprite(a *Atlas, name string, x, y int) bool {
+	r, ok := a.regions[name]
+	if !ok {
+		return false
+	}
+	c.DrawSpriteRegion(a.Sprite, x, y, r.X, r.Y, r.Width, r.Height)
+	return true
+}
+
+// PackSprites copies sprites into a single atlas using a shelf packer:
+// sprites are sorted tallest first and laid out left to right in rows.
+// The atlas starts as the smallest power-of-two square that could hold
+// them all and doubles in size (width and height in turn) until every
+// sprite fits, up to 8192×8192.
+func PackSprites(sprites map[string]*Sprite) (*Atlas, error) {
+	if len(sprites) == 0 {
+		return nil, errors.New("glow: no sprites to pack")
+	}
+
+	names := make([]string, 0, len(sprites))
+	area, widest := 0, 0
+	for name, s := range sprites {
+		if s == nil {
+			return nil, fmt.Errorf("glow: sprite %q is nil", name)
+		}
+		names = append(names, name)
+		area += s.Width() * s.Height()
+		widest = max(widest, s.Width())
+	}
+	// Tallest first packs shelves tightly; names break ties so the
+	// layout is the same on every run
+	sort.Slice(names, func(i, j int) bool {
+		hi, hj := sprites[names[i]].Height(), sprites[names[j]].Height()
+		if hi != hj {
+			return hi > hj
+		}
+		return names[i] < names[j]
+	})
+
+	w, h := 1, 1
+	for w*h < area || w < widest {
+		if w <= h {
+			w *= 2
+		} else {
+			h *= 2
+		}
+	}
+
+	for {
+		if w > maxAtlasSize || h > maxAtlasSize {
+			return nil, fmt.Errorf("glow: sprites do not fit in a %dx%d atlas", maxAtlasSize, maxAtlasSize)
+		}
+		if regions, ok := shelfPack(names, sprites, w, h); ok {
+			atlas := &Atlas{Sprite: newBlankSprite(w, h), regions: regions}
+			for name, r := range regions {
+				copySprite(atlas.Sprite, sprites[name], r.X, r.Y)
+			}
+			return atlas, nil
+		}
+		if w <= h {
+			w *= 2
+		} else {
+			h *= 2
+		}
+	}
+}
+
+// shelfPack places the sprites, in order, into rows within a w×h area.
+// It reports false if they don't all fit.
+func shelfPack(names []string, sprites map[string]*Sprite, w, h int) (map[string]Rect, bool) {
+	regions := make(map[string]Rect, len(names))
+	x, y, shelf := 0, 0, 0
+	for _, name := range names {
+		sw, sh := sprites[name].Width(), sprites[name].Height()
+		if x+sw > w {
+			// Start a new shelf below the tallest sprite of this one
+			x, y, shelf = 0, y+shelf, 0
+		}
+		if sw > w || y+sh > h {
+			return nil, false
+		}
+		regions[name] = Rect{X: x, Y: y, Width: sw, Height: sh}
+		x += sw
+		shelf = max(shelf, sh)
+	}
+	return regions, true
+}
+
+// copySprite copies src's pixels into dst with its top-left at (x, y).
+// The caller guarantees src fits.
+func copySprite(dst, src *Sprite, x, y int) {
+	rowBytes := src.data.Width * 4
+	for row := 0; row < src.data.Height; row++ {
+		off := ((y+row)*dst.data.Width + x) * 4
+		copy(dst.data.Pixels[off:off+rowBytes], src.data.Pixels[row*rowBytes:])
+	}
+}
+
+// Trim returns a copy of the sprite cropped to its non-transparent
+// pixels, and the area of the original it was cut from. Draw the trimmed
+// sprite at (x+r.X, y+r.Y) to place it where the original would be.
+// A fully transparent sprite trims to an empty 0×0 sprite.
+func (s *Sprite) Trim() (*Sprite, Rect) {
+	w, h := s.data.Width, s.data.Height
+	minX, minY, maxX, maxY := w, h, -1, -1
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if s.data.Pixels[(y*w+x)*4+3] == 0 {
+				continue
+			}
+			minX, maxX = min(minX, x), max(maxX, x)
+			minY, maxY = min(minY, y), max(maxY, y)
+		}
+	}
+	if maxX < 0 {
+		return newBlankSprite(0, 0), Rect{}
+	}
+
+	r := Rect{X: minX, Y: minY, Width: maxX - minX + 1, Height: maxY - minY + 1}
+	trimmed := newBlankSprite(r.Width, r.Height)
+	rowBytes := r.Width * 4
+	for row := 0; row < r.Height; row++ {
+		off := ((r.Y+row)*w + r.X) * 4
+		copy(trimmed.data.Pixels[row*rowBytes:(row+1)*rowBytes], s.data.Pixels[off:])
+	}
+	return trimmed, r
+}
@@ -0,0 +1,57 @@
+package glow
+
+import "testing"
+
+func TestPackSprites(t *testing.T) {
+	sprites := map[string]*Sprite{
+		"wide":   makeOpaqueRedSprite(20, 4),
+		"tall":   makeOpaqueRedSprite(4, 20),
+		"square": makeOpaqueRedSprite(10, 10),
+		"dot":    makeOpaqueRedSprite(1, 1),
+	}
+	atlas, err := PackSprites(sprites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := atlas.Names()
+	if len(names) != len(sprites) {
+		t.Fatalf("expected %d regions, got %d", len(sprites), len(names))
+	}
+	for i, a := range names {
+		ra, _ := atlas.Region(a)
+		if ra.Width != sprites[a].Width() || ra.Height != sprites[a].Height() {
+			t.Errorf("%s: region %+v does not match sprite size", a, ra)
+		}
+		if ra.X < 0 || ra.Y < 0 || ra.X+ra.Width > atlas.Sprite.Width() || ra.Y+ra.Height > atlas.Sprite.Height() {
+			t.Errorf("%s: region %+v outside atlas", a, ra)
+		}
+		if p := pixelAt(atlas.Sprite, ra.X, ra.Y); p != [4]byte{0, 0, 255, 255} {
+			t.Errorf("%s: pixels not copied, got %v", a, p)
+		}
+		for _, b := range names[i+1:] {
+			rb, _ := atlas.Region(b)
+			if ra.X < rb.X+rb.Width && rb.X < ra.X+ra.Width &&
+				ra.Y < rb.Y+rb.Height && rb.Y < ra.Y+ra.Height {
+				t.Errorf("%s %+v overlaps %s %+v", a, ra, b, rb)
+			}
+		}
+	}
+}
+
+func TestSpriteTrim(t *testing.T) {
+	s := newBlankSprite(8, 8)
+	s.setPixel(2, 3, Red, 255)
+	s.setPixel(5, 4, Red, 10)
+
+	trimmed, r := s.Trim()
+	if r != (Rect{X: 2, Y: 3, Width: 4, Height: 2}) {
+		t.Fatalf("unexpected trim rect %+v", r)
+	}
+	assertPixel(t, trimmed, 0, 0, 0, 0, 255, 255)
+	assertPixel(t, trimmed, 3, 1, 0, 0, 255, 10)
+
+	if empty, r := newBlankSprite(4, 4).Trim(); empty.Width() != 0 || r != (Rect{}) {
+		t.Errorf("transparent sprite should trim to nothing, got %dx%d %+v", empty.Width(), empty.Height(), r)
+	}
+}
@@ -1,57 +1,218 @@
 package glow
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/AchrafSoltani/glow/internal/pulse"
 )
 
 // AudioContext manages a connection to the PulseAudio server.
 type AudioContext struct {
-	conn       *pulse.Connection
-	sampleRate uint32
-	channels   uint8
-	format     uint8
+	conn *pulse.Connection
+	spec pulse.SampleSpec
+}
+
+// SampleFormat identifies how PCM samples are encoded.
+type SampleFormat int
+
+const (
+	SampleU8        SampleFormat = iota // Unsigned 8-bit
+	SampleS16LE                         // Signed 16-bit little-endian
+	SampleS24LE                         // Signed 24-bit little-endian, packed
+	SampleS32LE                         // Signed 32-bit little-endian
+	SampleFloat32LE                     // 32-bit float little-endian, -1.0 to 1.0
+	SampleS16BE                         // Signed 16-bit big-endian
+	SampleS24BE                         // Signed 24-bit big-endian, packed
+	SampleS32BE                         // Signed 32-bit big-endian
+	SampleFloat32BE                     // 32-bit float big-endian, -1.0 to 1.0
+)
+
+// hostBigEndian reports whether the machine stores integers big-endian.
+var hostBigEndian = binary.NativeEndian.Uint16([]byte{0, 1}) == 1
+
+// BigEndian reports whether f stores samples most significant byte
+// first. SampleU8 has no byte order and reports false.
+func (f SampleFormat) BigEndian() bool {
+	return f >= SampleS16BE && f <= SampleFloat32BE
+}
+
+// Native returns the variant of f in the host's byte order, for samples
+// generated by writing native integers or floats (as through unsafe or
+// binary.NativeEndian) without swapping bytes:
+//
+//	ctx, err := glow.NewAudioContextFmt(44100, 2, glow.SampleS16LE.Native())
+func (f SampleFormat) Native() SampleFormat {
+	if f.BigEndian() == hostBigEndian || f == SampleU8 {
+		return f
+	}
+	// The BE formats follow the LE ones in the same order
+	const swap = SampleS16BE - SampleS16LE
+	if f.BigEndian() {
+		return f - swap
+	}
+	return f + swap
+}
+
+// pulseFormat maps a SampleFormat to the PulseAudio constant.
+func (f SampleFormat) pulseFormat() (uint8, bool) {
+	switch f {
+	case SampleU8:
+		return pulse.SampleU8, true
+	case SampleS16LE:
+		return pulse.SampleS16LE, true
+	case SampleS24LE:
+		return pulse.SampleS24LE, true
+	case SampleS32LE:
+		return pulse.SampleS32LE, true
+	case SampleFloat32LE:
+		return pulse.SampleFloat32LE, true
+	case SampleS16BE:
+		return pulse.SampleS16BE, true
+	case SampleS24BE:
+		return pulse.SampleS24BE, true
+	case SampleS32BE:
+		return pulse.SampleS32BE, true
+	case SampleFloat32BE:
+		return pulse.SampleFloat32BE, true
+	}
+	return 0, false
+}
+
+// sampleFormatFromPulse maps a PulseAudio constant back to a SampleFormat.
+func sampleFormatFromPulse(format uint8) (SampleFormat, bool) {
+	for f := SampleU8; f <= SampleFloat32BE; f++ {
+		if pf, _ := f.pulseFormat(); pf == format {
+			return f, true
+		}
+	}
+	return 0, false
 }
 
 // NewAudioContext creates a new audio context connected to PulseAudio.
 // sampleRate is in Hz (e.g. 44100), channels is 1 for mono or 2 for stereo,
 // and bitDepth is the number of bytes per sample (2 for 16-bit).
+// Use NewAudioContextFmt to select float, unsigned or big-endian
+// formats.
 func NewAudioContext(sampleRate, channels, bitDepth int) (*AudioContext, error) {
-	conn, err := pulse.Connect()
-	if err != nil {
-		return nil, err
-	}
-
-	// Map bitDepth to PA sample format
-	var format uint8
+	// Map bitDepth to a sample format
+	var format SampleFormat
 	switch bitDepth {
 	case 1:
-		format = pulse.SampleU8
+		format = SampleU8
 	case 2:
-		format = pulse.SampleS16LE
+		format = SampleS16LE
 	case 3:
-		format = pulse.SampleS24LE
+		format = SampleS24LE
 	case 4:
-		format = pulse.SampleS32LE
+		format = SampleS32LE
 	default:
-		format = pulse.SampleS16LE
+		format = SampleS16LE
+	}
+
+	return NewAudioContextFmt(sampleRate, channels, format)
+}
+
+// NewAudioContextFmt creates a new audio context connected to PulseAudio
+// playing samples in the given format.
+func NewAudioContextFmt(sampleRate, channels int, format SampleFormat) (*AudioContext, error) {
+	paFormat, ok := format.pulseFormat()
+	if !ok {
+		return nil, fmt.Errorf("glow audio: unknown sample format %d", format)
+	}
+
+	conn, err := pulse.Connect()
+	if err != nil {
+		return nil, err
 	}
 
 	return &AudioContext{
-		conn:       conn,
-		sampleRate: uint32(sampleRate),
-		channels:   uint8(channels),
-		format:     format,
+		conn: conn,
+		spec: pulse.SampleSpec{
+			Format:   paFormat,
+			Channels: uint8(channels),
+			Rate:     uint32(sampleRate),
+		},
 	}, nil
 }
 
+// NewDefaultAudioContext creates an audio context matching the server's
+// default sample spec, so audio plays without being resampled. Check
+// SampleRate, Channels and Format to know what PCM data to produce.
+// If the server can't be queried, or uses a format glow doesn't expose,
+// it falls back to 44100 Hz stereo SampleS16LE (keeping the server's
+// rate and channels when known).
+func NewDefaultAudioContext() (*AudioContext, error) {
+	conn, err := pulse.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := pulse.SampleSpec{
+		Format:   pulse.SampleS16LE,
+		Channels: 2,
+		Rate:     44100,
+	}
+	if info, err := conn.GetServerInfo(); err == nil {
+		def := info.SampleSpec
+		if def.Rate > 0 && def.Channels > 0 {
+			spec.Rate = def.Rate
+			spec.Channels = def.Channels
+		}
+		if _, ok := sampleFormatFromPulse(def.Format); ok {
+			spec.Format = def.Format
+		}
+	}
+
+	return &AudioContext{
+		conn: conn,
+		spec: spec,
+	}, nil
+}
+
+// SampleRate returns the context's sample rate in Hz.
+func (ctx *AudioContext) SampleRate() int {
+	return int(ctx.spec.Rate)
+}
+
+// Channels returns the number of audio channels.
+func (ctx *AudioContext) Channels() int {
+	return int(ctx.spec.Channels)
+}
+
+// Format returns the sample format players are expected to supply.
+func (ctx *AudioContext) Format() SampleFormat {
+	f, _ := sampleFormatFromPulse(ctx.spec.Format)
+	return f
+}
+
+// FrameSize returns the number of bytes in one frame of audio
+// (one sample for every channel) in the context's format.
+// PCM data passed to players should be a whole number of frames.
+func (ctx *AudioContext) FrameSize() int {
+	return ctx.spec.FrameSize()
+}
+
+// SetChunkSize sets the largest data packet, in bytes, sent to the
+// server when playing. Larger chunks mean fewer writes for long sounds;
+// smaller ones let other players' commands get through sooner. Chunks
+// are cut on frame boundaries. n <= 0 restores the default of 64 KiB.
+func (ctx *AudioContext) SetChunkSize(n int) {
+	ctx.conn.SetChunkSize(n)
+}
+
 // NewPlayer creates a new audio player that reads PCM data from r.
 func (ctx *AudioContext) NewPlayer(r io.Reader) *AudioPlayer {
 	return &AudioPlayer{
 		ctx:    ctx,
 		reader: r,
+		volume: 1,
 	}
 }
 
@@ -62,38 +223,299 @@ func (ctx *AudioContext) Close() {
 	}
 }
 
+// ErrNotPlaying is returned when querying a player whose stream isn't
+// playing: Play hasn't been called, the stream is still being set up, or
+// the server has stopped consuming it.
+var ErrNotPlaying = errors.New("glow audio: player is not playing")
+
 // AudioPlayer plays PCM audio data from an io.Reader.
 type AudioPlayer struct {
 	ctx    *AudioContext
 	reader io.Reader
+	pcm    []byte // Data already in memory, played instead of reader's
+
+	mu      sync.Mutex
+	stream  *pulse.Stream // Set once Play has created it
+	volume  float64       // Linear gain, 1 plays samples unchanged
+	balance []float64     // Per-channel gains from SetChannelVolumes, nil for all 1
+	paused  bool          // Set by Pause, cleared by Resume
+	stopped bool          // Set by Stop; the player can't play again
+
+	onUnderrun, onOverrun func() // See OnUnderrun and OnOverrun
 }
 
 // Play starts playback in a goroutine. It reads all data from the reader,
 // creates a PulseAudio playback stream, and writes the PCM data.
-// This is fire-and-forget — the stream drains naturally.
+// The stream drains naturally unless cut short with Stop, and is deleted
+// once it has played out.
 func (p *AudioPlayer) Play() {
+	p.mu.Lock()
+	stopped := p.stopped
+	p.mu.Unlock()
+	if stopped {
+		return
+	}
 	go func() {
-		data, err := io.ReadAll(p.reader)
-		if err != nil {
-			log.Printf("glow audio: read error: %v", err)
-			return
+		data := p.pcm
+		if data == nil && p.reader != nil {
+			var err error
+			if data, err = io.ReadAll(p.reader); err != nil {
+				log.Printf("glow audio: read error: %v", err)
+				return
+			}
 		}
 		if len(data) == 0 {
 			return
 		}
 
-		stream, err := p.ctx.conn.CreatePlaybackStream(
-			p.ctx.format,
-			p.ctx.channels,
-			p.ctx.sampleRate,
-		)
+		stream, err := p.ctx.conn.CreatePlaybackStream(p.ctx.spec)
 		if err != nil {
 			log.Printf("glow audio: create stream error: %v", err)
 			return
 		}
+		p.mu.Lock()
+		if p.stopped {
+			p.mu.Unlock()
+			stream.Delete()
+			return
+		}
+		p.stream = stream
+		volumes := p.channelVolumes()
+		paused := p.paused
+		stream.OnUnderflow(p.onUnderrun)
+		stream.OnOverflow(p.onOverrun)
+		p.mu.Unlock()
+		if paused {
+			if err := stream.Cork(true); err != nil {
+				log.Printf("glow audio: pause error: %v", err)
+			}
+		}
+		if !unityVolumes(volumes) {
+			// No data has been written yet, so nothing plays too loud
+			if err := stream.SetChannelVolumes(volumes); err != nil {
+				log.Printf("glow audio: set volume error: %v", err)
+			}
+		}
 
-		if err := stream.WriteAll(data); err != nil {
+		err = stream.WriteAll(data)
+		if err == nil {
+			err = stream.Drain()
+		}
+		if err != nil && !errors.Is(err, pulse.ErrStreamDeleted) {
 			log.Printf("glow audio: write error: %v", err)
 		}
+		p.release(stream)
 	}()
 }
+
+// release deletes stream once it has played out, so finished sounds
+// don't hold on to server-side sink inputs.
+func (p *AudioPlayer) release(stream *pulse.Stream) {
+	p.mu.Lock()
+	if p.stream == stream {
+		p.stream = nil
+	}
+	p.mu.Unlock()
+	if err := stream.Delete(); err != nil {
+		log.Printf("glow audio: delete stream error: %v", err)
+	}
+}
+
+// Pause pauses playback; Resume continues from the same point. Calling
+// it before Play starts the sound paused. Pausing a stopped player does
+// nothing.
+func (p *AudioPlayer) Pause() error {
+	return p.setPaused(true)
+}
+
+// Resume continues playback paused with Pause.
+func (p *AudioPlayer) Resume() error {
+	return p.setPaused(false)
+}
+
+func (p *AudioPlayer) setPaused(paused bool) error {
+	p.mu.Lock()
+	if p.stopped || p.paused == paused {
+		p.mu.Unlock()
+		return nil
+	}
+	p.paused = paused
+	stream := p.stream
+	p.mu.Unlock()
+	if stream == nil {
+		return nil
+	}
+	return stream.Cork(paused)
+}
+
+// Stop stops playback at once and releases the player's stream. A
+// stopped player can't be played again; calling Stop more than once is
+// safe.
+func (p *AudioPlayer) Stop() error {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return nil
+	}
+	p.stopped = true
+	stream := p.stream
+	p.stream = nil
+	p.mu.Unlock()
+	if stream == nil {
+		return nil // Play deletes the stream as soon as it is created
+	}
+	return stream.Delete()
+}
+
+// OnUnderrun sets f to be called whenever the server runs out of audio
+// to play before the whole sound has been sent, heard as a gap or
+// click. Frequent underruns mean the app is feeding audio too slowly or
+// the latency is set too low. nil removes the handler. f runs on its own
+// goroutine and may be set before Play.
+func (p *AudioPlayer) OnUnderrun(f func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onUnderrun = f
+	if p.stream != nil {
+		p.stream.OnUnderflow(f)
+	}
+}
+
+// OnOverrun sets f to be called whenever more audio is sent than the
+// server can buffer, so some of it is dropped. nil removes the handler.
+// f runs on its own goroutine and may be set before Play.
+func (p *AudioPlayer) OnOverrun(f func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onOverrun = f
+	if p.stream != nil {
+		p.stream.OnOverflow(f)
+	}
+}
+
+// SetVolume sets the player's volume as a linear gain: 0 is silent, 1
+// plays samples unchanged and 0.5 halves their amplitude. It can be
+// called before Play; the volume then applies from the first sample.
+func (p *AudioPlayer) SetVolume(v float64) error {
+	p.mu.Lock()
+	p.volume = math.Max(v, 0)
+	stream := p.stream
+	volumes := p.channelVolumes()
+	p.mu.Unlock()
+	if stream == nil {
+		return nil
+	}
+	return stream.SetChannelVolumes(volumes)
+}
+
+// SetChannelVolumes sets a gain for each channel, in the context's
+// channel order (left then right for stereo), for balance without
+// software panning. Each gain is clamped to 0..1 and scales the volume
+// set with SetVolume, so fades keep the balance. vols must have one
+// entry per channel; nil restores an even balance.
+func (p *AudioPlayer) SetChannelVolumes(vols []float64) error {
+	var balance []float64
+	if vols != nil {
+		if len(vols) != p.ctx.Channels() {
+			return fmt.Errorf("glow audio: %d channel volumes for %d channels", len(vols), p.ctx.Channels())
+		}
+		balance = make([]float64, len(vols))
+		for i, v := range vols {
+			balance[i] = math.Max(0, math.Min(1, v))
+		}
+	}
+
+	p.mu.Lock()
+	p.balance = balance
+	stream := p.stream
+	volumes := p.channelVolumes()
+	p.mu.Unlock()
+	if stream == nil {
+		return nil
+	}
+	return stream.SetChannelVolumes(volumes)
+}
+
+// ChannelVolumes returns the per-channel gains last set with
+// SetChannelVolumes (all 1 by default).
+func (p *AudioPlayer) ChannelVolumes() []float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	vols := make([]float64, p.ctx.Channels())
+	for i := range vols {
+		vols[i] = 1
+		if p.balance != nil {
+			vols[i] = p.balance[i]
+		}
+	}
+	return vols
+}
+
+// channelVolumes returns the stream volume for each channel, combining
+// the volume and balance. p.mu must be held.
+func (p *AudioPlayer) channelVolumes() []uint32 {
+	volumes := make([]uint32, p.ctx.Channels())
+	for i := range volumes {
+		v := p.volume
+		if p.balance != nil {
+			v *= p.balance[i]
+		}
+		volumes[i] = pulseVolume(v)
+	}
+	return volumes
+}
+
+// unityVolumes reports whether every volume plays samples unchanged.
+func unityVolumes(volumes []uint32) bool {
+	for _, v := range volumes {
+		if v != pulse.VolumeNorm {
+			return false
+		}
+	}
+	return true
+}
+
+// Volume returns the volume last set with SetVolume (1 by default).
+func (p *AudioPlayer) Volume() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.volume
+}
+
+// pulseVolume converts a linear gain to PulseAudio's cubic volume scale.
+func pulseVolume(v float64) uint32 {
+	return uint32(math.Cbrt(v) * pulse.VolumeNorm)
+}
+
+// Position returns how far into the sound playback is, as heard: the
+// bytes written to the stream, less those the server still buffers and
+// the sink's own latency. It returns ErrNotPlaying if the stream isn't
+// playing.
+func (p *AudioPlayer) Position() (time.Duration, error) {
+	p.mu.Lock()
+	stream := p.stream
+	p.mu.Unlock()
+	if stream == nil {
+		return 0, ErrNotPlaying
+	}
+
+	lat, err := stream.Latency()
+	if err != nil {
+		return 0, err
+	}
+	if !lat.Playing {
+		return 0, ErrNotPlaying
+	}
+	return playbackPosition(stream.Written(), lat, stream.Spec()), nil
+}
+
+// playbackPosition converts a stream's buffer state into the time played.
+func playbackPosition(written int64, lat *pulse.PlaybackLatency, spec pulse.SampleSpec) time.Duration {
+	bytesPerSec := int64(spec.FrameSize()) * int64(spec.Rate)
+	if bytesPerSec <= 0 {
+		return 0
+	}
+	played := written - (lat.WriteIndex - lat.ReadIndex)
+	pos := time.Duration(played)*time.Second/time.Duration(bytesPerSec) - lat.Sink
+	return max(pos, 0)
+}
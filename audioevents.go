@@ -0,0 +1,74 @@
+package glow
+
+import "github.com/AchrafSoltani/glow/internal/pulse"
+
+// Masks for AudioContext.Subscribe, selecting which kinds of audio
+// server object to hear about. They double as SubscribeEvent.Facility
+// values.
+const (
+	AudioSinks         uint32 = pulse.SubscriptionMaskSink         // Output devices: volume, mute, active port (headphones plugged in)
+	AudioSources       uint32 = pulse.SubscriptionMaskSource       // Input devices
+	AudioSinkInputs    uint32 = pulse.SubscriptionMaskSinkInput    // Playback streams, this app's and others'
+	AudioSourceOutputs uint32 = pulse.SubscriptionMaskSourceOutput // Recording streams
+	AudioServer        uint32 = pulse.SubscriptionMaskServer       // Server settings, such as the default sink
+	AudioCards         uint32 = pulse.SubscriptionMaskCard         // Sound cards and their profiles
+)
+
+// AudioChange says what happened to the object in a SubscribeEvent.
+type AudioChange int
+
+const (
+	AudioObjectAdded AudioChange = iota
+	AudioObjectChanged
+	AudioObjectRemoved
+)
+
+// SubscribeEvent reports that an object on the audio server was added,
+// changed or removed. Events carry no details; query the server for the
+// object's new state if needed.
+type SubscribeEvent struct {
+	Facility uint32      // Kind of object, one of the Audio* masks
+	Change   AudioChange // What happened to it
+	Index    uint32      // The object's server-wide index
+}
+
+// Subscribe starts delivering events about the audio server objects
+// selected by mask, an OR of AudioSinks, AudioSources and so on, so apps
+// can react to the user changing the system volume or plugging in
+// headphones:
+//
+//	events, err := ctx.Subscribe(glow.AudioSinks | glow.AudioServer)
+//	...
+//	for e := range events {
+//		if e.Facility == glow.AudioSinks && e.Change == glow.AudioObjectChanged {
+//			...
+//		}
+//	}
+//
+// The context has one subscription: calling Subscribe again replaces
+// the mask and closes the earlier channel. The channel is also closed
+// when the connection to the server ends. Events are dropped if the app
+// falls far behind in reading them.
+func (ctx *AudioContext) Subscribe(mask uint32) (<-chan SubscribeEvent, error) {
+	in, err := ctx.conn.Subscribe(mask)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan SubscribeEvent, cap(in))
+	go func() {
+		defer close(out)
+		for e := range in {
+			select {
+			case out <- SubscribeEvent{
+				Facility: 1 << e.Facility(),
+				Change:   AudioChange(e.Kind() >> 4),
+				Index:    e.Index,
+			}:
+			default:
+				// Never block, so a replaced subscription's goroutine
+				// always ends
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,123 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestFindAuthHostnameMatching(t *testing.T) {
+	const familyLocal = 256
+	entries := []x11.AuthEntry{
+		{Family: familyLocal, Address: "other", Display: "0", Name: "MIT-MAGIC-COOKIE-1", Data: []byte{1}},
+		{Family: familyLocal, Address: "Box.Example.COM", Display: "0", Name: "MIT-MAGIC-COOKIE-1", Data: []byte{2}},
+	}
+
+	for _, host := range []string{"box", "BOX", "box.example.com", "box.lan"} {
+		e := x11.FindAuthForHost(entries, "0", host)
+		if e == nil || e.Data[0] != 2 {
+			t.Errorf("hostname %q: expected the Box.Example.COM entry, got %+v", host, e)
+		}
+	}
+	if e := x11.FindAuthForHost(entries, "0", "boxer"); e != nil {
+		t.Errorf("hostname boxer should not match, got %+v", e)
+	}
+	if e := x11.FindAuthForHost(entries, "1", "box"); e != nil {
+		t.Errorf("display 1 should not match, got %+v", e)
+	}
+}
+
+func TestFindAuthPrefersMagicCookie(t *testing.T) {
+	const familyWild = 65535
+	entries := []x11.AuthEntry{
+		{Family: familyWild, Display: "0", Name: "XDM-AUTHORIZATION-1", Data: []byte{1}},
+		{Family: familyWild, Display: "0", Name: x11.AuthMITMagicCookie, Data: []byte{2}},
+	}
+
+	if e := x11.FindAuthForHost(entries, "0", "box"); e == nil || e.Name != x11.AuthMITMagicCookie {
+		t.Errorf("expected the magic cookie entry, got %+v", e)
+	}
+	if e := x11.FindAuthByName(entries, "0", "box", "XDM-AUTHORIZATION-1"); e == nil || e.Data[0] != 1 {
+		t.Errorf("expected the XDM entry, got %+v", e)
+	}
+
+	// Other protocols are still used when there is no cookie
+	if e := x11.FindAuthForHost(entries[:1], "0", "box"); e == nil || e.Data[0] != 1 {
+		t.Errorf("expected fallback to the XDM entry, got %+v", e)
+	}
+}
+
+// writeXauthority writes entries to an Xauthority file and points
+// XAUTHORITY at it.
+func writeXauthority(t *testing.T, entries []x11.AuthEntry) {
+	t.Helper()
+	var b []byte
+	field := func(s []byte) {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+		b = append(b, s...)
+	}
+	for _, e := range entries {
+		b = binary.BigEndian.AppendUint16(b, e.Family)
+		field([]byte(e.Address))
+		field([]byte(e.Display))
+		field([]byte(e.Name))
+		field(e.Data)
+	}
+	path := filepath.Join(t.TempDir(), "Xauthority")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XAUTHORITY", path)
+}
+
+// handshakeAuthName runs a connection handshake against a server that
+// hangs up after the setup request, and returns the auth protocol name
+// the request carried.
+func handshakeAuthName(t *testing.T) string {
+	t.Helper()
+	client, server := net.Pipe()
+	name := make(chan string, 1)
+	go func() {
+		defer server.Close()
+		req := make([]byte, 12)
+		if _, err := io.ReadFull(server, req); err != nil {
+			name <- ""
+			return
+		}
+		n := int(binary.LittleEndian.Uint16(req[6:]))
+		rest := make([]byte, (n+3)&^3)
+		io.ReadFull(server, rest)
+		name <- string(rest[:n])
+	}()
+	if conn, err := x11.Handshake(client, "0"); err == nil {
+		conn.Close()
+	}
+	return <-name
+}
+
+func TestSetAuthProtocol(t *testing.T) {
+	const familyWild = 65535
+	writeXauthority(t, []x11.AuthEntry{
+		{Family: familyWild, Display: "0", Name: "XDM-AUTHORIZATION-1", Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{Family: familyWild, Display: "0", Name: x11.AuthMITMagicCookie, Data: []byte{9}},
+	})
+	t.Cleanup(func() { SetAuthProtocol("") })
+
+	if got := handshakeAuthName(t); got != x11.AuthMITMagicCookie {
+		t.Errorf("default handshake sent %q, want the magic cookie", got)
+	}
+	SetAuthProtocol("XDM-AUTHORIZATION-1")
+	if got := handshakeAuthName(t); got != "XDM-AUTHORIZATION-1" {
+		t.Errorf("handshake sent %q, want XDM-AUTHORIZATION-1", got)
+	}
+	// No entry of the protocol: no auth rather than another one
+	SetAuthProtocol("SUN-DES-1")
+	if got := handshakeAuthName(t); got != "" {
+		t.Errorf("handshake sent %q, want no auth", got)
+	}
+}
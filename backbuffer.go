@@ -0,0 +1,86 @@
+package glow
+
+// SetBackBuffer turns the back buffer on or off (on by default). With a
+// back buffer, Present uploads the frame into an off-screen pixmap and
+// then copies it to the window in a single request, so the window never
+// shows a half-uploaded frame, which can tear on slow window managers.
+// It costs one window-sized pixmap on the server and one extra copy
+// there per frame.
+func (w *Window) SetBackBuffer(enabled bool) {
+	if enabled == !w.noBackBuffer {
+		return
+	}
+	w.noBackBuffer = !enabled
+	if !enabled {
+		w.freeBackBuffer()
+	}
+	w.fullPresent.Store(true)
+}
+
+// BackBuffer reports whether Present goes through a back buffer (see
+// SetBackBuffer).
+func (w *Window) BackBuffer() bool {
+	return !w.noBackBuffer
+}
+
+// backBufferStale reports whether the back buffer must be created or
+// replaced before it can hold view.
+func (w *Window) backBufferStale(view Rect) bool {
+	return !w.noBackBuffer &&
+		(w.backBuffer == 0 || w.backW != view.Width || w.backH != view.Height)
+}
+
+// ensureBackBuffer creates the back buffer, or replaces it after a
+// resize, and has the next Present fill it in full.
+func (w *Window) ensureBackBuffer(view Rect) error {
+	if !w.backBufferStale(view) || view.Empty() {
+		return nil
+	}
+	w.freeBackBuffer()
+	id, err := w.conn.CreatePixmap(w.windowID, uint16(view.Width), uint16(view.Height), w.Depth())
+	if err != nil {
+		return err
+	}
+	w.backBuffer, w.backW, w.backH = id, view.Width, view.Height
+	w.fullPresent.Store(true)
+	return nil
+}
+
+// freeBackBuffer frees the back buffer pixmap, if any.
+func (w *Window) freeBackBuffer() {
+	if w.backBuffer == 0 {
+		return
+	}
+	w.conn.FreePixmap(w.backBuffer)
+	w.backBuffer, w.backW, w.backH = 0, 0, 0
+}
+
+// putRegions uploads regions of the canvas, parts of view, to the window.
+// With a back buffer they are uploaded into it and then copied to the
+// window together.
+func (w *Window) putRegions(regions []Rect, view Rect) error {
+	if len(regions) == 0 {
+		return nil
+	}
+	if err := w.waitShm(); err != nil {
+		return err
+	}
+
+	dst := w.windowID
+	if w.backBuffer != 0 {
+		dst = w.backBuffer
+	}
+	var damage Rect
+	for _, r := range regions {
+		if err := w.putRegion(dst, r, r.X-view.X, r.Y-view.Y); err != nil {
+			return err
+		}
+		damage = damage.Union(r)
+	}
+	if dst == w.windowID {
+		return nil
+	}
+	x, y := int16(damage.X-view.X), int16(damage.Y-view.Y)
+	return w.conn.CopyArea(w.backBuffer, w.windowID, w.gcID, x, y, x, y,
+		uint16(damage.Width), uint16(damage.Height))
+}
@@ -0,0 +1,137 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// recordRequests returns a connection whose requests are sent, one per
+// value, on the returned channel.
+func recordRequests(t *testing.T) (*x11.Connection, <-chan []byte) {
+	client, server := net.Pipe()
+	conn := x11.NewConnection(client)
+	conn.ResourceIDBase = 0x200000
+	conn.ResourceIDMask = 0xFFFF
+	conn.RootDepth = 24
+	t.Cleanup(func() { conn.Close() })
+
+	reqs := make(chan []byte, 64)
+	go func() {
+		for {
+			hdr := make([]byte, 4)
+			if _, err := io.ReadFull(server, hdr); err != nil {
+				return
+			}
+			req := make([]byte, int(binary.LittleEndian.Uint16(hdr[2:]))*4)
+			copy(req, hdr)
+			if _, err := io.ReadFull(server, req[4:]); err != nil {
+				return
+			}
+			reqs <- req
+		}
+	}()
+	return conn, reqs
+}
+
+// nextRequest returns the next recorded request, failing the test unless
+// it has the given opcode.
+func nextRequest(t *testing.T, reqs <-chan []byte, opcode uint8) []byte {
+	t.Helper()
+	select {
+	case req := <-reqs:
+		if req[0] != opcode {
+			t.Fatalf("request opcode = %d, want %d", req[0], opcode)
+		}
+		return req
+	case <-time.After(time.Second):
+		t.Fatalf("no request with opcode %d", opcode)
+		return nil
+	}
+}
+
+func TestBackBuffer(t *testing.T) {
+	conn, reqs := recordRequests(t)
+	const windowID, gcID = 0x100, 0x101
+	w := &Window{
+		conn: conn, windowID: windowID, gcID: gcID,
+		canvas: &Canvas{fb: x11.NewFramebuffer(40, 30)}, width: 40, height: 30,
+		shmOff: true,
+	}
+	u16 := func(req []byte, off int) int { return int(binary.LittleEndian.Uint16(req[off:])) }
+	u32 := func(req []byte, off int) uint32 { return binary.LittleEndian.Uint32(req[off:]) }
+
+	// checkCopy checks a CopyArea of rect r from the back buffer
+	checkCopy := func(req []byte, pixmap uint32, r Rect) {
+		t.Helper()
+		if u32(req, 4) != pixmap || u32(req, 8) != windowID || u32(req, 12) != gcID {
+			t.Errorf("CopyArea from %#x to %#x with GC %#x", u32(req, 4), u32(req, 8), u32(req, 12))
+		}
+		got := Rect{X: u16(req, 16), Y: u16(req, 18), Width: u16(req, 24), Height: u16(req, 26)}
+		if got != r || u16(req, 20) != r.X || u16(req, 22) != r.Y {
+			t.Errorf("CopyArea of %v to (%d, %d), want %v in place", got, u16(req, 20), u16(req, 22), r)
+		}
+	}
+
+	// The first Present creates the pixmap and fills it in full
+	if err := w.putCanvas(); err != nil {
+		t.Fatal(err)
+	}
+	req := nextRequest(t, reqs, x11.OpCreatePixmap)
+	pixmap := u32(req, 4)
+	if pixmap == 0 || u32(req, 8) != windowID || u16(req, 12) != 40 || u16(req, 14) != 30 || req[1] != 24 {
+		t.Errorf("CreatePixmap %#x on %#x, %dx%d depth %d", pixmap, u32(req, 8), u16(req, 12), u16(req, 14), req[1])
+	}
+	if req := nextRequest(t, reqs, x11.OpPutImage); u32(req, 4) != pixmap {
+		t.Errorf("PutImage into %#x, want the back buffer %#x", u32(req, 4), pixmap)
+	}
+	checkCopy(nextRequest(t, reqs, x11.OpCopyArea), pixmap, Rect{Width: 40, Height: 30})
+
+	// Later frames upload the damage and copy it in one request
+	w.canvas.SetPixel(5, 6, Red)
+	w.canvas.SetPixel(10, 3, Red)
+	if err := w.putCanvas(); err != nil {
+		t.Fatal(err)
+	}
+	nextRequest(t, reqs, x11.OpPutImage)
+	nextRequest(t, reqs, x11.OpPutImage)
+	checkCopy(nextRequest(t, reqs, x11.OpCopyArea), pixmap, Rect{X: 5, Y: 3, Width: 6, Height: 4})
+
+	// A resize replaces the pixmap
+	w.width, w.height = 20, 10
+	w.canvas.Resize(20, 10)
+	if err := w.putCanvas(); err != nil {
+		t.Fatal(err)
+	}
+	if req := nextRequest(t, reqs, x11.OpFreePixmap); u32(req, 4) != pixmap {
+		t.Errorf("freed %#x, want the old back buffer %#x", u32(req, 4), pixmap)
+	}
+	req = nextRequest(t, reqs, x11.OpCreatePixmap)
+	resized := u32(req, 4)
+	if resized == pixmap || u16(req, 12) != 20 || u16(req, 14) != 10 {
+		t.Errorf("CreatePixmap %#x, %dx%d after resize", resized, u16(req, 12), u16(req, 14))
+	}
+	nextRequest(t, reqs, x11.OpPutImage)
+	checkCopy(nextRequest(t, reqs, x11.OpCopyArea), resized, Rect{Width: 20, Height: 10})
+
+	// Turning it off frees it and uploads straight to the window
+	w.SetBackBuffer(false)
+	if req := nextRequest(t, reqs, x11.OpFreePixmap); u32(req, 4) != resized {
+		t.Errorf("freed %#x, want %#x", u32(req, 4), resized)
+	}
+	if err := w.putCanvas(); err != nil {
+		t.Fatal(err)
+	}
+	if req := nextRequest(t, reqs, x11.OpPutImage); u32(req, 4) != windowID {
+		t.Errorf("PutImage into %#x without back buffer, want the window", u32(req, 4))
+	}
+	select {
+	case req := <-reqs:
+		t.Errorf("unexpected request with opcode %d", req[0])
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,99 @@
+package glow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+)
+
+// beepVolume is the peak amplitude of a beep, -1.0 to 1.0. Kept well
+// below full scale so feedback sounds don't drown out other audio.
+const beepVolume = 0.25
+
+// Shared context for Beep and Sound, opened on first use
+var (
+	sharedAudioOnce sync.Once
+	sharedAudioCtx  *AudioContext
+	sharedAudioErr  error
+)
+
+// sharedAudio returns the package's default audio context, opening it
+// on the first call.
+func sharedAudio() (*AudioContext, error) {
+	sharedAudioOnce.Do(func() {
+		sharedAudioCtx, sharedAudioErr = NewDefaultAudioContext()
+	})
+	return sharedAudioCtx, sharedAudioErr
+}
+
+// Beep plays a short sine tone at freq Hz for d, for UI and game
+// feedback (a click, a paddle hit). The first call opens an audio
+// context that every later call shares. Beep is best effort: it returns
+// at once and is silent if audio is unavailable.
+func Beep(freq float64, d time.Duration) {
+	if ctx, err := sharedAudio(); err == nil {
+		ctx.Beep(freq, d)
+	}
+}
+
+// Beep plays a short sine tone at freq Hz for d on this context.
+// It returns at once; the tone plays in the background.
+func (ctx *AudioContext) Beep(freq float64, d time.Duration) {
+	pcm := ctx.Tone(freq, d)
+	if len(pcm) == 0 {
+		return
+	}
+	ctx.NewPlayer(bytes.NewReader(pcm)).Play()
+}
+
+// Tone synthesizes a sine tone at freq Hz lasting d, as PCM in the
+// context's sample rate, channel count and format. The volume fades out
+// linearly over the tone so it ends without a click.
+func (ctx *AudioContext) Tone(freq float64, d time.Duration) []byte {
+	rate := ctx.SampleRate()
+	frames := int(d.Seconds() * float64(rate))
+	if frames <= 0 || ctx.Channels() <= 0 {
+		return nil
+	}
+
+	sampleSize := ctx.spec.SampleSize()
+	buf := make([]byte, frames*ctx.FrameSize())
+	off := 0
+	for i := 0; i < frames; i++ {
+		t := float64(i) / float64(rate)
+		env := 1 - float64(i)/float64(frames)
+		v := math.Sin(2*math.Pi*freq*t) * env * beepVolume
+		for ch := 0; ch < ctx.Channels(); ch++ {
+			putSample(buf[off:], ctx.Format(), v)
+			off += sampleSize
+		}
+	}
+	return buf
+}
+
+// putSample encodes v, from -1.0 to 1.0, as one sample in format f.
+func putSample(b []byte, f SampleFormat, v float64) {
+	v = math.Max(-1, math.Min(1, v))
+	var order binary.ByteOrder = binary.LittleEndian
+	if f.BigEndian() {
+		order = binary.BigEndian
+	}
+	switch f {
+	case SampleU8:
+		b[0] = uint8(128 + v*127)
+	case SampleS16LE, SampleS16BE:
+		order.PutUint16(b, uint16(int16(v*math.MaxInt16)))
+	case SampleS24LE:
+		s := int32(v * (1<<23 - 1))
+		b[0], b[1], b[2] = byte(s), byte(s>>8), byte(s>>16)
+	case SampleS24BE:
+		s := int32(v * (1<<23 - 1))
+		b[0], b[1], b[2] = byte(s>>16), byte(s>>8), byte(s)
+	case SampleS32LE, SampleS32BE:
+		order.PutUint32(b, uint32(int32(v*math.MaxInt32)))
+	case SampleFloat32LE, SampleFloat32BE:
+		order.PutUint32(b, math.Float32bits(float32(v)))
+	}
+}
@@ -0,0 +1,87 @@
+package glow
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/pulse"
+)
+
+func TestTone(t *testing.T) {
+	ctx := &AudioContext{spec: pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 8000}}
+	pcm := ctx.Tone(1000, 10*time.Millisecond)
+	if len(pcm) != 80*4 {
+		t.Fatalf("expected 80 stereo frames, got %d bytes", len(pcm))
+	}
+
+	// Two frames in, a 1 kHz sine at 8 kHz is at its positive peak
+	left := int16(binary.LittleEndian.Uint16(pcm[8:]))
+	right := int16(binary.LittleEndian.Uint16(pcm[10:]))
+	if left != right || left <= 0 {
+		t.Errorf("expected matching positive samples, got %d / %d", left, right)
+	}
+	if binary.LittleEndian.Uint16(pcm[0:]) != 0 {
+		t.Errorf("tone should start at zero")
+	}
+}
+
+func TestToneBigEndian(t *testing.T) {
+	le := &AudioContext{spec: pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 1, Rate: 8000}}
+	be := &AudioContext{spec: pulse.SampleSpec{Format: pulse.SampleS16BE, Channels: 1, Rate: 8000}}
+	if be.Format() != SampleS16BE {
+		t.Fatalf("Format() = %v, want SampleS16BE", be.Format())
+	}
+	lePCM := le.Tone(1000, 10*time.Millisecond)
+	bePCM := be.Tone(1000, 10*time.Millisecond)
+	for i := 0; i < len(lePCM); i += 2 {
+		if binary.LittleEndian.Uint16(lePCM[i:]) != binary.BigEndian.Uint16(bePCM[i:]) {
+			t.Fatalf("sample %d differs between byte orders", i/2)
+		}
+	}
+}
+
+func TestSampleFormatNative(t *testing.T) {
+	pairs := [][2]SampleFormat{
+		{SampleS16LE, SampleS16BE},
+		{SampleS24LE, SampleS24BE},
+		{SampleS32LE, SampleS32BE},
+		{SampleFloat32LE, SampleFloat32BE},
+	}
+	for _, p := range pairs {
+		want := p[0]
+		if hostBigEndian {
+			want = p[1]
+		}
+		for _, f := range p {
+			if got := f.Native(); got != want {
+				t.Errorf("%v.Native() = %v, want %v", f, got, want)
+			}
+		}
+	}
+	if SampleU8.Native() != SampleU8 {
+		t.Errorf("SampleU8.Native() = %v", SampleU8.Native())
+	}
+}
+
+func TestBeepReleasesStreams(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	ctx := &AudioContext{
+		conn: pulse.NewConnection(client),
+		spec: pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 8000},
+	}
+	sent := fakePulsePlayback(server)
+
+	// Every paddle hit of a long game gets its stream back
+	const beeps = 20
+	for range beeps {
+		ctx.Beep(440, 5*time.Millisecond)
+	}
+	got := strings.Join(collectPulse(sent, beeps), " ")
+	if creates, deletes := strings.Count(got, "create"), strings.Count(got, "delete"); creates != beeps || deletes != beeps {
+		t.Errorf("%d streams created and %d deleted, want %d of each", creates, deletes, beeps)
+	}
+}
@@ -0,0 +1,93 @@
+package glow
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// decodeBMP decodes an uncompressed 24- or 32-bit Windows BMP. It covers
+// what image editors export by default without pulling in x/image.
+func decodeBMP(r io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 54 || data[0] != 'B' || data[1] != 'M' {
+		return nil, errors.New("bmp: not a BMP file")
+	}
+
+	pixelOffset := int(binary.LittleEndian.Uint32(data[10:14]))
+	headerSize := binary.LittleEndian.Uint32(data[14:18])
+	if headerSize < 40 {
+		return nil, errors.New("bmp: unsupported header version")
+	}
+	width := int(int32(binary.LittleEndian.Uint32(data[18:22])))
+	height := int(int32(binary.LittleEndian.Uint32(data[22:26])))
+	bpp := binary.LittleEndian.Uint16(data[28:30])
+	compression := binary.LittleEndian.Uint32(data[30:34])
+
+	// BI_RGB, or BI_BITFIELDS with the usual BGRA masks for 32-bit
+	if compression != 0 && !(compression == 3 && bpp == 32) {
+		return nil, errors.New("bmp: compressed images are not supported")
+	}
+	if bpp != 24 && bpp != 32 {
+		return nil, errors.New("bmp: only 24- and 32-bit images are supported")
+	}
+
+	// Positive height means rows are stored bottom-up
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("bmp: invalid dimensions")
+	}
+	// Same cap as sprite files; it also keeps the sizes below from
+	// overflowing
+	if width > maxSpriteSize || height > maxSpriteSize {
+		return nil, errors.New("bmp: image too large")
+	}
+	if pixelOffset < 54 || pixelOffset > len(data) {
+		return nil, errors.New("bmp: invalid pixel data offset")
+	}
+
+	bytesPerPixel := int(bpp) / 8
+	stride := (width*bytesPerPixel + 3) &^ 3
+	if stride > (len(data)-pixelOffset)/height {
+		return nil, errors.New("bmp: pixel data truncated")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		srcY := height - 1 - y
+		if topDown {
+			srcY = y
+		}
+		src := data[pixelOffset+srcY*stride:]
+		dst := img.Pix[y*img.Stride:]
+		for x := 0; x < width; x++ {
+			s := src[x*bytesPerPixel:]
+			dst[x*4] = s[2]   // R
+			dst[x*4+1] = s[1] // G
+			dst[x*4+2] = s[0] // B
+			dst[x*4+3] = 255
+			if bytesPerPixel == 4 {
+				dst[x*4+3] = s[3]
+				hasAlpha = hasAlpha || s[3] != 0
+			}
+		}
+	}
+
+	// Many writers leave the fourth byte of 32-bit pixels zeroed;
+	// treat an all-zero alpha channel as fully opaque
+	if bpp == 32 && !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+	}
+
+	return img, nil
+}
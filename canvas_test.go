@@ -0,0 +1,402 @@
+package glow
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestCanvasYUp(t *testing.T) {
+	fb := x11.NewFramebuffer(8, 8)
+	c := &Canvas{fb: fb}
+	c.SetYUp(true)
+
+	c.SetPixel(1, 0, Red)
+	assertFBPixel(t, fb, 1, 7, 255, 0, 0)
+	if got := c.GetPixel(1, 0); got != Red {
+		t.Errorf("GetPixel(1, 0) = %v, want red", got)
+	}
+
+	// Rectangles are anchored at their bottom-left corner
+	c.DrawRect(4, 0, 2, 3, Green)
+	assertFBPixel(t, fb, 4, 7, 0, 255, 0)
+	assertFBPixel(t, fb, 5, 5, 0, 255, 0)
+	assertFBPixel(t, fb, 4, 4, 0, 0, 0)
+
+	// So are sprites, which stay upright
+	s := makeOpaqueRedSprite(2, 2)
+	s.data.Pixels[0], s.data.Pixels[2] = 255, 0 // top-left pixel blue
+	c.DrawSprite(s, 0, 6)
+	assertFBPixel(t, fb, 0, 0, 0, 0, 255)
+	assertFBPixel(t, fb, 0, 1, 255, 0, 0)
+}
+
+func TestVirtualSizeScroll(t *testing.T) {
+	w := &Window{width: 4, height: 3, canvas: &Canvas{fb: x11.NewFramebuffer(4, 3)}}
+	w.SetVirtualSize(10, 8)
+	if w.canvas.Width() != 10 || w.canvas.Height() != 8 {
+		t.Fatalf("canvas is %dx%d, want 10x8", w.canvas.Width(), w.canvas.Height())
+	}
+
+	w.canvas.SetPixel(5, 2, Red)
+	w.SetScroll(5, 2)
+	r := w.visibleRegion()
+	if r != (Rect{X: 5, Y: 2, Width: 4, Height: 3}) {
+		t.Fatalf("unexpected visible region %+v", r)
+	}
+	if px := w.viewPixels(r); len(px) != 4*3*4 || px[2] != 255 {
+		t.Errorf("view should start with the red pixel, got %v", px[:4])
+	}
+
+	// Scrolling past the edge is clamped
+	w.SetScroll(100, -5)
+	if x, y := w.Scroll(); x != 6 || y != 0 {
+		t.Errorf("scroll clamped to (%d, %d), want (6, 0)", x, y)
+	}
+}
+
+func TestCanvasAsImage(t *testing.T) {
+	fb := x11.NewFramebuffer(4, 3)
+	c := &Canvas{fb: fb}
+	c.SetPixel(1, 2, RGB(10, 20, 30))
+
+	img := c.AsImage()
+	if img.Bounds() != image.Rect(0, 0, 4, 3) {
+		t.Fatalf("unexpected bounds %v", img.Bounds())
+	}
+	if got := img.At(1, 2); got != (color.RGBA{10, 20, 30, 255}) {
+		t.Errorf("At(1, 2) = %v", got)
+	}
+
+	// Draw into the canvas through image/draw
+	dst := img.(draw.Image)
+	draw.Draw(dst, image.Rect(2, 0, 4, 1), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	assertFBPixel(t, fb, 3, 0, 0, 0, 255)
+}
+
+func TestSoftwareCursorLeavesCanvasUntouched(t *testing.T) {
+	fb := x11.NewFramebuffer(8, 8)
+	fb.Clear(0, 0, 255)
+	w := &Window{width: 8, height: 8, canvas: &Canvas{fb: fb}}
+	w.SetSoftwareCursor(makeOpaqueRedSprite(3, 3), 1, 1)
+
+	// No pointer position yet: nothing to draw
+	if restore := w.drawSoftwareCursor(); restore != nil {
+		t.Fatal("cursor drawn before any mouse event")
+	}
+
+	w.trackInput(&Event{Type: EventMouseMotion, X: 7, Y: 7})
+	restore := w.drawSoftwareCursor()
+	if restore == nil {
+		t.Fatal("expected the cursor to be drawn")
+	}
+	// Hotspot (1, 1) at the pointer, clipped at the edge
+	assertFBPixel(t, fb, 6, 6, 255, 0, 0)
+	assertFBPixel(t, fb, 7, 7, 255, 0, 0)
+	assertFBPixel(t, fb, 5, 5, 0, 0, 255)
+
+	restore()
+	assertFBPixel(t, fb, 6, 6, 0, 0, 255)
+	assertFBPixel(t, fb, 7, 7, 0, 0, 255)
+}
+
+func TestSetPixelAlpha(t *testing.T) {
+	c := NewCanvas(2, 1)
+	c.Clear(White)
+
+	// 50% red over white, like TestAlphaBlending
+	c.SetPixel(0, 0, RGBA(255, 0, 0, 128))
+	got := c.GetPixel(0, 0)
+	if got.R != 255 || got.G < 126 || got.G > 128 || got.B < 126 || got.B > 128 {
+		t.Errorf("blended pixel = %v, want ~(255,127,127)", got)
+	}
+	if got.A != 255 {
+		t.Errorf("GetPixel alpha = %d, want 255", got.A)
+	}
+
+	// Transparent draws nothing, opaque replaces
+	c.SetPixel(1, 0, RGBA(0, 0, 0, 0))
+	assertFBPixel(t, c.fb, 1, 0, 255, 255, 255)
+	c.SetPixel(1, 0, RGB(10, 20, 30))
+	assertFBPixel(t, c.fb, 1, 0, 10, 20, 30)
+
+	if Red.A != 255 || Hex(0x123456).A != 255 || RGB(1, 2, 3).A != 255 {
+		t.Error("predefined and constructed colors must be opaque")
+	}
+}
+
+func TestColorAlphaConsistent(t *testing.T) {
+	// A keyed literal has A 0: no method draws it
+	keyed := Color{R: 255}
+	c, white := NewCanvas(8, 8), NewCanvas(8, 8)
+	c.Clear(White)
+	white.Clear(White)
+	c.SetPixel(0, 0, keyed)
+	c.DrawRect(2, 2, 3, 3, keyed)
+	c.FillCircle(5, 5, 2, keyed)
+	c.DrawLine(0, 7, 7, 7, keyed)
+	if !c.Equal(white) {
+		t.Error("Color{R: 255} drew something")
+	}
+
+	// Translucent shapes blend like SetPixel does
+	half := RGBA(255, 0, 0, 128)
+	c.SetPixel(0, 0, half)
+	c.DrawRect(2, 2, 3, 3, half)
+	c.DrawLine(0, 7, 7, 7, half)
+	for _, p := range [][2]int{{0, 0}, {3, 3}, {4, 7}} {
+		got := c.GetPixel(p[0], p[1])
+		if got.R != 255 || got.G < 126 || got.G > 128 || got.B < 126 || got.B > 128 {
+			t.Errorf("pixel %v = %v, want ~(255,127,127)", p, got)
+		}
+	}
+
+	// An opaque shape afterwards still replaces
+	c.DrawRect(2, 2, 3, 3, Blue)
+	assertFBPixel(t, c.fb, 3, 3, 0, 0, 255)
+}
+
+func TestCanvasEncodePNG(t *testing.T) {
+	c := NewCanvas(5, 3)
+	c.Clear(Gray)
+	c.SetPixel(0, 0, Red)
+	c.SetPixel(4, 2, RGB(12, 34, 56))
+	c.SetPixel(2, 1, White)
+
+	var buf bytes.Buffer
+	if err := c.EncodePNG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadPNGFromReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Width() != 5 || s.Height() != 3 {
+		t.Fatalf("decoded %dx%d, want 5x3", s.Width(), s.Height())
+	}
+	assertPixel(t, s, 0, 0, 0, 0, 255, 255)
+	assertPixel(t, s, 4, 2, 56, 34, 12, 255)
+	assertPixel(t, s, 2, 1, 255, 255, 255, 255)
+	assertPixel(t, s, 1, 1, 128, 128, 128, 255)
+}
+
+func TestCanvasSavePNG(t *testing.T) {
+	c := NewCanvas(2, 2)
+	c.SetPixel(1, 1, Blue)
+	path := filepath.Join(t.TempDir(), "out.png")
+	if err := c.SavePNG(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixel(t, s, 1, 1, 255, 0, 0, 255)
+}
+
+func TestWindowResizeEventResizesCanvas(t *testing.T) {
+	w := &Window{width: 4, height: 3, canvas: NewCanvas(4, 3), eventChan: make(chan Event, 8)}
+	w.canvas.SetPixel(1, 1, Red)
+	w.canvas.SetPixel(3, 2, Green)
+
+	w.eventChan <- Event{Type: EventWindowResize, Width: 10, Height: 8}
+	if e := w.PollEvent(); e == nil || e.Type != EventWindowResize {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	c := w.Canvas()
+	if c.Width() != 10 || c.Height() != 8 {
+		t.Fatalf("canvas is %dx%d, want 10x8", c.Width(), c.Height())
+	}
+	c.SetPixel(9, 7, Blue)
+	if got := c.GetPixel(9, 7); got != Blue {
+		t.Errorf("far corner = %v, want blue", got)
+	}
+	// Existing drawing survives
+	if c.GetPixel(1, 1) != Red || c.GetPixel(3, 2) != Green {
+		t.Error("resize lost the old content")
+	}
+
+	// Shrinking keeps the overlap too
+	w.eventChan <- Event{Type: EventWindowResize, Width: 2, Height: 2}
+	w.PollEvent()
+	if c.Width() != 2 || c.GetPixel(1, 1) != Red {
+		t.Errorf("after shrinking: %dx%d, (1,1) = %v", c.Width(), c.Height(), c.GetPixel(1, 1))
+	}
+}
+
+func TestDrawGlow(t *testing.T) {
+	c := NewCanvas(20, 20)
+	c.DrawGlow(10, 10, 5, RGB(200, 100, 0))
+
+	if got := c.GetPixel(10, 10); got != RGB(200, 100, 0) {
+		t.Errorf("centre = %+v, want the core color", got)
+	}
+	edge := c.GetPixel(14, 10)
+	if edge.R == 0 || edge.R >= 100 || edge.B != 0 {
+		t.Errorf("near the edge = %+v, want a dim glow", edge)
+	}
+	if got := c.GetPixel(15, 10); got != Black {
+		t.Errorf("at radius = %+v, want untouched", got)
+	}
+
+	// A second glow adds to the first, saturating
+	c.DrawGlow(10, 10, 5, RGB(200, 100, 0))
+	if got := c.GetPixel(10, 10); got != RGB(255, 200, 0) {
+		t.Errorf("overlapping centre = %+v, want (255, 200, 0)", got)
+	}
+	if got := c.GetPixel(14, 10); got.R != 2*edge.R {
+		t.Errorf("overlapping edge R = %d, want %d", got.R, 2*edge.R)
+	}
+
+	// Half alpha halves the intensity; glows off the canvas are clipped
+	c = NewCanvas(20, 20)
+	c.DrawGlow(0, 0, 8, RGBA(200, 100, 0, 128))
+	if got := c.GetPixel(0, 0); got != RGB(100, 50, 0) {
+		t.Errorf("half-alpha centre = %+v, want (100, 50, 0)", got)
+	}
+}
+
+func TestClearAlpha(t *testing.T) {
+	c := NewCanvas(4, 4)
+	c.Clear(RGBA(10, 20, 30, 0)) // Color alpha doesn't matter
+	if p := c.fb.Pixels[0:4]; p[0] != 30 || p[1] != 20 || p[2] != 10 || p[3] != 255 {
+		t.Errorf("Clear wrote BGRA %v, want [30 20 10 255]", p)
+	}
+
+	c.ClearRGBA(Red, 128)
+	for i := 0; i < len(c.fb.Pixels); i += 4 {
+		if c.fb.Pixels[i+2] != 255 || c.fb.Pixels[i+3] != 128 {
+			t.Fatalf("ClearRGBA wrote BGRA %v at %d", c.fb.Pixels[i:i+4], i/4)
+		}
+	}
+
+	c.ClearRGBA(Blue, 0)
+	if c.fb.Pixels[3] != 0 || c.GetPixel(3, 3) != Blue {
+		t.Errorf("ClearRGBA with alpha 0 wrote BGRA %v", c.fb.Pixels[0:4])
+	}
+
+	// Shapes drawn over a clear are opaque too, even on a transparent
+	// background
+	c.DrawRect(1, 1, 2, 2, Red)
+	c.SetPixel(0, 3, Red)
+	for _, p := range [][2]int{{1, 1}, {2, 2}, {0, 3}} {
+		if a := c.fb.Pixels[(p[1]*4+p[0])*4+3]; a != 255 {
+			t.Errorf("pixel %v drawn with alpha %d, want 255", p, a)
+		}
+	}
+	if a := c.fb.Pixels[(3*4+3)*4+3]; a != 0 {
+		t.Errorf("undrawn pixel has alpha %d, want the clear's 0", a)
+	}
+}
+
+func TestDrawOverTransparentClear(t *testing.T) {
+	alpha := func(c *Canvas, x, y int) uint8 { return c.fb.Pixels[(y*c.fb.Width+x)*4+3] }
+
+	c := NewCanvas(16, 16)
+	c.ClearRGBA(Black, 0)
+	s := newBlankSprite(2, 1)
+	s.setPixel(0, 0, Red, 255)
+	s.setPixel(1, 0, Red, 128)
+	c.DrawSprite(s, 1, 1)
+	if a := alpha(c, 1, 1); a != 255 {
+		t.Errorf("opaque sprite pixel has alpha %d, want 255", a)
+	}
+	if a := alpha(c, 2, 1); a != 128 {
+		t.Errorf("half-transparent sprite pixel has alpha %d, want 128", a)
+	}
+	c.DrawSpriteScaled(s, 0, 4, 4, 2)
+	if a := alpha(c, 0, 4); a != 255 {
+		t.Errorf("scaled sprite pixel has alpha %d, want 255", a)
+	}
+
+	c.ClearRGBA(Black, 0)
+	c.DrawGlow(8, 8, 6, White)
+	if a := alpha(c, 8, 8); a != 255 {
+		t.Errorf("glow centre has alpha %d, want 255", a)
+	}
+	if a := alpha(c, 11, 8); a == 0 || a == 255 {
+		t.Errorf("glow edge has alpha %d, want partial", a)
+	}
+	if a := alpha(c, 0, 0); a != 0 {
+		t.Errorf("pixel outside the glow has alpha %d, want 0", a)
+	}
+
+	// Blended shapes composite their alpha over what is there
+	c.ClearRGBA(Black, 0)
+	c.SetAntialias(true)
+	c.FillCircle(8, 8, 4, White)
+	if a := alpha(c, 8, 8); a != 255 {
+		t.Errorf("antialiased disc centre has alpha %d, want 255", a)
+	}
+	c.fb.BlendPixel(0, 0, 255, 255, 255, 128)
+	c.fb.BlendPixel(0, 0, 255, 255, 255, 128)
+	if a := alpha(c, 0, 0); a != 191 {
+		t.Errorf("two half blends give alpha %d, want 191", a)
+	}
+}
+
+func TestClipRect(t *testing.T) {
+	c := NewCanvas(20, 20)
+	c.SetClipRect(5, 5, 10, 8)
+	if got := c.ClipRect(); got != (Rect{X: 5, Y: 5, Width: 10, Height: 8}) {
+		t.Fatalf("ClipRect = %+v", got)
+	}
+
+	c.DrawRect(0, 0, 20, 20, Red)
+	c.FillCircle(10, 10, 30, Red)
+	c.SetPixel(0, 0, Red)
+	c.FillTriangle(-5, -5, 40, -5, -5, 40, Red)
+	c.DrawSprite(makeOpaqueRedSprite(20, 20), 0, 0)
+	c.DrawSpriteScaled(makeOpaqueRedSprite(2, 2), 0, 0, 20, 20)
+	c.DrawLine(0, 19, 19, 0, Red)
+	c.DrawGlow(10, 10, 30, Red)
+	c.DrawText(0, 0, "XXXXXXXXXX", Red, 2)
+
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			inside := x >= 5 && x < 15 && y >= 5 && y < 13
+			if got := c.GetPixel(x, y) != Black; got != inside {
+				t.Fatalf("pixel (%d, %d) drawn = %v, want %v", x, y, got, inside)
+			}
+		}
+	}
+
+	// Copies only write inside the clip
+	c.CopyRegion(5, 5, 10, 8, 0, 0)
+	if c.GetPixel(2, 2) != Black || c.GetPixel(6, 6) == Black {
+		t.Error("CopyRegion wrote outside the clip")
+	}
+
+	// A clip hanging off the canvas is cut to it
+	c.SetClipRect(15, 15, 100, 100)
+	if got := c.ClipRect(); got != (Rect{X: 15, Y: 15, Width: 5, Height: 5}) {
+		t.Errorf("ClipRect off the edge = %+v", got)
+	}
+
+	c.ClearClip()
+	c.SetPixel(0, 0, Blue)
+	if c.GetPixel(0, 0) != Blue {
+		t.Error("ClearClip didn't lift the clip")
+	}
+	if got := c.ClipRect(); got != (Rect{Width: 20, Height: 20}) {
+		t.Errorf("ClipRect without a clip = %+v", got)
+	}
+}
+
+func TestClipRectYUp(t *testing.T) {
+	c := NewCanvas(10, 10)
+	c.SetYUp(true)
+	c.SetClipRect(0, 0, 10, 2) // Bottom two rows
+	c.DrawRect(0, 0, 10, 10, Red)
+	if c.GetPixel(5, 1) != Red || c.GetPixel(5, 2) != Black {
+		t.Error("Y-up clip doesn't cover the bottom two rows")
+	}
+	if got := c.ClipRect(); got != (Rect{Width: 10, Height: 2}) {
+		t.Errorf("ClipRect = %+v", got)
+	}
+}
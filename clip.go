@@ -0,0 +1,31 @@
+package glow
+
+// SetClipRect restricts drawing to the width×height rectangle at (x, y):
+// every drawing method, from SetPixel to sprite blits and text, leaves
+// the canvas outside it untouched. Use it to keep a panel's contents
+// from bleeding onto its neighbours:
+//
+//	canvas.SetClipRect(0, 0, 200, 40)
+//	drawToolbar(canvas)
+//	canvas.ClearClip()
+//
+// Parts of the rectangle outside the canvas are ignored. A new clip
+// replaces the old one rather than nesting in it. Clear still fills the
+// whole canvas. The rectangle is converted to framebuffer rows when set,
+// so set it after SetYUp.
+func (c *Canvas) SetClipRect(x, y, width, height int) {
+	c.fb.SetClip(x, c.flipBox(y, height), width, height)
+}
+
+// ClearClip removes the clip rectangle, letting drawing reach the whole
+// canvas again.
+func (c *Canvas) ClearClip() {
+	c.fb.ClearClip()
+}
+
+// ClipRect returns the area drawing is restricted to: the clip
+// rectangle within the canvas, or the whole canvas if none is set.
+func (c *Canvas) ClipRect() Rect {
+	x, y, w, h := c.fb.Clip()
+	return Rect{X: x, Y: c.flipBox(y, h), Width: w, Height: h}
+}
@@ -0,0 +1,67 @@
+package glow
+
+import "time"
+
+// DefaultMaxDelta is the longest step, in seconds, a Clock's Tick
+// returns unless changed with SetMaxDelta.
+const DefaultMaxDelta = 0.25
+
+// Clock measures the time between frames for frame-rate independent
+// motion:
+//
+//	clock := glow.NewClock()
+//	for running {
+//		dt := clock.Tick()
+//		x += speed * dt // speed in pixels per second
+//		...
+//	}
+//
+// A step longer than the maximum, after the window was dragged or the
+// process stopped in a debugger, is cut short so objects don't jump
+// through walls. Pair it with a FrameLimiter or SetMaxFPS to keep the
+// steps even.
+type Clock struct {
+	now      func() time.Time
+	last     time.Time
+	elapsed  float64
+	maxDelta float64
+}
+
+// NewClock returns a clock whose first Tick measures from now.
+func NewClock() *Clock {
+	return NewClockFunc(time.Now)
+}
+
+// NewClockFunc returns a clock reading the time from now instead of the
+// system clock, for tests, replays and fixed-step tools.
+func NewClockFunc(now func() time.Time) *Clock {
+	return &Clock{now: now, last: now(), maxDelta: DefaultMaxDelta}
+}
+
+// SetMaxDelta sets the longest step Tick returns, in seconds. 0 or less
+// removes the limit.
+func (c *Clock) SetMaxDelta(seconds float64) {
+	c.maxDelta = seconds
+}
+
+// Tick returns the seconds since the previous Tick (or since the clock
+// was created), at most the maximum step.
+func (c *Clock) Tick() float64 {
+	now := c.now()
+	dt := now.Sub(c.last).Seconds()
+	c.last = now
+	if dt < 0 {
+		dt = 0
+	}
+	if c.maxDelta > 0 && dt > c.maxDelta {
+		dt = c.maxDelta
+	}
+	c.elapsed += dt
+	return dt
+}
+
+// Elapsed returns the total of the steps Tick has returned: the game
+// time, which leaves out what was cut from overlong steps.
+func (c *Clock) Elapsed() float64 {
+	return c.elapsed
+}
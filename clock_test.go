@@ -0,0 +1,39 @@
+package glow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewClockFunc(func() time.Time { return now })
+
+	step := func(d time.Duration, want float64) {
+		t.Helper()
+		now = now.Add(d)
+		if got := c.Tick(); got != want {
+			t.Errorf("Tick after %v = %v, want %v", d, got, want)
+		}
+	}
+	step(16*time.Millisecond, 0.016)
+	step(0, 0)
+	step(100*time.Millisecond, 0.1)
+	if got := c.Elapsed(); got != 0.116 {
+		t.Errorf("Elapsed = %v, want 0.116", got)
+	}
+
+	// A pause is cut to the maximum step, and left out of Elapsed
+	step(5*time.Second, DefaultMaxDelta)
+	if got := c.Elapsed(); got != 0.116+DefaultMaxDelta {
+		t.Errorf("Elapsed after pause = %v, want %v", got, 0.116+DefaultMaxDelta)
+	}
+
+	// The clock going backwards gives no step
+	step(-time.Second, 0)
+
+	c.SetMaxDelta(0)
+	step(5*time.Second, 5)
+	c.SetMaxDelta(0.05)
+	step(time.Second, 0.05)
+}
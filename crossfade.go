@@ -0,0 +1,57 @@
+package glow
+
+import (
+	"log"
+	"time"
+)
+
+// crossFadeStep is how often CrossFade updates the volumes.
+const crossFadeStep = 20 * time.Millisecond
+
+// CrossFade fades from out and to in over d, for seamless music
+// transitions: from's volume ramps down to 0 and to's ramps up from 0 to
+// the volume it had when CrossFade was called. It returns at once; the
+// fade runs on its own goroutine. to is silenced immediately, so it may
+// be started with Play before or after the call.
+//
+// Either player may finish, or fail, before the fade completes: a player
+// whose volume can no longer be set is left alone and the other one
+// keeps fading. Either player may be nil.
+func CrossFade(from, to *AudioPlayer, d time.Duration) {
+	var fromStart, toTarget float64
+	if from != nil {
+		fromStart = from.Volume()
+	}
+	if to != nil {
+		toTarget = to.Volume()
+		to.SetVolume(0)
+	}
+
+	go func() {
+		ticker := time.NewTicker(crossFadeStep)
+		defer ticker.Stop()
+		start := time.Now()
+		for from != nil || to != nil {
+			t := 1.0
+			if d > 0 {
+				t = min(float64(time.Since(start))/float64(d), 1)
+			}
+			if from != nil {
+				if err := from.SetVolume(fromStart * (1 - t)); err != nil {
+					log.Printf("glow audio: crossfade: %v", err)
+					from = nil
+				}
+			}
+			if to != nil {
+				if err := to.SetVolume(toTarget * t); err != nil {
+					log.Printf("glow audio: crossfade: %v", err)
+					to = nil
+				}
+			}
+			if t >= 1 {
+				return
+			}
+			<-ticker.C
+		}
+	}()
+}
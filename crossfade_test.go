@@ -0,0 +1,25 @@
+package glow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCrossFadeRampsVolumes(t *testing.T) {
+	ctx := &AudioContext{}
+	from, to := ctx.NewPlayer(nil), ctx.NewPlayer(nil)
+	to.SetVolume(0.8)
+
+	CrossFade(from, to, 40*time.Millisecond)
+	if v := to.Volume(); v > 0.8 {
+		t.Fatalf("to starts at volume %v", v)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for from.Volume() != 0 || to.Volume() != 0.8 {
+		if time.Now().After(deadline) {
+			t.Fatalf("fade ended at from=%v to=%v, want 0 and 0.8", from.Volume(), to.Volume())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
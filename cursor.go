@@ -0,0 +1,253 @@
+package glow
+
+import "fmt"
+
+// softCursor is a cursor sprite composited over each presented frame
+type softCursor struct {
+	sprite     *Sprite
+	hotX, hotY int
+	saved      []byte
+}
+
+// SetSoftwareCursor draws s at the mouse position on every Present, on
+// top of the frame, with (hotX, hotY) in the sprite placed at the
+// pointer. The cursor is composited only into what is sent to the
+// screen: the canvas is left untouched, so GetPixel, snapshots and
+// DirtyRegions never see it. Pass nil to remove it.
+//
+// The position comes from the mouse events the app has read, so the
+// cursor follows the pointer once per frame. Pair it with HideCursor
+// for fullscreen games.
+func (w *Window) SetSoftwareCursor(s *Sprite, hotX, hotY int) {
+	if s == nil {
+		w.cursor = nil
+		return
+	}
+	w.cursor = &softCursor{sprite: s, hotX: hotX, hotY: hotY}
+}
+
+// drawSoftwareCursor blits the software cursor into the framebuffer and
+// returns a function that puts back the pixels it covered, or nil if
+// there is nothing to draw.
+func (w *Window) drawSoftwareCursor() (restore func()) {
+	cur := w.cursor
+	if cur == nil || !w.mouse.seen {
+		return nil
+	}
+
+	fb := w.canvas.fb
+	x := w.mouse.X + w.scrollX - cur.hotX
+	y := w.mouse.Y + w.scrollY - cur.hotY
+	r := Rect{X: x, Y: y, Width: cur.sprite.Width(), Height: cur.sprite.Height()}.
+		Intersect(Rect{Width: fb.Width, Height: fb.Height})
+	if r.Empty() {
+		return nil
+	}
+
+	rowBytes := r.Width * 4
+	if cap(cur.saved) < rowBytes*r.Height {
+		cur.saved = make([]byte, rowBytes*r.Height)
+	}
+	saved := cur.saved[:rowBytes*r.Height]
+	for row := 0; row < r.Height; row++ {
+		off := ((r.Y+row)*fb.Width + r.X) * 4
+		copy(saved[row*rowBytes:(row+1)*rowBytes], fb.Pixels[off:])
+	}
+
+	// The cursor goes over everything, whatever the app's clip
+	if fb.HasClip() {
+		cx, cy, cw, ch := fb.Clip()
+		fb.ClearClip()
+		defer fb.SetClip(cx, cy, cw, ch)
+	}
+	fb.BlitSprite(cur.sprite.data, x, y)
+
+	return func() {
+		for row := 0; row < r.Height; row++ {
+			off := ((r.Y+row)*fb.Width + r.X) * 4
+			copy(fb.Pixels[off:off+rowBytes], saved[row*rowBytes:])
+		}
+	}
+}
+
+// CursorShape is a system mouse cursor, drawn by the X server (see
+// SetCursor).
+type CursorShape int
+
+const (
+	CursorDefault    CursorShape = iota // The desktop's default cursor
+	CursorArrow                         // Plain pointer
+	CursorText                          // I-beam, for text fields
+	CursorHand                          // Pointing hand, for links and buttons
+	CursorCrosshair                     // Precise selection
+	CursorMove                          // Four-way arrow, for moving and panning
+	CursorResizeEW                      // Left-right arrow, for vertical edges
+	CursorResizeNS                      // Up-down arrow, for horizontal edges
+	CursorResizeNWSE                    // Top-left and bottom-right corners
+	CursorResizeNESW                    // Top-right and bottom-left corners
+	CursorWait                          // Busy
+	CursorNotAllowed                    // The action isn't possible here
+)
+
+// cursorGlyphs maps shapes to glyphs of the X cursor font
+// (X11/cursorfont.h)
+var cursorGlyphs = [...]uint16{
+	CursorArrow:      68,  // XC_left_ptr
+	CursorText:       152, // XC_xterm
+	CursorHand:       60,  // XC_hand2
+	CursorCrosshair:  34,  // XC_crosshair
+	CursorMove:       52,  // XC_fleur
+	CursorResizeEW:   108, // XC_sb_h_double_arrow
+	CursorResizeNS:   116, // XC_sb_v_double_arrow
+	CursorResizeNWSE: 134, // XC_top_left_corner
+	CursorResizeNESW: 136, // XC_top_right_corner
+	CursorWait:       150, // XC_watch
+	CursorNotAllowed: 0,   // XC_X_cursor
+}
+
+// savedCursor is an entry of the PushCursor stack
+type savedCursor struct {
+	shape CursorShape
+	soft  *softCursor
+}
+
+// SetCursor sets the system cursor shown over the window. While the
+// cursor is hidden, the shape shows once ShowCursor is called.
+func (w *Window) SetCursor(shape CursorShape) error {
+	if shape < 0 || int(shape) >= len(cursorGlyphs) {
+		return fmt.Errorf("glow: unknown cursor shape %d", shape)
+	}
+	if shape == w.cursorShape {
+		return nil
+	}
+
+	id, err := w.shapeCursor(shape)
+	if err != nil {
+		return err
+	}
+	if !w.cursorHidden {
+		if err := w.conn.SetWindowCursor(w.windowID, id); err != nil {
+			return err
+		}
+	}
+	w.cursorShape = shape
+	return nil
+}
+
+// shapeCursor returns the server cursor for shape, creating it on first
+// use, or 0 (None: the parent's cursor) for CursorDefault.
+func (w *Window) shapeCursor(shape CursorShape) (uint32, error) {
+	if shape == CursorDefault {
+		return 0, nil
+	}
+	if id := w.cursorIDs[shape]; id != 0 {
+		return id, nil
+	}
+	id, err := w.conn.CreateFontCursor(cursorGlyphs[shape])
+	if err != nil {
+		return 0, err
+	}
+	if w.cursorIDs == nil {
+		w.cursorIDs = make(map[CursorShape]uint32)
+	}
+	w.cursorIDs[shape] = id
+	return id, nil
+}
+
+// HideCursor hides the system cursor while the pointer is over the
+// window, for games drawing their own (see SetSoftwareCursor) or using
+// mouselook (see WarpMouse).
+func (w *Window) HideCursor() error {
+	if w.cursorHidden {
+		return nil
+	}
+	if w.blankCursor == 0 {
+		id, err := w.conn.CreateBlankCursor(w.windowID)
+		if err != nil {
+			return err
+		}
+		w.blankCursor = id
+	}
+	if err := w.conn.SetWindowCursor(w.windowID, w.blankCursor); err != nil {
+		return err
+	}
+	w.cursorHidden = true
+	return nil
+}
+
+// ShowCursor shows the system cursor hidden by HideCursor again, with
+// the shape set by SetCursor.
+func (w *Window) ShowCursor() error {
+	if !w.cursorHidden {
+		return nil
+	}
+	id, err := w.shapeCursor(w.cursorShape)
+	if err != nil {
+		return err
+	}
+	if err := w.conn.SetWindowCursor(w.windowID, id); err != nil {
+		return err
+	}
+	w.cursorHidden = false
+	return nil
+}
+
+// CursorHidden reports whether the system cursor is hidden.
+func (w *Window) CursorHidden() bool { return w.cursorHidden }
+
+// WarpMouse moves the pointer to (x, y) in the window, as for mouselook,
+// which recenters the pointer every frame and reads how far it moved.
+// The move is reported like any other, with an EventMouseMotion.
+func (w *Window) WarpMouse(x, y int) error {
+	return w.conn.WarpPointer(w.windowID, int16(x), int16(y))
+}
+
+// Cursor returns the system cursor set with SetCursor.
+func (w *Window) Cursor() CursorShape { return w.cursorShape }
+
+// PushCursor switches to shape until the matching PopCursor, for
+// temporary cursors such as a resize arrow during a drag:
+//
+//	win.PushCursor(glow.CursorResizeEW)
+//	... drag ...
+//	win.PopCursor()
+//
+// A software cursor (SetSoftwareCursor) is put aside until then, so
+// the system cursor shows.
+func (w *Window) PushCursor(shape CursorShape) error {
+	saved := savedCursor{shape: w.cursorShape, soft: w.cursor}
+	w.cursor = nil
+	if err := w.SetCursor(shape); err != nil {
+		w.cursor = saved.soft
+		return err
+	}
+	w.cursorStack = append(w.cursorStack, saved)
+	return nil
+}
+
+// PopCursor restores the cursor in use before the last PushCursor,
+// system shape and software cursor alike. It does nothing if the stack
+// is empty.
+func (w *Window) PopCursor() error {
+	if len(w.cursorStack) == 0 {
+		return nil
+	}
+	saved := w.cursorStack[len(w.cursorStack)-1]
+	w.cursorStack = w.cursorStack[:len(w.cursorStack)-1]
+	w.cursor = saved.soft
+	return w.SetCursor(saved.shape)
+}
+
+// freeCursors shows the cursor if it was hidden and frees the system
+// cursors created by SetCursor and HideCursor.
+func (w *Window) freeCursors() {
+	w.ShowCursor()
+	for _, id := range w.cursorIDs {
+		w.conn.FreeCursor(id)
+	}
+	w.cursorIDs = nil
+	if w.blankCursor != 0 {
+		w.conn.FreeCursor(w.blankCursor)
+		w.blankCursor = 0
+	}
+}
@@ -0,0 +1,133 @@
+package glow
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestCursorStack(t *testing.T) {
+	w := &Window{}
+	soft := makeOpaqueRedSprite(4, 4)
+	w.SetSoftwareCursor(soft, 1, 2)
+	custom := w.cursor
+
+	// CursorDefault needs no server round trip
+	if err := w.PushCursor(CursorDefault); err != nil {
+		t.Fatalf("PushCursor: %v", err)
+	}
+	if w.cursor != nil {
+		t.Error("software cursor still set while a shape is pushed")
+	}
+	if err := w.PushCursor(CursorDefault); err != nil {
+		t.Fatalf("nested PushCursor: %v", err)
+	}
+
+	if err := w.PopCursor(); err != nil {
+		t.Fatalf("PopCursor: %v", err)
+	}
+	if w.cursor != nil {
+		t.Error("inner pop restored the software cursor too early")
+	}
+	if err := w.PopCursor(); err != nil {
+		t.Fatalf("PopCursor: %v", err)
+	}
+	if w.cursor != custom {
+		t.Error("outer pop didn't restore the original software cursor")
+	}
+	if w.cursor.hotX != 1 || w.cursor.hotY != 2 {
+		t.Errorf("hotspot = (%d, %d), want (1, 2)", w.cursor.hotX, w.cursor.hotY)
+	}
+
+	// An empty stack pops to nothing
+	if err := w.PopCursor(); err != nil || w.cursor != custom {
+		t.Errorf("PopCursor on empty stack = %v, cursor changed %v", err, w.cursor != custom)
+	}
+}
+
+func TestCursorUnknownShape(t *testing.T) {
+	w := &Window{}
+	w.SetSoftwareCursor(makeOpaqueRedSprite(2, 2), 0, 0)
+	soft := w.cursor
+
+	if err := w.SetCursor(CursorShape(99)); err == nil {
+		t.Error("SetCursor accepted an unknown shape")
+	}
+	if err := w.PushCursor(-1); err == nil {
+		t.Error("PushCursor accepted an unknown shape")
+	}
+	if w.cursor != soft || len(w.cursorStack) != 0 {
+		t.Error("failed PushCursor left the cursor state changed")
+	}
+}
+
+func TestHideCursor(t *testing.T) {
+	conn, reqs := recordRequests(t)
+	const windowID = 0x100
+	w := &Window{conn: conn, windowID: windowID}
+	u16 := func(req []byte, off int) uint16 { return binary.LittleEndian.Uint16(req[off:]) }
+	u32 := func(req []byte, off int) uint32 { return binary.LittleEndian.Uint32(req[off:]) }
+
+	// checkCursor checks a ChangeWindowAttributes setting the cursor
+	checkCursor := func(cursor uint32) {
+		t.Helper()
+		req := nextRequest(t, reqs, x11.OpChangeWindowAttributes)
+		if u32(req, 4) != windowID || u32(req, 8) != x11.CWCursor || u32(req, 12) != cursor {
+			t.Errorf("ChangeWindowAttributes on %#x mask %#x value %#x, want cursor %#x",
+				u32(req, 4), u32(req, 8), u32(req, 12), cursor)
+		}
+	}
+
+	if err := w.HideCursor(); err != nil {
+		t.Fatal(err)
+	}
+	req := nextRequest(t, reqs, x11.OpCreatePixmap)
+	pixmap := u32(req, 4)
+	if req[1] != 1 || u16(req, 12) != 1 || u16(req, 14) != 1 {
+		t.Errorf("blank cursor pixmap is %dx%d, depth %d; want a 1x1 bitmap", u16(req, 12), u16(req, 14), req[1])
+	}
+	nextRequest(t, reqs, x11.OpCreateGC)
+	nextRequest(t, reqs, x11.OpPolyFillRect)
+	req = nextRequest(t, reqs, x11.OpCreateCursor)
+	blank := u32(req, 4)
+	if u32(req, 8) != pixmap || u32(req, 12) != pixmap {
+		t.Errorf("CreateCursor from %#x masked by %#x, want the cleared bitmap %#x", u32(req, 8), u32(req, 12), pixmap)
+	}
+	nextRequest(t, reqs, x11.OpFreeGC)
+	nextRequest(t, reqs, x11.OpFreePixmap)
+	checkCursor(blank)
+	if !w.CursorHidden() {
+		t.Error("CursorHidden = false after HideCursor")
+	}
+
+	// Shapes set while hidden wait for ShowCursor; CursorDefault is
+	// None, so no font cursor is needed
+	w.cursorShape = CursorText
+	if err := w.SetCursor(CursorDefault); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.ShowCursor(); err != nil {
+		t.Fatal(err)
+	}
+	checkCursor(0)
+
+	if err := w.WarpMouse(300, -2); err != nil {
+		t.Fatal(err)
+	}
+	req = nextRequest(t, reqs, x11.OpWarpPointer)
+	if u16(req, 2) != 6 || u32(req, 4) != 0 || u32(req, 8) != windowID ||
+		int16(u16(req, 20)) != 300 || int16(u16(req, 22)) != -2 {
+		t.Errorf("WarpPointer request % x", req)
+	}
+
+	// Closing restores the cursor and frees the blank one, reused
+	// since the first HideCursor
+	w.HideCursor()
+	checkCursor(blank)
+	w.freeCursors()
+	checkCursor(0)
+	if req := nextRequest(t, reqs, x11.OpFreeCursor); u32(req, 4) != blank {
+		t.Errorf("freed cursor %#x, want %#x", u32(req, 4), blank)
+	}
+}
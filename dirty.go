@@ -0,0 +1,72 @@
+package glow
+
+// maxDirtyRects caps the damage list; past it the regions are collapsed
+// into their bounding box, so tracking stays cheap for busy frames.
+const maxDirtyRects = 16
+
+// DirtyRegions returns the areas of the canvas drawn to since the last
+// ResetDirty or Window.Present, in framebuffer coordinates (top-left
+// origin, regardless of SetYUp). Overlapping and touching areas are merged, so the list is
+// short but may cover some pixels that didn't change.
+func (c *Canvas) DirtyRegions() []Rect {
+	return append([]Rect(nil), c.dirty...)
+}
+
+// ResetDirty empties the damage list, typically once the changes have
+// been acted on.
+func (c *Canvas) ResetDirty() {
+	c.dirty = c.dirty[:0]
+}
+
+// markAllDirty marks the whole canvas as changed.
+func (c *Canvas) markAllDirty() {
+	c.dirty = append(c.dirty[:0], Rect{Width: c.fb.Width, Height: c.fb.Height})
+}
+
+// markDirty adds an area, in framebuffer coordinates, to the damage list.
+func (c *Canvas) markDirty(x, y, width, height int) {
+	r := Rect{X: x, Y: y, Width: width, Height: height}.
+		Intersect(Rect{Width: c.fb.Width, Height: c.fb.Height})
+	if r.Empty() {
+		return
+	}
+
+	// Fold in every region the new one touches; a merge can make it
+	// touch more, so repeat until nothing changes
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(c.dirty); i++ {
+			if touches(r, c.dirty[i]) {
+				r = r.Union(c.dirty[i])
+				c.dirty = append(c.dirty[:i], c.dirty[i+1:]...)
+				merged = true
+				i--
+			}
+		}
+	}
+
+	if len(c.dirty) >= maxDirtyRects {
+		for _, d := range c.dirty {
+			r = r.Union(d)
+		}
+		c.dirty = c.dirty[:0]
+	}
+	c.dirty = append(c.dirty, r)
+}
+
+// markDirtyPoints marks the bounding box of a set of framebuffer points,
+// grown by pad pixels on every side.
+func (c *Canvas) markDirtyPoints(pad int, xy ...int) {
+	x0, y0, x1, y1 := xy[0], xy[1], xy[0], xy[1]
+	for i := 2; i+1 < len(xy); i += 2 {
+		x0, x1 = min(x0, xy[i]), max(x1, xy[i])
+		y0, y1 = min(y0, xy[i+1]), max(y1, xy[i+1])
+	}
+	c.markDirty(x0-pad, y0-pad, x1-x0+1+2*pad, y1-y0+1+2*pad)
+}
+
+// touches reports whether two rectangles overlap or share an edge.
+func touches(a, b Rect) bool {
+	return a.X <= b.X+b.Width && b.X <= a.X+a.Width &&
+		a.Y <= b.Y+b.Height && b.Y <= a.Y+a.Height
+}
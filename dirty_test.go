@@ -0,0 +1,119 @@
+package glow
+
+import (
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestDirtyRegions(t *testing.T) {
+	c := &Canvas{fb: x11.NewFramebuffer(100, 100)}
+	if len(c.DirtyRegions()) != 0 {
+		t.Fatal("new canvas should have no damage")
+	}
+
+	c.SetPixel(5, 5, Red)
+	c.DrawRect(50, 50, 10, 10, Red)
+	got := c.DirtyRegions()
+	want := []Rect{{X: 5, Y: 5, Width: 1, Height: 1}, {X: 50, Y: 50, Width: 10, Height: 10}}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("DirtyRegions = %v, want %v", got, want)
+	}
+
+	// Touching regions merge; off-canvas parts are clipped
+	c.DrawRect(60, 50, 50, 5, Red)
+	if got := c.DirtyRegions(); len(got) != 2 || got[1] != (Rect{X: 50, Y: 50, Width: 50, Height: 10}) {
+		t.Errorf("expected merged region, got %v", got)
+	}
+
+	c.ResetDirty()
+	c.FillCircle(-20, -20, 5, Red) // entirely off-canvas
+	if got := c.DirtyRegions(); len(got) != 0 {
+		t.Errorf("off-canvas drawing marked %v", got)
+	}
+
+	// Many scattered changes collapse into one bounding box
+	for i := 0; i < maxDirtyRects+1; i++ {
+		c.SetPixel(i*5, i*5, Red)
+	}
+	if got := c.DirtyRegions(); len(got) > maxDirtyRects {
+		t.Errorf("damage list grew to %d regions", len(got))
+	}
+}
+
+// fakePresent does what Present does without a server, returning the
+// number of pixel bytes it would send.
+func fakePresent(w *Window) int {
+	view := w.visibleRegion()
+	n := 0
+	for _, r := range w.presentRegions(view) {
+		n += r.Width * r.Height * 4
+	}
+	w.presented = view
+	w.canvas.ResetDirty()
+	return n
+}
+
+func TestPresentRegions(t *testing.T) {
+	w := &Window{canvas: &Canvas{fb: x11.NewFramebuffer(100, 80)}, width: 100, height: 80}
+	view := Rect{Width: 100, Height: 80}
+	if got := w.presentRegions(view); len(got) != 1 || got[0] != view {
+		t.Fatalf("first Present sends %v, want the whole view", got)
+	}
+	fakePresent(w)
+
+	if got := w.presentRegions(view); len(got) != 0 {
+		t.Errorf("unchanged frame sends %v", got)
+	}
+
+	w.canvas.SetPixel(10, 20, Red)
+	w.canvas.FillCircle(98, 78, 5, Red) // Hangs off the bottom-right corner
+	got := w.presentRegions(view)
+	want := []Rect{{X: 10, Y: 20, Width: 1, Height: 1}, {X: 93, Y: 73, Width: 7, Height: 7}}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("presentRegions = %v, want %v", got, want)
+	}
+	fakePresent(w)
+
+	// Expose and PresentAll resend everything, once
+	w.convertEvent(x11.ExposeEvent{Width: 100, Height: 80})
+	if got := w.presentRegions(view); len(got) != 1 || got[0] != view {
+		t.Errorf("after Expose sends %v, want the whole view", got)
+	}
+	if got := w.presentRegions(view); len(got) != 0 {
+		t.Errorf("second Present after Expose sends %v", got)
+	}
+
+	// Only the visible part of a scrolled virtual canvas is sent
+	w.SetVirtualSize(300, 300)
+	w.SetScroll(100, 100)
+	fakePresent(w)
+	w.canvas.DrawRect(0, 0, 50, 50, Red)     // Off screen
+	w.canvas.DrawRect(190, 170, 20, 20, Red) // Across the view's corner
+	view = w.visibleRegion()
+	got = w.presentRegions(view)
+	if len(got) != 1 || got[0] != (Rect{X: 190, Y: 170, Width: 10, Height: 10}) {
+		t.Errorf("scrolled presentRegions = %v", got)
+	}
+}
+
+func BenchmarkPresentBytes(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		draw func(c *Canvas, i int)
+	}{
+		{"pixel", func(c *Canvas, i int) { c.SetPixel(i%640, i%480, RGB(uint8(i), 0, 0)) }},
+		{"full", func(c *Canvas, i int) { c.Clear(RGB(uint8(i), 0, 0)) }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			w := &Window{canvas: &Canvas{fb: x11.NewFramebuffer(640, 480)}, width: 640, height: 480}
+			fakePresent(w)
+			sent := 0
+			for i := 0; i < b.N; i++ {
+				bm.draw(w.canvas, i)
+				sent += fakePresent(w)
+			}
+			b.ReportMetric(float64(sent)/float64(b.N), "bytes/frame")
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package glow
+
+import (
+	"sync"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// Display is a single X11 connection shared by several windows.
+// Events for all of its windows are delivered through one queue, so a
+// multi-window app needs only one event loop:
+//
+//	for {
+//		win, e := display.WaitEvent()
+//		if win == nil {
+//			break // display closed
+//		}
+//		...
+//	}
+//
+// Windows created with NewWindow (not Display.NewWindow) keep their own
+// connection and are unaffected.
+type Display struct {
+	conn *x11.Connection
+
+	mu      sync.Mutex
+	windows map[uint32]*Window
+	closed  bool
+
+	eventChan chan displayEvent
+	quitChan  chan struct{}
+}
+
+// displayEvent is an event tagged with the window it belongs to
+type displayEvent struct {
+	win   *Window
+	event Event
+}
+
+// OpenDisplay connects to the X11 server for hosting several windows.
+func OpenDisplay() (*Display, error) {
+	conn, err := x11.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Display{
+		conn:      conn,
+		windows:   make(map[uint32]*Window),
+		eventChan: make(chan displayEvent, 256),
+		quitChan:  make(chan struct{}),
+	}
+
+	go d.pollEvents()
+
+	return d, nil
+}
+
+// NewWindow creates a window on the display's shared connection.
+// Its events are read with Display.WaitEvent or Display.PollEvent;
+// Window.PollEvent and Window.WaitEvent never see them.
+func (d *Display) NewWindow(title string, width, height int) (*Window, error) {
+	w, err := createWindow(d.conn, title, width, height, false)
+	if err != nil {
+		return nil, err
+	}
+	w.display = d
+
+	d.mu.Lock()
+	d.windows[w.windowID] = w
+	d.mu.Unlock()
+
+	// Tell the app its real size before the first frame
+	if e := w.initialResize(); e != nil {
+		select {
+		case d.eventChan <- displayEvent{win: w, event: *e}:
+		default:
+		}
+	}
+
+	return w, nil
+}
+
+// WaitEvent blocks until an event arrives for any of the display's
+// windows and returns it with the window it targets. It returns
+// (nil, nil) once the display is closed.
+//
+// WaitEvent and PollEvent are safe to call from any goroutine, but they
+// update the target window's size and canvas on resize, so call them
+// from the goroutine that draws.
+func (d *Display) WaitEvent() (*Window, *Event) {
+	for {
+		select {
+		case de := <-d.eventChan:
+			if de.win.closed.Load() || !de.win.applyEvent(&de.event) {
+				continue
+			}
+			return de.win, &de.event
+		case <-d.quitChan:
+			return nil, nil
+		}
+	}
+}
+
+// PollEvent returns the next event for any of the display's windows,
+// or (nil, nil) if none is available. It never blocks.
+func (d *Display) PollEvent() (*Window, *Event) {
+	for {
+		select {
+		case de := <-d.eventChan:
+			if de.win.closed.Load() || !de.win.applyEvent(&de.event) {
+				continue
+			}
+			return de.win, &de.event
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// Close closes every window still open on the display, then the
+// connection itself.
+func (d *Display) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	windows := make([]*Window, 0, len(d.windows))
+	for _, w := range d.windows {
+		windows = append(windows, w)
+	}
+	d.mu.Unlock()
+
+	for _, w := range windows {
+		w.Close()
+	}
+
+	close(d.quitChan)
+	d.conn.Close()
+}
+
+// removeWindow forgets a closed window. Events still queued for it are
+// dropped; the other windows keep receiving theirs.
+func (d *Display) removeWindow(w *Window) {
+	d.mu.Lock()
+	delete(d.windows, w.windowID)
+	d.mu.Unlock()
+}
+
+// queueEvent hands w's event e to the app, dropping it if the queue is
+// full. It returns false if the display closed while waiting.
+func (d *Display) queueEvent(w *Window, e *Event) bool {
+	select {
+	case d.eventChan <- displayEvent{win: w, event: *e}:
+	case <-d.quitChan:
+		return false
+	default:
+		// Channel full, drop event
+	}
+	return true
+}
+
+// reloadKeymaps fetches the keyboard mapping once and gives it to every
+// window.
+func (d *Display) reloadKeymaps() {
+	km, err := fetchKeymap(d.conn)
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	for _, w := range d.windows {
+		w.keymap.Store(km)
+	}
+	d.mu.Unlock()
+}
+
+// pollEvents runs in a goroutine, reading X11 events for every window
+// and tagging each with the window it was reported on.
+func (d *Display) pollEvents() {
+	for {
+		select {
+		case <-d.quitChan:
+			return
+		default:
+		}
+
+		// An error means the connection is gone for good
+		xEvent, err := d.conn.NextEvent()
+		if err != nil {
+			return
+		}
+
+		// MappingNotify isn't tied to a window; every window reloads
+		if e, ok := xEvent.(x11.MappingNotifyEvent); ok {
+			if e.Request == x11.MappingKeyboard || e.Request == x11.MappingModifier {
+				d.reloadKeymaps()
+			}
+			continue
+		}
+
+		d.mu.Lock()
+		w := d.windows[x11.EventWindow(xEvent)]
+		d.mu.Unlock()
+		if w == nil {
+			continue
+		}
+
+		if event := w.convertEvent(xEvent); event != nil {
+			if !d.queueEvent(w, event) {
+				return
+			}
+			if wheel := wheelEvent(event); wheel != nil {
+				if !d.queueEvent(w, wheel) {
+					return
+				}
+			}
+			if text := w.textInputEvent(event); text != nil {
+				if !d.queueEvent(w, text) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package glow
+
+import (
+	"encoding/binary"
+	"net/url"
+	"strings"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// dndState tracks an XDND drag currently hovering over the window.
+type dndState struct {
+	source  uint32 // source window, 0 when no drag is active
+	version uint32
+	accept  bool // source offers text/uri-list
+	x, y    int  // last pointer position, window-relative
+}
+
+// handleDnd runs the receiver side of the XDND protocol for a
+// ClientMessage. It reports false if the message is not an XDND message.
+func (w *Window) handleDnd(e x11.ClientMessageEvent) bool {
+	var l [5]uint32
+	for i := range l {
+		l[i] = binary.LittleEndian.Uint32(e.Data[i*4:])
+	}
+
+	switch x11.Atom(e.MessageType) {
+	case x11.AtomXdndEnter:
+		w.dnd = dndState{
+			source:  l[0],
+			version: l[1] >> 24,
+		}
+		if l[1]&1 != 0 {
+			// More than three types: the full list is on the source window
+			prop, err := w.conn.GetProperty(l[0], x11.AtomXdndTypeList, x11.AtomAtom, false)
+			if err == nil {
+				for i := 0; i+4 <= len(prop.Value); i += 4 {
+					if x11.Atom(binary.LittleEndian.Uint32(prop.Value[i:])) == x11.AtomTextURIList {
+						w.dnd.accept = true
+					}
+				}
+			}
+		} else {
+			for _, t := range l[2:] {
+				if x11.Atom(t) == x11.AtomTextURIList {
+					w.dnd.accept = true
+				}
+			}
+		}
+		return true
+
+	case x11.AtomXdndPosition:
+		if l[0] != w.dnd.source {
+			return true
+		}
+		rootX := int16(l[2] >> 16)
+		rootY := int16(l[2] & 0xFFFF)
+		if x, y, err := w.conn.TranslateCoordinates(w.conn.RootWindow, w.windowID, rootX, rootY); err == nil {
+			w.dnd.x, w.dnd.y = int(x), int(y)
+		}
+
+		// XdndStatus: bit 0 = accept, bit 1 = keep sending positions
+		status := [5]uint32{w.windowID, 2, 0, 0, 0}
+		if w.dnd.accept {
+			status[1] |= 1
+			status[4] = uint32(x11.AtomXdndActionCopy)
+		}
+		w.conn.SendClientMessage(w.dnd.source, w.dnd.source, x11.AtomXdndStatus, status)
+		return true
+
+	case x11.AtomXdndLeave:
+		w.dnd = dndState{}
+		return true
+
+	case x11.AtomXdndDrop:
+		if l[0] != w.dnd.source {
+			return true
+		}
+		if !w.dnd.accept {
+			w.finishDnd(false)
+			return true
+		}
+		// The file list arrives later as a SelectionNotify
+		w.conn.ConvertSelection(w.windowID, x11.AtomXdndSelection,
+			x11.AtomTextURIList, x11.AtomXdndSelection, l[2])
+		return true
+	}
+
+	return false
+}
+
+// handleDndSelection completes a drop once the source has converted
+// the selection. It returns the EventFileDrop, or nil if the drop failed.
+func (w *Window) handleDndSelection(e x11.SelectionNotifyEvent) *Event {
+	if w.dnd.source == 0 || e.Selection != x11.AtomXdndSelection {
+		return nil
+	}
+
+	if e.Property == 0 {
+		w.finishDnd(false)
+		return nil
+	}
+
+	prop, err := w.conn.GetProperty(w.windowID, e.Property, 0, true)
+	if err != nil {
+		w.finishDnd(false)
+		return nil
+	}
+
+	event := &Event{
+		Type:  EventFileDrop,
+		X:     w.dnd.x,
+		Y:     w.dnd.y,
+		Files: parseURIList(string(prop.Value)),
+	}
+	w.finishDnd(true)
+	return event
+}
+
+// finishDnd sends XdndFinished to the source and resets the drag state.
+func (w *Window) finishDnd(accepted bool) {
+	if w.dnd.version >= 2 {
+		finished := [5]uint32{w.windowID, 0, 0, 0, 0}
+		if accepted {
+			finished[1] = 1
+			finished[2] = uint32(x11.AtomXdndActionCopy)
+		}
+		w.conn.SendClientMessage(w.dnd.source, w.dnd.source, x11.AtomXdndFinished, finished)
+	}
+	w.dnd = dndState{}
+}
+
+// parseURIList extracts local file paths from a text/uri-list payload.
+// Comment lines, non-file URIs and files on other hosts are skipped.
+func parseURIList(list string) []string {
+	var files []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimRight(line, "\r\x00")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Scheme != "file" {
+			continue
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			continue
+		}
+		files = append(files, u.Path)
+	}
+	return files
+}
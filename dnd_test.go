@@ -0,0 +1,26 @@
+package glow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURIList(t *testing.T) {
+	list := "# dropped from nautilus\r\n" +
+		"file:///home/user/a.png\r\n" +
+		"file:///home/user/with%20space.txt\r\n" +
+		"https://example.com/remote.png\r\n" +
+		"file://localhost/tmp/b.png\r\n" +
+		"file://otherhost/tmp/c.png\r\n" +
+		"\x00"
+
+	got := parseURIList(list)
+	want := []string{
+		"/home/user/a.png",
+		"/home/user/with space.txt",
+		"/tmp/b.png",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseURIList: expected %q, got %q", want, got)
+	}
+}
@@ -1,6 +1,8 @@
 package glow
 
 import (
+	"encoding/binary"
+
 	"github.com/AchrafSoltani/glow/internal/x11"
 )
 
@@ -17,6 +19,11 @@ const (
 	EventMouseMotion
 	EventWindowResize
 	EventWindowExpose
+	EventClientMessage
+	EventFileDrop
+	EventPropertyChanged
+	EventTextInput
+	EventMouseWheel
 )
 
 // Event represents an input or window event
@@ -27,6 +34,68 @@ type Event struct {
 	X, Y   int
 	Width  int
 	Height int
+
+	// Keysym is the symbol the key produces for EventKeyDown and
+	// EventKeyUp, taking Shift and Caps Lock into account. It is
+	// resolved with the server's keyboard mapping, so it follows the
+	// user's layout where Key does not.
+	Keysym Keysym
+
+	// Rune is the character an EventKeyDown types, or 0 for keys that
+	// don't type one (arrows, Enter) and while Ctrl is held.
+	Rune rune
+
+	// Text is the text typed, for EventTextInput (see SetTextInput).
+	// It is usually one character, two when a dead key doesn't
+	// combine with the letter after it.
+	Text string
+
+	// WheelX and WheelY are the scroll amounts of an EventMouseWheel,
+	// in notches: WheelY is positive scrolling up (away from the user)
+	// and WheelX positive scrolling right. Button holds the wheel
+	// button the X server reported (MouseWheelUp to MouseWheelRight).
+	// The wheel buttons still report EventMouseButtonDown and Up too,
+	// with the EventMouseWheel right after the press.
+	WheelX, WheelY float64
+
+	// Mods holds the modifier keys held during a key, mouse button or
+	// motion event, as when the event happened: a KeyDown for Shift
+	// itself doesn't include ModShift.
+	Mods Modifier
+
+	// Buttons holds the mouse buttons held down during an
+	// EventMouseMotion, so drags can be told apart from hovering.
+	Buttons MouseButtons
+
+	// Raw ClientMessage fields, set for EventClientMessage only.
+	// MessageType is the message's type atom (compare it against
+	// Window.InternAtom results). Format is 8, 16 or 32 and says how
+	// the 20 Data bytes are laid out: 20 bytes, 10 uint16 values or
+	// 5 uint32 values, each little-endian. Most protocols (XDND,
+	// _NET_WM_*) use format 32; see Data32.
+	MessageType uint32
+	Format      uint8
+	Data        [20]byte
+
+	// Files lists the local paths dropped on the window (EventFileDrop).
+	// X, Y hold the drop position.
+	Files []string
+
+	// Property is the atom of the window property that changed, for
+	// EventPropertyChanged (compare it against Window.InternAtom
+	// results). PropertyDeleted is set if it was removed.
+	Property        uint32
+	PropertyDeleted bool
+}
+
+// Data32 decodes a format-32 ClientMessage payload into its five
+// 32-bit values (data.l[0] through data.l[4] in Xlib terms).
+func (e *Event) Data32() [5]uint32 {
+	var l [5]uint32
+	for i := range l {
+		l[i] = binary.LittleEndian.Uint32(e.Data[i*4:])
+	}
+	return l
 }
 
 // Key represents a keyboard key (X11 keycode)
@@ -119,62 +188,227 @@ const (
 	MouseRight      MouseButton = 3
 	MouseWheelUp    MouseButton = 4
 	MouseWheelDown  MouseButton = 5
+	MouseWheelLeft  MouseButton = 6
+	MouseWheelRight MouseButton = 7
+)
+
+// MouseButtons is a bitmask of mouse buttons held down
+type MouseButtons uint8
+
+const (
+	MouseLeftMask   MouseButtons = 1 << 0
+	MouseMiddleMask MouseButtons = 1 << 1
+	MouseRightMask  MouseButtons = 1 << 2
+)
+
+// Has reports whether button is held in the mask.
+func (b MouseButtons) Has(button MouseButton) bool {
+	switch button {
+	case MouseLeft:
+		return b&MouseLeftMask != 0
+	case MouseMiddle:
+		return b&MouseMiddleMask != 0
+	case MouseRight:
+		return b&MouseRightMask != 0
+	}
+	return false
+}
+
+// wheelDelta returns the scroll of one notch of a wheel button, and
+// false for other buttons.
+func wheelDelta(b MouseButton) (dx, dy float64, ok bool) {
+	switch b {
+	case MouseWheelUp:
+		return 0, 1, true
+	case MouseWheelDown:
+		return 0, -1, true
+	case MouseWheelLeft:
+		return -1, 0, true
+	case MouseWheelRight:
+		return 1, 0, true
+	}
+	return 0, 0, false
+}
+
+// wheelEvent returns the EventMouseWheel following e, or nil if e isn't
+// a wheel button going down. Each wheel notch is a press and an
+// immediate release; the wheel event goes with the press.
+func wheelEvent(e *Event) *Event {
+	if e.Type != EventMouseButtonDown {
+		return nil
+	}
+	dx, dy, ok := wheelDelta(e.Button)
+	if !ok {
+		return nil
+	}
+	return &Event{
+		Type:   EventMouseWheel,
+		Button: e.Button,
+		WheelX: dx,
+		WheelY: dy,
+		X:      e.X,
+		Y:      e.Y,
+		Mods:   e.Mods,
+	}
+}
+
+// buttonsFromState decodes the held buttons from an X11 input event state
+func buttonsFromState(state uint16) MouseButtons {
+	var b MouseButtons
+	if state&x11.Button1Mask != 0 {
+		b |= MouseLeftMask
+	}
+	if state&x11.Button2Mask != 0 {
+		b |= MouseMiddleMask
+	}
+	if state&x11.Button3Mask != 0 {
+		b |= MouseRightMask
+	}
+	return b
+}
+
+// Modifier is a bitmask of modifier keys held during an event. The bits
+// are glow's own, decoded from the X11 state's ShiftMask, ControlMask,
+// Mod1Mask (Alt) and Mod4Mask (Super); either side's key sets the bit.
+type Modifier uint8
+
+const (
+	ModShift Modifier = 1 << 0 // Either Shift key
+	ModCtrl  Modifier = 1 << 1 // Either Control key
+	ModAlt   Modifier = 1 << 2 // Alt (X11 Mod1)
+	ModSuper Modifier = 1 << 3 // Super, the Windows/Command key (X11 Mod4)
 )
 
+// Has reports whether every modifier in mods is held in m, so
+// e.Mods.Has(ModCtrl|ModShift) checks for both.
+func (m Modifier) Has(mods Modifier) bool { return m&mods == mods }
+
+// modsFromState decodes the held modifiers from an X11 input event
+// state. Caps Lock and Num Lock are ignored so they don't break combos.
+func modsFromState(state uint16) Modifier {
+	var m Modifier
+	if state&x11.ShiftMask != 0 {
+		m |= ModShift
+	}
+	if state&x11.ControlMask != 0 {
+		m |= ModCtrl
+	}
+	if state&x11.Mod1Mask != 0 {
+		m |= ModAlt
+	}
+	if state&x11.Mod4Mask != 0 {
+		m |= ModSuper
+	}
+	return m
+}
+
 // PollEvent returns the next event, or nil if none available
 // This is non-blocking - returns immediately
 func (w *Window) PollEvent() *Event {
-	select {
-	case e := <-w.eventChan:
-		// Update window dimensions and resize canvas if resize event
-		if e.Type == EventWindowResize {
-			w.width = e.Width
-			w.height = e.Height
-			w.canvas.Resize(w.width, w.height)
+	for {
+		select {
+		case e := <-w.eventChan:
+			if !w.applyEvent(&e) {
+				continue
+			}
+			return &e
+		default:
+			return nil
 		}
-		return &e
-	default:
-		return nil
 	}
 }
 
 // WaitEvent blocks until an event is available
 func (w *Window) WaitEvent() *Event {
-	e := <-w.eventChan
+	for {
+		e := <-w.eventChan
+		if !w.applyEvent(&e) {
+			continue
+		}
+		return &e
+	}
+}
+
+// applyEvent updates window state for an event as it is handed to the
+// application. It returns false if the event was consumed (by a
+// shortcut) and should not be delivered.
+func (w *Window) applyEvent(e *Event) bool {
 	// Update window dimensions and resize canvas if resize event
 	if e.Type == EventWindowResize {
 		w.width = e.Width
 		w.height = e.Height
-		w.canvas.Resize(w.width, w.height)
+		if w.virtualW == 0 {
+			w.canvas.Resize(w.width, w.height)
+		}
+		w.SetScroll(w.scrollX, w.scrollY)
+	}
+	if e.Type == eventFocusLost {
+		w.releaseKeys()
+		return false
 	}
-	return &e
+	w.trackInput(e)
+	return !w.dispatchShortcut(e)
 }
 
-// pollEvents runs in a goroutine, reading X11 events and sending to channel
-func (w *Window) pollEvents() {
+// pollEvents runs in a goroutine, reading X11 events and sending to channel.
+// It closes done when it returns, so a reconnect can wait for it before
+// replacing the fields it reads.
+func (w *Window) pollEvents(conn *x11.Connection, quit, done chan struct{}) {
+	defer close(done)
 	for {
 		select {
-		case <-w.quitChan:
+		case <-quit:
 			return
 		default:
-			xEvent, err := w.conn.NextEvent()
+			xEvent, err := conn.NextEvent()
 			if err != nil {
-				continue
+				select {
+				case <-quit:
+				default:
+					// The connection is gone; Present picks this up
+					w.lost.Store(true)
+				}
+				return
+			}
+
+			// Layout switches change the keyboard mapping, and can
+			// move AltGr to another modifier
+			if e, ok := xEvent.(x11.MappingNotifyEvent); ok && (e.Request == x11.MappingKeyboard || e.Request == x11.MappingModifier) {
+				w.loadKeymap(conn)
 			}
 
 			if event := w.convertEvent(xEvent); event != nil {
-				select {
-				case w.eventChan <- *event:
-				case <-w.quitChan:
+				if !w.queueEvent(event, quit) {
 					return
-				default:
-					// Channel full, drop event
+				}
+				if wheel := wheelEvent(event); wheel != nil {
+					if !w.queueEvent(wheel, quit) {
+						return
+					}
+				}
+				if text := w.textInputEvent(event); text != nil {
+					if !w.queueEvent(text, quit) {
+						return
+					}
 				}
 			}
 		}
 	}
 }
 
+// queueEvent hands e to the app, dropping it if the queue is full. It
+// returns false if quit closed while waiting.
+func (w *Window) queueEvent(e *Event, quit chan struct{}) bool {
+	select {
+	case w.eventChan <- *e:
+	case <-quit:
+		return false
+	default:
+		// Channel full, drop event
+	}
+	return true
+}
+
 func (w *Window) convertEvent(xEvent x11.Event) *Event {
 	if xEvent == nil {
 		return nil
@@ -186,12 +420,20 @@ func (w *Window) convertEvent(xEvent x11.Event) *Event {
 		if e.EventType == x11.EventKeyRelease {
 			evType = EventKeyUp
 		}
-		return &Event{
-			Type: evType,
-			Key:  Key(e.Keycode),
-			X:    int(e.X),
-			Y:    int(e.Y),
+		sym := w.keysym(Key(e.Keycode), e.State)
+		ev := &Event{
+			Type:   evType,
+			Key:    Key(e.Keycode),
+			Keysym: sym,
+			X:      int(e.X),
+			Y:      int(e.Y),
+			Mods:   modsFromState(e.State),
 		}
+		// Ctrl combinations are shortcuts, not typing
+		if evType == EventKeyDown && ev.Mods&ModCtrl == 0 {
+			ev.Rune = sym.Rune()
+		}
+		return ev
 
 	case x11.ButtonEvent:
 		evType := EventMouseButtonDown
@@ -203,16 +445,22 @@ func (w *Window) convertEvent(xEvent x11.Event) *Event {
 			Button: MouseButton(e.Button),
 			X:      int(e.X),
 			Y:      int(e.Y),
+			Mods:   modsFromState(e.State),
 		}
 
 	case x11.MotionEvent:
 		return &Event{
-			Type: EventMouseMotion,
-			X:    int(e.X),
-			Y:    int(e.Y),
+			Type:    EventMouseMotion,
+			X:       int(e.X),
+			Y:       int(e.Y),
+			Buttons: buttonsFromState(e.State),
+			Mods:    modsFromState(e.State),
 		}
 
 	case x11.ExposeEvent:
+		// The server lost the window's contents; Present must resend
+		// them even if nothing was drawn
+		w.fullPresent.Store(true)
 		return &Event{
 			Type:   EventWindowExpose,
 			Width:  int(e.Width),
@@ -220,6 +468,8 @@ func (w *Window) convertEvent(xEvent x11.Event) *Event {
 		}
 
 	case x11.ConfigureEvent:
+		// Maximizing resizes the window; see refreshState
+		w.refreshState()
 		return &Event{
 			Type:   EventWindowResize,
 			X:      int(e.X),
@@ -228,6 +478,35 @@ func (w *Window) convertEvent(xEvent x11.Event) *Event {
 			Height: int(e.Height),
 		}
 
+	case x11.SelectionNotifyEvent:
+		return w.handleDndSelection(e)
+
+	case x11.FocusEvent:
+		w.trackFocus(e)
+		if !w.state.focused.Load() {
+			// Key releases now go elsewhere; have the app side
+			// forget the held keys
+			return &Event{Type: eventFocusLost}
+		}
+		return nil
+
+	case x11.MapEvent:
+		w.refreshState()
+		return nil
+
+	case x11.PropertyNotifyEvent:
+		if e.Atom == x11.AtomWMState || e.Atom == x11.AtomNetWMState {
+			w.refreshState()
+		}
+		if !w.propertyEvents.Load() {
+			return nil
+		}
+		return &Event{
+			Type:            EventPropertyChanged,
+			Property:        uint32(e.Atom),
+			PropertyDeleted: e.State == x11.PropertyDelete,
+		}
+
 	case x11.ClientMessageEvent:
 		// Check for window close button
 		if x11.IsDeleteWindowEvent(e) {
@@ -235,6 +514,17 @@ func (w *Window) convertEvent(xEvent x11.Event) *Event {
 				Type: EventQuit,
 			}
 		}
+		// Drag-and-drop handshake messages are handled internally
+		if w.handleDnd(e) {
+			return nil
+		}
+		// Anything else is passed through untouched
+		return &Event{
+			Type:        EventClientMessage,
+			MessageType: e.MessageType,
+			Format:      e.Format,
+			Data:        e.Data,
+		}
 	}
 
 	return nil
@@ -15,6 +15,8 @@ func main() {
 	defer conn.Close()
 
 	fmt.Println("Successfully connected to X11!")
+	fmt.Printf("Server vendor: %s\n", conn.Vendor)
+	fmt.Printf("Server release: %d\n", conn.ReleaseNumber)
 	fmt.Printf("Screen size: %dx%d\n", conn.ScreenWidth, conn.ScreenHeight)
 	fmt.Printf("Root window: 0x%x\n", conn.RootWindow)
 	fmt.Printf("Root depth: %d bits\n", conn.RootDepth)
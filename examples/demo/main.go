@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"time"
 
 	"github.com/AchrafSoltani/glow"
 )
@@ -27,9 +26,6 @@ func main() {
 	playerY := float64(win.Height()) / 2
 	playerSpeed := 5.0
 
-	// Track key states
-	keys := make(map[glow.Key]bool)
-
 	// Circles placed by clicking
 	type Circle struct {
 		X, Y   int
@@ -52,14 +48,10 @@ func main() {
 
 			switch event.Type {
 			case glow.EventKeyDown:
-				keys[event.Key] = true
 				if event.Key == glow.KeyEscape || event.Key == glow.KeyQ {
 					running = false
 				}
 
-			case glow.EventKeyUp:
-				keys[event.Key] = false
-
 			case glow.EventMouseButtonDown:
 				if event.Button == glow.MouseLeft {
 					// Add a circle where clicked
@@ -74,18 +66,9 @@ func main() {
 		}
 
 		// Move player based on keys held
-		if keys[glow.KeyW] || keys[glow.KeyUp] {
-			playerY -= playerSpeed
-		}
-		if keys[glow.KeyS] || keys[glow.KeyDown] {
-			playerY += playerSpeed
-		}
-		if keys[glow.KeyA] || keys[glow.KeyLeft] {
-			playerX -= playerSpeed
-		}
-		if keys[glow.KeyD] || keys[glow.KeyRight] {
-			playerX += playerSpeed
-		}
+		dx, dy := win.MovementVector(*win.Keyboard())
+		playerX += dx * playerSpeed
+		playerY += dy * playerSpeed
 
 		// Keep player in bounds
 		playerX = clamp(playerX, 20, float64(win.Width())-20)
@@ -110,7 +93,7 @@ func main() {
 		// Draw player with pulsing effect
 		pulse := 5 * math.Sin(float64(frame)*0.1)
 		canvas.FillCircle(int(playerX), int(playerY), int(20+pulse), glow.Green)
-		canvas.DrawCircle(int(playerX), int(playerY), int(25+pulse), glow.Yellow)
+		canvas.DrawCircleAA(int(playerX), int(playerY), int(25+pulse), glow.Yellow)
 
 		// Draw instructions
 		canvas.DrawRect(10, 10, 200, 80, glow.RGB(0, 0, 0))
@@ -123,12 +106,11 @@ func main() {
 		}
 
 		// Present to screen
-		if err := win.Present(); err != nil {
+		if _, err := win.PresentThrottled(60); err != nil {
 			log.Printf("Present error: %v", err)
 		}
 
 		frame++
-		time.Sleep(16 * time.Millisecond) // ~60 FPS
 	}
 
 	fmt.Printf("\nExited after %d frames\n", frame)
@@ -6,6 +6,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/AchrafSoltani/glow"
 	"github.com/AchrafSoltani/glow/internal/x11"
 )
 
@@ -44,6 +45,7 @@ func main() {
 	// Animation loop
 	frame := 0
 	startTime := time.Now()
+	limiter := glow.NewFrameLimiter(60)
 
 	for {
 		// Clear to dark blue
@@ -100,7 +102,7 @@ func main() {
 			fmt.Printf("\rFrame %d, FPS: %.1f  ", frame, fps)
 		}
 
-		// Cap at ~60 FPS
-		time.Sleep(16 * time.Millisecond)
+		// Cap at 60 FPS
+		limiter.Wait()
 	}
 }
@@ -4,7 +4,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/AchrafSoltani/glow"
 )
@@ -56,6 +55,7 @@ func main() {
 	fmt.Println("Q/E: Decrease/Increase brush size")
 	fmt.Println("A/D: Previous/Next color")
 	fmt.Println("C: Clear canvas")
+	fmt.Println("S: Save to painting.png")
 	fmt.Println("ESC: Quit")
 
 	app := &PaintApp{
@@ -102,6 +102,12 @@ func main() {
 					running = false
 				case glow.KeyC:
 					clearCanvas(app)
+				case glow.KeyS:
+					if err := app.canvas.SavePNG("painting.png"); err != nil {
+						fmt.Println("Save failed:", err)
+					} else {
+						fmt.Println("Saved painting.png")
+					}
 				case glow.KeyQ:
 					app.brushSize = max(1, app.brushSize-2)
 					fmt.Printf("Brush size: %d\n", app.brushSize)
@@ -178,8 +184,7 @@ func main() {
 		// Draw toolbar
 		drawToolbar(app)
 
-		win.Present()
-		time.Sleep(8 * time.Millisecond)
+		win.PresentThrottled(120)
 	}
 
 	fmt.Println("\nPaint closed!")
@@ -239,34 +244,8 @@ func drawLineBrush(app *PaintApp, x0, y0, x1, y1 int) {
 }
 
 func drawLine(canvas *glow.Canvas, x0, y0, x1, y1 int, color glow.Color, thickness int) {
-	// Draw thick line
-	dx := abs(x1 - x0)
-	dy := -abs(y1 - y0)
-	sx := 1
-	if x0 > x1 {
-		sx = -1
-	}
-	sy := 1
-	if y0 > y1 {
-		sy = -1
-	}
-	err := dx + dy
-
-	for {
-		canvas.FillCircle(x0, y0, thickness, color)
-		if x0 == x1 && y0 == y1 {
-			break
-		}
-		e2 := 2 * err
-		if e2 >= dy {
-			err += dy
-			x0 += sx
-		}
-		if e2 <= dx {
-			err += dx
-			y0 += sy
-		}
-	}
+	// Match the brush, whose circles are 2*thickness+1 across
+	canvas.DrawLineThick(x0, y0, x1, y1, 2*thickness+1, color)
 }
 
 func drawRectTool(canvas *glow.Canvas, x0, y0, x1, y1 int, color glow.Color, thickness int) {
@@ -299,10 +278,9 @@ func drawCircleTool(canvas *glow.Canvas, x0, y0, x1, y1 int, color glow.Color, t
 	cy := (y0 + y1) / 2
 	rx := abs(x1-x0) / 2
 	ry := abs(y1-y0) / 2
-	radius := (rx + ry) / 2
 
-	for t := 0; t < thickness; t++ {
-		canvas.DrawCircle(cx, cy, radius-t, color)
+	for t := 0; t < thickness && t <= min(rx, ry); t++ {
+		canvas.DrawEllipse(cx, cy, rx-t, ry-t, color)
 	}
 }
 
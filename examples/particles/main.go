@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
-	"time"
 
 	"github.com/AchrafSoltani/glow"
 )
@@ -15,19 +13,8 @@ const (
 	screenWidth  = 1000
 	screenHeight = 700
 	maxParticles = 5000
-	gravity      = 0.2
 )
 
-type Particle struct {
-	X, Y     float64
-	VX, VY   float64
-	Life     float64
-	MaxLife  float64
-	Size     float64
-	R, G, B  uint8
-	Active   bool
-}
-
 type EmitterType int
 
 const (
@@ -38,18 +25,47 @@ const (
 	EmitterSpiral
 )
 
-type ParticleSystem struct {
-	particles    []Particle
-	emitterType  EmitterType
-	emitterX     float64
-	emitterY     float64
-	emitRate     int
-	frame        int
+// One emitter per mode, in pixels and seconds (the old per-frame values
+// times 60)
+var emitters = []*glow.Emitter{
+	EmitterFountain: {
+		Rate: 600, Angle: -math.Pi / 2, Spread: 0.5,
+		Speed: 450, SpeedVar: 150, Life: 2.1, LifeVar: 0.4,
+		Size: 7, SizeVar: 3, EndSize: 2,
+		StartColor: glow.RGB(75, 200, 228), EndColor: glow.RGBA(75, 200, 228, 0),
+		Gravity: 720,
+	},
+	EmitterExplosion: {
+		Shape: glow.EmitRadial,
+		Speed: 300, SpeedVar: 240, Life: 1.25, LifeVar: 0.4,
+		Size: 8, SizeVar: 4, EndSize: 2,
+		StartColor: glow.RGB(228, 150, 25), EndColor: glow.RGBA(228, 150, 25, 0),
+		Gravity: 720,
+	},
+	EmitterFire: {
+		Shape: glow.EmitLine, Rate: 1200, Angle: -math.Pi / 2, Spread: 0.6,
+		Speed: 210, SpeedVar: 90, Life: 0.9, LifeVar: 0.25,
+		Size: 11, SizeVar: 5, EndSize: 2,
+		StartColor: glow.RGB(255, 200, 0), EndColor: glow.RGBA(200, 50, 0, 0),
+		Gravity: -60,
+	},
+	EmitterSnow: {
+		Shape: glow.EmitLine, X2: screenWidth, Rate: 300,
+		Angle: math.Pi / 2, Spread: 0.6,
+		Speed: 120, SpeedVar: 60, Life: 6, LifeVar: 1,
+		Size: 4, SizeVar: 2, EndSize: 4,
+		StartColor: glow.RGB(228, 228, 248), EndColor: glow.RGBA(228, 228, 248, 0),
+		Drag: 0.05,
+	},
+	EmitterSpiral: {
+		Shape: glow.EmitRadial, Rate: 600,
+		Speed: 120, SpeedVar: 60, Life: 1.7, LifeVar: 0.35,
+		Size: 6, SizeVar: 2, EndSize: 1,
+		Gravity: 720,
+	},
 }
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
-
 	win, err := glow.NewWindow("Glow Particles", screenWidth, screenHeight)
 	if err != nil {
 		log.Fatal(err)
@@ -66,23 +82,23 @@ func main() {
 	fmt.Println("Space: Toggle continuous emission")
 	fmt.Println("ESC: Quit")
 
-	ps := &ParticleSystem{
-		particles:   make([]Particle, maxParticles),
-		emitterType: EmitterFountain,
-		emitterX:    screenWidth / 2,
-		emitterY:    screenHeight - 50,
-		emitRate:    10,
-	}
+	ps := glow.NewParticleSystem(maxParticles)
+	emitterType := EmitterFountain
+	emitterX, emitterY := float64(screenWidth)/2, float64(screenHeight-50)
+	ps.AddEmitter(emitters[emitterType])
 
-	// Initialize particle pool
-	for i := range ps.particles {
-		ps.particles[i].Active = false
-	}
-
-	keys := make(map[glow.Key]bool)
 	continuousEmit := true
 	mouseX := screenWidth / 2
-	_ = keys // Used for future expansion
+	clock := glow.NewClock()
+	nextExplosion := 0.0
+
+	setEmitter := func(t EmitterType, y float64) {
+		ps.RemoveEmitter(emitters[emitterType])
+		emitterType, emitterY = t, y
+		if continuousEmit {
+			ps.AddEmitter(emitters[emitterType])
+		}
+	}
 
 	running := true
 	for running {
@@ -98,293 +114,97 @@ func main() {
 				running = false
 
 			case glow.EventKeyDown:
-				keys[event.Key] = true
 				switch event.Key {
 				case glow.KeyEscape:
 					running = false
 				case glow.KeySpace:
 					continuousEmit = !continuousEmit
+					if continuousEmit {
+						ps.AddEmitter(emitters[emitterType])
+					} else {
+						ps.RemoveEmitter(emitters[emitterType])
+					}
 					fmt.Printf("Continuous emission: %v\n", continuousEmit)
 				case glow.Key1:
-					ps.emitterType = EmitterFountain
-					ps.emitterY = screenHeight - 50
+					setEmitter(EmitterFountain, screenHeight-50)
 					fmt.Println("Emitter: Fountain")
 				case glow.Key2:
-					ps.emitterType = EmitterExplosion
+					setEmitter(EmitterExplosion, emitterY)
 					fmt.Println("Emitter: Explosion")
 				case glow.Key3:
-					ps.emitterType = EmitterFire
-					ps.emitterY = screenHeight - 50
+					setEmitter(EmitterFire, screenHeight-50)
 					fmt.Println("Emitter: Fire")
 				case glow.Key4:
-					ps.emitterType = EmitterSnow
-					ps.emitterY = 10
+					setEmitter(EmitterSnow, 10)
 					fmt.Println("Emitter: Snow")
 				case glow.Key5:
-					ps.emitterType = EmitterSpiral
-					ps.emitterY = screenHeight / 2
+					setEmitter(EmitterSpiral, screenHeight/2)
 					fmt.Println("Emitter: Spiral")
 				}
 
-			case glow.EventKeyUp:
-				keys[event.Key] = false
-
 			case glow.EventMouseMotion:
 				mouseX = event.X
 
 			case glow.EventMouseButtonDown:
 				if event.Button == glow.MouseLeft {
 					// Spawn explosion at click
-					spawnExplosion(ps, float64(event.X), float64(event.Y), 100)
+					boom := emitters[EmitterExplosion]
+					boom.X, boom.Y = float64(event.X), float64(event.Y)
+					ps.Burst(boom, 100)
 				}
 			}
 		}
 
-		// Update emitter position based on mode
-		if ps.emitterType == EmitterSpiral {
-			t := float64(ps.frame) * 0.05
-			ps.emitterX = float64(screenWidth)/2 + 150*math.Cos(t)
-			ps.emitterY = float64(screenHeight)/2 + 150*math.Sin(t)
-		} else if ps.emitterType != EmitterSnow {
-			ps.emitterX = float64(mouseX)
-		}
+		dt := clock.Tick()
 
-		// Emit particles
-		if continuousEmit {
-			switch ps.emitterType {
-			case EmitterFountain:
-				emitFountain(ps, ps.emitRate)
-			case EmitterExplosion:
-				if ps.frame%30 == 0 {
-					spawnExplosion(ps, ps.emitterX, ps.emitterY, 50)
-				}
-			case EmitterFire:
-				emitFire(ps, ps.emitRate*2)
-			case EmitterSnow:
-				emitSnow(ps, ps.emitRate/2)
-			case EmitterSpiral:
-				emitSpiral(ps, ps.emitRate)
+		// Update emitter position based on mode
+		if emitterType == EmitterSpiral {
+			t := clock.Elapsed() * 3
+			emitterX = float64(screenWidth)/2 + 150*math.Cos(t)
+			emitterY = float64(screenHeight)/2 + 150*math.Sin(t)
+			// Rainbow colors going round the spiral
+			c := glow.RGB(uint8(128+127*math.Sin(t)), uint8(128+127*math.Sin(t+2)), uint8(128+127*math.Sin(t+4)))
+			emitters[EmitterSpiral].StartColor = c
+			emitters[EmitterSpiral].EndColor = glow.RGBA(c.R, c.G, c.B, 0)
+		} else if emitterType != EmitterSnow {
+			emitterX = float64(mouseX)
+		}
+
+		e := emitters[emitterType]
+		switch emitterType {
+		case EmitterFire:
+			e.X, e.Y, e.X2, e.Y2 = emitterX-20, emitterY, emitterX+20, emitterY
+		case EmitterSnow:
+			// Spans the top of the screen
+		default:
+			e.X, e.Y = emitterX, emitterY
+		}
+
+		// The explosion emitter has no Rate; burst it twice a second
+		if continuousEmit && emitterType == EmitterExplosion {
+			if nextExplosion -= dt; nextExplosion <= 0 {
+				ps.Burst(e, 50)
+				nextExplosion = 0.5
 			}
 		}
-
-		// Update particles
-		updateParticles(ps)
+		ps.Update(dt)
 
 		// Draw
 		canvas := win.Canvas()
 		canvas.Clear(glow.RGB(10, 10, 20))
 
-		// Draw particles
-		drawParticles(canvas, ps)
+		// Particles fade and shrink with age; glows add up where
+		// particles bunch together
+		ps.Draw(canvas)
 
 		// Draw emitter indicator
-		if continuousEmit && ps.emitterType != EmitterSnow {
-			canvas.DrawCircle(int(ps.emitterX), int(ps.emitterY), 5, glow.RGB(100, 100, 100))
-		}
-
-		// Draw stats
-		activeCount := 0
-		for i := range ps.particles {
-			if ps.particles[i].Active {
-				activeCount++
-			}
-		}
-		drawStats(canvas, activeCount, ps.emitterType)
-
-		win.Present()
-		ps.frame++
-		time.Sleep(16 * time.Millisecond)
-	}
-}
-
-func findInactiveParticle(ps *ParticleSystem) *Particle {
-	for i := range ps.particles {
-		if !ps.particles[i].Active {
-			return &ps.particles[i]
-		}
-	}
-	return nil
-}
-
-func emitFountain(ps *ParticleSystem, count int) {
-	for i := 0; i < count; i++ {
-		p := findInactiveParticle(ps)
-		if p == nil {
-			return
-		}
-
-		angle := -math.Pi/2 + (rand.Float64()-0.5)*0.5
-		speed := 5 + rand.Float64()*5
-
-		p.X = ps.emitterX + (rand.Float64()-0.5)*10
-		p.Y = ps.emitterY
-		p.VX = speed * math.Cos(angle)
-		p.VY = speed * math.Sin(angle)
-		p.Life = 100 + rand.Float64()*50
-		p.MaxLife = p.Life
-		p.Size = 2 + rand.Float64()*3
-		// Blue to cyan gradient
-		p.R = uint8(50 + rand.Intn(50))
-		p.G = uint8(150 + rand.Intn(100))
-		p.B = uint8(200 + rand.Intn(55))
-		p.Active = true
-	}
-}
-
-func spawnExplosion(ps *ParticleSystem, x, y float64, count int) {
-	for i := 0; i < count; i++ {
-		p := findInactiveParticle(ps)
-		if p == nil {
-			return
+		if continuousEmit && emitterType != EmitterSnow {
+			canvas.DrawCircle(int(emitterX), int(emitterY), 5, glow.RGB(100, 100, 100))
 		}
 
-		angle := rand.Float64() * 2 * math.Pi
-		speed := 2 + rand.Float64()*8
+		drawStats(canvas, ps.Count(), emitterType)
 
-		p.X = x
-		p.Y = y
-		p.VX = speed * math.Cos(angle)
-		p.VY = speed * math.Sin(angle)
-		p.Life = 50 + rand.Float64()*50
-		p.MaxLife = p.Life
-		p.Size = 2 + rand.Float64()*4
-		// Orange/yellow explosion
-		p.R = uint8(200 + rand.Intn(55))
-		p.G = uint8(100 + rand.Intn(100))
-		p.B = uint8(rand.Intn(50))
-		p.Active = true
-	}
-}
-
-func emitFire(ps *ParticleSystem, count int) {
-	for i := 0; i < count; i++ {
-		p := findInactiveParticle(ps)
-		if p == nil {
-			return
-		}
-
-		p.X = ps.emitterX + (rand.Float64()-0.5)*40
-		p.Y = ps.emitterY
-		p.VX = (rand.Float64() - 0.5) * 2
-		p.VY = -2 - rand.Float64()*3
-		p.Life = 40 + rand.Float64()*30
-		p.MaxLife = p.Life
-		p.Size = 3 + rand.Float64()*5
-		// Fire colors (red/orange/yellow)
-		p.R = uint8(200 + rand.Intn(55))
-		p.G = uint8(50 + rand.Intn(150))
-		p.B = 0
-		p.Active = true
-	}
-}
-
-func emitSnow(ps *ParticleSystem, count int) {
-	for i := 0; i < count; i++ {
-		p := findInactiveParticle(ps)
-		if p == nil {
-			return
-		}
-
-		p.X = rand.Float64() * screenWidth
-		p.Y = 0
-		p.VX = (rand.Float64() - 0.5) * 1
-		p.VY = 1 + rand.Float64()*2
-		p.Life = 300 + rand.Float64()*100
-		p.MaxLife = p.Life
-		p.Size = 1 + rand.Float64()*3
-		// White/light blue
-		v := uint8(200 + rand.Intn(55))
-		p.R = v
-		p.G = v
-		p.B = uint8(min(255, int(v)+20))
-		p.Active = true
-	}
-}
-
-func emitSpiral(ps *ParticleSystem, count int) {
-	for i := 0; i < count; i++ {
-		p := findInactiveParticle(ps)
-		if p == nil {
-			return
-		}
-
-		p.X = ps.emitterX
-		p.Y = ps.emitterY
-		// Emit outward from spiral center
-		angle := rand.Float64() * 2 * math.Pi
-		speed := 1 + rand.Float64()*2
-		p.VX = speed * math.Cos(angle)
-		p.VY = speed * math.Sin(angle)
-		p.Life = 80 + rand.Float64()*40
-		p.MaxLife = p.Life
-		p.Size = 2 + rand.Float64()*2
-		// Rainbow colors based on angle
-		p.R = uint8(128 + 127*math.Sin(angle))
-		p.G = uint8(128 + 127*math.Sin(angle+2))
-		p.B = uint8(128 + 127*math.Sin(angle+4))
-		p.Active = true
-	}
-}
-
-func updateParticles(ps *ParticleSystem) {
-	for i := range ps.particles {
-		p := &ps.particles[i]
-		if !p.Active {
-			continue
-		}
-
-		// Apply physics
-		p.X += p.VX
-		p.Y += p.VY
-
-		// Gravity (except for snow which falls slowly)
-		if ps.emitterType != EmitterSnow {
-			p.VY += gravity
-		} else {
-			// Snow sways
-			p.VX += (rand.Float64() - 0.5) * 0.2
-			p.VX *= 0.99 // Damping
-		}
-
-		// Age
-		p.Life--
-
-		// Die if too old or off screen
-		if p.Life <= 0 || p.Y > screenHeight+10 || p.X < -10 || p.X > screenWidth+10 {
-			p.Active = false
-		}
-	}
-}
-
-func drawParticles(canvas *glow.Canvas, ps *ParticleSystem) {
-	for i := range ps.particles {
-		p := &ps.particles[i]
-		if !p.Active {
-			continue
-		}
-
-		// Fade based on life
-		lifeFactor := p.Life / p.MaxLife
-		r := uint8(float64(p.R) * lifeFactor)
-		g := uint8(float64(p.G) * lifeFactor)
-		b := uint8(float64(p.B) * lifeFactor)
-
-		// Size can shrink over time for some effects
-		size := int(p.Size * lifeFactor)
-		if size < 1 {
-			size = 1
-		}
-
-		if size <= 2 {
-			canvas.SetPixel(int(p.X), int(p.Y), glow.RGB(r, g, b))
-			if size == 2 {
-				canvas.SetPixel(int(p.X)+1, int(p.Y), glow.RGB(r, g, b))
-				canvas.SetPixel(int(p.X), int(p.Y)+1, glow.RGB(r, g, b))
-				canvas.SetPixel(int(p.X)+1, int(p.Y)+1, glow.RGB(r, g, b))
-			}
-		} else {
-			canvas.FillCircle(int(p.X), int(p.Y), size, glow.RGB(r, g, b))
-		}
+		win.PresentThrottled(60)
 	}
 }
 
@@ -71,9 +71,6 @@ func main() {
 		Size: ballSize,
 	}
 
-	// Key states
-	keys := make(map[glow.Key]bool)
-
 	// Game state
 	gameStarted := false
 	gameOver := false
@@ -81,13 +78,11 @@ func main() {
 
 	// Main game loop
 	running := true
-	lastTime := time.Now()
+	clock := glow.NewClock()
 
 	for running {
 		// Delta time
-		now := time.Now()
-		dt := now.Sub(lastTime).Seconds() * 60 // Normalize to 60fps
-		lastTime = now
+		dt := clock.Tick() * 60 // Normalize to 60fps
 
 		// Handle events
 		for {
@@ -101,8 +96,6 @@ func main() {
 				running = false
 
 			case glow.EventKeyDown:
-				keys[event.Key] = true
-
 				if event.Key == glow.KeyEscape {
 					running = false
 				}
@@ -117,26 +110,23 @@ func main() {
 						gameOver = false
 					}
 				}
-
-			case glow.EventKeyUp:
-				keys[event.Key] = false
 			}
 		}
 
 		if gameStarted && !gameOver {
 			// Move paddle 1 (W/S)
-			if keys[glow.KeyW] {
+			if win.IsKeyDown(glow.KeyW) {
 				paddle1.Y -= paddleSpeed * dt
 			}
-			if keys[glow.KeyS] {
+			if win.IsKeyDown(glow.KeyS) {
 				paddle1.Y += paddleSpeed * dt
 			}
 
 			// Move paddle 2 (Up/Down)
-			if keys[glow.KeyUp] {
+			if win.IsKeyDown(glow.KeyUp) {
 				paddle2.Y -= paddleSpeed * dt
 			}
-			if keys[glow.KeyDown] {
+			if win.IsKeyDown(glow.KeyDown) {
 				paddle2.Y += paddleSpeed * dt
 			}
 
@@ -158,6 +148,7 @@ func main() {
 			if ballHitsPaddle(ball, paddle1) {
 				ball.VX = math.Abs(ball.VX) // Go right
 				ball.X = paddle1.X + paddle1.Width + 1
+				glow.Beep(440, 40*time.Millisecond)
 				// Add spin based on where it hits the paddle
 				relativeY := (ball.Y + ball.Size/2) - (paddle1.Y + paddle1.Height/2)
 				ball.VY += relativeY * 0.1
@@ -168,6 +159,7 @@ func main() {
 			if ballHitsPaddle(ball, paddle2) {
 				ball.VX = -math.Abs(ball.VX) // Go left
 				ball.X = paddle2.X - ball.Size - 1
+				glow.Beep(440, 40*time.Millisecond)
 				// Add spin
 				relativeY := (ball.Y + ball.Size/2) - (paddle2.Y + paddle2.Height/2)
 				ball.VY += relativeY * 0.1
@@ -177,6 +169,7 @@ func main() {
 
 			// Scoring
 			if ball.X < 0 {
+				glow.Beep(220, 150*time.Millisecond)
 				paddle2.Score++
 				if paddle2.Score >= winScore {
 					gameOver = true
@@ -187,6 +180,7 @@ func main() {
 			}
 
 			if ball.X > float64(screenWidth) {
+				glow.Beep(220, 150*time.Millisecond)
 				paddle1.Score++
 				if paddle1.Score >= winScore {
 					gameOver = true
@@ -231,8 +225,7 @@ func main() {
 			drawCenteredText(canvas, "PRESS SPACE TO RESTART", screenHeight/2+20)
 		}
 
-		win.Present()
-		time.Sleep(16 * time.Millisecond)
+		win.PresentThrottled(60)
 	}
 
 	fmt.Println("\nGame Over!")
@@ -325,14 +318,8 @@ func drawNumber(canvas *glow.Canvas, n int, x, y int, color glow.Color) {
 }
 
 func drawCenteredText(canvas *glow.Canvas, text string, y int) {
-	// Simple pixel text - just draw a rectangle as placeholder
-	w := len(text) * 8
+	const scale = 3
+	w, _ := glow.MeasureText(text, scale)
 	x := (screenWidth - w) / 2
-	canvas.DrawRect(x, y, w, 16, glow.RGB(80, 80, 80))
-	// Draw dots for each character
-	for i, c := range text {
-		if c != ' ' {
-			canvas.DrawRect(x+i*8+2, y+4, 4, 8, glow.White)
-		}
-	}
+	canvas.DrawTextOutlined(x, y, text, glow.White, glow.RGB(80, 80, 80), scale)
 }
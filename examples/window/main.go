@@ -29,6 +29,10 @@ func main() {
 	if err := conn.MapWindow(windowID); err != nil {
 		log.Fatal(err)
 	}
+	// Wait for the server to process the requests so far
+	if err := conn.Sync(); err != nil {
+		log.Fatal(err)
+	}
 	fmt.Println("Window mapped (visible)")
 
 	// Keep the window open for 5 seconds
@@ -39,5 +43,8 @@ func main() {
 	if err := conn.DestroyWindow(windowID); err != nil {
 		log.Printf("Error destroying window: %v", err)
 	}
+	if err := conn.Sync(); err != nil {
+		log.Printf("Error syncing: %v", err)
+	}
 	fmt.Println("Window destroyed")
 }
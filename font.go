@@ -0,0 +1,48 @@
+package glow
+
+// bitmapFontSize is the width and height of DefaultFont's glyph cells.
+const bitmapFontSize = 8
+
+// DefaultFont is an 8x8 monospace bitmap font built into the package.
+// DrawText and the other text methods draw in it, as does DrawTextFont
+// given a nil font; replacing it changes them all. It covers printable ASCII, including
+// lowercase, plus box-drawing (─ │ ┌ ╔ ...) and block (█ ▀ ░ ...)
+// characters, which join up across neighbouring cells for simple UI
+// frames. Characters it lacks are drawn as '?'.
+var DefaultFont = newBitmapFont(font8x8)
+
+// newBitmapFont makes a Font drawing glyphs from 8x8 bitmaps.
+func newBitmapFont(glyphs map[rune][8]uint8) *Font {
+	return &Font{
+		size:       bitmapFontSize,
+		ascent:     bitmapFontSize - 1, // The bottom row holds descenders
+		descent:    1,
+		lineHeight: bitmapFontSize, // Box-drawing lines meet between rows
+		bitmap:     glyphs,
+		cache:      newGlyphCache(bitmapFontSize, bitmapFontSize, len(glyphs)+1),
+	}
+}
+
+// bitmapGlyph converts a bitmap glyph to a coverage mask.
+func (f *Font) bitmapGlyph(r rune) *fontGlyph {
+	rows, ok := f.bitmap[r]
+	if !ok {
+		rows = f.bitmap['?']
+	}
+	g := &fontGlyph{
+		mask:    make([]uint8, bitmapFontSize*bitmapFontSize),
+		stride:  bitmapFontSize,
+		width:   bitmapFontSize,
+		height:  bitmapFontSize,
+		offY:    -f.ascent,
+		advance: bitmapFontSize,
+	}
+	for y, bits := range rows {
+		for x := 0; x < bitmapFontSize; x++ {
+			if bits&(1<<x) != 0 {
+				g.mask[y*bitmapFontSize+x] = 255
+			}
+		}
+	}
+	return g
+}
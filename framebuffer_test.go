@@ -0,0 +1,307 @@
+package glow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// boxScanFillCircle is the original bounding-box FillCircle, kept as the
+// reference the scanline version must match pixel for pixel.
+func boxScanFillCircle(fb *x11.Framebuffer, cx, cy, radius int, r, g, b uint8) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				fb.SetPixel(cx+x, cy+y, r, g, b)
+			}
+		}
+	}
+}
+
+func TestFillCircleMatchesBoxScan(t *testing.T) {
+	for radius := 0; radius <= 40; radius++ {
+		// Centred, and hanging off the top-left edge to exercise clipping
+		for _, c := range [][2]int{{50, 50}, {3, -2}} {
+			want := x11.NewFramebuffer(100, 100)
+			got := x11.NewFramebuffer(100, 100)
+			boxScanFillCircle(want, c[0], c[1], radius, 255, 128, 0)
+			got.FillCircle(c[0], c[1], radius, 255, 128, 0)
+			if !bytes.Equal(want.Pixels, got.Pixels) {
+				t.Fatalf("radius %d at %v: scanline output differs from box scan", radius, c)
+			}
+		}
+	}
+}
+
+func BenchmarkFillCircle(b *testing.B) {
+	fb := x11.NewFramebuffer(800, 600)
+	for i := 0; i < b.N; i++ {
+		fb.FillCircle(400, 300, 250, 255, 128, 0)
+	}
+}
+
+func BenchmarkFillCircleBoxScan(b *testing.B) {
+	fb := x11.NewFramebuffer(800, 600)
+	for i := 0; i < b.N; i++ {
+		boxScanFillCircle(fb, 400, 300, 250, 255, 128, 0)
+	}
+}
+
+func TestDrawCircleAABlendsEdge(t *testing.T) {
+	fb := x11.NewFramebuffer(41, 41)
+	fb.DrawCircleAA(20, 20, 10, 255, 255, 255)
+
+	// On the axes the circle passes exactly through a pixel centre
+	assertFBPixel(t, fb, 30, 20, 255, 255, 255)
+	assertFBPixel(t, fb, 20, 10, 255, 255, 255)
+
+	// At x=3 the circle is at y≈9.54, so the two pixels straddling it
+	// share the coverage; every octant must get the same blend exactly once
+	for _, p := range [][2]int{{3, 9}, {-3, 9}, {9, -3}, {-9, -3}} {
+		r, _, _ := fb.GetPixel(20+p[0], 20+p[1])
+		if r != 117 {
+			t.Errorf("inner pixel %v: expected 117, got %d", p, r)
+		}
+	}
+	r, _, _ := fb.GetPixel(23, 30)
+	if r != 138 {
+		t.Errorf("outer pixel: expected 138, got %d", r)
+	}
+
+	// The inside stays untouched
+	assertFBPixel(t, fb, 20, 20, 0, 0, 0)
+}
+
+func TestCanvasAntialiasToggle(t *testing.T) {
+	c := &Canvas{fb: x11.NewFramebuffer(16, 16)}
+	c.DrawLine(0, 0, 10, 5, White)
+	assertFBPixel(t, c.fb, 10, 5, 255, 255, 255)
+	if a, _, _ := c.fb.GetPixel(1, 0); a != 0 && a != 255 {
+		t.Fatalf("aliased line should set whole pixels, got %d", a)
+	}
+
+	c.Clear(Black)
+	c.SetAntialias(true)
+	c.DrawLine(0, 0, 10, 5, White)
+	// At x=1 the line is at y=0.5, split evenly between two rows
+	r0, _, _ := c.fb.GetPixel(1, 0)
+	r1, _, _ := c.fb.GetPixel(1, 1)
+	if r0 != 128 || r1 != 128 {
+		t.Errorf("antialiased line at x=1: got %d and %d, want 128 each", r0, r1)
+	}
+	assertFBPixel(t, c.fb, 0, 0, 255, 255, 255)
+	assertFBPixel(t, c.fb, 10, 5, 255, 255, 255)
+
+	c.Clear(Black)
+	c.FillCircle(8, 8, 5, White)
+	assertFBPixel(t, c.fb, 8, 8, 255, 255, 255)
+	assertFBPixel(t, c.fb, 12, 8, 255, 255, 255)
+	// The rim pixel on the axis is half inside radius+0.5
+	if r, _, _ := c.fb.GetPixel(13, 8); r != 128 {
+		t.Errorf("rim pixel: got %d, want 128", r)
+	}
+	assertFBPixel(t, c.fb, 14, 8, 0, 0, 0)
+}
+
+func TestFillTriangle(t *testing.T) {
+	fb := x11.NewFramebuffer(8, 8)
+	fb.FillTriangle(1, 1, 6, 1, 1, 6, 255, 255, 255)
+
+	for _, p := range [][2]int{{1, 1}, {2, 2}, {5, 1}, {1, 5}, {3, 2}} {
+		assertFBPixel(t, fb, p[0], p[1], 255, 255, 255)
+	}
+	for _, p := range [][2]int{{0, 0}, {6, 6}, {5, 5}, {7, 1}, {1, 7}, {0, 3}} {
+		assertFBPixel(t, fb, p[0], p[1], 0, 0, 0)
+	}
+
+	// Winding doesn't matter
+	other := x11.NewFramebuffer(8, 8)
+	other.FillTriangle(1, 1, 1, 6, 6, 1, 255, 255, 255)
+	if !bytes.Equal(fb.Pixels, other.Pixels) {
+		t.Error("reversed winding filled different pixels")
+	}
+
+	// Collinear points draw nothing
+	flat := x11.NewFramebuffer(8, 8)
+	flat.FillTriangle(0, 0, 3, 3, 7, 7, 255, 255, 255)
+	if !bytes.Equal(flat.Pixels, make([]byte, len(flat.Pixels))) {
+		t.Error("degenerate triangle drew pixels")
+	}
+
+	// Clipped triangles don't panic
+	flat.FillTriangle(-10, -10, 20, -10, -10, 20, 255, 255, 255)
+	assertFBPixel(t, flat, 0, 0, 255, 255, 255)
+}
+
+func TestFillTriangleSharedEdge(t *testing.T) {
+	// Two halves of a quad: together they cover it exactly once
+	a := x11.NewFramebuffer(8, 8)
+	b := x11.NewFramebuffer(8, 8)
+	a.FillTriangle(0, 0, 7, 0, 7, 7, 255, 255, 255)
+	b.FillTriangle(0, 0, 7, 7, 0, 7, 255, 255, 255)
+
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			ra, _, _ := a.GetPixel(x, y)
+			rb, _, _ := b.GetPixel(x, y)
+			inQuad := x < 7 && y < 7 // right and bottom edges are excluded
+			switch {
+			case ra != 0 && rb != 0:
+				t.Errorf("pixel (%d,%d) filled by both triangles", x, y)
+			case inQuad && ra == 0 && rb == 0:
+				t.Errorf("pixel (%d,%d) left as a seam", x, y)
+			case !inQuad && (ra != 0 || rb != 0):
+				t.Errorf("pixel (%d,%d) outside the quad filled", x, y)
+			}
+		}
+	}
+}
+
+func TestDrawLineThick(t *testing.T) {
+	fb := x11.NewFramebuffer(16, 8)
+	fb.DrawLineThick(2, 4, 13, 4, 3, 255, 255, 255)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			r, _, _ := fb.GetPixel(x, y)
+			want := x >= 2 && x <= 13 && y >= 3 && y <= 5
+			if (r != 0) != want {
+				t.Errorf("pixel (%d,%d) set=%v, want %v", x, y, r != 0, want)
+			}
+		}
+	}
+
+	// Vertical lines get three columns, clipped at the edge
+	fb = x11.NewFramebuffer(8, 8)
+	fb.DrawLineThick(0, -2, 0, 10, 3, 255, 255, 255)
+	for y := 0; y < 8; y++ {
+		assertFBPixel(t, fb, 0, y, 255, 255, 255)
+		assertFBPixel(t, fb, 1, y, 255, 255, 255)
+		assertFBPixel(t, fb, 2, y, 0, 0, 0)
+	}
+
+	// A 45-degree line has no gaps across it
+	fb = x11.NewFramebuffer(16, 16)
+	fb.DrawLineThick(0, 0, 15, 15, 3, 255, 255, 255)
+	for i := 1; i < 15; i++ {
+		for _, p := range [][2]int{{i, i}, {i + 1, i}, {i, i + 1}, {i - 1, i}, {i, i - 1}} {
+			assertFBPixel(t, fb, p[0], p[1], 255, 255, 255)
+		}
+		assertFBPixel(t, fb, i+3, i, 0, 0, 0)
+	}
+}
+
+func TestDrawLineThickWidthOne(t *testing.T) {
+	lines := [][4]int{{0, 0, 15, 15}, {1, 14, 14, 3}, {3, 2, 5, 15}, {15, 7, 0, 7}, {4, 4, 4, 4}}
+	for _, l := range lines {
+		a := x11.NewFramebuffer(16, 16)
+		b := x11.NewFramebuffer(16, 16)
+		a.DrawLine(l[0], l[1], l[2], l[3], 255, 255, 255)
+		b.DrawLineThick(l[0], l[1], l[2], l[3], 1, 255, 255, 255)
+		if !bytes.Equal(a.Pixels, b.Pixels) {
+			t.Errorf("width 1 line %v differs from DrawLine", l)
+		}
+	}
+}
+
+// numberedFramebuffer gives every pixel a distinct red/green value
+func numberedFramebuffer(w, h int) *x11.Framebuffer {
+	fb := x11.NewFramebuffer(w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fb.SetPixel(x, y, uint8(x), uint8(y), 0)
+		}
+	}
+	return fb
+}
+
+func TestCopyRegionOverlap(t *testing.T) {
+	// Shift by one pixel in each direction, overlapping the source
+	for _, d := range [][2]int{{1, 1}, {-1, -1}, {1, -1}, {-1, 1}, {2, 0}, {0, 2}} {
+		fb := numberedFramebuffer(8, 8)
+		fb.CopyRegion(2, 2, 4, 4, 2+d[0], 2+d[1])
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				assertFBPixel(t, fb, 2+d[0]+x, 2+d[1]+y, uint8(2+x), uint8(2+y), 0)
+			}
+		}
+	}
+}
+
+func TestCopyRegionClip(t *testing.T) {
+	fb := numberedFramebuffer(8, 8)
+	// Source hangs off the top-left, destination off the bottom-right
+	fb.CopyRegion(-2, -2, 6, 6, 3, 3)
+	assertFBPixel(t, fb, 7, 7, 2, 2, 0)
+	assertFBPixel(t, fb, 5, 5, 0, 0, 0)
+	assertFBPixel(t, fb, 4, 4, 4, 4, 0) // outside the destination
+
+	// Nothing left after clipping
+	fb.CopyRegion(0, 0, 4, 4, 8, 0)
+	fb.CopyRegion(0, 0, 0, 4, 2, 2)
+}
+
+func BenchmarkDrawGlow(b *testing.B) {
+	c := NewCanvas(800, 600)
+	for i := 0; i < b.N; i++ {
+		c.DrawGlow(i%800, 300, 8, RGB(255, 160, 40))
+	}
+}
+
+func TestFillEllipse(t *testing.T) {
+	radii := [][2]int{{1, 1}, {10, 4}, {4, 10}, {30, 17}, {7, 7}, {0, 5}, {5, 0}, {0, 0}}
+	for _, rad := range radii {
+		rx, ry := rad[0], rad[1]
+		fb := x11.NewFramebuffer(100, 100)
+		cx, cy := 50, 50
+		fb.FillEllipse(cx, cy, rx, ry, 255, 255, 255)
+
+		// Centre and cardinal points are set
+		for _, p := range [][2]int{{cx, cy}, {cx - rx, cy}, {cx + rx, cy}, {cx, cy - ry}, {cx, cy + ry}} {
+			if r, _, _ := fb.GetPixel(p[0], p[1]); r != 255 {
+				t.Errorf("radii %d,%d: pixel %v not set", rx, ry, p)
+			}
+		}
+		// Nothing outside the bounding box
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 100; x++ {
+				inside := x >= cx-rx && x <= cx+rx && y >= cy-ry && y <= cy+ry
+				if r, _, _ := fb.GetPixel(x, y); r != 0 && !inside {
+					t.Fatalf("radii %d,%d: pixel (%d, %d) outside the bounding box", rx, ry, x, y)
+				}
+			}
+		}
+	}
+
+	// Ellipses hanging off the edge are clipped
+	fb := x11.NewFramebuffer(10, 10)
+	fb.FillEllipse(-3, 5, 8, 4, 255, 0, 0)
+	if r, _, _ := fb.GetPixel(5, 5); r != 255 {
+		t.Error("clipped ellipse missing its right end")
+	}
+}
+
+func TestDrawEllipseOutline(t *testing.T) {
+	fb := x11.NewFramebuffer(100, 100)
+	fb.DrawEllipse(50, 50, 20, 8, 255, 255, 255)
+	for _, p := range [][2]int{{30, 50}, {70, 50}, {50, 42}, {50, 58}} {
+		if r, _, _ := fb.GetPixel(p[0], p[1]); r != 255 {
+			t.Errorf("pixel %v not set", p)
+		}
+	}
+	if r, _, _ := fb.GetPixel(50, 50); r != 0 {
+		t.Error("outline filled its centre")
+	}
+	// Every row from top to bottom has outline pixels
+	for y := 42; y <= 58; y++ {
+		n := 0
+		for x := 0; x < 100; x++ {
+			if r, _, _ := fb.GetPixel(x, y); r != 0 {
+				n++
+			}
+		}
+		if n == 0 {
+			t.Errorf("row %d of the outline is empty", y)
+		}
+	}
+}
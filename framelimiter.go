@@ -0,0 +1,76 @@
+package glow
+
+import "time"
+
+// FrameLimiter paces a loop that isn't tied to a Window, such as one
+// drawing through the x11 package directly or a fixed-rate simulation:
+//
+//	limiter := glow.NewFrameLimiter(60)
+//	for running {
+//		update()
+//		draw()
+//		limiter.Wait()
+//	}
+//
+// Unlike a fixed time.Sleep, Wait subtracts the time the frame took, and
+// frames are scheduled from a running deadline rather than from each
+// other, so rounding errors don't add up to drift. Window loops can use
+// SetMaxFPS instead.
+type FrameLimiter struct {
+	interval time.Duration
+	deadline time.Time // When the next Wait should return
+	last     time.Time // When the previous Wait returned
+	fps      float64
+
+	// Clock, replaced in tests
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewFrameLimiter returns a limiter for fps frames per second. An fps of
+// 0 or less never sleeps, but still measures LastFPS.
+func NewFrameLimiter(fps int) *FrameLimiter {
+	l := &FrameLimiter{now: time.Now, sleep: time.Sleep}
+	if fps > 0 {
+		l.interval = time.Second / time.Duration(fps)
+	}
+	return l
+}
+
+// Wait sleeps until one frame interval has passed since the previous
+// Wait returned, less any time already spent since. A frame that overran
+// its interval returns at once, and the schedule restarts from there
+// rather than rushing the following frames to catch up. The first call
+// returns at once.
+func (l *FrameLimiter) Wait() {
+	now := l.now()
+	slept := false
+	if l.interval > 0 && now.Before(l.deadline) {
+		l.sleep(l.deadline.Sub(now))
+		now = l.now()
+		slept = true
+	}
+
+	l.fps = 0
+	if !l.last.IsZero() {
+		if frameTime := now.Sub(l.last); frameTime > 0 {
+			l.fps = float64(time.Second) / float64(frameTime)
+		}
+	}
+	l.last = now
+
+	// After a sleep, the next frame is due one interval after this one
+	// was, whatever the sleep overshot. After an overrun (or the first
+	// frame), the schedule starts over from now.
+	if slept {
+		l.deadline = l.deadline.Add(l.interval)
+	} else {
+		l.deadline = now.Add(l.interval)
+	}
+}
+
+// LastFPS returns the frame rate of the last frame, measured between
+// the last two Waits, or 0 before the second Wait.
+func (l *FrameLimiter) LastFPS() float64 {
+	return l.fps
+}
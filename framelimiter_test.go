@@ -0,0 +1,94 @@
+package glow
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock for FrameLimiter that only moves when told to
+// or when slept on. Sleeps overshoot by oversleep, like a real scheduler.
+type fakeClock struct {
+	t         time.Time
+	oversleep time.Duration
+	slept     time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) sleep(d time.Duration) {
+	c.t = c.t.Add(d + c.oversleep)
+	c.slept += d
+}
+
+func newTestLimiter(fps int, clock *fakeClock) *FrameLimiter {
+	l := NewFrameLimiter(fps)
+	l.now, l.sleep = clock.now, clock.sleep
+	return l
+}
+
+func TestFrameLimiterInterval(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0), oversleep: 300 * time.Microsecond}
+	l := newTestLimiter(60, clock)
+	l.Wait()
+	start := clock.t
+
+	// Frames take 5ms to draw; the limiter makes up the rest
+	const frames = 600
+	for i := 0; i < frames; i++ {
+		clock.t = clock.t.Add(5 * time.Millisecond)
+		l.Wait()
+	}
+	elapsed := clock.t.Sub(start)
+	want := frames * (time.Second / 60)
+	if diff := elapsed - want; diff < 0 || diff > time.Millisecond {
+		t.Errorf("%d frames took %v, want %v (oversleeping shouldn't add up)", frames, elapsed, want)
+	}
+	if fps := l.LastFPS(); math.Abs(fps-60) > 2 {
+		t.Errorf("LastFPS = %.2f, want about 60", fps)
+	}
+}
+
+func TestFrameLimiterOverrun(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := newTestLimiter(50, clock) // 20ms frames
+	l.Wait()
+
+	// A 70ms frame doesn't sleep...
+	clock.t = clock.t.Add(70 * time.Millisecond)
+	l.Wait()
+	if clock.slept != 0 {
+		t.Errorf("overrun frame slept %v", clock.slept)
+	}
+	if fps := l.LastFPS(); math.Abs(fps-1000.0/70) > 0.01 {
+		t.Errorf("LastFPS = %.2f after a 70ms frame", fps)
+	}
+
+	// ...and the next frames aren't rushed to catch up
+	for i := 0; i < 3; i++ {
+		before := clock.t
+		clock.t = clock.t.Add(2 * time.Millisecond)
+		l.Wait()
+		if d := clock.t.Sub(before); d != 20*time.Millisecond {
+			t.Errorf("frame %d after overrun took %v, want 20ms", i, d)
+		}
+	}
+}
+
+func TestFrameLimiterUncapped(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := newTestLimiter(0, clock)
+	if l.LastFPS() != 0 {
+		t.Errorf("LastFPS before any frame = %v", l.LastFPS())
+	}
+	for i := 0; i < 3; i++ {
+		clock.t = clock.t.Add(4 * time.Millisecond)
+		l.Wait()
+	}
+	if clock.slept != 0 {
+		t.Errorf("uncapped limiter slept %v", clock.slept)
+	}
+	if fps := l.LastFPS(); fps != 250 {
+		t.Errorf("LastFPS = %v, want 250", fps)
+	}
+}
@@ -0,0 +1,31 @@
+package glow
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrRumbleUnsupported is returned by Gamepad.Rumble when the device
+// has no rumble motors (or the kernel driver doesn't expose them).
+var ErrRumbleUnsupported = errors.New("glow: gamepad does not support rumble")
+
+// ErrGamepadUnsupported is returned by OpenGamepad where glow doesn't
+// know the kernel's evdev structure layout: outside Linux, and on 32-bit
+// or big-endian Linux.
+var ErrGamepadUnsupported = errors.New("glow: gamepads are not supported on this platform")
+
+// Gamepad is a game controller opened through its Linux evdev device
+// (/dev/input/eventN). The user needs read and write access to the
+// device, which desktop distributions grant for local sessions.
+type Gamepad struct {
+	f         *os.File
+	name      string
+	canRumble bool
+	effectID  int16 // Uploaded rumble effect, -1 if none
+}
+
+// Name returns the device name reported by the driver.
+func (g *Gamepad) Name() string { return g.name }
+
+// CanRumble reports whether the gamepad has rumble motors.
+func (g *Gamepad) CanRumble() bool { return g.canRumble }
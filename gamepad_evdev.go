@@ -0,0 +1,132 @@
+//go:build linux && (amd64 || arm64 || loong64 || riscv64)
+
+package glow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Linux evdev constants (linux/input.h, linux/input-event-codes.h)
+const (
+	evFF     = 0x15
+	ffRumble = 0x50
+	ffMax    = 0x7f
+
+	iocWrite = 1
+	iocRead  = 2
+)
+
+// ffEffect mirrors struct ff_effect on 64-bit Linux, the only layout
+// this file is built for (32-bit kernels pack it in 44 bytes). The union
+// holding the effect parameters is 8-byte aligned and 32 bytes long; a
+// rumble effect uses its first two uint16s.
+type ffEffect struct {
+	Type            uint16
+	ID              int16
+	Direction       uint16
+	TriggerButton   uint16
+	TriggerInterval uint16
+	ReplayLength    uint16
+	ReplayDelay     uint16
+	_               uint16
+	U               [4]uint64
+}
+
+// OpenGamepad opens the evdev device at path, e.g. "/dev/input/event5".
+func OpenGamepad(path string) (*Gamepad, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("glow: open gamepad: %w", err)
+	}
+	g := &Gamepad{f: f, effectID: -1}
+
+	name := make([]byte, 256)
+	if n, err := g.ioctl(ioc(iocRead, 0x06, len(name)), unsafe.Pointer(&name[0])); err == nil && n > 0 {
+		g.name = strings.TrimRight(string(name[:n]), "\x00")
+	}
+
+	// Which force-feedback effects the device supports
+	var ffBits [ffMax/8 + 1]byte
+	if _, err := g.ioctl(ioc(iocRead, 0x20+evFF, len(ffBits)), unsafe.Pointer(&ffBits[0])); err == nil {
+		g.canRumble = ffBits[ffRumble/8]&(1<<(ffRumble%8)) != 0
+	}
+
+	return g, nil
+}
+
+// Rumble vibrates the gamepad at strength (0 to 1) for d. A new call
+// replaces any rumble still playing; a strength of 0 stops it. It
+// returns ErrRumbleUnsupported, doing nothing, on devices without
+// rumble motors.
+func (g *Gamepad) Rumble(strength float64, d time.Duration) error {
+	if !g.canRumble {
+		return ErrRumbleUnsupported
+	}
+
+	magnitude := uint16(math.Max(0, math.Min(1, strength)) * 0xFFFF)
+	ms := d.Milliseconds()
+	if ms > 0xFFFF {
+		ms = 0xFFFF
+	}
+	effect := ffEffect{
+		Type:         ffRumble,
+		ID:           g.effectID, // -1 uploads a new effect, else updates ours
+		ReplayLength: uint16(ms),
+	}
+	// Drive both motors: strong (low frequency) and weak (high frequency)
+	effect.U[0] = uint64(magnitude) | uint64(magnitude)<<16
+
+	if _, err := g.ioctl(ioc(iocWrite, 0x80, int(unsafe.Sizeof(effect))), unsafe.Pointer(&effect)); err != nil {
+		return fmt.Errorf("glow: upload rumble effect: %w", err)
+	}
+	g.effectID = effect.ID
+
+	return g.playEffect(magnitude > 0 && ms > 0)
+}
+
+// playEffect starts or stops the uploaded effect by writing an EV_FF
+// input event to the device.
+func (g *Gamepad) playEffect(play bool) error {
+	// struct input_event: struct timeval (ignored on write), type, code, value
+	ev := make([]byte, 24)
+	binary.LittleEndian.PutUint16(ev[16:], evFF)
+	binary.LittleEndian.PutUint16(ev[18:], uint16(g.effectID))
+	if play {
+		binary.LittleEndian.PutUint32(ev[20:], 1)
+	}
+	if _, err := g.f.Write(ev); err != nil {
+		return fmt.Errorf("glow: play rumble effect: %w", err)
+	}
+	return nil
+}
+
+// Close stops any rumble, frees the effect and closes the device.
+func (g *Gamepad) Close() error {
+	if g.effectID >= 0 {
+		g.playEffect(false)
+		// EVIOCRMFF takes the effect id by value
+		syscall.Syscall(syscall.SYS_IOCTL, g.f.Fd(), ioc(iocWrite, 0x81, 4), uintptr(g.effectID))
+		g.effectID = -1
+	}
+	return g.f.Close()
+}
+
+func (g *Gamepad) ioctl(req uintptr, arg unsafe.Pointer) (int, error) {
+	n, _, errno := syscall.Syscall(syscall.SYS_IOCTL, g.f.Fd(), req, uintptr(arg))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+// ioc builds an evdev ioctl request number (_IOC with type 'E').
+func ioc(dir, nr, size int) uintptr {
+	return uintptr(dir<<30 | size<<16 | 'E'<<8 | nr)
+}
@@ -0,0 +1,21 @@
+//go:build linux && (amd64 || arm64 || loong64 || riscv64)
+
+package glow
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestEvdevIoctlNumbers(t *testing.T) {
+	if size := unsafe.Sizeof(ffEffect{}); size != 48 {
+		t.Fatalf("ffEffect is %d bytes, want 48", size)
+	}
+	// Values from linux/input.h
+	if got := ioc(iocWrite, 0x80, int(unsafe.Sizeof(ffEffect{}))); got != 0x40304580 {
+		t.Errorf("EVIOCSFF = %#x, want 0x40304580", got)
+	}
+	if got := ioc(iocRead, 0x20+evFF, 16); got != 0x80104535 {
+		t.Errorf("EVIOCGBIT(EV_FF) = %#x, want 0x80104535", got)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !(linux && (amd64 || arm64 || loong64 || riscv64))
+
+package glow
+
+import "time"
+
+// OpenGamepad returns ErrGamepadUnsupported on this platform.
+func OpenGamepad(path string) (*Gamepad, error) {
+	return nil, ErrGamepadUnsupported
+}
+
+// Rumble returns ErrRumbleUnsupported; no gamepad opens on this
+// platform.
+func (g *Gamepad) Rumble(strength float64, d time.Duration) error {
+	return ErrRumbleUnsupported
+}
+
+// Close does nothing on this platform.
+func (g *Gamepad) Close() error { return nil }
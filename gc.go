@@ -0,0 +1,120 @@
+package glow
+
+import (
+	"errors"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// LineStyle selects how native lines are drawn.
+type LineStyle int
+
+const (
+	LineSolid      LineStyle = x11.LineSolid      // Continuous line
+	LineOnOffDash  LineStyle = x11.LineOnOffDash  // Dashes with gaps left untouched
+	LineDoubleDash LineStyle = x11.LineDoubleDash // Dashes with gaps in the background color
+)
+
+// FillStyle selects how native shapes are filled. The stippled and tiled
+// styles use the server's default pattern.
+type FillStyle int
+
+const (
+	FillSolid          FillStyle = x11.FillSolid
+	FillTiled          FillStyle = x11.FillTiled
+	FillStippled       FillStyle = x11.FillStippled
+	FillOpaqueStippled FillStyle = x11.FillOpaqueStippled
+)
+
+// GCOptions are the drawing attributes of a GC.
+type GCOptions struct {
+	Foreground Color
+	Background Color // Used by LineDoubleDash gaps and opaque stipples
+	LineWidth  int   // 0 draws the server's fast one-pixel lines
+	LineStyle  LineStyle
+	FillStyle  FillStyle
+}
+
+// GC is a graphics context for drawing natively on the X server, with
+// its own colors and line style. Native drawing goes to the window
+// directly, bypassing the canvas, so call it after Present; the next
+// Present covers it again.
+//
+// GCs are freed when their window closes, and are lost (not recreated)
+// if the window reconnects.
+type GC struct {
+	win *Window
+	id  uint32
+}
+
+// NewGC creates a graphics context with the given attributes.
+func (w *Window) NewGC(opts GCOptions) (*GC, error) {
+	if opts.LineWidth < 0 || opts.LineWidth > 0xFFFF {
+		return nil, errors.New("glow: invalid line width")
+	}
+	mask := uint32(x11.GCForeground | x11.GCBackground | x11.GCLineWidth |
+		x11.GCLineStyle | x11.GCFillStyle | x11.GCGraphicsExposures)
+	id, err := w.conn.CreateGCValues(w.windowID, mask, []uint32{
+		colorPixel(opts.Foreground),
+		colorPixel(opts.Background),
+		uint32(opts.LineWidth),
+		uint32(opts.LineStyle),
+		uint32(opts.FillStyle),
+		0, // no GraphicsExpose events
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := &GC{win: w, id: id}
+	if w.gcs == nil {
+		w.gcs = make(map[*GC]struct{})
+	}
+	w.gcs[g] = struct{}{}
+	return g, nil
+}
+
+// colorPixel packs a color as a TrueColor pixel value.
+func colorPixel(c Color) uint32 {
+	return uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+}
+
+// FillRect fills a rectangle on the window using the GC's fill style.
+func (g *GC) FillRect(x, y, width, height int) error {
+	if g.id == 0 {
+		return errors.New("glow: GC is freed")
+	}
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	return g.win.conn.FillRectangles(g.win.windowID, g.id, []x11.Rectangle{
+		{X: int16(x), Y: int16(y), Width: uint16(width), Height: uint16(height)},
+	})
+}
+
+// DrawLine draws a line on the window using the GC's line attributes.
+func (g *GC) DrawLine(x0, y0, x1, y1 int) error {
+	if g.id == 0 {
+		return errors.New("glow: GC is freed")
+	}
+	return g.win.conn.PolySegment(g.win.windowID, g.id, []x11.Segment{
+		{X1: int16(x0), Y1: int16(y0), X2: int16(x1), Y2: int16(y1)},
+	})
+}
+
+// Free releases the GC on the server. It is safe to call twice.
+func (g *GC) Free() {
+	if g.id == 0 {
+		return
+	}
+	g.win.conn.FreeGC(g.id)
+	delete(g.win.gcs, g)
+	g.id = 0
+}
+
+// freeGCs releases every GC still held by the window.
+func (w *Window) freeGCs() {
+	for g := range w.gcs {
+		g.Free()
+	}
+}
@@ -0,0 +1,201 @@
+package glow
+
+import "math"
+
+// Point is a position in 2D space.
+type Point struct {
+	X, Y float64
+}
+
+// Rect is an axis-aligned rectangle in pixel coordinates.
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// Empty reports whether the rectangle covers no pixels.
+func (r Rect) Empty() bool { return r.Width <= 0 || r.Height <= 0 }
+
+// Intersect returns the area covered by both rectangles, which is empty
+// if they don't overlap.
+func (r Rect) Intersect(o Rect) Rect {
+	x0, y0 := max(r.X, o.X), max(r.Y, o.Y)
+	x1, y1 := min(r.X+r.Width, o.X+o.Width), min(r.Y+r.Height, o.Y+o.Height)
+	if x1 <= x0 || y1 <= y0 {
+		return Rect{}
+	}
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// Union returns the smallest rectangle containing both rectangles.
+// An empty rectangle contributes nothing.
+func (r Rect) Union(o Rect) Rect {
+	if r.Empty() {
+		return o
+	}
+	if o.Empty() {
+		return r
+	}
+	x0, y0 := min(r.X, o.X), min(r.Y, o.Y)
+	x1, y1 := max(r.X+r.Width, o.X+o.Width), max(r.Y+r.Height, o.Y+o.Height)
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// Overlaps reports whether the rectangles share at least one pixel.
+func (r Rect) Overlaps(o Rect) bool {
+	return !r.Empty() && !o.Empty() &&
+		r.X < o.X+o.Width && o.X < r.X+r.Width &&
+		r.Y < o.Y+o.Height && o.Y < r.Y+r.Height
+}
+
+// Cull returns the indices, in order, of the rectangles that overlap
+// view, so only what's on screen needs drawing:
+//
+//	sx, sy := win.Scroll()
+//	view := glow.Rect{X: sx, Y: sy, Width: win.Width(), Height: win.Height()}
+//	for _, i := range glow.Cull(bounds, view) {
+//		...
+//	}
+func Cull(rects []Rect, view Rect) []int {
+	return CullFunc(rects, view, func(r Rect) Rect { return r })
+}
+
+// CullFunc is Cull for any item type, with bounds giving each item's
+// rectangle.
+func CullFunc[T any](items []T, view Rect, bounds func(T) Rect) []int {
+	var visible []int
+	for i, item := range items {
+		if bounds(item).Overlaps(view) {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// Segment is a line segment between two points.
+type Segment struct {
+	A, B Point
+}
+
+// geomEpsilon absorbs floating-point error in the intersection tests
+const geomEpsilon = 1e-9
+
+// cross returns the z component of the cross product of (ax, ay) and (bx, by).
+func cross(ax, ay, bx, by float64) float64 {
+	return ax*by - ay*bx
+}
+
+// SegmentsIntersect reports whether segment a-b intersects segment c-d
+// and returns the intersection point.
+//
+// Parallel segments never intersect. Collinear segments intersect if they
+// overlap; the point returned is then the start of the overlap nearest a.
+func SegmentsIntersect(a, b, c, d Point) (Point, bool) {
+	rx, ry := b.X-a.X, b.Y-a.Y
+	sx, sy := d.X-c.X, d.Y-c.Y
+	qpx, qpy := c.X-a.X, c.Y-a.Y
+
+	denom := cross(rx, ry, sx, sy)
+	if math.Abs(denom) < geomEpsilon {
+		if math.Abs(cross(qpx, qpy, rx, ry)) >= geomEpsilon {
+			return Point{}, false // parallel, not collinear
+		}
+		return collinearOverlap(a, b, c, d)
+	}
+
+	t := cross(qpx, qpy, sx, sy) / denom
+	u := cross(qpx, qpy, rx, ry) / denom
+	if t < -geomEpsilon || t > 1+geomEpsilon || u < -geomEpsilon || u > 1+geomEpsilon {
+		return Point{}, false
+	}
+
+	return Point{a.X + t*rx, a.Y + t*ry}, true
+}
+
+// collinearOverlap handles SegmentsIntersect for collinear segments by
+// projecting c-d onto a-b's parameter range.
+func collinearOverlap(a, b, c, d Point) (Point, bool) {
+	rx, ry := b.X-a.X, b.Y-a.Y
+	rr := rx*rx + ry*ry
+	if rr < geomEpsilon {
+		// a-b is a single point; it hits if it lies on c-d
+		if onSegment(a, c, d) {
+			return a, true
+		}
+		return Point{}, false
+	}
+
+	t0 := ((c.X-a.X)*rx + (c.Y-a.Y)*ry) / rr
+	t1 := ((d.X-a.X)*rx + (d.Y-a.Y)*ry) / rr
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+	if t1 < -geomEpsilon || t0 > 1+geomEpsilon {
+		return Point{}, false
+	}
+
+	t := math.Max(t0, 0)
+	return Point{a.X + t*rx, a.Y + t*ry}, true
+}
+
+// onSegment reports whether p lies on segment c-d.
+func onSegment(p, c, d Point) bool {
+	if math.Abs(cross(d.X-c.X, d.Y-c.Y, p.X-c.X, p.Y-c.Y)) >= geomEpsilon {
+		return false
+	}
+	return p.X >= math.Min(c.X, d.X)-geomEpsilon && p.X <= math.Max(c.X, d.X)+geomEpsilon &&
+		p.Y >= math.Min(c.Y, d.Y)-geomEpsilon && p.Y <= math.Max(c.Y, d.Y)+geomEpsilon
+}
+
+// RayCast casts a ray from origin in direction (dirX, dirY) and returns the
+// nearest point where it hits one of segments, along with the distance to
+// it. The direction does not need to be normalised. ok is false if nothing
+// is hit or the direction is zero.
+//
+// A segment lying along the ray is hit at its nearest point ahead of the
+// origin. Segments parallel to the ray are never hit.
+func RayCast(origin Point, dirX, dirY float64, segments []Segment) (hit Point, dist float64, ok bool) {
+	length := math.Hypot(dirX, dirY)
+	if length < geomEpsilon {
+		return Point{}, 0, false
+	}
+	dx, dy := dirX/length, dirY/length
+
+	best := math.Inf(1)
+	for _, s := range segments {
+		sx, sy := s.B.X-s.A.X, s.B.Y-s.A.Y
+		qpx, qpy := s.A.X-origin.X, s.A.Y-origin.Y
+
+		var t float64
+		denom := cross(dx, dy, sx, sy)
+		if math.Abs(denom) < geomEpsilon {
+			if math.Abs(cross(qpx, qpy, dx, dy)) >= geomEpsilon {
+				continue // parallel, not collinear
+			}
+			// Collinear: distance along the ray to each endpoint
+			t0 := qpx*dx + qpy*dy
+			t1 := (s.B.X-origin.X)*dx + (s.B.Y-origin.Y)*dy
+			if t0 > t1 {
+				t0, t1 = t1, t0
+			}
+			if t1 < 0 {
+				continue // entirely behind the origin
+			}
+			t = math.Max(t0, 0)
+		} else {
+			t = cross(qpx, qpy, sx, sy) / denom
+			u := cross(qpx, qpy, dx, dy) / denom
+			if t < 0 || u < -geomEpsilon || u > 1+geomEpsilon {
+				continue
+			}
+		}
+
+		if t < best {
+			best = t
+		}
+	}
+
+	if math.IsInf(best, 1) {
+		return Point{}, 0, false
+	}
+	return Point{origin.X + best*dx, origin.Y + best*dy}, best, true
+}
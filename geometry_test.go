@@ -0,0 +1,100 @@
+package glow
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSegmentsIntersect(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d Point
+		want       Point
+		ok         bool
+	}{
+		{"crossing", Point{0, 0}, Point{10, 10}, Point{0, 10}, Point{10, 0}, Point{5, 5}, true},
+		{"touching endpoint", Point{0, 0}, Point{5, 0}, Point{5, 0}, Point{5, 5}, Point{5, 0}, true},
+		{"disjoint", Point{0, 0}, Point{1, 1}, Point{2, 0}, Point{3, -1}, Point{}, false},
+		{"parallel", Point{0, 0}, Point{10, 0}, Point{0, 1}, Point{10, 1}, Point{}, false},
+		{"collinear overlap", Point{0, 0}, Point{10, 0}, Point{4, 0}, Point{20, 0}, Point{4, 0}, true},
+		{"collinear contains a", Point{2, 0}, Point{3, 0}, Point{10, 0}, Point{0, 0}, Point{2, 0}, true},
+		{"collinear apart", Point{0, 0}, Point{1, 0}, Point{2, 0}, Point{3, 0}, Point{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := SegmentsIntersect(tt.a, tt.b, tt.c, tt.d)
+		if ok != tt.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tt.name, tt.ok, ok)
+			continue
+		}
+		if ok && !closePoint(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRayCast(t *testing.T) {
+	walls := []Segment{
+		{Point{10, -5}, Point{10, 5}}, // wall ahead
+		{Point{5, -5}, Point{5, 5}},   // nearer wall ahead
+		{Point{-3, -5}, Point{-3, 5}}, // wall behind
+		{Point{0, 2}, Point{20, 2}},   // parallel to the ray
+	}
+
+	hit, dist, ok := RayCast(Point{0, 0}, 2, 0, walls)
+	if !ok {
+		t.Fatalf("expected a hit")
+	}
+	if !closePoint(hit, Point{5, 0}) || math.Abs(dist-5) > 1e-9 {
+		t.Errorf("expected hit (5,0) at 5, got %v at %v", hit, dist)
+	}
+
+	// Looking up only sees the parallel wall edge-on: no hit
+	if _, _, ok := RayCast(Point{0, 0}, 0, -1, walls[3:]); ok {
+		t.Errorf("expected no hit")
+	}
+
+	// A segment along the ray is hit at its near end
+	along := []Segment{{Point{7, 0}, Point{3, 0}}}
+	hit, dist, ok = RayCast(Point{0, 0}, 1, 0, along)
+	if !ok || !closePoint(hit, Point{3, 0}) || math.Abs(dist-3) > 1e-9 {
+		t.Errorf("collinear: expected (3,0) at 3, got %v at %v (ok=%v)", hit, dist, ok)
+	}
+
+	if _, _, ok := RayCast(Point{0, 0}, 0, 0, walls); ok {
+		t.Errorf("zero direction: expected no hit")
+	}
+}
+
+func closePoint(a, b Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestCull(t *testing.T) {
+	view := Rect{X: 100, Y: 100, Width: 200, Height: 100}
+	rects := []Rect{
+		{X: 150, Y: 120, Width: 10, Height: 10}, // Inside
+		{X: 90, Y: 90, Width: 20, Height: 20},   // Across the corner
+		{X: 300, Y: 150, Width: 10, Height: 10}, // Touches the right edge only
+		{X: 0, Y: 0, Width: 50, Height: 50},     // Far away
+		{X: 120, Y: 120, Width: 0, Height: 10},  // Empty
+		{X: 0, Y: 0, Width: 1000, Height: 1000}, // Covers the view
+	}
+	got := Cull(rects, view)
+	want := []int{0, 1, 5}
+	if len(got) != len(want) {
+		t.Fatalf("Cull = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Cull = %v, want %v", got, want)
+		}
+	}
+
+	type sprite struct{ x, y int }
+	sprites := []sprite{{0, 0}, {110, 110}}
+	vis := CullFunc(sprites, view, func(s sprite) Rect { return Rect{X: s.x, Y: s.y, Width: 16, Height: 16} })
+	if len(vis) != 1 || vis[0] != 1 {
+		t.Errorf("CullFunc = %v, want [1]", vis)
+	}
+}
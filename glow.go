@@ -4,42 +4,83 @@ package glow
 
 import (
 	"encoding/binary"
+	"sync/atomic"
+	"time"
 
 	"github.com/AchrafSoltani/glow/internal/x11"
 )
 
-// Color represents an RGB color
+// Color represents an RGB color with an alpha channel. A is opacity:
+// 255 is opaque and 0 fully transparent. The drawing methods blend
+// translucent colors over the canvas; Clear ignores A. A keyed literal
+// such as Color{R: 255} leaves A at 0 and draws nothing, so give A or
+// use RGB.
 type Color struct {
-	R, G, B uint8
+	R, G, B, A uint8
 }
 
 // Predefined colors
 var (
-	Black   = Color{0, 0, 0}
-	White   = Color{255, 255, 255}
-	Red     = Color{255, 0, 0}
-	Green   = Color{0, 255, 0}
-	Blue    = Color{0, 0, 255}
-	Yellow  = Color{255, 255, 0}
-	Cyan    = Color{0, 255, 255}
-	Magenta = Color{255, 0, 255}
-	Orange  = Color{255, 165, 0}
-	Purple  = Color{128, 0, 128}
-	Gray    = Color{128, 128, 128}
+	Black   = Color{0, 0, 0, 255}
+	White   = Color{255, 255, 255, 255}
+	Red     = Color{255, 0, 0, 255}
+	Green   = Color{0, 255, 0, 255}
+	Blue    = Color{0, 0, 255, 255}
+	Yellow  = Color{255, 255, 0, 255}
+	Cyan    = Color{0, 255, 255, 255}
+	Magenta = Color{255, 0, 255, 255}
+	Orange  = Color{255, 165, 0, 255}
+	Purple  = Color{128, 0, 128, 255}
+	Gray    = Color{128, 128, 128, 255}
 )
 
-// RGB creates a color from red, green, blue components
+// RGB creates an opaque color from red, green, blue components
 func RGB(r, g, b uint8) Color {
-	return Color{r, g, b}
+	return Color{r, g, b, 255}
 }
 
-// Hex creates a color from a hex value (0xRRGGBB)
+// RGBA creates a color from red, green, blue and alpha components.
+// The components are not premultiplied by alpha.
+func RGBA(r, g, b, a uint8) Color {
+	return Color{r, g, b, a}
+}
+
+// Hex creates an opaque color from a hex value (0xRRGGBB)
 func Hex(hex uint32) Color {
 	return Color{
 		R: uint8((hex >> 16) & 0xFF),
 		G: uint8((hex >> 8) & 0xFF),
 		B: uint8(hex & 0xFF),
+		A: 255,
+	}
+}
+
+// ErrNoDisplay is returned (wrapped) by NewWindow and OpenDisplay when
+// there is no X server to connect to, as on CI machines and headless
+// servers. Test for it with errors.Is.
+var ErrNoDisplay = x11.ErrNoDisplay
+
+// SetAuthProtocol makes connections to the X server authenticate only
+// with Xauthority entries of the auth protocol name, such as
+// "MIT-MAGIC-COOKIE-1", for when the file holds several and the
+// server wants a particular one. Without a matching entry no auth is
+// sent. The default, "", prefers MIT-MAGIC-COOKIE-1 and falls back to
+// any entry for the display. It applies to windows and displays opened
+// afterwards.
+func SetAuthProtocol(name string) {
+	x11.SetAuthProtocol(name)
+}
+
+// DisplayAvailable reports whether an X server can be connected to.
+// It opens and immediately closes a connection, so call it once at
+// startup rather than every frame.
+func DisplayAvailable() bool {
+	conn, err := x11.Connect()
+	if err != nil {
+		return false
 	}
+	conn.Close()
+	return true
 }
 
 // Window represents a graphics window
@@ -47,41 +88,183 @@ type Window struct {
 	conn     *x11.Connection
 	windowID uint32
 	gcID     uint32
+	title    string
+	class    [2]string // WM_CLASS instance and class set by SetClass
 	canvas   *Canvas
 	width    int
 	height   int
-	closed   bool
+	closed   atomic.Bool // Read by Display.WaitEvent from any goroutine
+
+	// Visual, when not the root's (see NewTranslucentWindow)
+	translucent bool   // An ARGB visual was asked for
+	depth       uint8  // 0 for the root's
+	visual      uint32 // 0 for the root's
+	colormap    uint32 // Made for visual; 0 if none
 
 	// Fullscreen state
 	fullscreen bool
 
+	// Frame pacing
+	lastFrame   time.Time
+	maxFPS      int
+	fps         float64
+	refreshRate float64 // Measured by EstimateRefreshRate, 0 until then
+
+	// Drag-and-drop state, only touched by the event goroutine
+	dnd dndState
+
+	// Window manager state, set by the event goroutine (see windowstate.go)
+	state windowState
+	// Deliver EventPropertyChanged (see SetPropertyEvents)
+	propertyEvents atomic.Bool
+
+	// Connection loss handling (see reconnect.go)
+	lost          atomic.Bool
+	disconnected  bool // OnDisconnect already called for this loss
+	onDisconnect  func()
+	autoReconnect bool
+
+	// Virtual canvas size and scroll offset (see viewport.go)
+	virtualW, virtualH int
+	scrollX, scrollY   int
+	viewBuf            []byte
+
+	// Front buffer for SwapBuffers (see swap.go)
+	swap swapState
+
+	// Cursor composited by Present, nil if none (see cursor.go)
+	cursor *softCursor
+
+	// System cursor, the shapes created for it and the PushCursor
+	// stack (see cursor.go)
+	cursorShape CursorShape
+	cursorIDs   map[CursorShape]uint32
+	cursorStack []savedCursor
+
+	// Whether HideCursor hid the system cursor, and the invisible
+	// cursor it uses
+	cursorHidden bool
+	blankCursor  uint32
+
+	// Server-side pixmaps, freed on Close (see pixmap.go)
+	pixmaps map[*Pixmap]struct{}
+	// Graphics contexts made by NewGC, freed on Close (see gc.go)
+	gcs map[*GC]struct{}
+
+	// Server keyboard mapping, nil until loaded (see keysym.go). Read by
+	// the event goroutine.
+	keymap atomic.Pointer[keymap]
+
+	// Text input (see textinput.go). deadKey is the accent waiting for
+	// the next letter, owned by the event goroutine.
+	textInput atomic.Bool
+	deadKey   Keysym
+
+	// Input state, updated as events are handed to the app (see input.go)
+	keyboard KeyboardState
+	mouse    MouseState
+
+	// Keyboard shortcuts registered with OnShortcut
+	shortcuts map[shortcut]func()
+
+	// Owning display, nil if the window has its own connection
+	display *Display
+
+	// Dirty-rectangle Present state (see putCanvas): the view the last
+	// Present sent, and whether the next must send all of it
+	presented   Rect
+	fullPresent atomic.Bool
+
+	// Off-screen pixmap frames are uploaded into before being copied to
+	// the window, 0 if not created yet (see backbuffer.go)
+	backBuffer   uint32
+	backW, backH int
+	noBackBuffer bool
+
+	// MIT-SHM segment Present copies frames into (see shm.go), whether
+	// the server may still be reading it, and whether it is unavailable
+	shm     *x11.ShmSegment
+	shmBusy bool
+	shmOff  bool
+
 	// Event handling
 	eventChan chan Event
 	quitChan  chan struct{}
+	pollDone  chan struct{} // Closed when the event goroutine exits
 }
 
 // Canvas is the drawing surface
 type Canvas struct {
-	fb *x11.Framebuffer
+	fb    *x11.Framebuffer
+	yUp   bool   // see SetYUp
+	aa    bool   // see SetAntialias
+	dirty []Rect // see DirtyRegions
 }
 
 // NewWindow creates a new window with the given title and dimensions
 func NewWindow(title string, width, height int) (*Window, error) {
+	return newWindow(title, width, height, false)
+}
+
+// NewTranslucentWindow is NewWindow on a 32-bit ARGB visual, so the
+// alpha of each canvas pixel sets its opacity under a compositing
+// manager. Pixels are premultiplied: a color at alpha a should have its
+// red, green and blue scaled by a/255, as ClearRGBA and the drawing
+// methods leave them. Without an ARGB visual it falls back to an
+// opaque window; HasAlpha tells which one it got.
+func NewTranslucentWindow(title string, width, height int) (*Window, error) {
+	return newWindow(title, width, height, true)
+}
+
+// newWindow opens a connection and creates a window on it, on an ARGB
+// visual if translucent is set.
+func newWindow(title string, width, height int, translucent bool) (*Window, error) {
 	conn, err := x11.Connect()
 	if err != nil {
 		return nil, err
 	}
 
-	windowID, err := conn.CreateWindow(100, 100, uint16(width), uint16(height))
+	w, err := createWindow(conn, title, width, height, translucent)
 	if err != nil {
 		conn.Close()
 		return nil, err
 	}
 
+	// Tell the app its real size before the first frame
+	if e := w.initialResize(); e != nil {
+		w.eventChan <- *e
+	}
+
+	// Start event polling goroutine
+	w.pollDone = make(chan struct{})
+	go w.pollEvents(w.conn, w.quitChan, w.pollDone)
+
+	return w, nil
+}
+
+// createWindow creates, configures and maps a window on conn, on an
+// ARGB visual if translucent is set and the screen has one. On error
+// the window's resources are released but conn is left open.
+func createWindow(conn *x11.Connection, title string, width, height int, translucent bool) (*Window, error) {
+	depth, visual, colormap := conn.RootDepth, conn.RootVisual, uint32(0)
+	if v, ok := conn.ARGBVisual(); translucent && ok && v.ID != conn.RootVisual {
+		cm, err := conn.CreateColormap(v.ID)
+		if err != nil {
+			return nil, err
+		}
+		depth, visual, colormap = v.Depth, v.ID, cm
+	}
+	windowID, err := conn.CreateWindowVisual(100, 100, uint16(width), uint16(height), depth, visual, colormap)
+	if err != nil {
+		if colormap != 0 {
+			conn.FreeColormap(colormap)
+		}
+		return nil, err
+	}
+
 	gcID, err := conn.CreateGC(windowID)
 	if err != nil {
 		conn.DestroyWindow(windowID)
-		conn.Close()
 		return nil, err
 	}
 
@@ -89,7 +272,13 @@ func NewWindow(title string, width, height int) (*Window, error) {
 	if err := conn.SetWindowTitle(windowID, title); err != nil {
 		conn.FreeGC(gcID)
 		conn.DestroyWindow(windowID)
-		conn.Close()
+		return nil, err
+	}
+
+	// Default WM_CLASS until the app sets its own
+	if err := conn.SetWindowClass(windowID, defaultClassInstance, defaultClass); err != nil {
+		conn.FreeGC(gcID)
+		conn.DestroyWindow(windowID)
 		return nil, err
 	}
 
@@ -97,14 +286,19 @@ func NewWindow(title string, width, height int) (*Window, error) {
 	if err := conn.EnableCloseButton(windowID); err != nil {
 		conn.FreeGC(gcID)
 		conn.DestroyWindow(windowID)
-		conn.Close()
+		return nil, err
+	}
+
+	// Accept files dropped from file managers
+	if err := conn.EnableDragAndDrop(windowID); err != nil {
+		conn.FreeGC(gcID)
+		conn.DestroyWindow(windowID)
 		return nil, err
 	}
 
 	if err := conn.MapWindow(windowID); err != nil {
 		conn.FreeGC(gcID)
 		conn.DestroyWindow(windowID)
-		conn.Close()
 		return nil, err
 	}
 
@@ -114,34 +308,86 @@ func NewWindow(title string, width, height int) (*Window, error) {
 		conn:      conn,
 		windowID:  windowID,
 		gcID:      gcID,
+		title:     title,
 		canvas:    &Canvas{fb: fb},
 		width:     width,
 		height:    height,
 		eventChan: make(chan Event, 256),
 		quitChan:  make(chan struct{}),
-	}
-
-	// Start event polling goroutine
-	go w.pollEvents()
 
+		translucent: translucent,
+		depth:       depth,
+		visual:      visual,
+		colormap:    colormap,
+	}
+	w.loadKeymap(conn)
 	return w, nil
 }
 
+// initialResize queries the mapped window's actual size and returns it
+// as an EventWindowResize, so the first frame is laid out correctly even
+// if the window manager changed the requested size. It returns nil if
+// the query fails.
+func (w *Window) initialResize() *Event {
+	geom, err := w.conn.GetGeometry(w.windowID)
+	if err != nil {
+		return nil
+	}
+	return &Event{
+		Type:   EventWindowResize,
+		X:      int(geom.X),
+		Y:      int(geom.Y),
+		Width:  int(geom.Width),
+		Height: int(geom.Height),
+	}
+}
+
 // Close closes the window and releases resources
 func (w *Window) Close() {
-	if w.closed {
+	if !w.closed.CompareAndSwap(false, true) {
 		return
 	}
-	w.closed = true
 
 	// Signal event goroutine to stop
 	close(w.quitChan)
 
+	w.freePixmaps()
+	w.freeGCs()
+	w.freeCursors()
+	w.freeShm()
+	w.freeBackBuffer()
 	w.conn.FreeGC(w.gcID)
 	w.conn.DestroyWindow(w.windowID)
+	if w.colormap != 0 {
+		w.conn.FreeColormap(w.colormap)
+	}
+
+	// Windows on a Display share its connection
+	if w.display != nil {
+		w.display.removeWindow(w)
+		return
+	}
 	w.conn.Close()
 }
 
+// Default WM_CLASS for new windows
+const (
+	defaultClassInstance = "glow"
+	defaultClass         = "Glow"
+)
+
+// SetClass sets the window's WM_CLASS hint: instance is usually the
+// program name and class the application name ("myeditor", "MyEditor").
+// Desktops use it to group windows in the taskbar, find the app's icon
+// and apply window rules. Windows default to "glow", "Glow".
+func (w *Window) SetClass(instance, class string) error {
+	if err := w.conn.SetWindowClass(w.windowID, instance, class); err != nil {
+		return err
+	}
+	w.class = [2]string{instance, class}
+	return nil
+}
+
 // SetFullscreen toggles fullscreen mode via _NET_WM_STATE.
 func (w *Window) SetFullscreen(fullscreen bool) error {
 	action := uint32(0) // _NET_WM_STATE_REMOVE
@@ -172,6 +418,38 @@ func (w *Window) SetFullscreen(fullscreen bool) error {
 // IsFullscreen returns the current fullscreen state.
 func (w *Window) IsFullscreen() bool { return w.fullscreen }
 
+// InternAtom returns the X11 atom for name, creating it if needed.
+// Use it to recognise the MessageType of EventClientMessage events.
+func (w *Window) InternAtom(name string) (uint32, error) {
+	atom, err := w.conn.InternAtom(name, false)
+	return uint32(atom), err
+}
+
+// SetPropertyEvents turns EventPropertyChanged on or off (off by
+// default). When on, an event is delivered every time a property of the
+// window is changed or deleted, whether by the window manager (EWMH
+// state such as _NET_WM_STATE) or by another client, so apps can react
+// to changes instead of polling. Most apps don't need the extra events,
+// so the server only sends them while this is on; IsMinimized,
+// IsMaximized and IsFocused are kept up to date either way.
+func (w *Window) SetPropertyEvents(enabled bool) error {
+	mask := uint32(x11.WindowEventMask)
+	if enabled {
+		mask |= x11.PropertyChangeMask
+	}
+	if err := w.conn.SelectInput(w.windowID, mask); err != nil {
+		return err
+	}
+	w.propertyEvents.Store(enabled)
+	return nil
+}
+
+// DeleteProperty removes a property (an atom from InternAtom) from the
+// window, e.g. one set by the app for another client to read.
+func (w *Window) DeleteProperty(property uint32) error {
+	return w.conn.DeleteProperty(w.windowID, x11.Atom(property))
+}
+
 // Width returns the window width
 func (w *Window) Width() int { return w.width }
 
@@ -181,59 +459,365 @@ func (w *Window) Height() int { return w.height }
 // Canvas returns the drawing canvas
 func (w *Window) Canvas() *Canvas { return w.canvas }
 
-// Present copies the canvas to the screen
+// Present copies the canvas to the screen. Only the areas drawn to since
+// the last Present (see Canvas.DirtyRegions) are sent, so a mostly
+// static frame costs little; the whole view is sent on the first
+// Present, after the window is resized, scrolled or exposed, and while a
+// software cursor is set. Present empties the canvas's damage list.
+//
+// Pixels go through shared memory when the server allows it (see
+// SharedMemory), and reach the window in one copy from a back buffer
+// (see SetBackBuffer). With a frame cap set by SetMaxFPS, Present then
+// sleeps for the rest of the frame budget. If the connection to the X
+// server has been lost, Present reports it through OnDisconnect and,
+// with SetAutoReconnect, reconnects first.
 func (w *Window) Present() error {
-	return w.conn.PutImage(w.windowID, w.gcID,
-		uint16(w.canvas.fb.Width), uint16(w.canvas.fb.Height), 0, 0,
-		w.conn.RootDepth, w.canvas.fb.Pixels)
+	err := w.present()
+	w.pace(w.maxFPS)
+	return err
+}
+
+// PresentAll is Present sending the whole view, whatever was drawn, to
+// force a full refresh when the window may be showing stale pixels.
+func (w *Window) PresentAll() error {
+	w.fullPresent.Store(true)
+	return w.Present()
+}
+
+// Sync waits until the X server has processed every request sent so
+// far. Requests are asynchronous: Present returns once the frame is
+// written to the socket, not once it is on screen. Most programs never
+// need Sync, but call it before something that depends on the server
+// having caught up, such as timing how long frames take to land,
+// taking a screenshot with another tool, or exiting right after the last
+// Present. It costs a round trip to the server.
+func (w *Window) Sync() error {
+	return w.conn.Sync()
+}
+
+// present uploads the canvas, recovering from a lost connection.
+func (w *Window) present() error {
+	if w.lost.Load() {
+		if err := w.recoverConnection(errConnectionLost); err != nil {
+			return err
+		}
+	}
+
+	err := w.putCanvas()
+	if err != nil && w.display == nil {
+		// Write errors on the X11 socket mean the server is gone
+		if rerr := w.recoverConnection(err); rerr != nil {
+			return rerr
+		}
+		err = w.putCanvas()
+	}
+	return err
+}
+
+// putCanvas uploads the changed parts of the visible canvas to the
+// window
+func (w *Window) putCanvas() error {
+	view := w.visibleRegion()
+	if err := w.ensureBackBuffer(view); err != nil {
+		return err
+	}
+	regions := w.presentRegions(view)
+	if restore := w.drawSoftwareCursor(); restore != nil {
+		defer restore()
+	}
+	if err := w.putRegions(regions, view); err != nil {
+		// The window's contents are unknown; start over
+		w.fullPresent.Store(true)
+		return err
+	}
+	w.presented = view
+	w.canvas.ResetDirty()
+	return nil
+}
+
+// presentRegions returns the parts of view, in canvas coordinates, that
+// Present must send: the dirty regions within view, or view itself
+// when the window's contents can't be trusted.
+func (w *Window) presentRegions(view Rect) []Rect {
+	if view.Empty() {
+		return nil
+	}
+	full := w.fullPresent.Swap(false)
+	if full || view != w.presented || w.cursor != nil {
+		return []Rect{view}
+	}
+	var regions []Rect
+	for _, d := range w.canvas.dirty {
+		if r := d.Intersect(view); !r.Empty() {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// Depth returns the window's color depth in bits: 24 for the usual
+// RGB visuals, 32 for ARGB ones.
+func (w *Window) Depth() uint8 {
+	if w.depth != 0 {
+		return w.depth
+	}
+	return w.conn.RootDepth
+}
+
+// HasAlpha reports whether the window's visual has an alpha channel, so
+// the alpha byte of each canvas pixel sets its opacity under a
+// compositing manager. Otherwise the alpha byte is ignored.
+func (w *Window) HasAlpha() bool {
+	visual := w.visual
+	if visual == 0 {
+		visual = w.conn.RootVisual
+	}
+	v, ok := w.conn.Visual(visual)
+	return ok && v.HasAlpha()
+}
+
+// ServerVendor returns the X server's vendor string and release number,
+// e.g. "The X.Org Foundation" and 12101011, for diagnosing
+// server-specific behaviour (Xorg, XWayland, Xephyr, ...).
+func (w *Window) ServerVendor() (vendor string, release uint32) {
+	return w.conn.Vendor, w.conn.ReleaseNumber
+}
+
+// AuthProtocol returns the name of the X11 auth protocol used to connect
+// (normally "MIT-MAGIC-COOKIE-1"), or "" if none was sent. Useful when
+// diagnosing rejected connections.
+func (w *Window) AuthProtocol() string {
+	return w.conn.AuthName
+}
+
+// HasCompositor reports whether a compositing manager is running.
+// Window opacity and tear-free presentation generally need one; apps can
+// check this to decide whether to rely on them.
+func (w *Window) HasCompositor() bool {
+	return w.conn.HasCompositor()
+}
+
+// PresentThrottled presents the canvas, then sleeps for whatever is left
+// of the frame budget for targetFPS, measured from the previous
+// presented frame. A frame that overran its budget doesn't sleep.
+// It returns the frame rate actually achieved for this frame (0 on the
+// first call). A targetFPS of 0 or less presents without sleeping.
+// targetFPS overrides any cap set with SetMaxFPS for this frame.
+func (w *Window) PresentThrottled(targetFPS int) (float64, error) {
+	err := w.present()
+	w.pace(targetFPS)
+	return w.fps, err
+}
+
+// SetMaxFPS caps the frame rate: every Present sleeps for the rest of
+// the 1/n second frame budget, so the app's loop needs no sleep of its
+// own. 0 (the default) leaves Present uncapped.
+func (w *Window) SetMaxFPS(n int) {
+	if n < 0 {
+		n = 0
+	}
+	w.maxFPS = n
+}
+
+// FPS returns the frame rate achieved by the most recent Present or
+// PresentThrottled call, including any time spent sleeping for a cap.
+func (w *Window) FPS() float64 { return w.fps }
+
+// pace sleeps out the remainder of the frame budget for targetFPS and
+// records the achieved frame rate. targetFPS <= 0 only measures.
+func (w *Window) pace(targetFPS int) {
+	now := time.Now()
+	if targetFPS > 0 && !w.lastFrame.IsZero() {
+		budget := time.Second / time.Duration(targetFPS)
+		if elapsed := now.Sub(w.lastFrame); elapsed < budget {
+			time.Sleep(budget - elapsed)
+			now = time.Now()
+		}
+	}
+
+	w.fps = 0
+	if !w.lastFrame.IsZero() {
+		if frameTime := now.Sub(w.lastFrame); frameTime > 0 {
+			w.fps = float64(time.Second) / float64(frameTime)
+		}
+	}
+	w.lastFrame = now
 }
 
 // --- Canvas Drawing Methods ---
 
-// Clear fills the canvas with a solid color
+// Clear fills the canvas with a solid color. The pixels are written
+// opaque whatever color's alpha, so the window shows on compositing
+// 32-bit visuals, which read the alpha byte as opacity. Use ClearRGBA
+// to clear to a translucent color, or with an alpha of 0 for the old
+// behavior of writing alpha 0.
 func (c *Canvas) Clear(color Color) {
-	c.fb.Clear(color.R, color.G, color.B)
+	c.ClearRGBA(color, 255)
 }
 
-// SetPixel sets a single pixel
+// ClearRGBA fills the canvas with color's red, green and blue and alpha
+// a, replacing what is there rather than blending over it. The alpha
+// only matters on 32-bit ARGB visuals (see Window.HasAlpha), where it
+// sets the window's opacity and expects color premultiplied by it.
+func (c *Canvas) ClearRGBA(color Color, a uint8) {
+	c.fb.ClearAlpha(color.R, color.G, color.B, a)
+	c.markAllDirty()
+}
+
+// SetPixel sets a single pixel. A translucent color (A below 255) is
+// blended over the pixel already there, as in every drawing method.
 func (c *Canvas) SetPixel(x, y int, color Color) {
-	c.fb.SetPixel(x, y, color.R, color.G, color.B)
+	fy := c.flipY(y)
+	c.fb.SetOpacity(color.A)
+	c.fb.SetPixel(x, fy, color.R, color.G, color.B)
+	c.markDirty(x, fy, 1, 1)
 }
 
 // GetPixel returns the color at (x, y)
 func (c *Canvas) GetPixel(x, y int) Color {
-	r, g, b := c.fb.GetPixel(x, y)
-	return Color{r, g, b}
+	r, g, b := c.fb.GetPixel(x, c.flipY(y))
+	return Color{r, g, b, 255}
 }
 
 // DrawRect draws a filled rectangle
 func (c *Canvas) DrawRect(x, y, width, height int, color Color) {
-	c.fb.DrawRect(x, y, width, height, color.R, color.G, color.B)
+	c.fb.SetOpacity(color.A)
+	fy := c.flipBox(y, height)
+	c.fb.DrawRect(x, fy, width, height, color.R, color.G, color.B)
+	c.markDirty(x, fy, width, height)
 }
 
 // DrawRectOutline draws a rectangle outline
 func (c *Canvas) DrawRectOutline(x, y, width, height int, color Color) {
-	c.fb.DrawRectOutline(x, y, width, height, color.R, color.G, color.B)
+	c.fb.SetOpacity(color.A)
+	fy := c.flipBox(y, height)
+	c.fb.DrawRectOutline(x, fy, width, height, color.R, color.G, color.B)
+	c.markDirty(x, fy, width, height)
 }
 
 // DrawLine draws a line between two points
 func (c *Canvas) DrawLine(x0, y0, x1, y1 int, color Color) {
-	c.fb.DrawLine(x0, y0, x1, y1, color.R, color.G, color.B)
+	c.fb.SetOpacity(color.A)
+	fy0, fy1 := c.flipY(y0), c.flipY(y1)
+	if c.aa {
+		c.fb.DrawLineAA(x0, fy0, x1, fy1, color.R, color.G, color.B)
+		c.markDirtyPoints(1, x0, fy0, x1, fy1)
+		return
+	}
+	c.fb.DrawLine(x0, fy0, x1, fy1, color.R, color.G, color.B)
+	c.markDirtyPoints(0, x0, fy0, x1, fy1)
+}
+
+// DrawLineThick draws a line width pixels thick, centered on the line,
+// with square ends. A width of 1 is the same as DrawLine.
+func (c *Canvas) DrawLineThick(x0, y0, x1, y1, width int, color Color) {
+	c.fb.SetOpacity(color.A)
+	if width <= 1 {
+		c.DrawLine(x0, y0, x1, y1, color)
+		return
+	}
+	fy0, fy1 := c.flipY(y0), c.flipY(y1)
+	c.fb.DrawLineThick(x0, fy0, x1, fy1, width, color.R, color.G, color.B)
+	// Diagonal spans reach up to width·√2 across
+	c.markDirtyPoints(width, x0, fy0, x1, fy1)
 }
 
 // DrawCircle draws a circle outline
 func (c *Canvas) DrawCircle(x, y, radius int, color Color) {
-	c.fb.DrawCircle(x, y, radius, color.R, color.G, color.B)
+	c.fb.SetOpacity(color.A)
+	if c.aa {
+		c.DrawCircleAA(x, y, radius, color)
+		return
+	}
+	fy := c.flipY(y)
+	c.fb.DrawCircle(x, fy, radius, color.R, color.G, color.B)
+	c.markDirtyPoints(radius, x, fy)
+}
+
+// DrawCircleAA draws an antialiased circle outline, blending its edge
+// into what is already on the canvas. It is slower than DrawCircle.
+func (c *Canvas) DrawCircleAA(x, y, radius int, color Color) {
+	c.fb.SetOpacity(color.A)
+	fy := c.flipY(y)
+	c.fb.DrawCircleAA(x, fy, radius, color.R, color.G, color.B)
+	c.markDirtyPoints(radius+1, x, fy)
 }
 
 // FillCircle draws a filled circle
 func (c *Canvas) FillCircle(x, y, radius int, color Color) {
-	c.fb.FillCircle(x, y, radius, color.R, color.G, color.B)
+	c.fb.SetOpacity(color.A)
+	fy := c.flipY(y)
+	if c.aa {
+		c.fb.FillCircleAA(x, fy, radius, color.R, color.G, color.B)
+	} else {
+		c.fb.FillCircle(x, fy, radius, color.R, color.G, color.B)
+	}
+	c.markDirtyPoints(radius, x, fy)
+}
+
+// DrawEllipse draws an ellipse outline centred on (x, y) with
+// horizontal radius rx and vertical radius ry. A zero radius draws a
+// line.
+func (c *Canvas) DrawEllipse(x, y, rx, ry int, color Color) {
+	c.fb.SetOpacity(color.A)
+	fy := c.flipY(y)
+	c.fb.DrawEllipse(x, fy, rx, ry, color.R, color.G, color.B)
+	c.markDirty(x-rx, fy-ry, 2*rx+1, 2*ry+1)
+}
+
+// FillEllipse draws a filled ellipse centred on (x, y) with horizontal
+// radius rx and vertical radius ry. A zero radius draws a line.
+func (c *Canvas) FillEllipse(x, y, rx, ry int, color Color) {
+	c.fb.SetOpacity(color.A)
+	fy := c.flipY(y)
+	c.fb.FillEllipse(x, fy, rx, ry, color.R, color.G, color.B)
+	c.markDirty(x-rx, fy-ry, 2*rx+1, 2*ry+1)
+}
+
+// DrawGlow adds a soft glow centred on (x, y): core at the centre,
+// fading smoothly to nothing at radius. The glow is added to what is
+// already drawn rather than blended over it, so overlapping glows
+// brighten towards white, as light does; draw glows over a dark
+// background. core's alpha scales the glow's intensity.
+func (c *Canvas) DrawGlow(x, y, radius int, core Color) {
+	if radius <= 0 || core.A == 0 {
+		return
+	}
+	r, g, b := core.R, core.G, core.B
+	if core.A < 255 {
+		scale := func(v uint8) uint8 { return uint8((uint32(v)*uint32(core.A) + 127) / 255) }
+		r, g, b = scale(r), scale(g), scale(b)
+	}
+	fy := c.flipY(y)
+	c.fb.AddGlow(x, fy, radius, glowFalloff(radius), r, g, b)
+	c.markDirtyPoints(radius, x, fy)
 }
 
 // DrawTriangle draws a triangle outline
 func (c *Canvas) DrawTriangle(x0, y0, x1, y1, x2, y2 int, color Color) {
-	c.fb.DrawTriangle(x0, y0, x1, y1, x2, y2, color.R, color.G, color.B)
+	c.fb.SetOpacity(color.A)
+	fy0, fy1, fy2 := c.flipY(y0), c.flipY(y1), c.flipY(y2)
+	c.fb.DrawTriangle(x0, fy0, x1, fy1, x2, fy2, color.R, color.G, color.B)
+	c.markDirtyPoints(0, x0, fy0, x1, fy1, x2, fy2)
+}
+
+// FillTriangle draws a solid triangle. Triangles sharing an edge join
+// without gaps or overlap; collinear vertices draw nothing.
+func (c *Canvas) FillTriangle(x0, y0, x1, y1, x2, y2 int, color Color) {
+	c.fb.SetOpacity(color.A)
+	fy0, fy1, fy2 := c.flipY(y0), c.flipY(y1), c.flipY(y2)
+	c.fb.FillTriangle(x0, fy0, x1, fy1, x2, fy2, color.R, color.G, color.B)
+	c.markDirtyPoints(0, x0, fy0, x1, fy1, x2, fy2)
+}
+
+// CopyRegion copies the width×height rectangle at (srcX, srcY) to
+// (dstX, dstY) within the canvas, for scrolling or motion trails. The
+// rectangles may overlap; parts outside the canvas are skipped. Unlike
+// a server-side copy, it changes the canvas itself.
+func (c *Canvas) CopyRegion(srcX, srcY, width, height, dstX, dstY int) {
+	fsy, fdy := c.flipBox(srcY, height), c.flipBox(dstY, height)
+	c.fb.CopyRegion(srcX, fsy, width, height, dstX, fdy)
+	c.markDirty(dstX, fdy, width, height)
 }
 
 // Width returns the canvas width
@@ -242,7 +826,57 @@ func (c *Canvas) Width() int { return c.fb.Width }
 // Height returns the canvas height
 func (c *Canvas) Height() int { return c.fb.Height }
 
-// Resize reallocates the canvas to new dimensions.
+// SetYUp switches the canvas between the default top-left origin with Y
+// growing downward and a bottom-left origin with Y growing upward, as in
+// OpenGL and most maths texts. The setting applies to every coordinate
+// passed to the canvas's drawing methods and GetPixel. Shapes with a size
+// (rectangles, sprites, text) are then positioned by their bottom-left
+// corner instead of their top-left; sprites and text are still drawn
+// upright, and sprite source regions keep the image's own top-left origin.
+func (c *Canvas) SetYUp(yUp bool) {
+	c.yUp = yUp
+}
+
+// YUp reports whether the canvas uses a bottom-left origin.
+func (c *Canvas) YUp() bool { return c.yUp }
+
+// SetAntialias makes DrawLine, DrawCircle, FillCircle and FillPolygon
+// draw with antialiased edges. It is off by default: antialiasing is
+// slower, and aliased drawing is pixel-exact. Antialiased edges are
+// blended into what is already on the canvas, so drawing the same
+// shape twice darkens or brightens its rim rather than leaving it
+// unchanged.
+func (c *Canvas) SetAntialias(enabled bool) {
+	c.aa = enabled
+}
+
+// Antialias reports whether antialiasing is enabled.
+func (c *Canvas) Antialias() bool { return c.aa }
+
+// flipY converts a canvas y coordinate to a framebuffer row
+func (c *Canvas) flipY(y int) int {
+	if !c.yUp {
+		return y
+	}
+	return c.fb.Height - 1 - y
+}
+
+// flipBox converts the y of a box height rows tall from canvas
+// coordinates to the framebuffer row of its top edge
+func (c *Canvas) flipBox(y, height int) int {
+	if !c.yUp {
+		return y
+	}
+	return c.fb.Height - y - height
+}
+
+// Resize reallocates the canvas to new dimensions, keeping what was
+// drawn in the area the old and new sizes share. Windows resize their
+// canvas themselves when the window is resized.
 func (c *Canvas) Resize(width, height int) {
+	if width == c.fb.Width && height == c.fb.Height {
+		return
+	}
 	c.fb.Resize(width, height)
+	c.markAllDirty()
 }
@@ -0,0 +1,124 @@
+package glow
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	glyphAtlasSize = 1024 // Caps each side of a font's glyph atlas in pixels
+	maxGlyphSize   = 4096 // Largest glyph cell, in pixels, ParseTTF accepts
+)
+
+// FontCacheStats describes how well a font's glyph cache is working.
+type FontCacheStats struct {
+	Hits      uint64 // Glyph lookups served from the atlas
+	Misses    uint64 // Glyph lookups that had to rasterize
+	Evictions uint64 // Glyphs dropped to make room for others
+	Glyphs    int    // Glyphs currently in the atlas
+	Capacity  int    // Glyphs the atlas can hold
+}
+
+// glyphCache keeps rasterized glyphs in a shared coverage atlas divided
+// into equal cells, each big enough for any glyph of the font. When all
+// cells are taken the least recently used glyph gives up its cell.
+//
+// Fonts such as DefaultFont are shared between goroutines, so mu guards
+// the cache: lookup and insert must be called with it held, and kept
+// held while the glyph they return is read, since another goroutine's
+// insert may hand its cell to a different glyph.
+type glyphCache struct {
+	mu sync.Mutex
+
+	cellW, cellH int
+	cols         int
+	stride       int     // Atlas width in pixels
+	pixels       []uint8 // Atlas coverage, 0 to 255
+
+	entries map[rune]*list.Element // Values are *glyphEntry
+	lru     list.List              // Most recently used at the front
+	free    []int                  // Unused cells
+	stats   FontCacheStats
+}
+
+type glyphEntry struct {
+	r     rune
+	cell  int
+	glyph fontGlyph
+}
+
+// newGlyphCache makes a cache of up to capacity cells of cellW×cellH
+// pixels, laid out in an atlas no wider than glyphAtlasSize.
+func newGlyphCache(cellW, cellH, capacity int) *glyphCache {
+	cellW, cellH, capacity = max(cellW, 1), max(cellH, 1), max(capacity, 1)
+	cols := max(1, min(capacity, glyphAtlasSize/cellW))
+	rows := (capacity + cols - 1) / cols
+	gc := &glyphCache{
+		cellW:   cellW,
+		cellH:   cellH,
+		cols:    cols,
+		stride:  cols * cellW,
+		pixels:  make([]uint8, cols*cellW*rows*cellH),
+		entries: make(map[rune]*list.Element, capacity),
+	}
+	for i := capacity - 1; i >= 0; i-- {
+		gc.free = append(gc.free, i)
+	}
+	gc.stats.Capacity = capacity
+	return gc
+}
+
+// lookup returns the cached glyph for r, marking it recently used.
+func (gc *glyphCache) lookup(r rune) (*fontGlyph, bool) {
+	e, ok := gc.entries[r]
+	if !ok {
+		gc.stats.Misses++
+		return nil, false
+	}
+	gc.stats.Hits++
+	gc.lru.MoveToFront(e)
+	return &e.Value.(*glyphEntry).glyph, true
+}
+
+// insert copies a freshly rasterized glyph into a cell, evicting the
+// least recently used glyph if the atlas is full. Glyphs too big for a
+// cell are returned as they are, uncached.
+func (gc *glyphCache) insert(r rune, g *fontGlyph) *fontGlyph {
+	if g.width > gc.cellW || g.height > gc.cellH {
+		return g
+	}
+
+	var cell int
+	if n := len(gc.free); n > 0 {
+		cell = gc.free[n-1]
+		gc.free = gc.free[:n-1]
+	} else {
+		oldest := gc.lru.Back()
+		victim := oldest.Value.(*glyphEntry)
+		gc.lru.Remove(oldest)
+		delete(gc.entries, victim.r)
+		cell = victim.cell
+		gc.stats.Evictions++
+	}
+
+	origin := (cell/gc.cols)*gc.cellH*gc.stride + (cell%gc.cols)*gc.cellW
+	for row := 0; row < g.height; row++ {
+		copy(gc.pixels[origin+row*gc.stride:], g.mask[row*g.width:(row+1)*g.width])
+	}
+
+	e := &glyphEntry{r: r, cell: cell, glyph: *g}
+	e.glyph.mask = gc.pixels[origin:]
+	e.glyph.stride = gc.stride
+	gc.entries[r] = gc.lru.PushFront(e)
+	return &e.glyph
+}
+
+// CacheStats reports the glyph cache's hit, miss and eviction counts
+// and how full its atlas is.
+func (f *Font) CacheStats() FontCacheStats {
+	f.cache.mu.Lock()
+	defer f.cache.mu.Unlock()
+	s := f.cache.stats
+	s.Glyphs = len(f.cache.entries)
+	return s
+}
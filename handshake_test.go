@@ -0,0 +1,63 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// setupReply builds a successful setup reply whose header announces
+// words 4-byte units of data, followed by data.
+func setupReply(words uint16, data []byte) []byte {
+	reply := make([]byte, 8)
+	reply[0] = 1 // Success
+	binary.LittleEndian.PutUint16(reply[2:], 11)
+	binary.LittleEndian.PutUint16(reply[6:], words)
+	return append(reply, data...)
+}
+
+func TestHandshakeTruncatedSetup(t *testing.T) {
+	t.Setenv("XAUTHORITY", filepath.Join(t.TempDir(), "none"))
+
+	// fixed is the fixed part of the setup data, announcing a vendor
+	// string of vendorLen bytes and one screen
+	fixed := func(vendorLen uint16) []byte {
+		d := make([]byte, 32)
+		binary.LittleEndian.PutUint16(d[16:], vendorLen)
+		d[20] = 1 // Screens
+		return d
+	}
+	tests := []struct {
+		name  string
+		reply []byte
+	}{
+		{"cut off", setupReply(10, fixed(0)[:8])},
+		{"shorter than the fixed part", setupReply(2, make([]byte, 8))},
+		{"vendor past the end", setupReply(10, append(fixed(100), make([]byte, 8)...))},
+		{"no room for the screen", setupReply(8, fixed(0))},
+		{"largest length", setupReply(0xffff, fixed(0))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			go func() {
+				defer server.Close()
+				// No Xauthority: the setup request is 12 bytes
+				if _, err := io.ReadFull(server, make([]byte, 12)); err != nil {
+					return
+				}
+				server.Write(tt.reply)
+			}()
+
+			conn, err := x11.Handshake(client, "0")
+			if err == nil {
+				conn.Close()
+				t.Fatal("truncated setup accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package glow
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// NewCanvas creates an off-screen canvas, cleared to black. It needs no
+// X server, so scenes can be rendered and checked in tests.
+func NewCanvas(width, height int) *Canvas {
+	return &Canvas{fb: x11.NewFramebuffer(width, height)}
+}
+
+// Hash returns a 64-bit FNV-1a hash of the canvas size and visible
+// pixels. It is deterministic across runs and machines, so a test can
+// render a scene and compare the result against a golden value. Only
+// red, green and blue count: alpha only shows on translucent windows,
+// and leaving it out keeps golden values recorded before drawing
+// tracked alpha valid.
+func (c *Canvas) Hash() uint64 {
+	h := fnv.New64a()
+	var size [8]byte
+	binary.LittleEndian.PutUint32(size[0:], uint32(c.fb.Width))
+	binary.LittleEndian.PutUint32(size[4:], uint32(c.fb.Height))
+	h.Write(size[:])
+
+	// The fourth byte of each pixel is alpha, left out as above
+	row := make([]byte, 0, c.fb.Width*3)
+	for y := 0; y < c.fb.Height; y++ {
+		row = row[:0]
+		off := y * c.fb.Width * 4
+		for x := 0; x < c.fb.Width; x++ {
+			row = append(row, c.fb.Pixels[off:off+3]...)
+			off += 4
+		}
+		h.Write(row)
+	}
+	return h.Sum64()
+}
+
+// Equal reports whether two canvases have the same size and pixels,
+// ignoring alpha as Hash does.
+func (c *Canvas) Equal(other *Canvas) bool {
+	if other == nil || c.fb.Width != other.fb.Width || c.fb.Height != other.fb.Height {
+		return false
+	}
+	a, b := c.fb.Pixels, other.fb.Pixels
+	for i := 0; i+3 < len(a); i += 4 {
+		if a[i] != b[i] || a[i+1] != b[i+1] || a[i+2] != b[i+2] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package glow
+
+import "testing"
+
+func drawHashScene(c *Canvas) {
+	c.Clear(Black)
+	c.DrawRect(2, 2, 10, 6, Red)
+	c.FillCircle(20, 12, 5, Green)
+	c.DrawLine(0, 31, 31, 0, White)
+}
+
+func TestCanvasHash(t *testing.T) {
+	a, b := NewCanvas(32, 32), NewCanvas(32, 32)
+	drawHashScene(a)
+	drawHashScene(b)
+	if a.Hash() != b.Hash() || !a.Equal(b) {
+		t.Fatal("identical scenes differ")
+	}
+
+	b.SetPixel(5, 5, Blue)
+	if a.Hash() == b.Hash() || a.Equal(b) {
+		t.Error("one changed pixel went unnoticed")
+	}
+
+	// The alpha byte doesn't count
+	b.SetPixel(5, 5, Red)
+	b.fb.Pixels[3] = 0xff
+	if a.Hash() != b.Hash() || !a.Equal(b) {
+		t.Error("alpha byte changed the hash")
+	}
+
+	// Same pixel count, different size
+	if NewCanvas(4, 2).Hash() == NewCanvas(2, 4).Hash() || NewCanvas(4, 2).Equal(NewCanvas(2, 4)) {
+		t.Error("different sizes compare equal")
+	}
+	if a.Equal(nil) {
+		t.Error("Equal(nil) = true")
+	}
+}
+
+// TestCanvasHashGolden pins the hash format: FNV-1a over the
+// little-endian size followed by BGR rows.
+func TestCanvasHashGolden(t *testing.T) {
+	c := NewCanvas(4, 4)
+	c.SetPixel(1, 2, Red)
+	if got := c.Hash(); got != 0xdaba5419b3c9ee50 {
+		t.Errorf("Hash() = %#x, want 0xdaba5419b3c9ee50", got)
+	}
+}
@@ -0,0 +1,81 @@
+package glow
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+// canvasImage is a live image.Image view of a canvas's framebuffer.
+type canvasImage struct {
+	c *Canvas
+}
+
+// AsImage returns an image.Image view of the canvas, without copying,
+// for passing the canvas to image/png, image/draw or other image
+// packages. Pixels are reported as opaque color.RGBA. The view is live:
+// it reflects later drawing and resizes. Coordinates are always
+// top-left based, regardless of SetYUp.
+//
+// The returned image also implements draw.Image, so image/draw can
+// render straight into the canvas. Colors set through it are stored
+// opaque; translucent colors end up blended over black, so composite
+// with draw.Over to blend onto what is there.
+func (c *Canvas) AsImage() image.Image {
+	return canvasImage{c: c}
+}
+
+// ColorModel implements image.Image.
+func (img canvasImage) ColorModel() color.Model { return color.RGBAModel }
+
+// Bounds implements image.Image.
+func (img canvasImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, img.c.fb.Width, img.c.fb.Height)
+}
+
+// At implements image.Image.
+func (img canvasImage) At(x, y int) color.Color {
+	if !image.Pt(x, y).In(img.Bounds()) {
+		return color.RGBA{}
+	}
+	r, g, b := img.c.fb.GetPixel(x, y)
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
+
+// Set implements draw.Image.
+func (img canvasImage) Set(x, y int, c color.Color) {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	img.c.fb.SetOpacity(255) // draw.Image's Set replaces the pixel
+	img.c.fb.SetPixel(x, y, rgba.R, rgba.G, rgba.B)
+	img.c.markDirty(x, y, 1, 1)
+}
+
+// EncodePNG writes the canvas to w as an opaque PNG image, top row
+// first regardless of SetYUp.
+func (c *Canvas) EncodePNG(w io.Writer) error {
+	fb := c.fb
+	img := image.NewNRGBA(image.Rect(0, 0, fb.Width, fb.Height))
+	for i := 0; i+3 < len(fb.Pixels); i += 4 {
+		img.Pix[i] = fb.Pixels[i+2]   // R
+		img.Pix[i+1] = fb.Pixels[i+1] // G
+		img.Pix[i+2] = fb.Pixels[i]   // B
+		img.Pix[i+3] = 255
+	}
+	return png.Encode(w, img)
+}
+
+// SavePNG writes the canvas to a PNG file at path, replacing any file
+// already there.
+func (c *Canvas) SavePNG(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.EncodePNG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
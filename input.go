@@ -0,0 +1,241 @@
+package glow
+
+import "math"
+
+// KeyboardState tracks which keys are held, and which went down or up
+// since the last Window.UpdateInput. It is kept up to date by PollEvent
+// and WaitEvent, so it reflects every event the app has read.
+//
+// The edges compare against the keys held at the last UpdateInput, so
+// the release and press pairs X sends while a key auto-repeats don't
+// count as the key going up and down again.
+type KeyboardState struct {
+	down     [256]bool
+	prevDown [256]bool // down at the last UpdateInput
+	pressed  [256]bool // Went down since, while up at the last UpdateInput
+}
+
+// IsDown reports whether key is held.
+func (k *KeyboardState) IsDown(key Key) bool { return k.down[key] }
+
+// JustPressed reports whether key went down since the last UpdateInput.
+// A tap that is pressed and released within one frame still counts.
+func (k *KeyboardState) JustPressed(key Key) bool { return k.pressed[key] }
+
+// JustReleased reports whether key went up since the last UpdateInput.
+func (k *KeyboardState) JustReleased(key Key) bool {
+	return !k.down[key] && (k.prevDown[key] || k.pressed[key])
+}
+
+// MouseState is the mouse counterpart of KeyboardState.
+type MouseState struct {
+	X, Y     int  // Last known pointer position
+	seen     bool // X, Y have been reported at least once
+	down     [8]bool
+	pressed  [8]bool
+	released [8]bool
+
+	// Wheel scroll since the last UpdateInput
+	wheelX, wheelY float64
+}
+
+// IsDown reports whether button is held.
+func (m *MouseState) IsDown(button MouseButton) bool {
+	return int(button) < len(m.down) && m.down[button]
+}
+
+// JustPressed reports whether button went down since the last UpdateInput.
+func (m *MouseState) JustPressed(button MouseButton) bool {
+	return int(button) < len(m.pressed) && m.pressed[button]
+}
+
+// JustReleased reports whether button went up since the last UpdateInput.
+func (m *MouseState) JustReleased(button MouseButton) bool {
+	return int(button) < len(m.released) && m.released[button]
+}
+
+// Wheel returns how far the wheel scrolled since the last UpdateInput,
+// in notches, with the signs of Event.WheelX and WheelY. Fast scrolling
+// sends many events a frame; this adds them up so a view can move by
+// the total once.
+func (m *MouseState) Wheel() (dx, dy float64) {
+	return m.wheelX, m.wheelY
+}
+
+// eventFocusLost is queued when the window loses the keyboard focus. It
+// is consumed by PollEvent and WaitEvent, never returned.
+const eventFocusLost EventType = -1
+
+// IsKeyDown reports whether key is held: it went down and hasn't come up
+// since. Keys held when the window loses the focus count as released,
+// since their KeyUp events go to another window. Like the rest of the
+// input state, it reflects the events the app has read with PollEvent
+// or WaitEvent.
+func (w *Window) IsKeyDown(key Key) bool { return w.keyboard.IsDown(key) }
+
+// PressedKeys returns the keys currently held, in keycode order.
+func (w *Window) PressedKeys() []Key {
+	var keys []Key
+	for k, down := range w.keyboard.down {
+		if down {
+			keys = append(keys, Key(k))
+		}
+	}
+	return keys
+}
+
+// MovementVector returns the direction the movement keys held in keys
+// point in, for top-down and platform game movement: W, A, S, D and the
+// arrow keys, with y growing downward. Diagonals are normalized to
+// length 1 so they aren't faster than straight moves; with no keys, or
+// only opposing ones, it returns (0, 0). Keys are matched by the symbol
+// they type on the user's layout, so W is the key labelled W on any
+// layout; on layouts without Latin letters, such as Russian or Greek,
+// the keys where a US keyboard has W, A, S and D are used instead.
+// Pass *w.Keyboard() to read the window's own keyboard state.
+func (w *Window) MovementVector(keys KeyboardState) (dx, dy float64) {
+	km := w.keymap.Load()
+	if km == nil {
+		km = &usKeymap
+	}
+
+	var up, down, left, right bool
+	for k, held := range keys.down {
+		if !held {
+			continue
+		}
+		sym := km.syms[k][0]
+		switch Key(k) {
+		case KeyW, KeyA, KeyS, KeyD:
+			if !isLatinLetter(sym) {
+				sym = usKeymap.syms[k][0]
+			}
+		}
+		switch sym {
+		case 'w', KeysymUp:
+			up = true
+		case 's', KeysymDown:
+			down = true
+		case 'a', KeysymLeft:
+			left = true
+		case 'd', KeysymRight:
+			right = true
+		}
+	}
+
+	dx, dy = axis(left, right), axis(up, down)
+	if dx != 0 && dy != 0 {
+		dx, dy = dx*math.Sqrt2/2, dy*math.Sqrt2/2
+	}
+	return dx, dy
+}
+
+// isLatinLetter reports whether sym is one of the letters A to Z.
+func isLatinLetter(sym Keysym) bool {
+	return sym >= 'a' && sym <= 'z' || sym >= 'A' && sym <= 'Z'
+}
+
+// axis returns -1 if only neg is set, 1 if only pos is, and 0 otherwise.
+func axis(neg, pos bool) float64 {
+	switch {
+	case neg && !pos:
+		return -1
+	case pos && !neg:
+		return 1
+	}
+	return 0
+}
+
+// releaseKeys releases every held key, as if its KeyUp had arrived.
+func (w *Window) releaseKeys() {
+	w.keyboard.down = [256]bool{}
+}
+
+// Keyboard returns the window's keyboard state.
+func (w *Window) Keyboard() *KeyboardState { return &w.keyboard }
+
+// Mouse returns the window's mouse state.
+func (w *Window) Mouse() *MouseState { return &w.mouse }
+
+// MousePosition asks the X server where the pointer is now, relative
+// to the window's top-left corner, for apps that look it up now and
+// then instead of following EventMouseMotion. It costs a round trip.
+// The position can be outside the window. If the server can't be
+// reached, it returns the last position the app read from events.
+func (w *Window) MousePosition() (x, y int) {
+	p, err := w.conn.QueryPointer(w.windowID)
+	if err != nil {
+		return w.mouse.X, w.mouse.Y
+	}
+	return int(p.X), int(p.Y)
+}
+
+// MouseButtons asks the X server which mouse buttons are held now, like
+// MousePosition. If the server can't be reached, it returns the buttons
+// held according to the events the app has read.
+func (w *Window) MouseButtons() MouseButtons {
+	p, err := w.conn.QueryPointer(w.windowID)
+	if err != nil {
+		var b MouseButtons
+		for _, button := range []MouseButton{MouseLeft, MouseMiddle, MouseRight} {
+			if w.mouse.IsDown(button) {
+				b |= MouseLeftMask << (button - MouseLeft)
+			}
+		}
+		return b
+	}
+	return buttonsFromState(p.State)
+}
+
+// UpdateInput starts a new input frame, clearing the JustPressed and
+// JustReleased edges. Call it once per loop, before reading events:
+//
+//	for running {
+//		win.UpdateInput()
+//		for e := win.PollEvent(); e != nil; e = win.PollEvent() {
+//			...
+//		}
+//		if win.Keyboard().JustPressed(glow.KeySpace) {
+//			jump()
+//		}
+//		...
+//	}
+func (w *Window) UpdateInput() {
+	w.keyboard.prevDown = w.keyboard.down
+	w.keyboard.pressed = [256]bool{}
+	w.mouse.pressed = [8]bool{}
+	w.mouse.released = [8]bool{}
+	w.mouse.wheelX, w.mouse.wheelY = 0, 0
+}
+
+// trackInput records a key, button or motion event in the input state.
+func (w *Window) trackInput(e *Event) {
+	switch e.Type {
+	case EventKeyDown:
+		// Auto-repeat presses a key that was already held
+		if !w.keyboard.prevDown[e.Key] {
+			w.keyboard.pressed[e.Key] = true
+		}
+		w.keyboard.down[e.Key] = true
+	case EventKeyUp:
+		w.keyboard.down[e.Key] = false
+	case EventMouseButtonDown, EventMouseButtonUp:
+		w.mouse.X, w.mouse.Y, w.mouse.seen = e.X, e.Y, true
+		if int(e.Button) >= len(w.mouse.down) {
+			return
+		}
+		down := e.Type == EventMouseButtonDown
+		w.mouse.down[e.Button] = down
+		if down {
+			w.mouse.pressed[e.Button] = true
+		} else {
+			w.mouse.released[e.Button] = true
+		}
+	case EventMouseMotion:
+		w.mouse.X, w.mouse.Y, w.mouse.seen = e.X, e.Y, true
+	case EventMouseWheel:
+		w.mouse.X, w.mouse.Y, w.mouse.seen = e.X, e.Y, true
+		w.mouse.wheelX += e.WheelX
+		w.mouse.wheelY += e.WheelY
+	}
+}
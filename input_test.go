@@ -0,0 +1,304 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestKeyboardEdges(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	kb := w.Keyboard()
+
+	w.UpdateInput()
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeySpace}
+	for w.PollEvent() != nil {
+	}
+	if !kb.IsDown(KeySpace) || !kb.JustPressed(KeySpace) {
+		t.Fatal("space should be down and just pressed")
+	}
+
+	// Still held next frame, but no longer an edge
+	w.UpdateInput()
+	if !kb.IsDown(KeySpace) || kb.JustPressed(KeySpace) {
+		t.Fatal("space should be held without a press edge")
+	}
+
+	// A tap within a single frame still registers both edges
+	w.UpdateInput()
+	w.eventChan <- Event{Type: EventKeyUp, Key: KeySpace}
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyW}
+	w.eventChan <- Event{Type: EventKeyUp, Key: KeyW}
+	for w.PollEvent() != nil {
+	}
+	if kb.IsDown(KeySpace) || !kb.JustReleased(KeySpace) {
+		t.Error("space should be just released")
+	}
+	if kb.IsDown(KeyW) || !kb.JustPressed(KeyW) || !kb.JustReleased(KeyW) {
+		t.Error("tapped W should have both edges")
+	}
+}
+
+func TestKeyRepeat(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	kb := w.Keyboard()
+
+	w.UpdateInput()
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyA}
+	for w.PollEvent() != nil {
+	}
+	w.UpdateInput()
+
+	// Auto-repeat: X sends a release and a press for the held key
+	w.eventChan <- Event{Type: EventKeyUp, Key: KeyA}
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyA}
+	for w.PollEvent() != nil {
+	}
+	if !kb.IsDown(KeyA) || kb.JustPressed(KeyA) || kb.JustReleased(KeyA) {
+		t.Errorf("repeat gave down %v, pressed %v, released %v; want only down",
+			kb.IsDown(KeyA), kb.JustPressed(KeyA), kb.JustReleased(KeyA))
+	}
+
+	// The real release still counts
+	w.UpdateInput()
+	w.eventChan <- Event{Type: EventKeyUp, Key: KeyA}
+	w.PollEvent()
+	if kb.IsDown(KeyA) || !kb.JustReleased(KeyA) {
+		t.Error("A should be just released")
+	}
+}
+
+func TestMouseEdges(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	w.eventChan <- Event{Type: EventMouseButtonDown, Button: MouseLeft, X: 4, Y: 5}
+	w.PollEvent()
+
+	m := w.Mouse()
+	if !m.IsDown(MouseLeft) || !m.JustPressed(MouseLeft) || m.X != 4 || m.Y != 5 {
+		t.Fatalf("unexpected mouse state %+v", m)
+	}
+	w.UpdateInput()
+	w.eventChan <- Event{Type: EventMouseButtonUp, Button: MouseLeft}
+	w.PollEvent()
+	if m.IsDown(MouseLeft) || m.JustPressed(MouseLeft) || !m.JustReleased(MouseLeft) {
+		t.Errorf("unexpected mouse state %+v", m)
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 32)}
+	wheel := func(button uint8, release bool) {
+		e := x11.ButtonEvent{EventType: x11.EventButtonPress, Button: button, X: 7, Y: 8}
+		if release {
+			e.EventType = x11.EventButtonRelease
+		}
+		if ev := w.convertEvent(e); ev != nil {
+			w.eventChan <- *ev
+			if ev := wheelEvent(ev); ev != nil {
+				w.eventChan <- *ev
+			}
+		}
+	}
+	for _, b := range []uint8{4, 4, 4, 7, 6, 7, 5} {
+		wheel(b, false)
+		wheel(b, true)
+	}
+
+	var events, buttons []*Event
+	for e := w.PollEvent(); e != nil; e = w.PollEvent() {
+		if e.Type == EventMouseWheel {
+			events = append(events, e)
+		} else {
+			buttons = append(buttons, e)
+		}
+	}
+	if len(events) != 7 {
+		t.Fatalf("got %d wheel events, want one per notch", len(events))
+	}
+	// Apps that read the wheel as buttons 4 and 5 still get them
+	if len(buttons) != 14 || buttons[0].Type != EventMouseButtonDown || buttons[0].Button != MouseWheelUp ||
+		buttons[1].Type != EventMouseButtonUp {
+		t.Errorf("wheel button events = %d, first %+v", len(buttons), buttons[0])
+	}
+	if e := events[3]; e.Type != EventMouseWheel || e.Button != MouseWheelRight ||
+		e.WheelX != 1 || e.WheelY != 0 || e.X != 7 || e.Y != 8 {
+		t.Errorf("wheel right event = %+v", e)
+	}
+	if e := events[6]; e.WheelX != 0 || e.WheelY != -1 {
+		t.Errorf("wheel down scrolls (%v, %v)", e.WheelX, e.WheelY)
+	}
+
+	m := w.Mouse()
+	if dx, dy := m.Wheel(); dx != 1 || dy != 2 {
+		t.Errorf("Wheel = (%v, %v), want (1, 2)", dx, dy)
+	}
+	if !m.JustPressed(MouseWheelUp) || m.IsDown(MouseWheelUp) {
+		t.Error("wheel up should be just pressed, not held")
+	}
+	w.UpdateInput()
+	if dx, dy := m.Wheel(); dx != 0 || dy != 0 {
+		t.Errorf("Wheel after UpdateInput = (%v, %v)", dx, dy)
+	}
+}
+
+func TestIsKeyDown(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyA}
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyLeft}
+	for w.PollEvent() != nil {
+	}
+	if !w.IsKeyDown(KeyA) || !w.IsKeyDown(KeyLeft) || w.IsKeyDown(KeyD) {
+		t.Fatal("IsKeyDown doesn't match the keys pressed")
+	}
+	if got := w.PressedKeys(); len(got) != 2 || got[0] != KeyA || got[1] != KeyLeft {
+		t.Errorf("PressedKeys() = %v, want [%d %d]", got, KeyA, KeyLeft)
+	}
+
+	w.eventChan <- Event{Type: EventKeyUp, Key: KeyA}
+	w.PollEvent()
+	if w.IsKeyDown(KeyA) || !w.IsKeyDown(KeyLeft) {
+		t.Fatal("KeyUp didn't release only its key")
+	}
+
+	// Losing the focus releases the rest, without delivering an event
+	w.UpdateInput()
+	w.eventChan <- *w.convertEvent(x11.FocusEvent{EventType: x11.EventFocusOut, Mode: x11.NotifyNormal})
+	if e := w.PollEvent(); e != nil {
+		t.Errorf("focus loss delivered %+v", e)
+	}
+	if w.IsKeyDown(KeyLeft) || !w.Keyboard().JustReleased(KeyLeft) || len(w.PressedKeys()) != 0 {
+		t.Error("keys still held after focus loss")
+	}
+}
+
+func TestMovementVector(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	press := func(keys ...Key) KeyboardState {
+		var k KeyboardState
+		for _, key := range keys {
+			k.down[key] = true
+		}
+		return k
+	}
+	const d = math.Sqrt2 / 2
+	tests := []struct {
+		keys   []Key
+		dx, dy float64
+	}{
+		{nil, 0, 0},
+		{[]Key{KeyW}, 0, -1},
+		{[]Key{KeyDown}, 0, 1},
+		{[]Key{KeyA, KeyS}, -d, d},
+		{[]Key{KeyUp, KeyRight}, d, -d},
+		{[]Key{KeyW, KeyS}, 0, 0},         // Opposing keys cancel
+		{[]Key{KeyW, KeyUp, KeyD}, d, -d}, // Both bindings for one direction
+		{[]Key{KeyQ, KeySpace}, 0, 0},
+	}
+	for _, tt := range tests {
+		dx, dy := w.MovementVector(press(tt.keys...))
+		if math.Abs(dx-tt.dx) > 1e-9 || math.Abs(dy-tt.dy) > 1e-9 {
+			t.Errorf("keys %v: got (%v, %v), want (%v, %v)", tt.keys, dx, dy, tt.dx, tt.dy)
+		}
+	}
+
+	// On a French (AZERTY) layout the keys labelled W and A sit where
+	// US Z and Q are
+	m, err := x11.ParseKeyboardMapping(8, keyboardMappingReply(8, map[uint8][]uint32{
+		uint8(KeyQ): {'a', 'A'},
+		uint8(KeyZ): {'w', 'W'},
+		uint8(KeyW): {'z', 'Z'},
+		uint8(KeyA): {'q', 'Q'},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.keymap.Store(newKeymap(m))
+	if dx, dy := w.MovementVector(press(KeyZ)); dx != 0 || dy != -1 {
+		t.Errorf("AZERTY W: got (%v, %v), want (0, -1)", dx, dy)
+	}
+	if dx, dy := w.MovementVector(press(KeyW, KeyA)); dx != 0 || dy != 0 {
+		t.Errorf("AZERTY Z+Q: got (%v, %v), want (0, 0)", dx, dy)
+	}
+
+	// Cyrillic letters on every key: W, A, S and D are found by position
+	m, err = x11.ParseKeyboardMapping(8, keyboardMappingReply(8, map[uint8][]uint32{
+		uint8(KeyW): {0x6c3, 0x6e3}, // Cyrillic_tse
+		uint8(KeyA): {0x6c6, 0x6e6}, // Cyrillic_ef
+		uint8(KeyS): {0x6d9, 0x6f9}, // Cyrillic_yeru
+		uint8(KeyD): {0x6d7, 0x6f7}, // Cyrillic_ve
+		uint8(KeyQ): {0x6ca, 0x6ea}, // Cyrillic_shorti
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.keymap.Store(newKeymap(m))
+	if dx, dy := w.MovementVector(press(KeyW, KeyD)); dx != d || dy != -d {
+		t.Errorf("Cyrillic W+D: got (%v, %v), want (%v, %v)", dx, dy, d, -d)
+	}
+	if dx, dy := w.MovementVector(press(KeyQ)); dx != 0 || dy != 0 {
+		t.Errorf("Cyrillic Q: got (%v, %v), want (0, 0)", dx, dy)
+	}
+
+	// The window's own state
+	w.keyboard.down[KeyA] = true
+	if dx, _ := w.MovementVector(*w.Keyboard()); dx != -1 {
+		t.Errorf("window keys: dx = %v, want -1", dx)
+	}
+}
+
+func TestMousePosition(t *testing.T) {
+	client, server := net.Pipe()
+	conn := x11.NewConnection(client)
+	defer conn.Close()
+	w := &Window{conn: conn, windowID: 0x400001}
+
+	// A QueryPointer reply: pointer at (120, -5) in the window, left and
+	// right buttons and Shift held
+	reply := make([]byte, 32)
+	reply[0] = 1 // Reply
+	reply[1] = 1 // Same screen
+	binary.LittleEndian.PutUint16(reply[2:], 1)
+	binary.LittleEndian.PutUint32(reply[8:], 0x2a5)
+	binary.LittleEndian.PutUint16(reply[16:], 620)
+	binary.LittleEndian.PutUint16(reply[18:], 395)
+	binary.LittleEndian.PutUint16(reply[20:], 120)
+	binary.LittleEndian.PutUint16(reply[22:], uint16(0xFFFB)) // -5
+	binary.LittleEndian.PutUint16(reply[24:], x11.Button1Mask|x11.Button3Mask|x11.ShiftMask)
+
+	answer := func() {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(server, req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req[0] != x11.OpQueryPointer || binary.LittleEndian.Uint32(req[4:]) != 0x400001 {
+			t.Errorf("request %v, want QueryPointer on the window", req)
+		}
+		server.Write(reply)
+	}
+
+	go answer()
+	if x, y := w.MousePosition(); x != 120 || y != -5 {
+		t.Errorf("MousePosition = (%d, %d), want (120, -5)", x, y)
+	}
+	binary.LittleEndian.PutUint16(reply[2:], 2) // Sequence number
+	go answer()
+	if b := w.MouseButtons(); b != MouseLeftMask|MouseRightMask {
+		t.Errorf("MouseButtons = %b, want left and right", b)
+	}
+
+	// Without a server it falls back to what events said
+	server.Close()
+	w.mouse.X, w.mouse.Y = 3, 4
+	w.mouse.down[MouseMiddle] = true
+	if x, y := w.MousePosition(); x != 3 || y != 4 {
+		t.Errorf("MousePosition without server = (%d, %d), want (3, 4)", x, y)
+	}
+	if b := w.MouseButtons(); b != MouseMiddleMask {
+		t.Errorf("MouseButtons without server = %b, want middle", b)
+	}
+}
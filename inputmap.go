@@ -0,0 +1,183 @@
+package glow
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// binding is a key or mouse button an action is bound to.
+type binding struct {
+	mouse bool
+	code  uint8 // Key or MouseButton
+}
+
+// InputMap maps named actions ("jump", "fire") to keys and mouse
+// buttons, so games query actions rather than raw keys and players can
+// rebind controls. An action may have any number of bindings; it is
+// active when any of them is.
+//
+// Maps are saved as text, one binding per line:
+//
+//	# action  device  code
+//	jump      key     65
+//	fire      mouse   1
+//
+// Codes are Key and MouseButton values, which stay stable across runs.
+type InputMap struct {
+	actions map[string][]binding
+}
+
+// NewInputMap returns an empty input map.
+func NewInputMap() *InputMap {
+	return &InputMap{actions: make(map[string][]binding)}
+}
+
+// Bind adds key to the keys that trigger action.
+func (m *InputMap) Bind(action string, key Key) {
+	m.add(action, binding{code: uint8(key)})
+}
+
+// BindMouse adds button to the mouse buttons that trigger action.
+func (m *InputMap) BindMouse(action string, button MouseButton) {
+	m.add(action, binding{mouse: true, code: uint8(button)})
+}
+
+func (m *InputMap) add(action string, b binding) {
+	for _, have := range m.actions[action] {
+		if have == b {
+			return
+		}
+	}
+	m.actions[action] = append(m.actions[action], b)
+}
+
+// Unbind removes every binding of action.
+func (m *InputMap) Unbind(action string) {
+	delete(m.actions, action)
+}
+
+// Keys returns the keys bound to action, in the order they were bound.
+func (m *InputMap) Keys(action string) []Key {
+	var keys []Key
+	for _, b := range m.actions[action] {
+		if !b.mouse {
+			keys = append(keys, Key(b.code))
+		}
+	}
+	return keys
+}
+
+// Buttons returns the mouse buttons bound to action, in the order they
+// were bound.
+func (m *InputMap) Buttons(action string) []MouseButton {
+	var buttons []MouseButton
+	for _, b := range m.actions[action] {
+		if b.mouse {
+			buttons = append(buttons, MouseButton(b.code))
+		}
+	}
+	return buttons
+}
+
+// Actions returns the names of the actions with bindings, sorted.
+func (m *InputMap) Actions() []string {
+	names := make([]string, 0, len(m.actions))
+	for name := range m.actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pressed reports whether any binding of action is held. Pass
+// *w.Keyboard() and *w.Mouse() to read a window's own state, or a
+// zero MouseState to consider keys only.
+func (m *InputMap) Pressed(action string, keys KeyboardState, mouse MouseState) bool {
+	return m.any(action, &keys, &mouse, (*KeyboardState).IsDown, (*MouseState).IsDown)
+}
+
+// JustPressed reports whether any binding of action went down since the
+// last Window.UpdateInput.
+func (m *InputMap) JustPressed(action string, keys KeyboardState, mouse MouseState) bool {
+	return m.any(action, &keys, &mouse, (*KeyboardState).JustPressed, (*MouseState).JustPressed)
+}
+
+// JustReleased reports whether any binding of action went up since the
+// last Window.UpdateInput.
+func (m *InputMap) JustReleased(action string, keys KeyboardState, mouse MouseState) bool {
+	return m.any(action, &keys, &mouse, (*KeyboardState).JustReleased, (*MouseState).JustReleased)
+}
+
+func (m *InputMap) any(action string, keys *KeyboardState, mouse *MouseState,
+	keyTest func(*KeyboardState, Key) bool, buttonTest func(*MouseState, MouseButton) bool) bool {
+	for _, b := range m.actions[action] {
+		if b.mouse {
+			if buttonTest(mouse, MouseButton(b.code)) {
+				return true
+			}
+		} else if keyTest(keys, Key(b.code)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the map in its text format, actions sorted by name.
+// Action names must be non-empty and contain no whitespace.
+func (m *InputMap) Save(w io.Writer) error {
+	actions := m.Actions()
+	for _, action := range actions {
+		if action == "" || strings.ContainsAny(action, " \t\r\n") || strings.HasPrefix(action, "#") {
+			return fmt.Errorf("glow: action name %q can't be saved", action)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, action := range actions {
+		for _, b := range m.actions[action] {
+			device := "key"
+			if b.mouse {
+				device = "mouse"
+			}
+			fmt.Fprintf(bw, "%s %s %d\n", action, device, b.code)
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadInputMap reads a map saved by Save. Blank lines and lines
+// starting with '#' are ignored.
+func LoadInputMap(r io.Reader) (*InputMap, error) {
+	m := NewInputMap()
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("glow: input map line %d: want \"action device code\"", line)
+		}
+		code, err := strconv.ParseUint(fields[2], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("glow: input map line %d: bad code %q", line, fields[2])
+		}
+		switch fields[1] {
+		case "key":
+			m.Bind(fields[0], Key(code))
+		case "mouse":
+			m.BindMouse(fields[0], MouseButton(code))
+		default:
+			return nil, fmt.Errorf("glow: input map line %d: unknown device %q", line, fields[1])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("glow: read input map: %w", err)
+	}
+	return m, nil
+}
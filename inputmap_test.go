@@ -0,0 +1,69 @@
+package glow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInputMapActions(t *testing.T) {
+	m := NewInputMap()
+	m.Bind("jump", KeySpace)
+	m.Bind("jump", KeyW)
+	m.BindMouse("fire", MouseLeft)
+
+	w := &Window{eventChan: make(chan Event, 8)}
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyW}
+	w.eventChan <- Event{Type: EventMouseButtonDown, Button: MouseLeft}
+	for w.PollEvent() != nil {
+	}
+
+	keys, mouse := *w.Keyboard(), *w.Mouse()
+	if !m.Pressed("jump", keys, mouse) || !m.JustPressed("jump", keys, MouseState{}) {
+		t.Error("jump should be pressed through its second key")
+	}
+	if !m.Pressed("fire", keys, mouse) {
+		t.Error("fire should be pressed through the mouse")
+	}
+	if m.Pressed("fire", keys, MouseState{}) {
+		t.Error("fire has no keys and the mouse had no buttons down")
+	}
+	if m.Pressed("duck", keys, mouse) {
+		t.Error("unbound action should never be pressed")
+	}
+}
+
+func TestInputMapSaveLoad(t *testing.T) {
+	m := NewInputMap()
+	m.Bind("jump", KeySpace)
+	m.Bind("jump", KeyUp)
+	m.BindMouse("fire", MouseRight)
+
+	var sb strings.Builder
+	if err := m.Save(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := "fire mouse 3\njump key 65\njump key 111\n"
+	if sb.String() != want {
+		t.Fatalf("saved %q, want %q", sb.String(), want)
+	}
+
+	loaded, err := LoadInputMap(strings.NewReader("# controls\n\n" + want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := loaded.Keys("jump"); len(keys) != 2 || keys[0] != KeySpace || keys[1] != KeyUp {
+		t.Errorf("jump keys %v", keys)
+	}
+	if b := loaded.Buttons("fire"); len(b) != 1 || b[0] != MouseRight {
+		t.Errorf("fire buttons %v", b)
+	}
+
+	if _, err := LoadInputMap(strings.NewReader("jump pad 1\n")); err == nil {
+		t.Error("unknown device should fail to load")
+	}
+	bad := NewInputMap()
+	bad.Bind("move left", KeyA)
+	if err := bad.Save(&sb); err == nil {
+		t.Error("action with a space should fail to save")
+	}
+}
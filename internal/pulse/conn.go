@@ -14,11 +14,31 @@ import (
 // Connection represents a connection to the PulseAudio server.
 type Connection struct {
 	conn          net.Conn
-	mu            sync.Mutex
+	mu            sync.Mutex // Serializes writes and tag allocation
 	nextTag       uint32
 	serverVersion uint32
+	chunkSize     int // 0 means DefaultChunkSize
+
+	// Everything the server sends after the handshake is read by one
+	// goroutine (see readLoop), started by the first command
+	readOnce sync.Once
+	readMu   sync.Mutex
+	pending  map[uint32]chan reply // Commands awaiting a reply, by tag
+	streams  map[uint32]*Stream    // Streams with notification handlers, by channel
+	events   chan SubscribeEvent   // Set by Subscribe
+	readErr  error                 // Set once the connection is unreadable
 }
 
+// reply is a REPLY or ERROR frame handed to the command awaiting it.
+type reply struct {
+	cmd uint32
+	tp  *TagParser
+}
+
+// DefaultChunkSize is the largest data packet WriteData sends unless
+// changed with SetChunkSize.
+const DefaultChunkSize = 65536
+
 // Connect connects to the PulseAudio server and performs the handshake.
 func Connect() (*Connection, error) {
 	socketPath := findSocket()
@@ -53,6 +73,23 @@ func (c *Connection) Close() error {
 	return c.conn.Close()
 }
 
+// NewConnection wraps an established connection to a PulseAudio server
+// without performing the handshake. It is meant for tests and custom
+// transports; use Connect to talk to the local server.
+func NewConnection(conn net.Conn) *Connection {
+	return &Connection{conn: conn}
+}
+
+// SetChunkSize sets the largest data packet WriteData sends, in bytes.
+// Larger chunks mean fewer writes for long sounds; smaller ones let other
+// requests on the connection get through sooner. n <= 0 restores
+// DefaultChunkSize.
+func (c *Connection) SetChunkSize(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chunkSize = n
+}
+
 // ServerVersion returns the server's protocol version.
 func (c *Connection) ServerVersion() uint32 {
 	return c.serverVersion
@@ -102,7 +139,7 @@ func (c *Connection) auth() error {
 	c.nextTag++
 	frame := BuildCommand(CmdAuth, tag, tb.Bytes())
 
-	if _, err := c.conn.Write(frame); err != nil {
+	if err := writeAll(c.conn, frame); err != nil {
 		return fmt.Errorf("pulse: auth write: %w", err)
 	}
 
@@ -139,7 +176,7 @@ func (c *Connection) setClientName() error {
 	c.nextTag++
 	frame := BuildCommand(CmdSetClientName, tag, tb.Bytes())
 
-	if _, err := c.conn.Write(frame); err != nil {
+	if err := writeAll(c.conn, frame); err != nil {
 		return fmt.Errorf("pulse: set_client_name write: %w", err)
 	}
 
@@ -157,80 +194,175 @@ func (c *Connection) setClientName() error {
 	return nil
 }
 
-// SendCommand sends a command and returns the parsed reply.
-// The caller must hold no lock — this method acquires c.mu.
+// SendCommand sends a command and waits for the server's REPLY or
+// ERROR to it. Notifications and stream data arriving meanwhile are
+// handled by the connection's reader goroutine, so other commands and
+// streams can use the connection while this one waits.
 func (c *Connection) SendCommand(command uint32, payload []byte) (uint32, uint32, *TagParser, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.readOnce.Do(func() { go c.readLoop() })
+	ch := make(chan reply, 1)
 
+	c.mu.Lock()
 	tag := c.nextTag
 	c.nextTag++
-	frame := BuildCommand(command, tag, payload)
+	c.readMu.Lock()
+	if c.readErr != nil {
+		err := c.readErr
+		c.readMu.Unlock()
+		c.mu.Unlock()
+		return 0, 0, nil, err
+	}
+	if c.pending == nil {
+		c.pending = make(map[uint32]chan reply)
+	}
+	c.pending[tag] = ch
+	c.readMu.Unlock()
 
-	if _, err := c.conn.Write(frame); err != nil {
+	err := writeAll(c.conn, BuildCommand(command, tag, payload))
+	c.mu.Unlock()
+	if err != nil {
+		c.readMu.Lock()
+		delete(c.pending, tag)
+		c.readMu.Unlock()
 		return 0, 0, nil, fmt.Errorf("pulse: write command %d: %w", command, err)
 	}
 
-	return c.readReply()
+	r, ok := <-ch
+	if !ok {
+		c.readMu.Lock()
+		err := c.readErr
+		c.readMu.Unlock()
+		return 0, 0, nil, err
+	}
+	return r.cmd, tag, r.tp, nil
+}
+
+// command is SendCommand for the usual case: an ERROR reply becomes an
+// error, and the parser for a successful reply's payload is returned.
+// name identifies the command in errors.
+func (c *Connection) command(name string, command uint32, payload []byte) (*TagParser, error) {
+	replyCmd, _, tp, err := c.SendCommand(command, payload)
+	if err != nil {
+		return nil, fmt.Errorf("pulse: %s: %w", name, err)
+	}
+	if replyCmd == CmdError {
+		code, _ := tp.ReadU32()
+		return nil, fmt.Errorf("pulse: %s error (code %d)", name, code)
+	}
+	if replyCmd != CmdReply {
+		return nil, fmt.Errorf("pulse: %s unexpected response %d", name, replyCmd)
+	}
+	return tp, nil
 }
 
 // WriteData writes raw PCM data on a stream channel.
-func (c *Connection) WriteData(channel uint32, data []byte) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// frameSize is the stream's bytes per frame (see SampleSpec.FrameSize);
+// chunks are cut on frame boundaries so no frame is split between two
+// data packets.
+func (c *Connection) WriteData(channel uint32, frameSize int, data []byte) error {
+	return c.writeData(channel, frameSize, data, nil)
+}
 
+// writeData is WriteData, calling sent with the size of each chunk once
+// it is on the wire; if sent returns false the rest of data is dropped.
+// The lock is taken per chunk so commands on other streams can get
+// through while a long sound is written.
+func (c *Connection) writeData(channel uint32, frameSize int, data []byte, sent func(n int) bool) error {
 	// Send data in chunks to avoid overly large writes.
-	// PA accepts data frames up to 64KB typically, but let's use
-	// a generous chunk size. The server tells us how much it wants
-	// via requested_bytes, but for fire-and-forget we just send all.
-	const maxChunk = 65536
+	// The server tells us how much it wants via requested_bytes,
+	// but for fire-and-forget we just send all.
+	if frameSize < 1 {
+		frameSize = 1
+	}
 
 	for len(data) > 0 {
+		c.mu.Lock()
+		chunkSize := c.chunkSize
+		if chunkSize <= 0 {
+			chunkSize = DefaultChunkSize
+		}
+		maxChunk := max(chunkSize-chunkSize%frameSize, frameSize)
+
 		chunk := data
 		if len(chunk) > maxChunk {
 			chunk = data[:maxChunk]
 		}
 		data = data[len(chunk):]
 
+		// Descriptor and payload go out in one writev on Unix sockets,
+		// without copying the payload
 		desc := BuildDescriptor(uint32(len(chunk)), channel)
-		if _, err := c.conn.Write(desc); err != nil {
-			return fmt.Errorf("pulse: write data descriptor: %w", err)
+		err := writeBuffers(c.conn, net.Buffers{desc, chunk})
+		c.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("pulse: write data: %w", err)
 		}
-		if _, err := c.conn.Write(chunk); err != nil {
-			return fmt.Errorf("pulse: write data payload: %w", err)
+		if sent != nil && !sent(len(chunk)) {
+			return nil
 		}
 	}
 
 	return nil
 }
 
-// readReply reads a single PA frame from the connection.
-// Returns the command, tag, and a TagParser for the remaining payload.
-func (c *Connection) readReply() (cmd uint32, tag uint32, tp *TagParser, err error) {
-	// Read descriptor
-	desc := make([]byte, DescriptorSize)
-	if _, err = io.ReadFull(c.conn, desc); err != nil {
-		return 0, 0, nil, fmt.Errorf("pulse: read descriptor: %w", err)
+// writeAll writes all of b, retrying after short writes. A frame cut
+// short would desynchronize the protocol for every later frame.
+func writeAll(w io.Writer, b []byte) error {
+	for len(b) > 0 {
+		n, err := w.Write(b)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		b = b[n:]
 	}
+	return nil
+}
 
-	length := binary.BigEndian.Uint32(desc[0:4])
-	channel := binary.BigEndian.Uint32(desc[4:8])
-
-	if length == 0 {
-		return 0, 0, NewTagParser(nil), nil
+// writeBuffers is writeAll for a set of buffers. Unix and TCP sockets
+// send them in one writev, which retries short writes itself; other
+// connections get one writeAll per buffer.
+func writeBuffers(w io.Writer, bufs net.Buffers) error {
+	switch w.(type) {
+	case *net.UnixConn, *net.TCPConn:
+		_, err := bufs.WriteTo(w)
+		return err
 	}
-
-	payload := make([]byte, length)
-	if _, err = io.ReadFull(c.conn, payload); err != nil {
-		return 0, 0, nil, fmt.Errorf("pulse: read payload (%d bytes): %w", length, err)
+	for _, b := range bufs {
+		if err := writeAll(w, b); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Non-control channel — data frame, skip
-	if channel != ControlChannel {
-		return 0, 0, NewTagParser(nil), nil
-	}
+// readReply reads the next control frame from the connection, skipping
+// data frames. Returns the command, tag, and a TagParser for the
+// remaining payload.
+func (c *Connection) readReply() (cmd uint32, tag uint32, tp *TagParser, err error) {
+	desc := make([]byte, DescriptorSize)
+	for {
+		if _, err = io.ReadFull(c.conn, desc); err != nil {
+			return 0, 0, nil, fmt.Errorf("pulse: read descriptor: %w", err)
+		}
 
-	tp = NewTagParser(payload)
+		length := binary.BigEndian.Uint32(desc[0:4])
+		channel := binary.BigEndian.Uint32(desc[4:8])
+
+		payload := make([]byte, length)
+		if _, err = io.ReadFull(c.conn, payload); err != nil {
+			return 0, 0, nil, fmt.Errorf("pulse: read payload (%d bytes): %w", length, err)
+		}
+
+		// Data frames (on stream channels) and empty frames carry no
+		// command
+		if channel == ControlChannel && length > 0 {
+			tp = NewTagParser(payload)
+			break
+		}
+	}
 
 	// Parse command and tag
 	cmd, err = tp.ReadU32()
@@ -245,49 +377,65 @@ func (c *Connection) readReply() (cmd uint32, tag uint32, tp *TagParser, err err
 	return cmd, tag, tp, nil
 }
 
-// DrainReplies reads and discards incoming frames until a REPLY or
-// ERROR control message arrives. This handles interleaved async
-// notifications (STARTED, REQUEST, SUBSCRIBE_EVENT, etc.) that the
-// server sends before or alongside the actual reply.
-func (c *Connection) DrainReplies() (cmd uint32, tag uint32, tp *TagParser, err error) {
+// readLoop reads every frame the server sends, handing replies to the
+// commands awaiting them and dispatching notifications. Data frames and
+// notifications we have no use for (REQUEST, STARTED, ...) are dropped.
+// When the connection fails, every waiting command is woken.
+func (c *Connection) readLoop() {
 	for {
-		// Read descriptor
-		desc := make([]byte, DescriptorSize)
-		if _, err = io.ReadFull(c.conn, desc); err != nil {
-			return 0, 0, nil, fmt.Errorf("pulse: drain read descriptor: %w", err)
-		}
-
-		length := binary.BigEndian.Uint32(desc[0:4])
-		channel := binary.BigEndian.Uint32(desc[4:8])
-
-		if length == 0 {
-			continue
-		}
-
-		payload := make([]byte, length)
-		if _, err = io.ReadFull(c.conn, payload); err != nil {
-			return 0, 0, nil, fmt.Errorf("pulse: drain read payload: %w", err)
-		}
-
-		// Skip non-control frames (data frames on stream channels)
-		if channel != ControlChannel {
-			continue
-		}
-
-		tp = NewTagParser(payload)
-		cmd, err = tp.ReadU32()
-		if err != nil {
-			return 0, 0, nil, err
-		}
-		tag, err = tp.ReadU32()
+		cmd, tag, tp, err := c.readReply()
 		if err != nil {
-			return 0, 0, nil, err
+			c.readMu.Lock()
+			c.readErr = err
+			for tag, ch := range c.pending {
+				close(ch)
+				delete(c.pending, tag)
+			}
+			if c.events != nil {
+				close(c.events)
+				c.events = nil
+			}
+			c.readMu.Unlock()
+			return
 		}
 
-		// Only return on REPLY or ERROR — skip async notifications
-		if cmd == CmdReply || cmd == CmdError {
-			return cmd, tag, tp, nil
+		switch cmd {
+		case CmdReply, CmdError:
+			c.readMu.Lock()
+			ch := c.pending[tag]
+			delete(c.pending, tag)
+			c.readMu.Unlock()
+			if ch != nil {
+				ch <- reply{cmd: cmd, tp: tp}
+			}
+
+		case CmdUnderflow, CmdOverflow:
+			channel, err := tp.ReadU32()
+			if err != nil {
+				continue
+			}
+			c.readMu.Lock()
+			s := c.streams[channel]
+			c.readMu.Unlock()
+			if s != nil {
+				s.notify(cmd)
+			}
+
+		case CmdSubscribeEvent:
+			var e SubscribeEvent
+			if e.Type, err = tp.ReadU32(); err != nil {
+				continue
+			}
+			if e.Index, err = tp.ReadU32(); err != nil {
+				continue
+			}
+			c.readMu.Lock()
+			select {
+			case c.events <- e: // A nil channel never takes it
+			default:
+				// Subscriber is behind; drop rather than stall replies
+			}
+			c.readMu.Unlock()
 		}
-		// Otherwise discard (STARTED, REQUEST, SUBSCRIBE_EVENT, etc.)
 	}
 }
@@ -0,0 +1,50 @@
+package pulse
+
+import (
+	"fmt"
+)
+
+// ServerInfo describes the PulseAudio server and its defaults.
+type ServerInfo struct {
+	PackageName    string
+	PackageVersion string
+	UserName       string
+	HostName       string
+	SampleSpec     SampleSpec // Default sample spec for new streams
+	DefaultSink    string
+	DefaultSource  string
+}
+
+// GetServerInfo queries the server's version and default settings.
+func (c *Connection) GetServerInfo() (*ServerInfo, error) {
+	tp, err := c.command("get_server_info", CmdGetServerInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ServerInfo{}
+	if info.PackageName, err = tp.ReadString(); err != nil {
+		return nil, fmt.Errorf("pulse: parse package_name: %w", err)
+	}
+	if info.PackageVersion, err = tp.ReadString(); err != nil {
+		return nil, fmt.Errorf("pulse: parse package_version: %w", err)
+	}
+	if info.UserName, err = tp.ReadString(); err != nil {
+		return nil, fmt.Errorf("pulse: parse user_name: %w", err)
+	}
+	if info.HostName, err = tp.ReadString(); err != nil {
+		return nil, fmt.Errorf("pulse: parse host_name: %w", err)
+	}
+	if info.SampleSpec, err = tp.ReadSampleSpec(); err != nil {
+		return nil, fmt.Errorf("pulse: parse sample_spec: %w", err)
+	}
+	if info.DefaultSink, err = tp.ReadString(); err != nil {
+		return nil, fmt.Errorf("pulse: parse default_sink_name: %w", err)
+	}
+	if info.DefaultSource, err = tp.ReadString(); err != nil {
+		return nil, fmt.Errorf("pulse: parse default_source_name: %w", err)
+	}
+	// cookie and channel_map follow; we don't need them
+
+	return info, nil
+}
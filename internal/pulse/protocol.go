@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // PulseAudio native protocol command IDs
@@ -20,7 +21,17 @@ const (
 	CmdAuth                 = 8
 	CmdSetClientName        = 9
 	CmdDrainPlaybackStream  = 12
+	CmdGetPlaybackLatency   = 14
+	CmdGetServerInfo        = 20
+	CmdSubscribe            = 35
+	CmdSetSinkInputVolume   = 37
+	CmdCorkPlaybackStream   = 41
 	CmdRequest              = 61
+	CmdOverflow             = 62
+	CmdUnderflow            = 63
+	CmdPlaybackStreamKilled = 64
+	CmdSubscribeEvent       = 66
+	CmdStarted              = 86
 )
 
 // Sample formats
@@ -40,6 +51,33 @@ const (
 	SampleS2432BE   = 12
 )
 
+// SampleSpec describes the PCM data carried by a stream.
+type SampleSpec struct {
+	Format   uint8 // One of the Sample* constants
+	Channels uint8
+	Rate     uint32 // Frames per second
+}
+
+// SampleSize returns the size in bytes of one sample of one channel.
+func (s SampleSpec) SampleSize() int {
+	switch s.Format {
+	case SampleU8, SampleALaw, SampleULaw:
+		return 1
+	case SampleS16LE, SampleS16BE:
+		return 2
+	case SampleS24LE, SampleS24BE:
+		return 3
+	default: // float32, s32 and s24-in-32
+		return 4
+	}
+}
+
+// FrameSize returns the size in bytes of one frame: one sample for
+// every channel.
+func (s SampleSpec) FrameSize() int {
+	return s.SampleSize() * int(s.Channels)
+}
+
 // Channel positions
 const (
 	ChannelMono      = 0
@@ -62,8 +100,14 @@ const (
 	TagCVolume    = 'v'
 	TagPropList   = 'P'
 	TagFormatInfo = 'f'
+	TagUsec       = 'U'
+	TagU64        = 'X'
+	TagTimeval    = 'T'
 )
 
+// VolumeNorm is the volume at which samples play unchanged (100%)
+const VolumeNorm = 0x10000
+
 // Protocol version we advertise (35 is widely supported)
 const ProtocolVersion = 35
 
@@ -105,6 +149,23 @@ func (tb *TagBuilder) AddS64(v int64) {
 	tb.buf = append(tb.buf, b...)
 }
 
+// AddUsec appends a TAG_USEC (a duration in microseconds).
+func (tb *TagBuilder) AddUsec(d time.Duration) {
+	tb.buf = append(tb.buf, TagUsec)
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(d.Microseconds()))
+	tb.buf = append(tb.buf, b...)
+}
+
+// AddTimeval appends a TAG_TIMEVAL (seconds and microseconds).
+func (tb *TagBuilder) AddTimeval(t time.Time) {
+	tb.buf = append(tb.buf, TagTimeval)
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b[0:], uint32(t.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(t.Nanosecond()/1000))
+	tb.buf = append(tb.buf, b...)
+}
+
 // AddString appends a TAG_STRING value (null-terminated).
 func (tb *TagBuilder) AddString(s string) {
 	tb.buf = append(tb.buf, TagString)
@@ -142,12 +203,12 @@ func (tb *TagBuilder) AddArbitrary(data []byte) {
 }
 
 // AddSampleSpec appends a TAG_SAMPLE_SPEC (format, channels, rate).
-func (tb *TagBuilder) AddSampleSpec(format uint8, channels uint8, rate uint32) {
+func (tb *TagBuilder) AddSampleSpec(spec SampleSpec) {
 	tb.buf = append(tb.buf, TagSampleSpec)
-	tb.buf = append(tb.buf, format)
-	tb.buf = append(tb.buf, channels)
+	tb.buf = append(tb.buf, spec.Format)
+	tb.buf = append(tb.buf, spec.Channels)
 	b := make([]byte, 4)
-	binary.BigEndian.PutUint32(b, rate)
+	binary.BigEndian.PutUint32(b, spec.Rate)
 	tb.buf = append(tb.buf, b...)
 }
 
@@ -160,12 +221,19 @@ func (tb *TagBuilder) AddChannelMap(channels uint8, positions []uint8) {
 
 // AddCVolume appends a TAG_CVOLUME (per-channel volumes).
 func (tb *TagBuilder) AddCVolume(channels uint8, volume uint32) {
+	volumes := make([]uint32, channels)
+	for i := range volumes {
+		volumes[i] = volume
+	}
+	tb.AddCVolumes(volumes)
+}
+
+// AddCVolumes appends a TAG_CVOLUME with a volume for each channel.
+func (tb *TagBuilder) AddCVolumes(volumes []uint32) {
 	tb.buf = append(tb.buf, TagCVolume)
-	tb.buf = append(tb.buf, channels)
-	for i := uint8(0); i < channels; i++ {
-		b := make([]byte, 4)
-		binary.BigEndian.PutUint32(b, volume)
-		tb.buf = append(tb.buf, b...)
+	tb.buf = append(tb.buf, uint8(len(volumes)))
+	for _, v := range volumes {
+		tb.buf = binary.BigEndian.AppendUint32(tb.buf, v)
 	}
 }
 
@@ -252,6 +320,54 @@ func (tp *TagParser) ReadS64() (int64, error) {
 	return v, nil
 }
 
+// ReadUsec reads a TAG_USEC as a duration.
+func (tp *TagParser) ReadUsec() (time.Duration, error) {
+	v, err := tp.readU64Tag(TagUsec, "USEC")
+	return time.Duration(v) * time.Microsecond, err
+}
+
+// ReadU64 reads a TAG_U64.
+func (tp *TagParser) ReadU64() (uint64, error) {
+	return tp.readU64Tag(TagU64, "U64")
+}
+
+// readU64Tag reads an 8-byte big-endian value carried by the given tag.
+func (tp *TagParser) readU64Tag(want byte, name string) (uint64, error) {
+	if tp.pos >= len(tp.data) {
+		return 0, fmt.Errorf("pulse: unexpected end of data reading %s tag byte", name)
+	}
+	tag := tp.data[tp.pos]
+	tp.pos++
+	if tag != want {
+		return 0, fmt.Errorf("pulse: expected TAG_%s (0x%02x), got 0x%02x", name, want, tag)
+	}
+	if tp.pos+8 > len(tp.data) {
+		return 0, fmt.Errorf("pulse: unexpected end of data reading %s value", name)
+	}
+	v := binary.BigEndian.Uint64(tp.data[tp.pos:])
+	tp.pos += 8
+	return v, nil
+}
+
+// ReadTimeval reads a TAG_TIMEVAL.
+func (tp *TagParser) ReadTimeval() (time.Time, error) {
+	if tp.pos >= len(tp.data) {
+		return time.Time{}, fmt.Errorf("pulse: unexpected end of data reading TIMEVAL tag byte")
+	}
+	tag := tp.data[tp.pos]
+	tp.pos++
+	if tag != TagTimeval {
+		return time.Time{}, fmt.Errorf("pulse: expected TAG_TIMEVAL (0x%02x), got 0x%02x", TagTimeval, tag)
+	}
+	if tp.pos+8 > len(tp.data) {
+		return time.Time{}, fmt.Errorf("pulse: unexpected end of data reading TIMEVAL value")
+	}
+	sec := binary.BigEndian.Uint32(tp.data[tp.pos:])
+	usec := binary.BigEndian.Uint32(tp.data[tp.pos+4:])
+	tp.pos += 8
+	return time.Unix(int64(sec), int64(usec)*1000), nil
+}
+
 // ReadBool reads a boolean tag.
 func (tp *TagParser) ReadBool() (bool, error) {
 	if tp.pos >= len(tp.data) {
@@ -320,23 +436,25 @@ func (tp *TagParser) ReadArbitrary() ([]byte, error) {
 }
 
 // ReadSampleSpec reads a TAG_SAMPLE_SPEC.
-func (tp *TagParser) ReadSampleSpec() (format uint8, channels uint8, rate uint32, err error) {
+func (tp *TagParser) ReadSampleSpec() (SampleSpec, error) {
 	if tp.pos >= len(tp.data) {
-		return 0, 0, 0, fmt.Errorf("pulse: unexpected end of data reading sample spec tag")
+		return SampleSpec{}, fmt.Errorf("pulse: unexpected end of data reading sample spec tag")
 	}
 	tag := tp.data[tp.pos]
 	tp.pos++
 	if tag != TagSampleSpec {
-		return 0, 0, 0, fmt.Errorf("pulse: expected TAG_SAMPLE_SPEC, got 0x%02x", tag)
+		return SampleSpec{}, fmt.Errorf("pulse: expected TAG_SAMPLE_SPEC, got 0x%02x", tag)
 	}
 	if tp.pos+6 > len(tp.data) {
-		return 0, 0, 0, fmt.Errorf("pulse: unexpected end of data reading sample spec")
+		return SampleSpec{}, fmt.Errorf("pulse: unexpected end of data reading sample spec")
+	}
+	spec := SampleSpec{
+		Format:   tp.data[tp.pos],
+		Channels: tp.data[tp.pos+1],
+		Rate:     binary.BigEndian.Uint32(tp.data[tp.pos+2:]),
 	}
-	format = tp.data[tp.pos]
-	channels = tp.data[tp.pos+1]
-	rate = binary.BigEndian.Uint32(tp.data[tp.pos+2:])
 	tp.pos += 6
-	return
+	return spec, nil
 }
 
 // ReadChannelMap reads a TAG_CHANNEL_MAP.
@@ -469,7 +587,7 @@ func (tp *TagParser) Skip() error {
 	switch tag {
 	case TagU32:
 		tp.pos += 5 // tag + 4 bytes
-	case TagS64:
+	case TagS64, TagUsec, TagU64, TagTimeval:
 		tp.pos += 9 // tag + 8 bytes
 	case TagU8:
 		tp.pos += 2 // tag + 1 byte
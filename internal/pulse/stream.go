@@ -1,22 +1,47 @@
 package pulse
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Stream represents a PulseAudio playback stream.
 type Stream struct {
-	conn    *Connection
-	channel uint32 // server-assigned data channel ID
+	conn      *Connection
+	channel   uint32 // server-assigned data channel ID
+	sinkInput uint32 // server-wide sink input index
+	spec      SampleSpec
+	written   atomic.Int64 // PCM bytes sent so far
+	writing   atomic.Int32 // WriteAll calls in progress
+	deleted   atomic.Bool  // Set by Delete
+
+	mu          sync.Mutex
+	onUnderflow func() // See OnUnderflow
+	onOverflow  func() // See OnOverflow
 }
 
-// CreatePlaybackStream creates a new playback stream.
-func (c *Connection) CreatePlaybackStream(format uint8, channels uint8, rate uint32) (*Stream, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// ErrStreamDeleted is returned by WriteAll once the stream has been
+// deleted.
+var ErrStreamDeleted = errors.New("pulse: stream deleted")
+
+// PlaybackLatency is the buffer state of a playback stream as reported
+// by GET_PLAYBACK_LATENCY.
+type PlaybackLatency struct {
+	Sink       time.Duration // Audio queued in the sink, not yet heard
+	Source     time.Duration // Always 0 for playback streams
+	Playing    bool          // False while corked or underrunning
+	Local      time.Time     // When the request was sent
+	Remote     time.Time     // When the server answered
+	WriteIndex int64         // Byte offset of the write pointer
+	ReadIndex  int64         // Byte offset the sink has consumed up to
+}
 
-	tag := c.nextTag
-	c.nextTag++
+// CreatePlaybackStream creates a new playback stream.
+func (c *Connection) CreatePlaybackStream(spec SampleSpec) (*Stream, error) {
+	channels := spec.Channels
 
 	// Build channel map positions
 	positions := make([]uint8, channels)
@@ -33,7 +58,7 @@ func (c *Connection) CreatePlaybackStream(format uint8, channels uint8, rate uin
 	tb := NewTagBuilder()
 
 	// sample_spec
-	tb.AddSampleSpec(format, channels, rate)
+	tb.AddSampleSpec(spec)
 
 	// channel_map
 	tb.AddChannelMap(channels, positions)
@@ -55,7 +80,7 @@ func (c *Connection) CreatePlaybackStream(format uint8, channels uint8, rate uin
 	tb.AddU32(0)
 
 	// cvolume
-	tb.AddCVolume(channels, 0x10000) // PA_VOLUME_NORM = 0x10000
+	tb.AddCVolume(channels, VolumeNorm)
 
 	// Since protocol >= 12: no_remap, no_remix, fix_format, fix_rate, fix_channels,
 	// no_move, variable_rate
@@ -96,24 +121,9 @@ func (c *Connection) CreatePlaybackStream(format uint8, channels uint8, rate uin
 	tb.buf = append(tb.buf, TagU8, 1)        // encoding = PA_ENCODING_PCM (1)
 	tb.AddPropList(map[string]string{})      // empty proplist for format info
 
-	frame := BuildCommand(CmdCreatePlaybackStream, tag, tb.Bytes())
-
-	if _, err := c.conn.Write(frame); err != nil {
-		return nil, fmt.Errorf("pulse: create_playback_stream write: %w", err)
-	}
-
-	// The server may interleave data-request frames before the reply,
-	// so we need to drain until we get our control reply.
-	replyCmd, _, tp, err := c.drainForReply()
+	tp, err := c.command("create_playback_stream", CmdCreatePlaybackStream, tb.Bytes())
 	if err != nil {
-		return nil, fmt.Errorf("pulse: create_playback_stream read: %w", err)
-	}
-	if replyCmd == CmdError {
-		code, _ := tp.ReadU32()
-		return nil, fmt.Errorf("pulse: create_playback_stream error (code %d)", code)
-	}
-	if replyCmd != CmdReply {
-		return nil, fmt.Errorf("pulse: create_playback_stream unexpected response %d", replyCmd)
+		return nil, err
 	}
 
 	// Parse reply: stream_index, sink_input_index, missing (requested_bytes)
@@ -128,7 +138,6 @@ func (c *Connection) CreatePlaybackStream(format uint8, channels uint8, rate uin
 	if err != nil {
 		return nil, fmt.Errorf("pulse: parse sink_input_index: %w", err)
 	}
-	_ = sinkInputIndex
 
 	// missing = how many bytes the server wants immediately
 	_, err = tp.ReadU32()
@@ -137,18 +146,200 @@ func (c *Connection) CreatePlaybackStream(format uint8, channels uint8, rate uin
 	}
 
 	return &Stream{
-		conn:    c,
-		channel: streamIndex,
+		conn:      c,
+		channel:   streamIndex,
+		sinkInput: sinkInputIndex,
+		spec:      spec,
 	}, nil
 }
 
-// drainForReply reads frames until a control reply is received.
-// Must be called with c.mu held.
-func (c *Connection) drainForReply() (cmd uint32, tag uint32, tp *TagParser, err error) {
-	return c.DrainReplies()
+// Spec returns the stream's sample spec.
+func (s *Stream) Spec() SampleSpec {
+	return s.spec
 }
 
-// WriteAll writes all PCM data to the stream.
+// WriteAll writes all PCM data to the stream. If the stream is deleted
+// meanwhile, it stops after the chunk being written and returns
+// ErrStreamDeleted.
 func (s *Stream) WriteAll(data []byte) error {
-	return s.conn.WriteData(s.channel, data)
+	s.writing.Add(1)
+	defer s.writing.Add(-1)
+	if s.deleted.Load() {
+		return ErrStreamDeleted
+	}
+	err := s.conn.writeData(s.channel, s.spec.FrameSize(), data, func(n int) bool {
+		s.written.Add(int64(n))
+		return !s.deleted.Load()
+	})
+	if err == nil && s.deleted.Load() {
+		return ErrStreamDeleted
+	}
+	return err
+}
+
+// Cork pauses (cork true) or resumes playback of the stream. Data
+// written while corked is buffered by the server and played on resume.
+func (s *Stream) Cork(cork bool) error {
+	tb := NewTagBuilder()
+	tb.AddU32(s.channel)
+	tb.AddBool(cork)
+	_, err := s.conn.command("cork_playback_stream", CmdCorkPlaybackStream, tb.Bytes())
+	return err
+}
+
+// Drain waits until the server has played all audio written to the
+// stream. It returns ErrStreamDeleted if the stream is deleted first.
+func (s *Stream) Drain() error {
+	if s.deleted.Load() {
+		return ErrStreamDeleted
+	}
+	tb := NewTagBuilder()
+	tb.AddU32(s.channel)
+	_, err := s.conn.command("drain_playback_stream", CmdDrainPlaybackStream, tb.Bytes())
+	if s.deleted.Load() {
+		return ErrStreamDeleted
+	}
+	return err
+}
+
+// Delete stops the stream at once, discarding any audio the server
+// still buffers, and makes WriteAll stop writing. Deleting a deleted
+// stream does nothing.
+func (s *Stream) Delete() error {
+	if !s.deleted.CompareAndSwap(false, true) {
+		return nil
+	}
+	s.OnUnderflow(nil)
+	s.OnOverflow(nil)
+
+	tb := NewTagBuilder()
+	tb.AddU32(s.channel)
+	_, err := s.conn.command("delete_playback_stream", CmdDeletePlaybackStream, tb.Bytes())
+	return err
+}
+
+// Written returns the number of PCM bytes sent on the stream so far.
+func (s *Stream) Written() int64 {
+	return s.written.Load()
+}
+
+// Latency queries the server for the stream's buffer state.
+func (s *Stream) Latency() (*PlaybackLatency, error) {
+	tb := NewTagBuilder()
+	tb.AddU32(s.channel)
+	tb.AddTimeval(time.Now())
+	tp, err := s.conn.command("get_playback_latency", CmdGetPlaybackLatency, tb.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	lat := &PlaybackLatency{}
+	if lat.Sink, err = tp.ReadUsec(); err != nil {
+		return nil, fmt.Errorf("pulse: parse sink_usec: %w", err)
+	}
+	if lat.Source, err = tp.ReadUsec(); err != nil {
+		return nil, fmt.Errorf("pulse: parse source_usec: %w", err)
+	}
+	if lat.Playing, err = tp.ReadBool(); err != nil {
+		return nil, fmt.Errorf("pulse: parse playing: %w", err)
+	}
+	if lat.Local, err = tp.ReadTimeval(); err != nil {
+		return nil, fmt.Errorf("pulse: parse local_time: %w", err)
+	}
+	if lat.Remote, err = tp.ReadTimeval(); err != nil {
+		return nil, fmt.Errorf("pulse: parse remote_time: %w", err)
+	}
+	if lat.WriteIndex, err = tp.ReadS64(); err != nil {
+		return nil, fmt.Errorf("pulse: parse write_index: %w", err)
+	}
+	if lat.ReadIndex, err = tp.ReadS64(); err != nil {
+		return nil, fmt.Errorf("pulse: parse read_index: %w", err)
+	}
+	// underrun_for and playing_for follow; we don't need them
+
+	return lat, nil
+}
+
+// SetVolume sets the volume of every channel of the stream, where
+// VolumeNorm plays samples unchanged. PulseAudio volumes are on a cubic
+// scale: VolumeNorm/2 is well below half loudness.
+func (s *Stream) SetVolume(volume uint32) error {
+	volumes := make([]uint32, s.spec.Channels)
+	for i := range volumes {
+		volumes[i] = volume
+	}
+	return s.SetChannelVolumes(volumes)
+}
+
+// SetChannelVolumes sets each channel's volume, in the stream's channel
+// order, on the same scale as SetVolume. There must be one volume per
+// channel.
+func (s *Stream) SetChannelVolumes(volumes []uint32) error {
+	if len(volumes) != int(s.spec.Channels) {
+		return fmt.Errorf("pulse: %d channel volumes for %d channels", len(volumes), s.spec.Channels)
+	}
+
+	tb := NewTagBuilder()
+	tb.AddU32(s.sinkInput)
+	tb.AddCVolumes(volumes)
+	_, err := s.conn.command("set_sink_input_volume", CmdSetSinkInputVolume, tb.Bytes())
+	return err
+}
+
+// OnUnderflow sets f to be called each time the stream runs out of data
+// while playing, which is heard as a gap or click. Running out after the
+// last WriteAll has returned is the sound ending, not an underflow, and
+// isn't reported. nil removes the handler. f runs on its own goroutine.
+func (s *Stream) OnUnderflow(f func()) {
+	s.mu.Lock()
+	s.onUnderflow = f
+	s.mu.Unlock()
+	s.watch()
+}
+
+// OnOverflow sets f to be called each time more data is written than
+// the server's buffer holds; the excess is dropped. nil removes the
+// handler. f runs on its own goroutine.
+func (s *Stream) OnOverflow(f func()) {
+	s.mu.Lock()
+	s.onOverflow = f
+	s.mu.Unlock()
+	s.watch()
+}
+
+// watch registers the stream for notifications with the connection's
+// reader while it has a handler, and unregisters it otherwise.
+func (s *Stream) watch() {
+	s.mu.Lock()
+	watched := (s.onUnderflow != nil || s.onOverflow != nil) && !s.deleted.Load()
+	s.mu.Unlock()
+
+	c := s.conn
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	if !watched {
+		delete(c.streams, s.channel)
+		return
+	}
+	if c.streams == nil {
+		c.streams = make(map[uint32]*Stream)
+	}
+	c.streams[s.channel] = s
+}
+
+// notify runs the handler for an UNDERFLOW or OVERFLOW notification.
+func (s *Stream) notify(cmd uint32) {
+	if cmd == CmdUnderflow && s.writing.Load() == 0 {
+		return // Played to the end
+	}
+	s.mu.Lock()
+	f := s.onUnderflow
+	if cmd == CmdOverflow {
+		f = s.onOverflow
+	}
+	s.mu.Unlock()
+	if f != nil {
+		// Off the reader goroutine, so f may send commands itself
+		go f()
+	}
 }
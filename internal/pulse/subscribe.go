@@ -0,0 +1,84 @@
+package pulse
+
+// Subscription masks for Subscribe, selecting which kinds of server
+// object to hear about
+const (
+	SubscriptionMaskSink         = 0x0001
+	SubscriptionMaskSource       = 0x0002
+	SubscriptionMaskSinkInput    = 0x0004
+	SubscriptionMaskSourceOutput = 0x0008
+	SubscriptionMaskModule       = 0x0010
+	SubscriptionMaskClient       = 0x0020
+	SubscriptionMaskSampleCache  = 0x0040
+	SubscriptionMaskServer       = 0x0080
+	SubscriptionMaskCard         = 0x0200
+)
+
+// Subscription event facilities, in the low bits of SubscribeEvent.Type
+const (
+	SubscriptionEventSink         = 0x0000
+	SubscriptionEventSource       = 0x0001
+	SubscriptionEventSinkInput    = 0x0002
+	SubscriptionEventSourceOutput = 0x0003
+	SubscriptionEventModule       = 0x0004
+	SubscriptionEventClient       = 0x0005
+	SubscriptionEventSampleCache  = 0x0006
+	SubscriptionEventServer       = 0x0007
+	SubscriptionEventCard         = 0x0009
+	SubscriptionEventFacilityMask = 0x000f
+)
+
+// Subscription event kinds, in the next bits of SubscribeEvent.Type
+const (
+	SubscriptionEventNew      = 0x0000
+	SubscriptionEventChange   = 0x0010
+	SubscriptionEventRemove   = 0x0020
+	SubscriptionEventTypeMask = 0x0030
+)
+
+// subscribeBuffer is how many events a subscriber may fall behind by
+// before events are dropped.
+const subscribeBuffer = 32
+
+// SubscribeEvent is a SUBSCRIBE_EVENT notification: an object on the
+// server was created, changed or removed.
+type SubscribeEvent struct {
+	Type  uint32 // A facility ORed with a kind
+	Index uint32 // The object's server-wide index
+}
+
+// Facility returns which kind of object the event is about.
+func (e SubscribeEvent) Facility() uint32 { return e.Type & SubscriptionEventFacilityMask }
+
+// Kind returns whether the object was created, changed or removed.
+func (e SubscribeEvent) Kind() uint32 { return e.Type & SubscriptionEventTypeMask }
+
+// Subscribe asks the server for events about the objects selected by
+// mask and returns the channel they arrive on. The server keeps one
+// subscription per connection, so a later call replaces the mask and
+// closes the earlier channel; a zero mask stops events. The channel is
+// also closed when the connection fails. Events a slow reader leaves
+// waiting are dropped once the channel's buffer fills.
+func (c *Connection) Subscribe(mask uint32) (<-chan SubscribeEvent, error) {
+	events := make(chan SubscribeEvent, subscribeBuffer)
+	c.readMu.Lock()
+	if c.events != nil {
+		close(c.events)
+	}
+	// Installed before the command so no event after its reply is lost
+	c.events = events
+	c.readMu.Unlock()
+
+	tb := NewTagBuilder()
+	tb.AddU32(mask)
+	if _, err := c.command("subscribe", CmdSubscribe, tb.Bytes()); err != nil {
+		c.readMu.Lock()
+		if c.events == events {
+			close(events)
+			c.events = nil
+		}
+		c.readMu.Unlock()
+		return nil, err
+	}
+	return events, nil
+}
@@ -3,7 +3,6 @@ package x11
 import (
 	"encoding/binary"
 	"fmt"
-	"io"
 )
 
 // Atom is an X11 atom (interned string identifier)
@@ -19,8 +18,30 @@ var (
 	AtomNetWMName            Atom
 	AtomNetWMState           Atom
 	AtomNetWMStateFullscreen Atom
+	AtomNetWMStateHidden     Atom
+	AtomNetWMStateMaxVert    Atom
+	AtomNetWMStateMaxHorz    Atom
+	AtomWMState              Atom
+	AtomAtom                 Atom
+	AtomWMClass              Atom
+
+	// XDND drag-and-drop protocol
+	AtomXdndAware      Atom
+	AtomXdndEnter      Atom
+	AtomXdndPosition   Atom
+	AtomXdndStatus     Atom
+	AtomXdndLeave      Atom
+	AtomXdndDrop       Atom
+	AtomXdndFinished   Atom
+	AtomXdndSelection  Atom
+	AtomXdndTypeList   Atom
+	AtomXdndActionCopy Atom
+	AtomTextURIList    Atom
 )
 
+// XdndVersion is the XDND protocol version we advertise
+const XdndVersion = 5
+
 // InternAtom converts a string to an atom
 func (c *Connection) InternAtom(name string, onlyIfExists bool) (Atom, error) {
 	nameBytes := []byte(name)
@@ -41,19 +62,9 @@ func (c *Connection) InternAtom(name string, onlyIfExists bool) (Atom, error) {
 	binary.LittleEndian.PutUint16(req[6:], 0) // Unused
 	copy(req[8:], nameBytes)
 
-	if _, err := c.conn.Write(req); err != nil {
-		return 0, err
-	}
-
-	// Read reply (32 bytes)
-	reply := make([]byte, 32)
-	if _, err := io.ReadFull(c.conn, reply); err != nil {
-		return 0, err
-	}
-
-	// Check for error
-	if reply[0] == 0 {
-		return 0, fmt.Errorf("InternAtom failed for %s", name)
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return 0, fmt.Errorf("InternAtom failed for %s: %w", name, err)
 	}
 
 	atom := Atom(binary.LittleEndian.Uint32(reply[8:12]))
@@ -104,6 +115,55 @@ func (c *Connection) InitAtoms() error {
 		return err
 	}
 
+	wmState := []struct {
+		atom *Atom
+		name string
+	}{
+		{&AtomNetWMStateHidden, "_NET_WM_STATE_HIDDEN"},
+		{&AtomNetWMStateMaxVert, "_NET_WM_STATE_MAXIMIZED_VERT"},
+		{&AtomNetWMStateMaxHorz, "_NET_WM_STATE_MAXIMIZED_HORZ"},
+		{&AtomWMState, "WM_STATE"},
+	}
+	for _, a := range wmState {
+		*a.atom, err = c.InternAtom(a.name, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	AtomAtom, err = c.InternAtom("ATOM", false)
+	if err != nil {
+		return err
+	}
+
+	AtomWMClass, err = c.InternAtom("WM_CLASS", false)
+	if err != nil {
+		return err
+	}
+
+	xdnd := []struct {
+		atom *Atom
+		name string
+	}{
+		{&AtomXdndAware, "XdndAware"},
+		{&AtomXdndEnter, "XdndEnter"},
+		{&AtomXdndPosition, "XdndPosition"},
+		{&AtomXdndStatus, "XdndStatus"},
+		{&AtomXdndLeave, "XdndLeave"},
+		{&AtomXdndDrop, "XdndDrop"},
+		{&AtomXdndFinished, "XdndFinished"},
+		{&AtomXdndSelection, "XdndSelection"},
+		{&AtomXdndTypeList, "XdndTypeList"},
+		{&AtomXdndActionCopy, "XdndActionCopy"},
+		{&AtomTextURIList, "text/uri-list"},
+	}
+	for _, a := range xdnd {
+		*a.atom, err = c.InternAtom(a.name, false)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -128,10 +188,69 @@ func (c *Connection) ChangeProperty(window uint32, property, propType Atom,
 	binary.LittleEndian.PutUint32(req[20:], uint32(dataLen/(int(format)/8)))
 	copy(req[24:], data)
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
 
+// Property is the value of a window property as returned by GetProperty
+type Property struct {
+	Type   Atom  // 0 (None) if the property does not exist
+	Format uint8 // 8, 16 or 32
+	Value  []byte
+}
+
+// propertyReadLength is how many 4-byte units GetProperty asks for
+const propertyReadLength = 0x1000000
+
+// DeleteProperty removes a property from a window. Deleting a property
+// the window doesn't have is not an error.
+func (c *Connection) DeleteProperty(window uint32, property Atom) error {
+	req := make([]byte, 12)
+	req[0] = OpDeleteProperty
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 3)
+	binary.LittleEndian.PutUint32(req[4:], window)
+	binary.LittleEndian.PutUint32(req[8:], uint32(property))
+
+	return c.send(req)
+}
+
+// GetProperty reads a window property. propType 0 accepts any type.
+// If deleteProp is set and the whole value was read, the server
+// deletes the property afterwards.
+func (c *Connection) GetProperty(window uint32, property, propType Atom,
+	deleteProp bool) (*Property, error) {
+
+	req := make([]byte, 24)
+	req[0] = OpGetProperty
+	if deleteProp {
+		req[1] = 1
+	}
+	binary.LittleEndian.PutUint16(req[2:], 6)
+	binary.LittleEndian.PutUint32(req[4:], window)
+	binary.LittleEndian.PutUint32(req[8:], uint32(property))
+	binary.LittleEndian.PutUint32(req[12:], uint32(propType))
+	binary.LittleEndian.PutUint32(req[16:], 0)                  // Long offset
+	binary.LittleEndian.PutUint32(req[20:], propertyReadLength) // Long length
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("GetProperty failed: %w", err)
+	}
+
+	p := &Property{
+		Format: reply[1],
+		Type:   Atom(binary.LittleEndian.Uint32(reply[8:12])),
+	}
+	valueLen := int(binary.LittleEndian.Uint32(reply[16:20])) * int(p.Format) / 8
+	if valueLen > len(reply)-32 {
+		valueLen = len(reply) - 32
+	}
+	p.Value = reply[32 : 32+valueLen]
+
+	return p, nil
+}
+
 // SetWindowTitle sets the window title
 func (c *Connection) SetWindowTitle(window uint32, title string) error {
 	titleBytes := []byte(title)
@@ -151,17 +270,31 @@ func (c *Connection) SetWindowTitle(window uint32, title string) error {
 
 // EnableCloseButton registers for WM_DELETE_WINDOW messages
 func (c *Connection) EnableCloseButton(window uint32) error {
-	// Get ATOM type
-	atomAtom, err := c.InternAtom("ATOM", false)
-	if err != nil {
-		return err
-	}
-
 	// Set WM_PROTOCOLS property to include WM_DELETE_WINDOW
 	data := make([]byte, 4)
 	binary.LittleEndian.PutUint32(data, uint32(AtomWMDeleteWindow))
 
-	return c.ChangeProperty(window, AtomWMProtocols, atomAtom, 32, data)
+	return c.ChangeProperty(window, AtomWMProtocols, AtomAtom, 32, data)
+}
+
+// SetWindowClass sets WM_CLASS, which window managers and taskbars use
+// to group windows and pick icons and rules. The property holds the
+// instance and class names, each null-terminated (ICCCM 4.1.2.5).
+func (c *Connection) SetWindowClass(window uint32, instance, class string) error {
+	data := make([]byte, 0, len(instance)+len(class)+2)
+	data = append(data, instance...)
+	data = append(data, 0)
+	data = append(data, class...)
+	data = append(data, 0)
+	return c.ChangeProperty(window, AtomWMClass, AtomString, 8, data)
+}
+
+// EnableDragAndDrop advertises the window as an XDND drop target
+func (c *Connection) EnableDragAndDrop(window uint32) error {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, XdndVersion)
+
+	return c.ChangeProperty(window, AtomXdndAware, AtomAtom, 32, data)
 }
 
 // IsDeleteWindowEvent checks if a ClientMessage is WM_DELETE_WINDOW
@@ -5,6 +5,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 )
 
 // AuthEntry represents an Xauthority entry
@@ -103,8 +105,51 @@ func readString(r io.Reader) ([]byte, error) {
 	return data, nil
 }
 
-// FindAuth finds authentication for a display
+// AuthMITMagicCookie is the name of the cookie-based auth protocol every
+// X server supports, and the one the connection handshake sends.
+const AuthMITMagicCookie = "MIT-MAGIC-COOKIE-1"
+
+// authProtocol is the auth protocol set by SetAuthProtocol, "" for
+// the default choice.
+var authProtocol atomic.Value
+
+// SetAuthProtocol restricts the connection handshake to Xauthority
+// entries of the auth protocol name, such as AuthMITMagicCookie. If the
+// file has no such entry, the handshake sends no auth. An empty name
+// restores the default of FindAuth.
+func SetAuthProtocol(name string) {
+	authProtocol.Store(name)
+}
+
+// findHandshakeAuth picks the entry the handshake sends, following
+// SetAuthProtocol.
+func findHandshakeAuth(entries []AuthEntry, displayNum string) *AuthEntry {
+	name, _ := authProtocol.Load().(string)
+	if name == "" {
+		return FindAuth(entries, displayNum)
+	}
+	hostname, _ := os.Hostname()
+	return FindAuthByName(entries, displayNum, hostname, name)
+}
+
+// FindAuth finds authentication for a display, preferring a
+// MIT-MAGIC-COOKIE-1 entry over other protocols.
 func FindAuth(entries []AuthEntry, displayNum string) *AuthEntry {
+	hostname, _ := os.Hostname()
+	return FindAuthForHost(entries, displayNum, hostname)
+}
+
+// FindAuthForHost is FindAuth for a given local hostname.
+func FindAuthForHost(entries []AuthEntry, displayNum, hostname string) *AuthEntry {
+	if e := FindAuthByName(entries, displayNum, hostname, AuthMITMagicCookie); e != nil {
+		return e
+	}
+	return FindAuthByName(entries, displayNum, hostname, "")
+}
+
+// FindAuthByName returns the first entry for the display and host using
+// the auth protocol name, or any protocol if name is empty.
+func FindAuthByName(entries []AuthEntry, displayNum, hostname, name string) *AuthEntry {
 	// Family values
 	const (
 		FamilyLocal     = 256
@@ -112,20 +157,21 @@ func FindAuth(entries []AuthEntry, displayNum string) *AuthEntry {
 		FamilyLocalHost = 252
 	)
 
-	hostname, _ := os.Hostname()
-
 	for i := range entries {
 		e := &entries[i]
 
-		// Check display number
+		// Check display number and protocol
 		if e.Display != displayNum && e.Display != "" {
 			continue
 		}
+		if name != "" && e.Name != name {
+			continue
+		}
 
 		// Check family/address
 		switch e.Family {
 		case FamilyLocal:
-			if e.Address == hostname || e.Address == "" {
+			if e.Address == "" || sameHost(e.Address, hostname) {
 				return e
 			}
 		case FamilyWild:
@@ -134,7 +180,7 @@ func FindAuth(entries []AuthEntry, displayNum string) *AuthEntry {
 			return e
 		default:
 			// For other families, check if address matches
-			if e.Address == hostname || e.Address == "localhost" || e.Address == "" {
+			if e.Address == "" || e.Address == "localhost" || sameHost(e.Address, hostname) {
 				return e
 			}
 		}
@@ -142,3 +188,22 @@ func FindAuth(entries []AuthEntry, displayNum string) *AuthEntry {
 
 	return nil
 }
+
+// sameHost reports whether two hostnames name the same machine, ignoring
+// case and any domain suffix, so an entry written for "box.example.com"
+// matches the hostname "box" and vice versa.
+func sameHost(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	short := func(h string) string {
+		if i := strings.IndexByte(h, '.'); i > 0 {
+			return h[:i]
+		}
+		return h
+	}
+	return strings.EqualFold(short(a), short(b))
+}
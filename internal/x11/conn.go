@@ -8,8 +8,12 @@ import (
 	"net"
 	"os"
 	"strings"
+	"sync"
 )
 
+// ErrNoDisplay is returned by Connect when no X server can be reached
+var ErrNoDisplay = errors.New("no X11 display available")
+
 // Connection represents a connection to the X11 server
 type Connection struct {
 	conn net.Conn
@@ -24,8 +28,53 @@ type Connection struct {
 	ScreenWidth    uint16
 	ScreenHeight   uint16
 
+	// Visuals of the screen, at every depth it supports
+	Visuals []VisualInfo
+
+	// Range of keycodes the server sends, for GetKeyboardMapping
+	MinKeycode uint8
+	MaxKeycode uint8
+
+	// Server identification, e.g. "The X.Org Foundation" and 12101011
+	Vendor        string
+	ReleaseNumber uint32
+
+	// Auth protocol and data sent in the handshake, empty if none was
+	// found. Kept for diagnosing rejected connections.
+	AuthName string
+	AuthData []byte
+
 	// ID generation
 	nextID uint32
+
+	// RANDR extension, looked up on first use (see randr.go)
+	randrOnce   sync.Once
+	randrOpcode uint8
+	randrMinor  uint32
+	randrErr    error
+
+	// MIT-SHM extension, looked up on first use (see shm.go)
+	shmOnce   sync.Once
+	shmOpcode uint8
+	shmErr    error
+
+	// Request bookkeeping. Every request goes through send so the
+	// sequence number stays in step with the server's count.
+	writeMu sync.Mutex
+	seq     uint16
+
+	// Reply routing. The reader goroutine owns all reads after the
+	// handshake; a round trip registers the sequence number it expects
+	// and the reader hands the matching reply (or error) back to it.
+	roundTripMu sync.Mutex
+	mu          sync.Mutex
+	replySeq    uint16
+	replyCh     chan []byte
+
+	// Events queued by the reader goroutine for NextEvent
+	eventCond *sync.Cond
+	events    []Event
+	readErr   error
 }
 
 // Connect establishes a connection to the X11 server
@@ -50,16 +99,29 @@ func Connect() (*Connection, error) {
 	socketPath := fmt.Sprintf("/tmp/.X11-unix/X%s", displayNum)
 	conn, err := net.Dial("unix", socketPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to X11: %w", err)
+		return nil, fmt.Errorf("failed to connect to X11: %w (%v)", ErrNoDisplay, err)
 	}
 
+	return Handshake(conn, displayNum)
+}
+
+// Handshake performs the connection setup with the X server at the
+// other end of conn, which serves display displayNum, and returns the
+// Connection ready for requests. Connect calls it after dialing; it is
+// also meant for tests and custom transports. conn is closed if the
+// setup fails.
+func Handshake(conn net.Conn, displayNum string) (*Connection, error) {
 	c := &Connection{conn: conn}
+	c.eventCond = sync.NewCond(&c.mu)
 
-	if err := c.handshake(); err != nil {
+	if err := c.handshake(displayNum); err != nil {
 		conn.Close()
 		return nil, err
 	}
 
+	// From here on, only the reader goroutine reads from the socket
+	go c.readLoop()
+
 	// Initialize atoms for window manager integration
 	if err := c.InitAtoms(); err != nil {
 		conn.Close()
@@ -69,31 +131,49 @@ func Connect() (*Connection, error) {
 	return c, nil
 }
 
+// NewConnection wraps an established connection to an X server without
+// performing the handshake, so the setup fields are left zero. It is
+// meant for tests and custom transports; use Connect to talk to the
+// local server.
+func NewConnection(conn net.Conn) *Connection {
+	c := &Connection{conn: conn}
+	c.eventCond = sync.NewCond(&c.mu)
+	go c.readLoop()
+	return c
+}
+
 // Close closes the connection
 func (c *Connection) Close() error {
 	return c.conn.Close()
 }
 
-// Write writes raw bytes to the X11 connection
+// Write writes a raw request to the X11 connection.
+// data must hold exactly one complete request.
 func (c *Connection) Write(data []byte) (int, error) {
-	return c.conn.Write(data)
+	if err := c.send(data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
 }
 
-// Reader returns the underlying connection for reading
+// Reader returns the underlying connection for reading.
+// After Connect returns, the connection's reader goroutine consumes
+// everything the server sends; use NextEvent instead.
 func (c *Connection) Reader() io.Reader {
 	return c.conn
 }
 
-func (c *Connection) handshake() error {
+func (c *Connection) handshake(displayNum string) error {
 	// Read Xauthority for authentication
 	var authName, authData []byte
 
 	entries, err := ReadXauthority()
 	if err == nil {
-		// Try to find auth for display 0
-		if auth := FindAuth(entries, "0"); auth != nil {
+		if auth := findHandshakeAuth(entries, displayNum); auth != nil {
 			authName = []byte(auth.Name)
 			authData = auth.Data
+			c.AuthName = auth.Name
+			c.AuthData = auth.Data
 		}
 	}
 
@@ -117,13 +197,13 @@ func (c *Connection) handshake() error {
 	copy(setup[12:], authName)
 	copy(setup[12+len(authName)+authNamePad:], authData)
 
-	if _, err := c.conn.Write(setup); err != nil {
+	if err := writeAll(c.conn, setup); err != nil {
 		return fmt.Errorf("failed to send setup: %w", err)
 	}
 
 	// Read response header (8 bytes minimum)
 	header := make([]byte, 8)
-	if _, err := c.conn.Read(header); err != nil {
+	if _, err := io.ReadFull(c.conn, header); err != nil {
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
@@ -132,7 +212,7 @@ func (c *Connection) handshake() error {
 	case 0: // Failed
 		reasonLen := header[1]
 		reason := make([]byte, reasonLen)
-		c.conn.Read(reason)
+		io.ReadFull(c.conn, reason)
 		return fmt.Errorf("connection failed: %s", string(reason))
 	case 1: // Success
 		return c.parseSetupSuccess(header)
@@ -145,22 +225,28 @@ func (c *Connection) handshake() error {
 
 func (c *Connection) parseSetupSuccess(header []byte) error {
 	// Additional data length is in header[6:8] (in 4-byte units)
-	additionalLen := binary.LittleEndian.Uint16(header[6:]) * 4
+	additionalLen := int(binary.LittleEndian.Uint16(header[6:])) * 4
 
 	// Read the rest of the setup response
 	data := make([]byte, additionalLen)
-	if _, err := c.conn.Read(data); err != nil {
+	if _, err := io.ReadFull(c.conn, data); err != nil {
 		return fmt.Errorf("failed to read setup data: %w", err)
 	}
+	if len(data) < 32 {
+		return fmt.Errorf("setup data too short (%d bytes)", len(data))
+	}
 
 	// Parse the setup response
+	c.ReleaseNumber = binary.LittleEndian.Uint32(data[0:4])
 	c.ResourceIDBase = binary.LittleEndian.Uint32(data[4:8])
 	c.ResourceIDMask = binary.LittleEndian.Uint32(data[8:12])
 
 	// Skip to screen info
-	vendorLen := binary.LittleEndian.Uint16(data[16:18])
-	numFormats := data[21]
+	vendorLen := int(binary.LittleEndian.Uint16(data[16:18]))
+	numFormats := int(data[21])
 	numScreens := data[20]
+	c.MinKeycode = data[26]
+	c.MaxKeycode = data[27]
 
 	if numScreens == 0 {
 		return errors.New("no screens available")
@@ -169,8 +255,13 @@ func (c *Connection) parseSetupSuccess(header []byte) error {
 	// Calculate offset to first screen
 	// Vendor string is padded to 4-byte boundary
 	vendorPadded := (vendorLen + 3) &^ 3
-	formatSize := uint16(numFormats) * 8
-	screenOffset := 32 + vendorPadded + formatSize
+	formatOffset := 32 + vendorPadded
+	screenOffset := formatOffset + numFormats*8
+	if screenOffset+40 > len(data) {
+		return fmt.Errorf("setup data too short (%d bytes) for its vendor, formats and screen", len(data))
+	}
+
+	c.Vendor = string(data[32 : 32+vendorLen])
 
 	// Parse first screen
 	screen := data[screenOffset:]
@@ -179,11 +270,10 @@ func (c *Connection) parseSetupSuccess(header []byte) error {
 	c.ScreenHeight = binary.LittleEndian.Uint16(screen[22:24])
 	c.RootDepth = screen[38]
 	c.RootVisual = binary.LittleEndian.Uint32(screen[32:36])
+	c.Visuals = parseVisuals(screen)
 
 	// Parse pixmap formats to find bits-per-pixel for our depth
-	// Formats start at offset 32 + vendorPadded
-	formatOffset := 32 + int(vendorPadded)
-	for i := 0; i < int(numFormats); i++ {
+	for i := 0; i < numFormats; i++ {
 		fmtData := data[formatOffset+i*8:]
 		depth := fmtData[0]
 		bpp := fmtData[1]
@@ -214,26 +304,148 @@ func (c *Connection) GenerateID() uint32 {
 // Sync sends a GetInputFocus request and waits for the reply
 // This ensures all previous requests have been processed
 func (c *Connection) Sync() error {
-	req := make([]byte, 4)
-	req[0] = 43 // GetInputFocus opcode
-	req[1] = 0
-	binary.LittleEndian.PutUint16(req[2:], 1) // Length
+	_, err := c.GetInputFocus()
+	return err
+}
+
+// send writes a single request that has no reply.
+func (c *Connection) send(req []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 
-	if _, err := c.conn.Write(req); err != nil {
+	if err := writeAll(c.conn, req); err != nil {
 		return err
 	}
+	c.seq++
+	return nil
+}
 
-	// Read reply (32 bytes)
-	reply := make([]byte, 32)
-	if _, err := c.conn.Read(reply); err != nil {
-		return err
+// writeAll writes all of b, retrying after short writes. A request cut
+// short would leave the server reading the rest of it from whatever is
+// sent next, corrupting every later request.
+func writeAll(w io.Writer, b []byte) error {
+	for len(b) > 0 {
+		n, err := w.Write(b)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// roundTrip writes a single request and blocks until its reply arrives.
+// The returned slice holds the 32-byte reply header followed by any
+// additional reply data. An X11 error for the request is returned as err.
+func (c *Connection) roundTrip(req []byte) ([]byte, error) {
+	c.roundTripMu.Lock()
+	defer c.roundTripMu.Unlock()
+
+	ch := make(chan []byte, 1)
+
+	c.writeMu.Lock()
+	c.mu.Lock()
+	if c.readErr != nil {
+		err := c.readErr
+		c.mu.Unlock()
+		c.writeMu.Unlock()
+		return nil, err
+	}
+	// Register before writing so the reader can't miss a fast reply
+	c.replySeq = c.seq + 1
+	c.replyCh = ch
+	c.mu.Unlock()
+
+	err := writeAll(c.conn, req)
+	if err == nil {
+		c.seq++
+	}
+	c.writeMu.Unlock()
+
+	if err != nil {
+		c.mu.Lock()
+		c.replyCh = nil
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	reply, ok := <-ch
+	if !ok {
+		c.mu.Lock()
+		err := c.readErr
+		c.mu.Unlock()
+		return nil, err
 	}
 
 	// Check if it's an error (first byte = 0)
 	if reply[0] == 0 {
-		errorCode := reply[1]
-		return fmt.Errorf("X11 error: code %d", errorCode)
+		return nil, fmt.Errorf("X11 error: code %d", reply[1])
 	}
 
-	return nil
+	return reply, nil
+}
+
+// readLoop reads every packet the server sends after the handshake.
+// Replies and errors are handed to the waiting round trip; events are
+// queued for NextEvent.
+func (c *Connection) readLoop() {
+	for {
+		buf := make([]byte, 32)
+		if _, err := io.ReadFull(c.conn, buf); err != nil {
+			c.fail(err)
+			return
+		}
+
+		// Replies and GenericEvents carry additional data after
+		// the first 32 bytes, in 4-byte units
+		if buf[0] == 1 || buf[0]&0x7F == EventGeneric {
+			extra := binary.LittleEndian.Uint32(buf[4:8]) * 4
+			if extra > 0 {
+				full := make([]byte, 32+int(extra))
+				copy(full, buf)
+				if _, err := io.ReadFull(c.conn, full[32:]); err != nil {
+					c.fail(err)
+					return
+				}
+				buf = full
+			}
+		}
+
+		switch buf[0] {
+		case 0, 1: // Error, Reply
+			c.deliverReply(buf)
+		default:
+			c.queueEvent(parseEvent(buf))
+		}
+	}
+}
+
+// deliverReply hands a reply or error to the round trip waiting for it.
+// Errors for requests nobody is waiting on are dropped.
+func (c *Connection) deliverReply(buf []byte) {
+	seq := binary.LittleEndian.Uint16(buf[2:4])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.replyCh != nil && seq == c.replySeq {
+		c.replyCh <- buf
+		c.replyCh = nil
+	}
+}
+
+// fail records a read error and wakes everyone waiting on the reader.
+func (c *Connection) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readErr = err
+	if c.replyCh != nil {
+		close(c.replyCh)
+		c.replyCh = nil
+	}
+	c.eventCond.Broadcast()
 }
@@ -0,0 +1,133 @@
+package x11
+
+import "encoding/binary"
+
+// CreateFontCursor creates a cursor from glyph of the standard "cursor"
+// font (the XC_ values of X11/cursorfont.h), black on white like the
+// cursors other applications use.
+func (c *Connection) CreateFontCursor(glyph uint16) (uint32, error) {
+	fontID, err := c.openFont("cursor")
+	if err != nil {
+		return 0, err
+	}
+	defer c.closeFont(fontID)
+
+	cursorID := c.GenerateID()
+	req := make([]byte, 32)
+	req[0] = OpCreateGlyphCursor
+	binary.LittleEndian.PutUint16(req[2:], 8)
+	binary.LittleEndian.PutUint32(req[4:], cursorID)
+	binary.LittleEndian.PutUint32(req[8:], fontID)  // Source font
+	binary.LittleEndian.PutUint32(req[12:], fontID) // Mask font
+	binary.LittleEndian.PutUint16(req[16:], glyph)
+	binary.LittleEndian.PutUint16(req[18:], glyph+1) // Each glyph's mask follows it
+	// Foreground black (bytes 20-25 left 0), background white
+	binary.LittleEndian.PutUint16(req[26:], 0xffff)
+	binary.LittleEndian.PutUint16(req[28:], 0xffff)
+	binary.LittleEndian.PutUint16(req[30:], 0xffff)
+
+	if err := c.send(req); err != nil {
+		return 0, err
+	}
+	return cursorID, nil
+}
+
+// CreateBlankCursor creates an invisible cursor, for hiding the pointer
+// over a window. drawable picks the screen.
+func (c *Connection) CreateBlankCursor(drawable uint32) (uint32, error) {
+	// A 1x1 bitmap cleared to 0 serves as both the shape and the mask;
+	// the empty mask makes every pixel transparent
+	pixmapID, err := c.CreatePixmap(drawable, 1, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+	defer c.FreePixmap(pixmapID)
+	gcID, err := c.CreateGCValues(pixmapID, GCForeground, []uint32{0})
+	if err != nil {
+		return 0, err
+	}
+	defer c.FreeGC(gcID)
+	if err := c.FillRectangles(pixmapID, gcID, []Rectangle{{Width: 1, Height: 1}}); err != nil {
+		return 0, err
+	}
+
+	cursorID := c.GenerateID()
+	req := make([]byte, 32)
+	req[0] = OpCreateCursor
+	binary.LittleEndian.PutUint16(req[2:], 8)
+	binary.LittleEndian.PutUint32(req[4:], cursorID)
+	binary.LittleEndian.PutUint32(req[8:], pixmapID)  // Source
+	binary.LittleEndian.PutUint32(req[12:], pixmapID) // Mask
+	// Colors and hotspot (bytes 16-31) left 0
+
+	if err := c.send(req); err != nil {
+		return 0, err
+	}
+	return cursorID, nil
+}
+
+// WarpPointer moves the pointer to (x, y) relative to window, as if the
+// user had moved it there.
+func (c *Connection) WarpPointer(windowID uint32, x, y int16) error {
+	req := make([]byte, 24)
+	req[0] = OpWarpPointer
+	binary.LittleEndian.PutUint16(req[2:], 6)
+	// Source window None and an empty source area: warp from anywhere
+	binary.LittleEndian.PutUint32(req[8:], windowID)
+	binary.LittleEndian.PutUint16(req[20:], uint16(x))
+	binary.LittleEndian.PutUint16(req[22:], uint16(y))
+
+	return c.send(req)
+}
+
+// FreeCursor frees a cursor. Windows using it keep showing it until
+// their cursor is changed.
+func (c *Connection) FreeCursor(cursorID uint32) error {
+	req := make([]byte, 8)
+	req[0] = OpFreeCursor
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], cursorID)
+
+	return c.send(req)
+}
+
+// SetWindowCursor sets the cursor shown while the pointer is over
+// window. A cursor of 0 (None) uses the parent window's cursor.
+func (c *Connection) SetWindowCursor(windowID, cursorID uint32) error {
+	req := make([]byte, 16)
+	req[0] = OpChangeWindowAttributes
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], windowID)
+	binary.LittleEndian.PutUint32(req[8:], CWCursor)
+	binary.LittleEndian.PutUint32(req[12:], cursorID)
+
+	return c.send(req)
+}
+
+// openFont opens the server font called name.
+func (c *Connection) openFont(name string) (uint32, error) {
+	fontID := c.GenerateID()
+	n := len(name)
+	pad := (4 - n%4) % 4
+	req := make([]byte, 12+n+pad)
+	req[0] = OpOpenFont
+	binary.LittleEndian.PutUint16(req[2:], uint16(len(req)/4))
+	binary.LittleEndian.PutUint32(req[4:], fontID)
+	binary.LittleEndian.PutUint16(req[8:], uint16(n))
+	copy(req[12:], name)
+
+	if err := c.send(req); err != nil {
+		return 0, err
+	}
+	return fontID, nil
+}
+
+// closeFont releases a font opened with openFont.
+func (c *Connection) closeFont(fontID uint32) error {
+	req := make([]byte, 8)
+	req[0] = OpCloseFont
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], fontID)
+
+	return c.send(req)
+}
@@ -0,0 +1,7 @@
+//go:build !glowdebug
+
+package x11
+
+// debugChecks turns on the assertions of the unchecked fast paths, such
+// as BlitSpriteFast. Build with -tags glowdebug to enable them.
+const debugChecks = false
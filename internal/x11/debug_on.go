@@ -0,0 +1,5 @@
+//go:build glowdebug
+
+package x11
+
+const debugChecks = true
@@ -10,19 +10,41 @@ const (
 	GCForeground        = 1 << 2
 	GCBackground        = 1 << 3
 	GCLineWidth         = 1 << 4
+	GCLineStyle         = 1 << 5
+	GCFillStyle         = 1 << 8
 	GCGraphicsExposures = 1 << 16
 )
 
+// Line styles
+const (
+	LineSolid      = 0
+	LineOnOffDash  = 1
+	LineDoubleDash = 2
+)
+
+// Fill styles
+const (
+	FillSolid          = 0
+	FillTiled          = 1
+	FillStippled       = 2
+	FillOpaqueStippled = 3
+)
+
 // CreateGC creates a graphics context for drawing
 func (c *Connection) CreateGC(drawable uint32) (uint32, error) {
-	gcID := c.GenerateID()
-
 	// Set foreground (white), background (black), and disable
 	// graphics exposures (we don't want Expose events from drawing)
-	valueMask := uint32(GCForeground | GCBackground | GCGraphicsExposures)
-	valueCount := 3
+	return c.CreateGCValues(drawable, GCForeground|GCBackground|GCGraphicsExposures,
+		[]uint32{0xFFFFFF, 0x000000, 0})
+}
+
+// CreateGCValues creates a graphics context with the attributes in
+// valueMask set. values holds one entry per bit set in valueMask, in
+// order of increasing bit.
+func (c *Connection) CreateGCValues(drawable, valueMask uint32, values []uint32) (uint32, error) {
+	gcID := c.GenerateID()
 
-	reqLen := 4 + valueCount
+	reqLen := 4 + len(values)
 	req := make([]byte, reqLen*4)
 
 	req[0] = OpCreateGC
@@ -31,11 +53,11 @@ func (c *Connection) CreateGC(drawable uint32) (uint32, error) {
 	binary.LittleEndian.PutUint32(req[4:], gcID)
 	binary.LittleEndian.PutUint32(req[8:], drawable)
 	binary.LittleEndian.PutUint32(req[12:], valueMask)
-	binary.LittleEndian.PutUint32(req[16:], 0xFFFFFF) // Foreground: white
-	binary.LittleEndian.PutUint32(req[20:], 0x000000) // Background: black
-	binary.LittleEndian.PutUint32(req[24:], 0)        // GraphicsExposures: off
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(req[16+i*4:], v)
+	}
 
-	if _, err := c.conn.Write(req); err != nil {
+	if err := c.send(req); err != nil {
 		return 0, err
 	}
 
@@ -50,7 +72,7 @@ func (c *Connection) FreeGC(gcID uint32) error {
 	binary.LittleEndian.PutUint16(req[2:], 2)
 	binary.LittleEndian.PutUint32(req[4:], gcID)
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
 
@@ -125,7 +147,7 @@ func (c *Connection) putImageStrip(drawable, gc uint32, width, height uint16,
 	// Copy pixel data
 	copy(req[24:], data)
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
 
@@ -155,6 +177,34 @@ func (c *Connection) FillRectangles(drawable, gc uint32, rects []Rectangle) erro
 		offset += 8
 	}
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
+
+// Segment is a line from (X1, Y1) to (X2, Y2)
+type Segment struct {
+	X1, Y1, X2, Y2 int16
+}
+
+// PolySegment draws unconnected lines with the GC's line attributes
+func (c *Connection) PolySegment(drawable, gc uint32, segs []Segment) error {
+	reqLen := 3 + len(segs)*2
+	req := make([]byte, reqLen*4)
+
+	req[0] = OpPolySegment
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], uint16(reqLen))
+	binary.LittleEndian.PutUint32(req[4:], drawable)
+	binary.LittleEndian.PutUint32(req[8:], gc)
+
+	offset := 12
+	for _, s := range segs {
+		binary.LittleEndian.PutUint16(req[offset:], uint16(s.X1))
+		binary.LittleEndian.PutUint16(req[offset+2:], uint16(s.Y1))
+		binary.LittleEndian.PutUint16(req[offset+4:], uint16(s.X2))
+		binary.LittleEndian.PutUint16(req[offset+6:], uint16(s.Y2))
+		offset += 8
+	}
+
+	return c.send(req)
+}
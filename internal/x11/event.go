@@ -2,7 +2,6 @@ package x11
 
 import (
 	"encoding/binary"
-	"io"
 )
 
 // Event is the interface for all X11 events
@@ -13,6 +12,7 @@ type Event interface {
 // KeyEvent represents a key press or release
 type KeyEvent struct {
 	EventType int
+	Window    uint32 // Window the event was reported on
 	Keycode   uint8
 	State     uint16 // Modifier state (shift, ctrl, etc.)
 	X, Y      int16  // Position relative to window
@@ -25,7 +25,8 @@ func (e KeyEvent) Type() int { return e.EventType }
 // ButtonEvent represents a mouse button press or release
 type ButtonEvent struct {
 	EventType int
-	Button    uint8  // 1=left, 2=middle, 3=right, 4=wheel up, 5=wheel down
+	Window    uint32
+	Button    uint8  // 1=left, 2=middle, 3=right, 4/5=wheel up/down, 6/7=wheel left/right
 	State     uint16 // Modifier state
 	X, Y      int16
 	RootX     int16
@@ -36,10 +37,11 @@ func (e ButtonEvent) Type() int { return e.EventType }
 
 // MotionEvent represents mouse movement
 type MotionEvent struct {
-	X, Y  int16
-	RootX int16
-	RootY int16
-	State uint16 // Which buttons/modifiers are held
+	Window uint32
+	X, Y   int16
+	RootX  int16
+	RootY  int16
+	State  uint16 // Which buttons/modifiers are held
 }
 
 func (e MotionEvent) Type() int { return EventMotionNotify }
@@ -65,6 +67,38 @@ type ConfigureEvent struct {
 
 func (e ConfigureEvent) Type() int { return EventConfigureNotify }
 
+// MapEvent means the window was mapped (MapNotify) or unmapped
+// (UnmapNotify), as window managers do when it is restored or
+// minimized
+type MapEvent struct {
+	EventType int
+	Window    uint32
+}
+
+func (e MapEvent) Type() int { return e.EventType }
+
+// FocusEvent means the window gained (FocusIn) or lost (FocusOut) the
+// keyboard focus
+type FocusEvent struct {
+	EventType int
+	Window    uint32
+	Detail    uint8 // How focus moved relative to the window (NotifyPointer etc.)
+	Mode      uint8 // NotifyNormal, or NotifyGrab/NotifyUngrab for keyboard grabs
+}
+
+func (e FocusEvent) Type() int { return e.EventType }
+
+// PropertyNotifyEvent means a property of the window changed or was
+// deleted
+type PropertyNotifyEvent struct {
+	Window uint32
+	Atom   Atom
+	Time   uint32
+	State  uint8 // PropertyNewValue or PropertyDelete
+}
+
+func (e PropertyNotifyEvent) Type() int { return EventPropertyNotify }
+
 // ClientMessageEvent is used for window manager communication
 type ClientMessageEvent struct {
 	Window    uint32
@@ -75,6 +109,35 @@ type ClientMessageEvent struct {
 
 func (e ClientMessageEvent) Type() int { return EventClientMessage }
 
+// SelectionNotifyEvent reports the outcome of a ConvertSelection request
+type SelectionNotifyEvent struct {
+	Time      uint32
+	Requestor uint32
+	Selection Atom
+	Target    Atom
+	Property  Atom // 0 (None) if the conversion failed
+}
+
+func (e SelectionNotifyEvent) Type() int { return EventSelectionNotify }
+
+// Requests of a MappingNotify event
+const (
+	MappingModifier = 0
+	MappingKeyboard = 1
+	MappingPointer  = 2
+)
+
+// MappingNotifyEvent means the server's modifier, keyboard or pointer
+// mapping changed. After a MappingKeyboard change, clients should fetch
+// the keyboard mapping again.
+type MappingNotifyEvent struct {
+	Request      uint8 // MappingModifier, MappingKeyboard or MappingPointer
+	FirstKeycode uint8
+	Count        uint8
+}
+
+func (e MappingNotifyEvent) Type() int { return EventMappingNotify }
+
 // UnknownEvent for events we don't handle yet
 type UnknownEvent struct {
 	EventType int
@@ -83,15 +146,63 @@ type UnknownEvent struct {
 
 func (e UnknownEvent) Type() int { return e.EventType }
 
+// EventWindow returns the window an event was reported on, or 0 if the
+// event type isn't tied to one of our windows.
+func EventWindow(e Event) uint32 {
+	switch e := e.(type) {
+	case KeyEvent:
+		return e.Window
+	case ButtonEvent:
+		return e.Window
+	case MotionEvent:
+		return e.Window
+	case ExposeEvent:
+		return e.Window
+	case ConfigureEvent:
+		return e.Window
+	case MapEvent:
+		return e.Window
+	case ClientMessageEvent:
+		return e.Window
+	case SelectionNotifyEvent:
+		return e.Requestor
+	case FocusEvent:
+		return e.Window
+	case PropertyNotifyEvent:
+		return e.Window
+	}
+	return 0
+}
+
 // NextEvent blocks until an event is received, then returns it
 func (c *Connection) NextEvent() (Event, error) {
-	// All X11 events are exactly 32 bytes
-	buf := make([]byte, 32)
-	_, err := io.ReadFull(c.conn, buf)
-	if err != nil {
-		return nil, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.events) == 0 {
+		if c.readErr != nil {
+			return nil, c.readErr
+		}
+		c.eventCond.Wait()
 	}
 
+	e := c.events[0]
+	c.events[0] = nil
+	c.events = c.events[1:]
+	return e, nil
+}
+
+// queueEvent appends an event read by the reader goroutine.
+func (c *Connection) queueEvent(e Event) {
+	c.mu.Lock()
+	c.events = append(c.events, e)
+	c.mu.Unlock()
+	c.eventCond.Signal()
+}
+
+// parseEvent decodes a raw event packet.
+// All core X11 events are exactly 32 bytes.
+func parseEvent(buf []byte) Event {
 	// Event type is in first byte (high bit is "sent by SendEvent")
 	eventType := int(buf[0] & 0x7F)
 
@@ -99,33 +210,36 @@ func (c *Connection) NextEvent() (Event, error) {
 	case EventKeyPress, EventKeyRelease:
 		return KeyEvent{
 			EventType: eventType,
+			Window:    binary.LittleEndian.Uint32(buf[12:16]),
 			Keycode:   buf[1],
 			State:     binary.LittleEndian.Uint16(buf[28:30]),
 			X:         int16(binary.LittleEndian.Uint16(buf[24:26])),
 			Y:         int16(binary.LittleEndian.Uint16(buf[26:28])),
 			RootX:     int16(binary.LittleEndian.Uint16(buf[20:22])),
 			RootY:     int16(binary.LittleEndian.Uint16(buf[22:24])),
-		}, nil
+		}
 
 	case EventButtonPress, EventButtonRelease:
 		return ButtonEvent{
 			EventType: eventType,
+			Window:    binary.LittleEndian.Uint32(buf[12:16]),
 			Button:    buf[1],
 			State:     binary.LittleEndian.Uint16(buf[28:30]),
 			X:         int16(binary.LittleEndian.Uint16(buf[24:26])),
 			Y:         int16(binary.LittleEndian.Uint16(buf[26:28])),
 			RootX:     int16(binary.LittleEndian.Uint16(buf[20:22])),
 			RootY:     int16(binary.LittleEndian.Uint16(buf[22:24])),
-		}, nil
+		}
 
 	case EventMotionNotify:
 		return MotionEvent{
-			X:     int16(binary.LittleEndian.Uint16(buf[24:26])),
-			Y:     int16(binary.LittleEndian.Uint16(buf[26:28])),
-			RootX: int16(binary.LittleEndian.Uint16(buf[20:22])),
-			RootY: int16(binary.LittleEndian.Uint16(buf[22:24])),
-			State: binary.LittleEndian.Uint16(buf[28:30]),
-		}, nil
+			Window: binary.LittleEndian.Uint32(buf[12:16]),
+			X:      int16(binary.LittleEndian.Uint16(buf[24:26])),
+			Y:      int16(binary.LittleEndian.Uint16(buf[26:28])),
+			RootX:  int16(binary.LittleEndian.Uint16(buf[20:22])),
+			RootY:  int16(binary.LittleEndian.Uint16(buf[22:24])),
+			State:  binary.LittleEndian.Uint16(buf[28:30]),
+		}
 
 	case EventExpose:
 		return ExposeEvent{
@@ -135,7 +249,7 @@ func (c *Connection) NextEvent() (Event, error) {
 			Width:  binary.LittleEndian.Uint16(buf[12:14]),
 			Height: binary.LittleEndian.Uint16(buf[14:16]),
 			Count:  binary.LittleEndian.Uint16(buf[16:18]),
-		}, nil
+		}
 
 	case EventConfigureNotify:
 		return ConfigureEvent{
@@ -144,7 +258,29 @@ func (c *Connection) NextEvent() (Event, error) {
 			Y:      int16(binary.LittleEndian.Uint16(buf[18:20])),
 			Width:  binary.LittleEndian.Uint16(buf[20:22]),
 			Height: binary.LittleEndian.Uint16(buf[22:24]),
-		}, nil
+		}
+
+	case EventMapNotify, EventUnmapNotify:
+		return MapEvent{
+			EventType: eventType,
+			Window:    binary.LittleEndian.Uint32(buf[8:12]),
+		}
+
+	case EventFocusIn, EventFocusOut:
+		return FocusEvent{
+			EventType: eventType,
+			Detail:    buf[1],
+			Window:    binary.LittleEndian.Uint32(buf[4:8]),
+			Mode:      buf[8],
+		}
+
+	case EventPropertyNotify:
+		return PropertyNotifyEvent{
+			Window: binary.LittleEndian.Uint32(buf[4:8]),
+			Atom:   Atom(binary.LittleEndian.Uint32(buf[8:12])),
+			Time:   binary.LittleEndian.Uint32(buf[12:16]),
+			State:  buf[16],
+		}
 
 	case EventClientMessage:
 		e := ClientMessageEvent{
@@ -153,11 +289,27 @@ func (c *Connection) NextEvent() (Event, error) {
 			MessageType: binary.LittleEndian.Uint32(buf[8:12]),
 		}
 		copy(e.Data[:], buf[12:32])
-		return e, nil
+		return e
+
+	case EventSelectionNotify:
+		return SelectionNotifyEvent{
+			Time:      binary.LittleEndian.Uint32(buf[4:8]),
+			Requestor: binary.LittleEndian.Uint32(buf[8:12]),
+			Selection: Atom(binary.LittleEndian.Uint32(buf[12:16])),
+			Target:    Atom(binary.LittleEndian.Uint32(buf[16:20])),
+			Property:  Atom(binary.LittleEndian.Uint32(buf[20:24])),
+		}
+
+	case EventMappingNotify:
+		return MappingNotifyEvent{
+			Request:      buf[4],
+			FirstKeycode: buf[5],
+			Count:        buf[6],
+		}
 
 	default:
 		e := UnknownEvent{EventType: eventType}
 		copy(e.Data[:], buf)
-		return e, nil
+		return e
 	}
 }
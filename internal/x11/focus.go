@@ -0,0 +1,68 @@
+package x11
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Special focus windows
+const (
+	FocusNone        = 0 // Keyboard input is discarded
+	FocusPointerRoot = 1 // Keyboard input goes to the window under the pointer
+)
+
+// Where the focus goes when the focus window becomes unviewable
+const (
+	RevertToNone        = 0
+	RevertToPointerRoot = 1
+	RevertToParent      = 2
+)
+
+// InputFocus is the keyboard focus reported by GetInputFocus.
+type InputFocus struct {
+	Window   uint32 // A window, FocusNone or FocusPointerRoot
+	RevertTo uint8
+}
+
+// ParseInputFocusReply decodes a GetInputFocus reply.
+func ParseInputFocusReply(reply []byte) (InputFocus, error) {
+	if len(reply) < 32 {
+		return InputFocus{}, errors.New("short GetInputFocus reply")
+	}
+	return InputFocus{
+		Window:   binary.LittleEndian.Uint32(reply[8:12]),
+		RevertTo: reply[1],
+	}, nil
+}
+
+// GetInputFocus returns the window with the keyboard focus.
+func (c *Connection) GetInputFocus() (InputFocus, error) {
+	req := make([]byte, 4)
+	req[0] = OpGetInputFocus
+	binary.LittleEndian.PutUint16(req[2:], 1)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return InputFocus{}, fmt.Errorf("GetInputFocus failed: %w", err)
+	}
+	focus, err := ParseInputFocusReply(reply)
+	if err != nil {
+		return InputFocus{}, fmt.Errorf("GetInputFocus failed: %w", err)
+	}
+	return focus, nil
+}
+
+// SetInputFocus gives window the keyboard focus, moving it to revertTo
+// if the window later becomes unviewable. The window must be viewable
+// (mapped, with mapped ancestors), or the server ignores the request.
+func (c *Connection) SetInputFocus(window uint32, revertTo uint8) error {
+	req := make([]byte, 12)
+	req[0] = OpSetInputFocus
+	req[1] = revertTo
+	binary.LittleEndian.PutUint16(req[2:], 3)
+	binary.LittleEndian.PutUint32(req[4:], window)
+	binary.LittleEndian.PutUint32(req[8:], 0) // CurrentTime
+
+	return c.send(req)
+}
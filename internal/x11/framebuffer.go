@@ -1,5 +1,7 @@
 package x11
 
+import "math"
+
 // Framebuffer is a software pixel buffer for rendering
 // Pixels are stored in BGRA format (Blue, Green, Red, Alpha)
 // This matches X11's 24-bit depth format on little-endian systems
@@ -7,6 +9,14 @@ type Framebuffer struct {
 	Width  int
 	Height int
 	Pixels []byte // BGRA format, 4 bytes per pixel
+
+	// Clip rectangle, x1 and y1 exclusive (see SetClip)
+	clipped                        bool
+	clipX0, clipY0, clipX1, clipY1 int
+
+	// 255 minus the opacity drawing uses, so the zero value draws
+	// opaque (see SetOpacity)
+	transparency uint8
 }
 
 // NewFramebuffer creates a new framebuffer
@@ -18,33 +28,103 @@ func NewFramebuffer(width, height int) *Framebuffer {
 	}
 }
 
-// Resize reallocates the framebuffer to new dimensions.
+// Resize reallocates the framebuffer to new dimensions, keeping the
+// pixels of the area the old and new sizes share (anchored at the
+// top-left corner). New area is black. Resizing to the current size
+// does nothing.
 func (fb *Framebuffer) Resize(width, height int) {
+	if width == fb.Width && height == fb.Height {
+		return
+	}
+	pixels := make([]byte, width*height*4)
+	rowBytes := min(width, fb.Width) * 4
+	for y := 0; y < min(height, fb.Height); y++ {
+		copy(pixels[y*width*4:y*width*4+rowBytes], fb.Pixels[y*fb.Width*4:])
+	}
 	fb.Width = width
 	fb.Height = height
-	fb.Pixels = make([]byte, width*height*4)
+	fb.Pixels = pixels
+}
+
+// SetClip restricts drawing to the width×height rectangle at (x, y):
+// every drawing method leaves pixels outside it untouched. Parts of the
+// rectangle outside the framebuffer are ignored. Clear and ClearAlpha
+// still fill the whole framebuffer.
+func (fb *Framebuffer) SetClip(x, y, width, height int) {
+	fb.clipped = true
+	fb.clipX0, fb.clipY0 = x, y
+	fb.clipX1, fb.clipY1 = x+max(width, 0), y+max(height, 0)
+}
+
+// ClearClip lets drawing reach the whole framebuffer again.
+func (fb *Framebuffer) ClearClip() {
+	fb.clipped = false
+}
+
+// CopyClip gives fb the same clip rectangle as src, or none if src has
+// none, for double buffers that swap framebuffers under one canvas.
+func (fb *Framebuffer) CopyClip(src *Framebuffer) {
+	fb.clipped = src.clipped
+	fb.clipX0, fb.clipY0, fb.clipX1, fb.clipY1 = src.clipX0, src.clipY0, src.clipX1, src.clipY1
 }
 
-// Clear fills the entire framebuffer with a color
+// HasClip reports whether a clip rectangle is set.
+func (fb *Framebuffer) HasClip() bool { return fb.clipped }
+
+// Clip returns the rectangle drawing is restricted to: the clip set
+// with SetClip within the framebuffer, or the whole framebuffer.
+func (fb *Framebuffer) Clip() (x, y, width, height int) {
+	x0, y0, x1, y1 := fb.bounds()
+	return x0, y0, max(x1-x0, 0), max(y1-y0, 0)
+}
+
+// SetOpacity sets the opacity SetPixel, DrawHLine and BlendPixel, and
+// so every shape drawn with them, paint with: below 255 they blend
+// their color over the pixels there instead of replacing them.
+func (fb *Framebuffer) SetOpacity(a uint8) { fb.transparency = 255 - a }
+
+// bounds returns the area drawing may touch, x1 and y1 exclusive.
+func (fb *Framebuffer) bounds() (x0, y0, x1, y1 int) {
+	if !fb.clipped {
+		return 0, 0, fb.Width, fb.Height
+	}
+	return max(fb.clipX0, 0), max(fb.clipY0, 0), min(fb.clipX1, fb.Width), min(fb.clipY1, fb.Height)
+}
+
+// Clear fills the entire framebuffer with a color, leaving the alpha
+// byte 0
 func (fb *Framebuffer) Clear(r, g, b uint8) {
+	fb.ClearAlpha(r, g, b, 0)
+}
+
+// ClearAlpha fills the entire framebuffer with a color and alpha byte a.
+// Depth 24 visuals ignore the alpha byte; 32-bit ARGB visuals use it as
+// the window's opacity.
+func (fb *Framebuffer) ClearAlpha(r, g, b, a uint8) {
 	for i := 0; i < len(fb.Pixels); i += 4 {
 		fb.Pixels[i] = b   // Blue
 		fb.Pixels[i+1] = g // Green
 		fb.Pixels[i+2] = r // Red
-		fb.Pixels[i+3] = 0 // Alpha (unused)
+		fb.Pixels[i+3] = a // Alpha
 	}
 }
 
-// SetPixel sets a single pixel
+// SetPixel sets a single pixel, opaque on 32-bit ARGB visuals. Below
+// full opacity (see SetOpacity) it blends instead.
 func (fb *Framebuffer) SetPixel(x, y int, r, g, b uint8) {
-	if x < 0 || x >= fb.Width || y < 0 || y >= fb.Height {
+	if fb.transparency != 0 {
+		fb.BlendPixel(x, y, r, g, b, 255)
+		return
+	}
+	x0, y0, x1, y1 := fb.bounds()
+	if x < x0 || x >= x1 || y < y0 || y >= y1 {
 		return // Clipping
 	}
 	offset := (y*fb.Width + x) * 4
 	fb.Pixels[offset] = b
 	fb.Pixels[offset+1] = g
 	fb.Pixels[offset+2] = r
-	fb.Pixels[offset+3] = 0
+	fb.Pixels[offset+3] = 255
 }
 
 // GetPixel returns the color at (x, y)
@@ -136,14 +216,437 @@ func (fb *Framebuffer) DrawCircle(cx, cy, radius int, r, g, b uint8) {
 	}
 }
 
-// FillCircle draws a filled circle
-func (fb *Framebuffer) FillCircle(cx, cy, radius int, r, g, b uint8) {
+// DrawCircleAA draws an antialiased circle outline. Each column of an
+// octant covers the two pixels straddling the exact circle, weighted by
+// how close each is (Wu's algorithm), and blends them over the existing
+// pixels.
+func (fb *Framebuffer) DrawCircleAA(cx, cy, radius int, r, g, b uint8) {
+	if radius <= 0 {
+		fb.SetPixel(cx, cy, r, g, b)
+		return
+	}
+	rr := float64(radius * radius)
+	for x := 0; ; x++ {
+		y := math.Sqrt(rr - float64(x*x))
+		yi := int(y)
+		if x > yi {
+			break
+		}
+		frac := y - float64(yi)
+		fb.blendOctants(cx, cy, x, yi, uint8((1-frac)*255+0.5), r, g, b)
+		if x < yi {
+			// The outer pixel at the diagonal belongs to the next column
+			fb.blendOctants(cx, cy, x, yi+1, uint8(frac*255+0.5), r, g, b)
+		}
+	}
+}
+
+// DrawLineThick draws a line width pixels thick, centered on the line
+// from (x0, y0) to (x1, y1). Each Bresenham step draws a span across
+// the line's minor axis, lengthened for diagonal lines so the thickness
+// measured perpendicular to the line stays close to width. The ends are
+// cut square to the major axis. A width of 1 or less draws the same
+// pixels as DrawLine.
+func (fb *Framebuffer) DrawLineThick(x0, y0, x1, y1, width int, r, g, b uint8) {
+	if width <= 1 {
+		fb.DrawLine(x0, y0, x1, y1, r, g, b)
+		return
+	}
+
+	dx, dy := abs(x1-x0), abs(y1-y0)
+	major := max(dx, dy)
+	span := width
+	if major > 0 {
+		span = int(math.Round(float64(width) * math.Hypot(float64(dx), float64(dy)) / float64(major)))
+	}
+	before := (span - 1) / 2 // pixels on the low side of the line
+
+	xMajor := dx >= dy
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx - dy
+
+	for {
+		if xMajor {
+			for y := y0 - before; y < y0-before+span; y++ {
+				fb.SetPixel(x0, y, r, g, b)
+			}
+		} else {
+			fb.DrawHLine(x0-before, x0-before+span-1, y0, r, g, b)
+		}
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * err
+		if e2 >= -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// DrawLineAA draws an antialiased line with Wu's algorithm: along the
+// major axis each step covers the two pixels straddling the exact line,
+// weighted by how close each is, blended over the existing pixels.
+// Horizontal, vertical and 45° lines come out the same as DrawLine.
+func (fb *Framebuffer) DrawLineAA(x0, y0, x1, y1 int, r, g, b uint8) {
+	steep := abs(y1-y0) > abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	plot := func(x, y int, coverage float64) {
+		a := uint8(coverage*255 + 0.5)
+		if steep {
+			fb.BlendPixel(y, x, r, g, b, a)
+		} else {
+			fb.BlendPixel(x, y, r, g, b, a)
+		}
+	}
+
+	gradient := 0.0
+	if x1 != x0 {
+		gradient = float64(y1-y0) / float64(x1-x0)
+	}
+	y := float64(y0)
+	for x := x0; x <= x1; x++ {
+		yi := math.Floor(y)
+		frac := y - yi
+		plot(x, int(yi), 1-frac)
+		if frac > 0 {
+			plot(x, int(yi)+1, frac)
+		}
+		y += gradient
+	}
+}
+
+// FillCircleAA draws a filled circle whose edge pixels are blended by
+// how much of them lies within radius+0.5 of the centre, so the disc
+// matches FillCircle's in size but has a smooth rim.
+func (fb *Framebuffer) FillCircleAA(cx, cy, radius int, r, g, b uint8) {
+	if radius <= 0 {
+		fb.SetPixel(cx, cy, r, g, b)
+		return
+	}
+	rf := float64(radius)
+	inner, outer := (rf-0.5)*(rf-0.5), (rf+0.5)*(rf+0.5)
 	for y := -radius; y <= radius; y++ {
-		for x := -radius; x <= radius; x++ {
-			if x*x+y*y <= radius*radius {
-				fb.SetPixel(cx+x, cy+y, r, g, b)
+		yy := float64(y * y)
+
+		// Pixels entirely inside the circle form a solid span
+		hw := -1
+		if yy <= inner {
+			hw = int(math.Sqrt(inner - yy))
+			fb.DrawHLine(cx-hw, cx+hw, cy+y, r, g, b)
+		}
+
+		for x := hw + 1; float64(x*x)+yy < outer; x++ {
+			coverage := math.Min(rf+0.5-math.Hypot(float64(x), float64(y)), 1)
+			a := uint8(coverage*255 + 0.5)
+			fb.BlendPixel(cx+x, cy+y, r, g, b, a)
+			if x != 0 {
+				fb.BlendPixel(cx-x, cy+y, r, g, b, a)
+			}
+		}
+	}
+}
+
+// blendOctants blends (x, y) mirrored into all eight octants around
+// (cx, cy), visiting each distinct pixel once.
+func (fb *Framebuffer) blendOctants(cx, cy, x, y int, a, r, g, b uint8) {
+	fb.blendQuadrants(cx, cy, x, y, a, r, g, b)
+	if x != y {
+		fb.blendQuadrants(cx, cy, y, x, a, r, g, b)
+	}
+}
+
+// blendQuadrants blends (±x, ±y) around (cx, cy), skipping the mirrored
+// copy on an axis.
+func (fb *Framebuffer) blendQuadrants(cx, cy, x, y int, a, r, g, b uint8) {
+	fb.BlendPixel(cx+x, cy+y, r, g, b, a)
+	if x != 0 {
+		fb.BlendPixel(cx-x, cy+y, r, g, b, a)
+	}
+	if y != 0 {
+		fb.BlendPixel(cx+x, cy-y, r, g, b, a)
+		if x != 0 {
+			fb.BlendPixel(cx-x, cy-y, r, g, b, a)
+		}
+	}
+}
+
+// BlendPixel blends a color over the pixel at (x, y) with opacity a
+// (0 = leave as is, 255 = replace), scaled by the framebuffer's opacity
+// (see SetOpacity). The alpha byte is composited the same way, so a
+// pixel over a transparent one takes opacity a.
+func (fb *Framebuffer) BlendPixel(x, y int, r, g, b, a uint8) {
+	if fb.transparency != 0 {
+		a = uint8((uint32(a)*uint32(255-fb.transparency) + 127) / 255)
+	}
+	x0, y0, x1, y1 := fb.bounds()
+	if x < x0 || x >= x1 || y < y0 || y >= y1 || a == 0 {
+		return
+	}
+	offset := (y*fb.Width + x) * 4
+	src := [4]uint8{b, g, r, 255}
+	alpha := uint32(a)
+	invA := 255 - alpha
+	for ch := 0; ch < 4; ch++ {
+		v := uint32(src[ch])*alpha + uint32(fb.Pixels[offset+ch])*invA
+		fb.Pixels[offset+ch] = uint8((v + 1 + (v >> 8)) >> 8)
+	}
+}
+
+// AddGlow adds a color to the pixels within radius of (cx, cy), scaled
+// at each pixel by falloff[dx²+dy²]/255 and saturating at 255, so
+// overlapping glows brighten. The falloff is added to the alpha byte
+// too, so a glow over a transparent pixel shows. falloff must have
+// radius² entries.
+func (fb *Framebuffer) AddGlow(cx, cy, radius int, falloff []uint8, r, g, b uint8) {
+	if radius <= 0 {
+		return
+	}
+	bx0, by0, bx1, by1 := fb.bounds()
+	x0, x1 := max(-radius, bx0-cx), min(radius, bx1-1-cx)
+	y0, y1 := max(-radius, by0-cy), min(radius, by1-1-cy)
+	rr := radius * radius
+	for y := y0; y <= y1; y++ {
+		row := ((cy+y)*fb.Width + cx) * 4
+		for x := x0; x <= x1; x++ {
+			d := x*x + y*y
+			if d >= rr || falloff[d] == 0 {
+				continue
 			}
+			a := uint32(falloff[d])
+			p := fb.Pixels[row+x*4 : row+x*4+4 : row+x*4+4]
+			p[0] = addSat(p[0], (uint32(b)*a+127)/255)
+			p[1] = addSat(p[1], (uint32(g)*a+127)/255)
+			p[2] = addSat(p[2], (uint32(r)*a+127)/255)
+			p[3] = addSat(p[3], a)
+		}
+	}
+}
+
+// addSat returns v+d, saturating at 255.
+func addSat(v uint8, d uint32) uint8 {
+	if s := uint32(v) + d; s < 255 {
+		return uint8(s)
+	}
+	return 255
+}
+
+// FillCircle draws a filled circle one horizontal span per row.
+// It sets exactly the pixels with x²+y² <= radius².
+func (fb *Framebuffer) FillCircle(cx, cy, radius int, r, g, b uint8) {
+	if radius < 0 {
+		return
+	}
+	rr := radius * radius
+	for y := -radius; y <= radius; y++ {
+		// Largest half-width with x²+y² <= r², corrected for float error
+		hw := int(math.Sqrt(float64(rr - y*y)))
+		for (hw+1)*(hw+1)+y*y <= rr {
+			hw++
+		}
+		for hw > 0 && hw*hw+y*y > rr {
+			hw--
+		}
+		fb.DrawHLine(cx-hw, cx+hw, cy+y, r, g, b)
+	}
+}
+
+// DrawEllipse draws an ellipse outline with radii rx and ry using the
+// midpoint algorithm. A zero radius draws a line; both zero, a point.
+func (fb *Framebuffer) DrawEllipse(cx, cy, rx, ry int, r, g, b uint8) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		fb.FillEllipse(cx, cy, rx, ry, r, g, b)
+		return
+	}
+	ellipseQuadrant(rx, ry, func(x, y int) {
+		fb.SetPixel(cx+x, cy+y, r, g, b)
+		fb.SetPixel(cx-x, cy+y, r, g, b)
+		fb.SetPixel(cx-x, cy-y, r, g, b)
+		fb.SetPixel(cx+x, cy-y, r, g, b)
+	})
+}
+
+// FillEllipse draws a filled ellipse, one horizontal span per row
+// reaching DrawEllipse's outline. A zero radius draws a line; both
+// zero, a point.
+func (fb *Framebuffer) FillEllipse(cx, cy, rx, ry int, r, g, b uint8) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	if ry == 0 {
+		fb.DrawHLine(cx-rx, cx+rx, cy, r, g, b)
+		return
+	}
+	if rx == 0 {
+		for y := cy - ry; y <= cy+ry; y++ {
+			fb.SetPixel(cx, y, r, g, b)
 		}
+		return
+	}
+
+	// The outline visits each row with x growing, so the last x seen
+	// is the row's half-width
+	hw := make([]int, ry+1)
+	ellipseQuadrant(rx, ry, func(x, y int) { hw[y] = x })
+	fb.DrawHLine(cx-hw[0], cx+hw[0], cy, r, g, b)
+	for y := 1; y <= ry; y++ {
+		fb.DrawHLine(cx-hw[y], cx+hw[y], cy-y, r, g, b)
+		fb.DrawHLine(cx-hw[y], cx+hw[y], cy+y, r, g, b)
+	}
+}
+
+// ellipseQuadrant walks the outline of an ellipse centred on the origin
+// from (0, ry) to (rx, 0) with the midpoint algorithm, calling plot for
+// each pixel. Decision variables are kept at four times their usual
+// value so they stay integers.
+func ellipseQuadrant(rx, ry int, plot func(x, y int)) {
+	rx2, ry2 := int64(rx)*int64(rx), int64(ry)*int64(ry)
+	x, y := 0, ry
+	dx, dy := int64(0), 2*rx2*int64(y)
+
+	// Region 1: the slope is shallower than -1, so x steps every time
+	p := 4*ry2 - 4*rx2*int64(ry) + rx2
+	for dx < dy {
+		plot(x, y)
+		x++
+		dx += 2 * ry2
+		if p < 0 {
+			p += 4 * (dx + ry2)
+		} else {
+			y--
+			dy -= 2 * rx2
+			p += 4 * (dx - dy + ry2)
+		}
+	}
+
+	// Region 2: y steps every time
+	fx := int64(2*x + 1)
+	fy := int64(y - 1)
+	p = ry2*fx*fx + 4*rx2*fy*fy - 4*rx2*ry2
+	for y >= 0 {
+		plot(x, y)
+		y--
+		dy -= 2 * rx2
+		if p > 0 {
+			p += 4 * (rx2 - dy)
+		} else {
+			x++
+			dx += 2 * ry2
+			p += 4 * (dx - dy + rx2)
+		}
+	}
+}
+
+// CopyRegion copies the width×height rectangle at (srcX, srcY) to
+// (dstX, dstY). The rectangles may overlap: rows are copied in the
+// order that reads each source row before it is overwritten. Parts of
+// either rectangle outside the framebuffer are skipped.
+func (fb *Framebuffer) CopyRegion(srcX, srcY, width, height, dstX, dstY int) {
+	// Clip the source, then the destination, moving both together
+	if srcX < 0 {
+		width += srcX
+		dstX -= srcX
+		srcX = 0
+	}
+	if srcY < 0 {
+		height += srcY
+		dstY -= srcY
+		srcY = 0
+	}
+	if dstX < 0 {
+		width += dstX
+		srcX -= dstX
+		dstX = 0
+	}
+	if dstY < 0 {
+		height += dstY
+		srcY -= dstY
+		dstY = 0
+	}
+	width = min(width, fb.Width-max(srcX, dstX))
+	height = min(height, fb.Height-max(srcY, dstY))
+
+	// Only the destination is held to the clip rectangle
+	cx0, cy0, cx1, cy1 := fb.bounds()
+	if d := cx0 - dstX; d > 0 {
+		width -= d
+		srcX += d
+		dstX = cx0
+	}
+	if d := cy0 - dstY; d > 0 {
+		height -= d
+		srcY += d
+		dstY = cy0
+	}
+	width = min(width, cx1-dstX)
+	height = min(height, cy1-dstY)
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	stride := fb.Width * 4
+	n := width * 4
+	copyRow := func(row int) {
+		src := (srcY+row)*stride + srcX*4
+		dst := (dstY+row)*stride + dstX*4
+		copy(fb.Pixels[dst:dst+n], fb.Pixels[src:src+n]) // copy handles overlap within a row
+	}
+	if dstY > srcY {
+		for row := height - 1; row >= 0; row-- {
+			copyRow(row)
+		}
+	} else {
+		for row := 0; row < height; row++ {
+			copyRow(row)
+		}
+	}
+}
+
+// DrawHLine fills the horizontal span x0..x1 (inclusive) on row y with
+// opaque pixels, or blends it below full opacity (see SetOpacity). The
+// span is clipped once up front, then filled without bounds checks.
+func (fb *Framebuffer) DrawHLine(x0, x1, y int, r, g, b uint8) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	cx0, cy0, cx1, cy1 := fb.bounds()
+	if y < cy0 || y >= cy1 || x1 < cx0 || x0 >= cx1 {
+		return
+	}
+	x0 = max(x0, cx0)
+	x1 = min(x1, cx1-1)
+
+	row := fb.Pixels[(y*fb.Width+x0)*4 : (y*fb.Width+x1+1)*4]
+	if fb.transparency != 0 {
+		src := [4]byte{b, g, r, 255 - fb.transparency}
+		for i := 0; i < len(row); i += 4 {
+			blendBGRA(row[i:i+4], src[:])
+		}
+		return
+	}
+	for i := 0; i < len(row); i += 4 {
+		row[i] = b
+		row[i+1] = g
+		row[i+2] = r
+		row[i+3] = 255
 	}
 }
 
@@ -154,6 +657,60 @@ func (fb *Framebuffer) DrawTriangle(x0, y0, x1, y1, x2, y2 int, r, g, b uint8) {
 	fb.DrawLine(x2, y2, x0, y0, r, g, b)
 }
 
+// FillTriangle draws a solid triangle. A pixel is filled when its
+// top-left corner, the integer coordinate, lies inside the triangle;
+// pixels exactly on an edge follow the top-left rule (filled for top
+// and left edges only), so triangles sharing an edge, such as the two
+// halves of a quad, leave no gap and overlap nowhere. Collinear
+// vertices draw nothing.
+func (fb *Framebuffer) FillTriangle(x0, y0, x1, y1, x2, y2 int, r, g, b uint8) {
+	area := edgeFunc(x0, y0, x1, y1, x2, y2)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		// Use one winding so "inside" is always positive
+		x1, y1, x2, y2 = x2, y2, x1, y1
+	}
+
+	cx0, cy0, cx1, cy1 := fb.bounds()
+	minX := max(min(x0, min(x1, x2)), cx0)
+	maxX := min(max(x0, max(x1, x2)), cx1-1)
+	minY := max(min(y0, min(y1, y2)), cy0)
+	maxY := min(max(y0, max(y1, y2)), cy1-1)
+
+	// Points on an edge count only for top and left edges
+	bias0 := topLeftBias(x1, y1, x2, y2)
+	bias1 := topLeftBias(x2, y2, x0, y0)
+	bias2 := topLeftBias(x0, y0, x1, y1)
+
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if edgeFunc(x1, y1, x2, y2, x, y)+bias0 >= 0 &&
+				edgeFunc(x2, y2, x0, y0, x, y)+bias1 >= 0 &&
+				edgeFunc(x0, y0, x1, y1, x, y)+bias2 >= 0 {
+				fb.SetPixel(x, y, r, g, b)
+			}
+		}
+	}
+}
+
+// edgeFunc returns twice the signed area of the triangle (a, b, p):
+// positive when p is on the inner side of edge a→b.
+func edgeFunc(ax, ay, bx, by, px, py int) int {
+	return (bx-ax)*(py-ay) - (by-ay)*(px-ax)
+}
+
+// topLeftBias is 0 for a top or left edge, whose points are inside the
+// triangle, and -1 for others, whose points are not.
+func topLeftBias(ax, ay, bx, by int) int {
+	dx, dy := bx-ax, by-ay
+	if (dy == 0 && dx > 0) || dy < 0 {
+		return 0
+	}
+	return -1
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
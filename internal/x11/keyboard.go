@@ -0,0 +1,122 @@
+package x11
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// NoSymbol is the keysym of an unused slot in a keyboard mapping
+const NoSymbol = 0
+
+// KeysymISOLevel3Shift is the keysym of AltGr on most layouts
+const KeysymISOLevel3Shift = 0xfe03
+
+// KeyboardMapping is the server's table of keysyms for each keycode.
+// Each keycode has KeysymsPerKeycode slots: unshifted and shifted in
+// group 1, then the same for group 2 (Mode_switch). Servers with XKB put
+// group 1's levels 3 and 4 (AltGr and Shift+AltGr) in slots 4 and 5.
+type KeyboardMapping struct {
+	FirstKeycode      uint8
+	KeysymsPerKeycode int
+	Keysyms           []uint32
+}
+
+// Keysym returns the keysym in slot col for keycode, or NoSymbol if the
+// keycode or slot is outside the mapping.
+func (m *KeyboardMapping) Keysym(keycode uint8, col int) uint32 {
+	if keycode < m.FirstKeycode || col < 0 || col >= m.KeysymsPerKeycode {
+		return NoSymbol
+	}
+	i := int(keycode-m.FirstKeycode)*m.KeysymsPerKeycode + col
+	if i >= len(m.Keysyms) {
+		return NoSymbol
+	}
+	return m.Keysyms[i]
+}
+
+// ParseKeyboardMapping decodes a GetKeyboardMapping reply for keycodes
+// starting at first.
+func ParseKeyboardMapping(first uint8, reply []byte) (*KeyboardMapping, error) {
+	if len(reply) < 32 {
+		return nil, errors.New("short GetKeyboardMapping reply")
+	}
+	perKeycode := int(reply[1])
+	n := int(binary.LittleEndian.Uint32(reply[4:8]))
+	if perKeycode == 0 || len(reply) < 32+n*4 {
+		return nil, fmt.Errorf("bad GetKeyboardMapping reply (%d keysyms per keycode, %d words)", perKeycode, n)
+	}
+
+	m := &KeyboardMapping{
+		FirstKeycode:      first,
+		KeysymsPerKeycode: perKeycode,
+		Keysyms:           make([]uint32, n),
+	}
+	for i := range m.Keysyms {
+		m.Keysyms[i] = binary.LittleEndian.Uint32(reply[32+i*4:])
+	}
+	return m, nil
+}
+
+// GetKeyboardMapping fetches the keysyms of every keycode the server
+// sends, MinKeycode to MaxKeycode.
+func (c *Connection) GetKeyboardMapping() (*KeyboardMapping, error) {
+	if c.MaxKeycode < c.MinKeycode {
+		return nil, errors.New("GetKeyboardMapping failed: no keycode range")
+	}
+
+	req := make([]byte, 8)
+	req[0] = OpGetKeyboardMapping
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	req[4] = c.MinKeycode
+	req[5] = c.MaxKeycode - c.MinKeycode + 1
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("GetKeyboardMapping failed: %w", err)
+	}
+	m, err := ParseKeyboardMapping(c.MinKeycode, reply)
+	if err != nil {
+		return nil, fmt.Errorf("GetKeyboardMapping failed: %w", err)
+	}
+	return m, nil
+}
+
+// ModifierMapping lists the keycodes bound to each of the eight
+// modifiers, in state bit order: Shift, Lock, Control, Mod1 to Mod5.
+type ModifierMapping [8][]uint8
+
+// ParseModifierMapping decodes a GetModifierMapping reply. Unused
+// keycode slots are left out.
+func ParseModifierMapping(reply []byte) (ModifierMapping, error) {
+	var m ModifierMapping
+	if len(reply) < 32 || len(reply) < 32+8*int(reply[1]) {
+		return m, errors.New("short GetModifierMapping reply")
+	}
+	perModifier := int(reply[1])
+	for mod := range m {
+		for _, code := range reply[32+mod*perModifier : 32+(mod+1)*perModifier] {
+			if code != 0 {
+				m[mod] = append(m[mod], code)
+			}
+		}
+	}
+	return m, nil
+}
+
+// GetModifierMapping fetches which keycodes act as which modifiers.
+func (c *Connection) GetModifierMapping() (ModifierMapping, error) {
+	req := make([]byte, 4)
+	req[0] = OpGetModifierMapping
+	binary.LittleEndian.PutUint16(req[2:], 1)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return ModifierMapping{}, fmt.Errorf("GetModifierMapping failed: %w", err)
+	}
+	m, err := ParseModifierMapping(reply)
+	if err != nil {
+		return ModifierMapping{}, fmt.Errorf("GetModifierMapping failed: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,59 @@
+package x11
+
+import (
+	"encoding/binary"
+)
+
+// CreatePixmap creates an off-screen image of the given depth on the
+// same screen as drawable.
+func (c *Connection) CreatePixmap(drawable uint32, width, height uint16, depth uint8) (uint32, error) {
+	pixmapID := c.GenerateID()
+
+	req := make([]byte, 16)
+	req[0] = OpCreatePixmap
+	req[1] = depth
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], pixmapID)
+	binary.LittleEndian.PutUint32(req[8:], drawable)
+	binary.LittleEndian.PutUint16(req[12:], width)
+	binary.LittleEndian.PutUint16(req[14:], height)
+
+	if err := c.send(req); err != nil {
+		return 0, err
+	}
+
+	return pixmapID, nil
+}
+
+// FreePixmap frees a pixmap
+func (c *Connection) FreePixmap(pixmapID uint32) error {
+	req := make([]byte, 8)
+	req[0] = OpFreePixmap
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], pixmapID)
+
+	return c.send(req)
+}
+
+// CopyArea copies a width×height area from (srcX, srcY) in src to
+// (dstX, dstY) in dst. Both drawables must have the same depth.
+func (c *Connection) CopyArea(src, dst, gc uint32, srcX, srcY, dstX, dstY int16,
+	width, height uint16) error {
+
+	req := make([]byte, 28)
+	req[0] = OpCopyArea
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 7)
+	binary.LittleEndian.PutUint32(req[4:], src)
+	binary.LittleEndian.PutUint32(req[8:], dst)
+	binary.LittleEndian.PutUint32(req[12:], gc)
+	binary.LittleEndian.PutUint16(req[16:], uint16(srcX))
+	binary.LittleEndian.PutUint16(req[18:], uint16(srcY))
+	binary.LittleEndian.PutUint16(req[20:], uint16(dstX))
+	binary.LittleEndian.PutUint16(req[22:], uint16(dstY))
+	binary.LittleEndian.PutUint16(req[24:], width)
+	binary.LittleEndian.PutUint16(req[26:], height)
+
+	return c.send(req)
+}
@@ -0,0 +1,53 @@
+package x11
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// PointerState is the pointer position and button state reported by
+// QueryPointer.
+type PointerState struct {
+	Root, Child  uint32 // Child is the child window under the pointer, or 0
+	RootX, RootY int16
+	X, Y         int16  // Relative to the queried window
+	State        uint16 // Buttons and modifiers, as in input events
+	SameScreen   bool   // False if the pointer is on another screen; X, Y are then 0
+}
+
+// ParsePointerReply decodes a QueryPointer reply.
+func ParsePointerReply(reply []byte) (PointerState, error) {
+	if len(reply) < 32 {
+		return PointerState{}, errors.New("short QueryPointer reply")
+	}
+	return PointerState{
+		SameScreen: reply[1] != 0,
+		Root:       binary.LittleEndian.Uint32(reply[8:12]),
+		Child:      binary.LittleEndian.Uint32(reply[12:16]),
+		RootX:      int16(binary.LittleEndian.Uint16(reply[16:18])),
+		RootY:      int16(binary.LittleEndian.Uint16(reply[18:20])),
+		X:          int16(binary.LittleEndian.Uint16(reply[20:22])),
+		Y:          int16(binary.LittleEndian.Uint16(reply[22:24])),
+		State:      binary.LittleEndian.Uint16(reply[24:26]),
+	}, nil
+}
+
+// QueryPointer asks where the pointer is relative to window, and which
+// buttons and modifiers are held.
+func (c *Connection) QueryPointer(window uint32) (PointerState, error) {
+	req := make([]byte, 8)
+	req[0] = OpQueryPointer
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], window)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return PointerState{}, fmt.Errorf("QueryPointer failed: %w", err)
+	}
+	p, err := ParsePointerReply(reply)
+	if err != nil {
+		return PointerState{}, fmt.Errorf("QueryPointer failed: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,64 @@
+package x11
+
+import (
+	"image"
+	"slices"
+)
+
+// FillPolygon fills the polygon with the given vertices, closed back to
+// the first, using the even-odd rule: a pixel is filled when a ray from
+// it crosses the outline an odd number of times, so concave and
+// self-intersecting polygons fill sensibly. As in FillTriangle, a pixel
+// is sampled at its top-left corner and pixels exactly on a right or
+// bottom edge are left out, so polygons sharing an edge don't overlap.
+// Two points draw a line; fewer draw nothing.
+func (fb *Framebuffer) FillPolygon(points []image.Point, r, g, b uint8) {
+	switch len(points) {
+	case 0, 1:
+		return
+	case 2:
+		fb.DrawLine(points[0].X, points[0].Y, points[1].X, points[1].Y, r, g, b)
+		return
+	}
+
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points[1:] {
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	_, cy0, _, cy1 := fb.bounds()
+	minY, maxY = max(minY, cy0), min(maxY, cy1-1)
+
+	var xs []int
+	for y := minY; y <= maxY; y++ {
+		// Where each edge crosses the row, rounded up to the first
+		// pixel at or right of the crossing. Edges are half-open in y
+		// so a vertex shared by two edges counts once.
+		xs = xs[:0]
+		for i, p0 := range points {
+			p1 := points[(i+1)%len(points)]
+			if p0.Y > p1.Y {
+				p0, p1 = p1, p0
+			}
+			if y < p0.Y || y >= p1.Y {
+				continue
+			}
+			dy := p1.Y - p0.Y
+			xs = append(xs, ceilDiv(p0.X*dy+(y-p0.Y)*(p1.X-p0.X), dy))
+		}
+		slices.Sort(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			if xs[i] < xs[i+1] {
+				fb.DrawHLine(xs[i], xs[i+1]-1, y, r, g, b)
+			}
+		}
+	}
+}
+
+// ceilDiv returns a/b rounded up, for b > 0.
+func ceilDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a > 0 {
+		q++
+	}
+	return q
+}
@@ -9,15 +9,37 @@ const (
 	OpMapWindow              = 8
 	OpUnmapWindow            = 10
 	OpConfigureWindow        = 12
+	OpGetGeometry            = 14
 	OpSendEvent              = 25
 	OpInternAtom             = 16
 	OpChangeProperty         = 18
 	OpDeleteProperty         = 19
 	OpGetProperty            = 20
+	OpGetSelectionOwner      = 23
+	OpConvertSelection       = 24
+	OpQueryPointer           = 38
+	OpWarpPointer            = 41
+	OpSetInputFocus          = 42
+	OpGetInputFocus          = 43
+	OpTranslateCoordinates   = 40
+	OpOpenFont               = 45
+	OpCloseFont              = 46
+	OpCreatePixmap           = 53
+	OpFreePixmap             = 54
 	OpCreateGC               = 55
 	OpFreeGC                 = 60
+	OpCopyArea               = 62
+	OpPolySegment            = 66
 	OpPolyFillRect           = 70
 	OpPutImage               = 72
+	OpCreateColormap         = 78
+	OpFreeColormap           = 79
+	OpCreateCursor           = 93
+	OpCreateGlyphCursor      = 94
+	OpFreeCursor             = 95
+	OpQueryExtension         = 98
+	OpGetKeyboardMapping     = 101
+	OpGetModifierMapping     = 119
 )
 
 // Window classes
@@ -60,6 +82,7 @@ const (
 	SubstructureNotifyMask   = 1 << 19
 	SubstructureRedirectMask = 1 << 20
 	FocusChangeMask          = 1 << 21
+	PropertyChangeMask       = 1 << 22
 )
 
 // Event types - the type field in event packets
@@ -78,7 +101,43 @@ const (
 	EventUnmapNotify     = 18
 	EventMapNotify       = 19
 	EventConfigureNotify = 22
+	EventPropertyNotify  = 28
+	EventSelectionNotify = 31
 	EventClientMessage   = 33
+	EventMappingNotify   = 34
+	EventGeneric         = 35
+)
+
+// Focus event modes and details (FocusIn/FocusOut)
+const (
+	NotifyNormal = 0
+	NotifyGrab   = 1
+	NotifyUngrab = 2
+
+	NotifyPointer = 5
+)
+
+// PropertyNotify states
+const (
+	PropertyNewValue = 0
+	PropertyDelete   = 1
+)
+
+// Key/button state masks - the State field of input events
+const (
+	ShiftMask   = 1 << 0
+	LockMask    = 1 << 1
+	ControlMask = 1 << 2
+	Mod1Mask    = 1 << 3
+	Mod2Mask    = 1 << 4
+	Mod3Mask    = 1 << 5
+	Mod4Mask    = 1 << 6
+	Mod5Mask    = 1 << 7
+	Button1Mask = 1 << 8
+	Button2Mask = 1 << 9
+	Button3Mask = 1 << 10
+	Button4Mask = 1 << 11
+	Button5Mask = 1 << 12
 )
 
 // Image formats for PutImage
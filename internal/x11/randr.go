@@ -0,0 +1,213 @@
+package x11
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// RANDR minor opcodes
+const (
+	randrQueryVersion              = 0
+	randrGetScreenResources        = 8
+	randrGetCrtcInfo               = 20
+	randrGetScreenResourcesCurrent = 25
+)
+
+// Mode flags
+const (
+	ModeInterlace  = 1 << 4
+	ModeDoubleScan = 1 << 5
+)
+
+// ErrNoRandR is returned when the server lacks RANDR 1.2 or later.
+var ErrNoRandR = errors.New("x11: RANDR 1.2 extension not available")
+
+// ModeInfo is a display mode known to the server
+type ModeInfo struct {
+	ID            uint32
+	Width, Height uint16
+	DotClock      uint32
+	HTotal        uint16
+	VTotal        uint16
+	Flags         uint32
+}
+
+// Refresh returns the mode's vertical refresh rate in Hz, or 0 if the
+// timings are unknown.
+func (m ModeInfo) Refresh() float64 {
+	vtotal := float64(m.VTotal)
+	if m.Flags&ModeDoubleScan != 0 {
+		vtotal *= 2
+	}
+	if m.Flags&ModeInterlace != 0 {
+		vtotal /= 2
+	}
+	if m.HTotal == 0 || vtotal == 0 {
+		return 0
+	}
+	return float64(m.DotClock) / (float64(m.HTotal) * vtotal)
+}
+
+// ScreenResources lists the CRTCs and modes of a screen
+type ScreenResources struct {
+	ConfigTimestamp uint32
+	Crtcs           []uint32
+	Outputs         []uint32
+	Modes           []ModeInfo
+}
+
+// CrtcInfo describes what a CRTC scans out. Mode is 0 if it is off.
+type CrtcInfo struct {
+	X, Y          int16
+	Width, Height uint16
+	Mode          uint32
+	Outputs       []uint32
+}
+
+// QueryExtension asks whether the server supports the named extension
+// and returns its major opcode.
+func (c *Connection) QueryExtension(name string) (present bool, opcode uint8, err error) {
+	n := len(name)
+	pad := (4 - n%4) % 4
+	req := make([]byte, 8+n+pad)
+	req[0] = OpQueryExtension
+	binary.LittleEndian.PutUint16(req[2:], uint16(len(req)/4))
+	binary.LittleEndian.PutUint16(req[4:], uint16(n))
+	copy(req[8:], name)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return false, 0, fmt.Errorf("QueryExtension failed: %w", err)
+	}
+	return reply[8] != 0, reply[9], nil
+}
+
+// randr returns the RANDR major opcode, checking once per connection
+// that the server supports version 1.2 or later.
+func (c *Connection) randr() (uint8, error) {
+	c.randrOnce.Do(func() {
+		present, opcode, err := c.QueryExtension("RANDR")
+		if err != nil {
+			c.randrErr = err
+			return
+		}
+		if !present {
+			c.randrErr = ErrNoRandR
+			return
+		}
+
+		req := make([]byte, 12)
+		req[0] = opcode
+		req[1] = randrQueryVersion
+		binary.LittleEndian.PutUint16(req[2:], 3)
+		binary.LittleEndian.PutUint32(req[4:], 1)
+		binary.LittleEndian.PutUint32(req[8:], 5)
+		reply, err := c.roundTrip(req)
+		if err != nil {
+			c.randrErr = fmt.Errorf("RRQueryVersion failed: %w", err)
+			return
+		}
+		major := binary.LittleEndian.Uint32(reply[8:12])
+		minor := binary.LittleEndian.Uint32(reply[12:16])
+		if major < 1 || (major == 1 && minor < 2) {
+			c.randrErr = ErrNoRandR
+			return
+		}
+		c.randrOpcode = opcode
+		c.randrMinor = minor
+	})
+	return c.randrOpcode, c.randrErr
+}
+
+// GetScreenResources returns the root window's CRTCs, outputs and modes.
+// It uses the cheaper GetScreenResourcesCurrent where available, which
+// reports the server's current knowledge without probing the hardware.
+func (c *Connection) GetScreenResources() (*ScreenResources, error) {
+	opcode, err := c.randr()
+	if err != nil {
+		return nil, err
+	}
+
+	req := make([]byte, 8)
+	req[0] = opcode
+	req[1] = randrGetScreenResources
+	if c.randrMinor >= 3 {
+		req[1] = randrGetScreenResourcesCurrent
+	}
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], c.RootWindow)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("RRGetScreenResources failed: %w", err)
+	}
+
+	numCrtcs := int(binary.LittleEndian.Uint16(reply[16:18]))
+	numOutputs := int(binary.LittleEndian.Uint16(reply[18:20]))
+	numModes := int(binary.LittleEndian.Uint16(reply[20:22]))
+	if len(reply) < 32+(numCrtcs+numOutputs)*4+numModes*32 {
+		return nil, errors.New("RRGetScreenResources: short reply")
+	}
+
+	res := &ScreenResources{
+		ConfigTimestamp: binary.LittleEndian.Uint32(reply[12:16]),
+	}
+	off := 32
+	for i := 0; i < numCrtcs; i++ {
+		res.Crtcs = append(res.Crtcs, binary.LittleEndian.Uint32(reply[off:]))
+		off += 4
+	}
+	for i := 0; i < numOutputs; i++ {
+		res.Outputs = append(res.Outputs, binary.LittleEndian.Uint32(reply[off:]))
+		off += 4
+	}
+	for i := 0; i < numModes; i++ {
+		m := reply[off : off+32]
+		res.Modes = append(res.Modes, ModeInfo{
+			ID:       binary.LittleEndian.Uint32(m[0:4]),
+			Width:    binary.LittleEndian.Uint16(m[4:6]),
+			Height:   binary.LittleEndian.Uint16(m[6:8]),
+			DotClock: binary.LittleEndian.Uint32(m[8:12]),
+			HTotal:   binary.LittleEndian.Uint16(m[16:18]),
+			VTotal:   binary.LittleEndian.Uint16(m[24:26]),
+			Flags:    binary.LittleEndian.Uint32(m[28:32]),
+		})
+		off += 32
+	}
+	return res, nil
+}
+
+// GetCrtcInfo returns the position, size and mode of a CRTC.
+// configTimestamp comes from GetScreenResources.
+func (c *Connection) GetCrtcInfo(crtc, configTimestamp uint32) (*CrtcInfo, error) {
+	opcode, err := c.randr()
+	if err != nil {
+		return nil, err
+	}
+
+	req := make([]byte, 12)
+	req[0] = opcode
+	req[1] = randrGetCrtcInfo
+	binary.LittleEndian.PutUint16(req[2:], 3)
+	binary.LittleEndian.PutUint32(req[4:], crtc)
+	binary.LittleEndian.PutUint32(req[8:], configTimestamp)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("RRGetCrtcInfo failed: %w", err)
+	}
+
+	info := &CrtcInfo{
+		X:      int16(binary.LittleEndian.Uint16(reply[12:14])),
+		Y:      int16(binary.LittleEndian.Uint16(reply[14:16])),
+		Width:  binary.LittleEndian.Uint16(reply[16:18]),
+		Height: binary.LittleEndian.Uint16(reply[18:20]),
+		Mode:   binary.LittleEndian.Uint32(reply[20:24]),
+	}
+	numOutputs := int(binary.LittleEndian.Uint16(reply[28:30]))
+	for i := 0; i < numOutputs && 32+i*4+4 <= len(reply); i++ {
+		info.Outputs = append(info.Outputs, binary.LittleEndian.Uint32(reply[32+i*4:]))
+	}
+	return info, nil
+}
@@ -0,0 +1,77 @@
+package x11
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GetSelectionOwner returns the window owning selection, or 0 if it has
+// no owner.
+func (c *Connection) GetSelectionOwner(selection Atom) (uint32, error) {
+	req := make([]byte, 8)
+	req[0] = OpGetSelectionOwner
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], uint32(selection))
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return 0, fmt.Errorf("GetSelectionOwner failed: %w", err)
+	}
+	return binary.LittleEndian.Uint32(reply[8:12]), nil
+}
+
+// HasCompositor reports whether a compositing manager is running on the
+// connection's screen, i.e. whether anyone owns the _NET_WM_CM_S0
+// selection (EWMH "Compositing Managers"). Errors count as no compositor.
+func (c *Connection) HasCompositor() bool {
+	atom, err := c.InternAtom("_NET_WM_CM_S0", false)
+	if err != nil {
+		return false
+	}
+	owner, err := c.GetSelectionOwner(atom)
+	return err == nil && owner != 0
+}
+
+// ConvertSelection asks the owner of selection to convert it to target
+// and store the result in property on the requestor window. The owner
+// replies with a SelectionNotify event.
+func (c *Connection) ConvertSelection(requestor uint32, selection, target,
+	property Atom, time uint32) error {
+
+	req := make([]byte, 24)
+	req[0] = OpConvertSelection
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 6)
+	binary.LittleEndian.PutUint32(req[4:], requestor)
+	binary.LittleEndian.PutUint32(req[8:], uint32(selection))
+	binary.LittleEndian.PutUint32(req[12:], uint32(target))
+	binary.LittleEndian.PutUint32(req[16:], uint32(property))
+	binary.LittleEndian.PutUint32(req[20:], time)
+
+	return c.send(req)
+}
+
+// TranslateCoordinates converts (x, y) relative to srcWindow into
+// coordinates relative to dstWindow.
+func (c *Connection) TranslateCoordinates(srcWindow, dstWindow uint32,
+	x, y int16) (int16, int16, error) {
+
+	req := make([]byte, 16)
+	req[0] = OpTranslateCoordinates
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], srcWindow)
+	binary.LittleEndian.PutUint32(req[8:], dstWindow)
+	binary.LittleEndian.PutUint16(req[12:], uint16(x))
+	binary.LittleEndian.PutUint16(req[14:], uint16(y))
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("TranslateCoordinates failed: %w", err)
+	}
+
+	dstX := int16(binary.LittleEndian.Uint16(reply[16:18]))
+	dstY := int16(binary.LittleEndian.Uint16(reply[18:20]))
+	return dstX, dstY, nil
+}
@@ -0,0 +1,184 @@
+package x11
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// MIT-SHM minor opcodes
+const (
+	shmQueryVersion = 0
+	shmAttach       = 1
+	shmDetach       = 2
+	shmPutImage     = 3
+	shmGetImage     = 4
+)
+
+// ErrNoShm is returned when the server lacks the MIT-SHM extension.
+var ErrNoShm = errors.New("x11: MIT-SHM extension not available")
+
+// ShmSegment is a System V shared memory segment attached to the
+// server, so images written to Data can be drawn with ShmPutImage
+// without sending the pixels over the socket.
+type ShmSegment struct {
+	ID   uint32 // Server-side ShmSeg
+	Data []byte
+
+	shmid int
+}
+
+// shm returns the MIT-SHM major opcode, checking once per connection
+// that the server supports it.
+func (c *Connection) shm() (uint8, error) {
+	c.shmOnce.Do(func() {
+		present, opcode, err := c.QueryExtension("MIT-SHM")
+		if err != nil {
+			c.shmErr = err
+			return
+		}
+		if !present {
+			c.shmErr = ErrNoShm
+			return
+		}
+
+		req := make([]byte, 4)
+		req[0] = opcode
+		req[1] = shmQueryVersion
+		binary.LittleEndian.PutUint16(req[2:], 1)
+		if _, err := c.roundTrip(req); err != nil {
+			c.shmErr = fmt.Errorf("ShmQueryVersion failed: %w", err)
+			return
+		}
+		c.shmOpcode = opcode
+	})
+	return c.shmOpcode, c.shmErr
+}
+
+// NewShmSegment creates a shared memory segment of size bytes and
+// attaches it to the server. It fails if the server lacks MIT-SHM or
+// can't reach the segment, as when it runs on another machine or in
+// another IPC namespace; callers then fall back to PutImage. drawable
+// is any drawable of the connection, used to check the attachment.
+func (c *Connection) NewShmSegment(size int, drawable uint32) (*ShmSegment, error) {
+	opcode, err := c.shm()
+	if err != nil {
+		return nil, err
+	}
+
+	shmid, data, err := shmCreate(size)
+	if err != nil {
+		return nil, fmt.Errorf("NewShmSegment failed: %w", err)
+	}
+	seg := &ShmSegment{ID: c.GenerateID(), Data: data, shmid: shmid}
+
+	req := make([]byte, 16)
+	req[0] = opcode
+	req[1] = shmAttach
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], seg.ID)
+	binary.LittleEndian.PutUint32(req[8:], uint32(shmid))
+	req[12] = 0 // Read-write, for the ShmGetImage below
+	if err := c.send(req); err != nil {
+		seg.release()
+		return nil, fmt.Errorf("ShmAttach failed: %w", err)
+	}
+
+	// Errors for requests without a reply are dropped, so find out
+	// whether the attach worked by reading a pixel through the segment
+	if err := c.shmGetImage(opcode, drawable, seg); err != nil {
+		seg.release()
+		return nil, fmt.Errorf("ShmAttach failed: %w", err)
+	}
+
+	// Both sides are attached now; have the segment removed once they
+	// detach, even if the program dies
+	shmRemove(shmid)
+	return seg, nil
+}
+
+// shmGetImage reads the top-left pixel of drawable into seg.
+func (c *Connection) shmGetImage(opcode uint8, drawable uint32, seg *ShmSegment) error {
+	req := make([]byte, 32)
+	req[0] = opcode
+	req[1] = shmGetImage
+	binary.LittleEndian.PutUint16(req[2:], 8)
+	binary.LittleEndian.PutUint32(req[4:], drawable)
+	binary.LittleEndian.PutUint16(req[12:], 1) // Width
+	binary.LittleEndian.PutUint16(req[14:], 1) // Height
+	binary.LittleEndian.PutUint32(req[16:], 0xFFFFFFFF)
+	req[20] = ImageFormatZPixmap
+	binary.LittleEndian.PutUint32(req[24:], seg.ID)
+	_, err := c.roundTrip(req)
+	return err
+}
+
+// ShmPutImage draws the width x height area at (srcX, srcY) of a
+// totalWidth x totalHeight ZPixmap image stored at the start of seg.
+// The server reads the segment after the request is sent, so don't
+// overwrite the image until a round trip (such as Sync) has completed.
+func (c *Connection) ShmPutImage(drawable, gc uint32, seg *ShmSegment,
+	totalWidth, totalHeight, srcX, srcY, width, height uint16,
+	dstX, dstY int16, depth uint8) error {
+
+	opcode, err := c.shm()
+	if err != nil {
+		return err
+	}
+
+	req := make([]byte, 40)
+	req[0] = opcode
+	req[1] = shmPutImage
+	binary.LittleEndian.PutUint16(req[2:], 10)
+	binary.LittleEndian.PutUint32(req[4:], drawable)
+	binary.LittleEndian.PutUint32(req[8:], gc)
+	binary.LittleEndian.PutUint16(req[12:], totalWidth)
+	binary.LittleEndian.PutUint16(req[14:], totalHeight)
+	binary.LittleEndian.PutUint16(req[16:], srcX)
+	binary.LittleEndian.PutUint16(req[18:], srcY)
+	binary.LittleEndian.PutUint16(req[20:], width)
+	binary.LittleEndian.PutUint16(req[22:], height)
+	binary.LittleEndian.PutUint16(req[24:], uint16(dstX))
+	binary.LittleEndian.PutUint16(req[26:], uint16(dstY))
+	req[28] = depth
+	req[29] = ImageFormatZPixmap
+	req[30] = 0 // No completion event
+	binary.LittleEndian.PutUint32(req[32:], seg.ID)
+	binary.LittleEndian.PutUint32(req[36:], 0) // Offset
+
+	if err := c.send(req); err != nil {
+		return fmt.Errorf("ShmPutImage failed: %w", err)
+	}
+	return nil
+}
+
+// FreeShmSegment detaches seg from the server and from this process.
+func (c *Connection) FreeShmSegment(seg *ShmSegment) error {
+	defer seg.release()
+
+	opcode, err := c.shm()
+	if err != nil {
+		return err
+	}
+	req := make([]byte, 8)
+	req[0] = opcode
+	req[1] = shmDetach
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], seg.ID)
+	return c.send(req)
+}
+
+// Release detaches seg from this process only, for when the connection
+// it was attached to is gone.
+func (seg *ShmSegment) Release() {
+	seg.release()
+}
+
+func (seg *ShmSegment) release() {
+	if seg.Data == nil {
+		return
+	}
+	shmDetachMemory(seg.Data)
+	shmRemove(seg.shmid)
+	seg.Data = nil
+}
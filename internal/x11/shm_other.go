@@ -0,0 +1,16 @@
+//go:build !(linux && (amd64 || arm || arm64 || loong64 || mips64 || mips64le || riscv64))
+
+package x11
+
+import "errors"
+
+// System V shared memory isn't wired up on this platform, so
+// NewShmSegment always fails and Present uses PutImage.
+
+func shmCreate(size int) (shmid int, data []byte, err error) {
+	return 0, nil, errors.ErrUnsupported
+}
+
+func shmRemove(shmid int) {}
+
+func shmDetachMemory(data []byte) {}
@@ -0,0 +1,39 @@
+//go:build linux && (amd64 || arm || arm64 || loong64 || mips64 || mips64le || riscv64)
+
+package x11
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	ipcPrivate = 0
+	ipcCreat   = 0o1000
+	ipcRmid    = 0
+)
+
+// shmCreate creates a private shared memory segment readable and
+// writable by this user, and maps it into the process.
+func shmCreate(size int) (shmid int, data []byte, err error) {
+	id, _, errno := syscall.Syscall(syscall.SYS_SHMGET, ipcPrivate, uintptr(size), ipcCreat|0o600)
+	if errno != 0 {
+		return 0, nil, errno
+	}
+	addr, _, errno := syscall.Syscall(syscall.SYS_SHMAT, id, 0, 0)
+	if errno != 0 {
+		shmRemove(int(id))
+		return 0, nil, errno
+	}
+	return int(id), unsafe.Slice((*byte)(unsafe.Add(nil, addr)), size), nil
+}
+
+// shmRemove marks the segment for removal once nothing has it attached.
+func shmRemove(shmid int) {
+	syscall.Syscall(syscall.SYS_SHMCTL, uintptr(shmid), ipcRmid, 0)
+}
+
+// shmDetachMemory unmaps a segment mapped by shmCreate.
+func shmDetachMemory(data []byte) {
+	syscall.Syscall(syscall.SYS_SHMDT, uintptr(unsafe.Pointer(&data[0])), 0, 0)
+}
@@ -1,13 +1,25 @@
 package x11
 
+import "fmt"
+
 // SpriteData holds pixel data in BGRA format, matching the Framebuffer layout.
 type SpriteData struct {
 	Width, Height int
 	Pixels        []byte // BGRA format, 4 bytes per pixel
 }
 
+// Valid reports whether Pixels holds exactly Width×Height pixels. The
+// blit functions draw nothing for an invalid sprite rather than read
+// past its pixels.
+func (s *SpriteData) Valid() bool {
+	return s != nil && s.Width >= 0 && s.Height >= 0 && len(s.Pixels) == s.Width*s.Height*4
+}
+
 // BlitSprite draws an entire sprite onto the framebuffer at (dstX, dstY).
 func (fb *Framebuffer) BlitSprite(s *SpriteData, dstX, dstY int) {
+	if !s.Valid() {
+		return
+	}
 	fb.BlitSpriteRegion(s, dstX, dstY, 0, 0, s.Width, s.Height)
 }
 
@@ -16,6 +28,10 @@ func (fb *Framebuffer) BlitSprite(s *SpriteData, dstX, dstY int) {
 // It is placed at (dstX, dstY) on the framebuffer. All clipping is done
 // up front so the inner loop has zero bounds checks.
 func (fb *Framebuffer) BlitSpriteRegion(s *SpriteData, dstX, dstY, srcX, srcY, srcW, srcH int) {
+	if !s.Valid() {
+		return
+	}
+
 	// Clip source region to sprite bounds
 	if srcX < 0 {
 		srcW += srcX
@@ -34,29 +50,52 @@ func (fb *Framebuffer) BlitSpriteRegion(s *SpriteData, dstX, dstY, srcX, srcY, s
 		srcH = s.Height - srcY
 	}
 
-	// Clip destination against framebuffer edges
-	if dstX < 0 {
-		srcX -= dstX
-		srcW += dstX
-		dstX = 0
+	// Clip destination against the framebuffer's clip rectangle
+	cx0, cy0, cx1, cy1 := fb.bounds()
+	if d := cx0 - dstX; d > 0 {
+		srcX += d
+		srcW -= d
+		dstX = cx0
 	}
-	if dstY < 0 {
-		srcY -= dstY
-		srcH += dstY
-		dstY = 0
+	if d := cy0 - dstY; d > 0 {
+		srcY += d
+		srcH -= d
+		dstY = cy0
 	}
-	if dstX+srcW > fb.Width {
-		srcW = fb.Width - dstX
+	if dstX+srcW > cx1 {
+		srcW = cx1 - dstX
 	}
-	if dstY+srcH > fb.Height {
-		srcH = fb.Height - dstY
+	if dstY+srcH > cy1 {
+		srcH = cy1 - dstY
 	}
 
 	// Nothing to draw after clipping
 	if srcW <= 0 || srcH <= 0 {
 		return
 	}
+	fb.blitRegion(s, dstX, dstY, srcX, srcY, srcW, srcH)
+}
 
+// BlitSpriteFast draws an entire sprite at (dstX, dstY) like BlitSprite,
+// without checking the sprite or clipping it. The whole sprite must lie
+// within the framebuffer and its clip rectangle: anything else draws
+// garbage or panics. It is meant for inner loops, such as particles,
+// that already know where they draw. Builds with the glowdebug tag
+// check the bounds and panic with a clear message.
+func (fb *Framebuffer) BlitSpriteFast(s *SpriteData, dstX, dstY int) {
+	if debugChecks {
+		x0, y0, x1, y1 := fb.bounds()
+		if !s.Valid() || dstX < x0 || dstY < y0 || dstX+s.Width > x1 || dstY+s.Height > y1 {
+			panic(fmt.Sprintf("x11: BlitSpriteFast of %dx%d sprite at (%d, %d) outside (%d, %d)-(%d, %d)",
+				s.Width, s.Height, dstX, dstY, x0, y0, x1, y1))
+		}
+	}
+	fb.blitRegion(s, dstX, dstY, 0, 0, s.Width, s.Height)
+}
+
+// blitRegion is the inner loop of BlitSpriteRegion, for a region already
+// clipped to the sprite and the framebuffer.
+func (fb *Framebuffer) blitRegion(s *SpriteData, dstX, dstY, srcX, srcY, srcW, srcH int) {
 	fbStride := fb.Width * 4
 	spStride := s.Width * 4
 	fbPix := fb.Pixels
@@ -77,26 +116,75 @@ func (fb *Framebuffer) BlitSpriteRegion(s *SpriteData, dstX, dstY, srcX, srcY, s
 			}
 
 			if a == 255 {
-				// Fully opaque — direct copy (B, G, R)
-				fbPix[fbOff] = spPix[spOff]
-				fbPix[fbOff+1] = spPix[spOff+1]
-				fbPix[fbOff+2] = spPix[spOff+2]
+				// Fully opaque — direct copy (B, G, R, A)
+				copy(fbPix[fbOff:fbOff+4], spPix[spOff:spOff+4])
 				fbOff += 4
 				spOff += 4
 				continue
 			}
 
-			// Alpha blend: out = (src*a + dst*(255-a) + 1 + ((src*a + dst*(255-a)) >> 8)) >> 8
-			invA := 255 - a
-			for ch := 0; ch < 3; ch++ {
-				s := uint32(spPix[spOff+ch])
-				d := uint32(fbPix[fbOff+ch])
-				v := s*a + d*invA
-				fbPix[fbOff+ch] = uint8((v + 1 + (v >> 8)) >> 8)
-			}
+			blendBGRA(fbPix[fbOff:fbOff+4], spPix[spOff:spOff+4])
 
 			fbOff += 4
 			spOff += 4
 		}
 	}
 }
+
+// BlitSpriteScaled draws a whole sprite stretched or shrunk to
+// dstW×dstH at (dstX, dstY), with nearest-neighbour sampling so pixel
+// art stays crisp. Alpha is blended as in BlitSpriteRegion.
+func (fb *Framebuffer) BlitSpriteScaled(s *SpriteData, dstX, dstY, dstW, dstH int) {
+	if !s.Valid() || dstW <= 0 || dstH <= 0 || s.Width <= 0 || s.Height <= 0 {
+		return
+	}
+
+	// Clip the destination against the clip rectangle; (x0, y0) is
+	// the first visible pixel relative to (dstX, dstY)
+	cx0, cy0, cx1, cy1 := fb.bounds()
+	x0, y0 := max(cx0-dstX, 0), max(cy0-dstY, 0)
+	x1 := min(dstW, cx1-dstX)
+	y1 := min(dstH, cy1-dstY)
+	if x0 >= x1 || y0 >= y1 {
+		return
+	}
+
+	// Source column offset for each visible destination column
+	cols := make([]int, x1-x0)
+	for i := range cols {
+		cols[i] = (x0 + i) * s.Width / dstW * 4
+	}
+
+	fbStride := fb.Width * 4
+	spStride := s.Width * 4
+	for y := y0; y < y1; y++ {
+		fbOff := (dstY+y)*fbStride + (dstX+x0)*4
+		spRow := s.Pixels[y*s.Height/dstH*spStride:]
+		for _, col := range cols {
+			src := spRow[col : col+4]
+			switch src[3] {
+			case 0:
+			case 255:
+				copy(fb.Pixels[fbOff:fbOff+4], src)
+			default:
+				blendBGRA(fb.Pixels[fbOff:fbOff+4], src)
+			}
+			fbOff += 4
+		}
+	}
+}
+
+// blendBGRA blends the straight-alpha BGRA pixel src over the
+// premultiplied BGRA pixel dst: out = (src*a + dst*(255-a) + 1 +
+// ((src*a + dst*(255-a)) >> 8)) >> 8, with src's alpha taken as 255 for
+// the alpha byte so it composites to a + dst_a*(255-a)/255.
+func blendBGRA(dst, src []byte) {
+	a := uint32(src[3])
+	invA := 255 - a
+	for ch := 0; ch < 3; ch++ {
+		v := uint32(src[ch])*a + uint32(dst[ch])*invA
+		dst[ch] = uint8((v + 1 + (v >> 8)) >> 8)
+	}
+	v := 255*a + uint32(dst[3])*invA
+	dst[3] = uint8((v + 1 + (v >> 8)) >> 8)
+}
@@ -0,0 +1,113 @@
+package x11
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Visual classes
+const (
+	VisualStaticGray  = 0
+	VisualGrayScale   = 1
+	VisualStaticColor = 2
+	VisualPseudoColor = 3
+	VisualTrueColor   = 4
+	VisualDirectColor = 5
+)
+
+// VisualInfo describes a visual of the screen: how pixel values of a
+// drawable map to colors.
+type VisualInfo struct {
+	ID                           uint32
+	Depth                        uint8
+	Class                        uint8
+	RedMask, GreenMask, BlueMask uint32
+}
+
+// HasAlpha reports whether pixels of the visual have bits besides red,
+// green and blue, which compositing managers read as alpha, as in the
+// 32-bit ARGB visuals.
+func (v VisualInfo) HasAlpha() bool {
+	return v.Class == VisualTrueColor &&
+		bits.OnesCount32(v.RedMask|v.GreenMask|v.BlueMask) < int(v.Depth)
+}
+
+// Visual returns the screen's visual with the given ID.
+func (c *Connection) Visual(id uint32) (VisualInfo, bool) {
+	for _, v := range c.Visuals {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return VisualInfo{}, false
+}
+
+// ARGBVisual returns a 32-bit TrueColor visual with an alpha channel,
+// the kind compositing managers blend translucent windows with, if the
+// screen has one.
+func (c *Connection) ARGBVisual() (VisualInfo, bool) {
+	for _, v := range c.Visuals {
+		if v.Depth == 32 && v.HasAlpha() {
+			return v, true
+		}
+	}
+	return VisualInfo{}, false
+}
+
+// CreateColormap creates a colormap for visual on the root window's
+// screen, as windows of a visual other than the root's need.
+func (c *Connection) CreateColormap(visual uint32) (uint32, error) {
+	colormapID := c.GenerateID()
+
+	req := make([]byte, 16)
+	req[0] = OpCreateColormap
+	req[1] = 0 // alloc: None
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], colormapID)
+	binary.LittleEndian.PutUint32(req[8:], c.RootWindow)
+	binary.LittleEndian.PutUint32(req[12:], visual)
+
+	if err := c.send(req); err != nil {
+		return 0, err
+	}
+	return colormapID, nil
+}
+
+// FreeColormap frees a colormap made by CreateColormap.
+func (c *Connection) FreeColormap(colormapID uint32) error {
+	req := make([]byte, 8)
+	req[0] = OpFreeColormap
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], colormapID)
+
+	return c.send(req)
+}
+
+// parseVisuals decodes the allowed depths and their visuals following a
+// screen's 40-byte description in the setup data.
+func parseVisuals(screen []byte) []VisualInfo {
+	if len(screen) < 40 {
+		return nil
+	}
+	numDepths := int(screen[39])
+	var visuals []VisualInfo
+	off := 40
+	for i := 0; i < numDepths && off+8 <= len(screen); i++ {
+		depth := screen[off]
+		n := int(binary.LittleEndian.Uint16(screen[off+2:]))
+		off += 8
+		for j := 0; j < n && off+24 <= len(screen); j++ {
+			v := screen[off:]
+			visuals = append(visuals, VisualInfo{
+				ID:        binary.LittleEndian.Uint32(v[0:]),
+				Depth:     depth,
+				Class:     v[4],
+				RedMask:   binary.LittleEndian.Uint32(v[8:]),
+				GreenMask: binary.LittleEndian.Uint32(v[12:]),
+				BlueMask:  binary.LittleEndian.Uint32(v[16:]),
+			})
+			off += 24
+		}
+	}
+	return visuals
+}
@@ -2,51 +2,67 @@ package x11
 
 import (
 	"encoding/binary"
+	"fmt"
 )
 
+// WindowEventMask is the set of events new windows select.
+// PropertyChangeMask is left out, as most property changes interest
+// nobody; SelectInput adds it when wanted.
+const WindowEventMask = ExposureMask |
+	KeyPressMask |
+	KeyReleaseMask |
+	ButtonPressMask |
+	ButtonReleaseMask |
+	PointerMotionMask |
+	StructureNotifyMask |
+	FocusChangeMask
+
 // CreateWindow creates a new window and returns its ID
 func (c *Connection) CreateWindow(x, y int16, width, height uint16) (uint32, error) {
+	return c.CreateWindowVisual(x, y, width, height, c.RootDepth, c.RootVisual, 0)
+}
+
+// CreateWindowVisual creates a new window of the given depth and visual
+// and returns its ID. A visual other than the root's needs a colormap
+// made for it with CreateColormap; pass 0 to use the root's.
+func (c *Connection) CreateWindowVisual(x, y int16, width, height uint16, depth uint8, visual, colormap uint32) (uint32, error) {
 	windowID := c.GenerateID()
 
-	// We want to receive these events
-	eventMask := uint32(
-		ExposureMask |
-		KeyPressMask |
-		KeyReleaseMask |
-		ButtonPressMask |
-		ButtonReleaseMask |
-		PointerMotionMask |
-		StructureNotifyMask,
-	)
-
-	// We're setting: background pixel (black) and event mask
+	// We're setting: background pixel (black) and event mask, plus a
+	// border pixel and the colormap for a visual of our own, as the
+	// parent's don't match it
+	values := []uint32{0x00000000, WindowEventMask} // CWBackPixel: black; CWEventMask
 	valueMask := uint32(CWBackPixel | CWEventMask)
-	valueCount := 2
+	if colormap != 0 {
+		values = []uint32{0x00000000, 0x00000000, WindowEventMask, colormap}
+		valueMask |= CWBorderPixel | CWColormap
+	}
 
 	// Request length in 4-byte units: header (8 words) + values
-	reqLen := 8 + valueCount
+	reqLen := 8 + len(values)
 	req := make([]byte, reqLen*4)
 
 	// Build the CreateWindow request
-	req[0] = OpCreateWindow                                  // Opcode
-	req[1] = c.RootDepth                                     // Depth (copy from root)
-	binary.LittleEndian.PutUint16(req[2:], uint16(reqLen))   // Request length
-	binary.LittleEndian.PutUint32(req[4:], windowID)         // New window ID
-	binary.LittleEndian.PutUint32(req[8:], c.RootWindow)     // Parent window
-	binary.LittleEndian.PutUint16(req[12:], uint16(x))       // X position
-	binary.LittleEndian.PutUint16(req[14:], uint16(y))       // Y position
-	binary.LittleEndian.PutUint16(req[16:], width)           // Width
-	binary.LittleEndian.PutUint16(req[18:], height)          // Height
-	binary.LittleEndian.PutUint16(req[20:], 0)               // Border width
+	req[0] = OpCreateWindow                                         // Opcode
+	req[1] = depth                                                  // Depth
+	binary.LittleEndian.PutUint16(req[2:], uint16(reqLen))          // Request length
+	binary.LittleEndian.PutUint32(req[4:], windowID)                // New window ID
+	binary.LittleEndian.PutUint32(req[8:], c.RootWindow)            // Parent window
+	binary.LittleEndian.PutUint16(req[12:], uint16(x))              // X position
+	binary.LittleEndian.PutUint16(req[14:], uint16(y))              // Y position
+	binary.LittleEndian.PutUint16(req[16:], width)                  // Width
+	binary.LittleEndian.PutUint16(req[18:], height)                 // Height
+	binary.LittleEndian.PutUint16(req[20:], 0)                      // Border width
 	binary.LittleEndian.PutUint16(req[22:], WindowClassInputOutput) // Window class
-	binary.LittleEndian.PutUint32(req[24:], c.RootVisual)    // Visual ID
-	binary.LittleEndian.PutUint32(req[28:], valueMask)       // Value mask
+	binary.LittleEndian.PutUint32(req[24:], visual)                 // Visual ID
+	binary.LittleEndian.PutUint32(req[28:], valueMask)              // Value mask
 
 	// Values are written in order of the bits in valueMask
-	binary.LittleEndian.PutUint32(req[32:], 0x00000000) // CWBackPixel: black
-	binary.LittleEndian.PutUint32(req[36:], eventMask) // CWEventMask
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(req[32+i*4:], v)
+	}
 
-	if _, err := c.conn.Write(req); err != nil {
+	if err := c.send(req); err != nil {
 		return 0, err
 	}
 
@@ -57,11 +73,11 @@ func (c *Connection) CreateWindow(x, y int16, width, height uint16) (uint32, err
 func (c *Connection) MapWindow(windowID uint32) error {
 	req := make([]byte, 8)
 	req[0] = OpMapWindow
-	req[1] = 0 // Unused
+	req[1] = 0                                // Unused
 	binary.LittleEndian.PutUint16(req[2:], 2) // Request length: 2 words
 	binary.LittleEndian.PutUint32(req[4:], windowID)
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
 
@@ -73,10 +89,56 @@ func (c *Connection) UnmapWindow(windowID uint32) error {
 	binary.LittleEndian.PutUint16(req[2:], 2)
 	binary.LittleEndian.PutUint32(req[4:], windowID)
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
 
+// Geometry is the position and size of a drawable
+type Geometry struct {
+	Root          uint32
+	Depth         uint8
+	X, Y          int16 // Relative to the parent window
+	Width, Height uint16
+	BorderWidth   uint16
+}
+
+// GetGeometry returns the current geometry of a window or pixmap
+func (c *Connection) GetGeometry(drawable uint32) (*Geometry, error) {
+	req := make([]byte, 8)
+	req[0] = OpGetGeometry
+	req[1] = 0
+	binary.LittleEndian.PutUint16(req[2:], 2)
+	binary.LittleEndian.PutUint32(req[4:], drawable)
+
+	reply, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("GetGeometry failed: %w", err)
+	}
+
+	return &Geometry{
+		Depth:       reply[1],
+		Root:        binary.LittleEndian.Uint32(reply[8:12]),
+		X:           int16(binary.LittleEndian.Uint16(reply[12:14])),
+		Y:           int16(binary.LittleEndian.Uint16(reply[14:16])),
+		Width:       binary.LittleEndian.Uint16(reply[16:18]),
+		Height:      binary.LittleEndian.Uint16(reply[18:20]),
+		BorderWidth: binary.LittleEndian.Uint16(reply[20:22]),
+	}, nil
+}
+
+// SelectInput replaces the set of events window reports, e.g.
+// WindowEventMask|PropertyChangeMask.
+func (c *Connection) SelectInput(windowID, eventMask uint32) error {
+	req := make([]byte, 16)
+	req[0] = OpChangeWindowAttributes
+	binary.LittleEndian.PutUint16(req[2:], 4)
+	binary.LittleEndian.PutUint32(req[4:], windowID)
+	binary.LittleEndian.PutUint32(req[8:], CWEventMask)
+	binary.LittleEndian.PutUint32(req[12:], eventMask)
+
+	return c.send(req)
+}
+
 // DestroyWindow destroys a window and frees its resources
 func (c *Connection) DestroyWindow(windowID uint32) error {
 	req := make([]byte, 8)
@@ -85,7 +147,7 @@ func (c *Connection) DestroyWindow(windowID uint32) error {
 	binary.LittleEndian.PutUint16(req[2:], 2)
 	binary.LittleEndian.PutUint32(req[4:], windowID)
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
 
@@ -94,12 +156,29 @@ func (c *Connection) DestroyWindow(windowID uint32) error {
 func (c *Connection) SendEvent(destination uint32, eventMask uint32, event []byte) error {
 	req := make([]byte, 44)
 	req[0] = OpSendEvent
-	req[1] = 0 // propagate = false
+	req[1] = 0                                 // propagate = false
 	binary.LittleEndian.PutUint16(req[2:], 11) // request length: 11 words (44 bytes)
 	binary.LittleEndian.PutUint32(req[4:], destination)
 	binary.LittleEndian.PutUint32(req[8:], eventMask)
 	copy(req[12:], event[:32])
 
-	_, err := c.conn.Write(req)
+	err := c.send(req)
 	return err
 }
+
+// SendClientMessage sends a format-32 ClientMessage event to destination.
+// window fills the event's window field and data holds data.l[0..4].
+func (c *Connection) SendClientMessage(destination, window uint32,
+	messageType Atom, data [5]uint32) error {
+
+	var event [32]byte
+	event[0] = EventClientMessage
+	event[1] = 32 // format = 32-bit
+	binary.LittleEndian.PutUint32(event[4:], window)
+	binary.LittleEndian.PutUint32(event[8:], uint32(messageType))
+	for i, v := range data {
+		binary.LittleEndian.PutUint32(event[12+i*4:], v)
+	}
+
+	return c.SendEvent(destination, 0, event[:])
+}
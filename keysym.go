@@ -0,0 +1,220 @@
+package glow
+
+import (
+	"unicode"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// Keysym identifies the symbol a key produces, as opposed to the
+// physical key (Key). Printable keysyms in the Latin-1 range equal their
+// character code, so 'a' and 'A' are distinct keysyms; other characters
+// are 0x01000000 plus their code point. Use Rune to get the character.
+type Keysym uint32
+
+// Keysyms for keys that don't produce a character
+const (
+	KeysymNone      Keysym = 0
+	KeysymBackSpace Keysym = 0xff08
+	KeysymTab       Keysym = 0xff09
+	KeysymReturn    Keysym = 0xff0d
+	KeysymEscape    Keysym = 0xff1b
+	KeysymHome      Keysym = 0xff50
+	KeysymLeft      Keysym = 0xff51
+	KeysymUp        Keysym = 0xff52
+	KeysymRight     Keysym = 0xff53
+	KeysymDown      Keysym = 0xff54
+	KeysymPageUp    Keysym = 0xff55
+	KeysymPageDown  Keysym = 0xff56
+	KeysymEnd       Keysym = 0xff57
+	KeysymInsert    Keysym = 0xff63
+	KeysymF1        Keysym = 0xffbe // F2..F12 follow consecutively
+	KeysymShiftL    Keysym = 0xffe1
+	KeysymShiftR    Keysym = 0xffe2
+	KeysymCtrlL     Keysym = 0xffe3
+	KeysymCtrlR     Keysym = 0xffe4
+	KeysymAltL      Keysym = 0xffe9
+	KeysymAltR      Keysym = 0xffea
+	KeysymDelete    Keysym = 0xffff
+)
+
+// Unicode keysyms are keysymUnicode plus the code point
+const keysymUnicode = 0x01000000
+
+// Rune returns the character the keysym types, or 0 if it isn't a
+// printable character. The pre-Unicode keysym sets many layouts still
+// use (Latin-2, Cyrillic, Greek and the rest) are converted too.
+func (k Keysym) Rune() rune {
+	if (k >= 0x20 && k <= 0x7e) || (k >= 0xa0 && k <= 0xff) {
+		return rune(k)
+	}
+	if k >= keysymUnicode+0x100 && k <= keysymUnicode+unicode.MaxRune {
+		return rune(k - keysymUnicode)
+	}
+	return legacyKeysyms[k]
+}
+
+// keysymForRune returns the keysym that types r.
+func keysymForRune(r rune) Keysym {
+	if r < 0x100 {
+		return Keysym(r)
+	}
+	return keysymUnicode + Keysym(r)
+}
+
+// keymap holds the keysyms of each keycode at levels 1 to 4: unshifted,
+// shifted, and the same again with AltGr (ISO_Level3_Shift) held.
+type keymap struct {
+	syms   [256][4]Keysym
+	level3 uint16 // Modifier mask AltGr sets
+}
+
+// usKeymap is the keymap of a standard US layout (evdev keycodes, as used
+// by the Key constants). It is used until the server's own mapping is
+// known.
+var usKeymap = keymap{level3: x11.Mod5Mask}
+
+func init() {
+	rows := []struct {
+		first           Key
+		normal, shifted string
+	}{
+		{Key1, "1234567890-=", "!@#$%^&*()_+"},
+		{KeyQ, "qwertyuiop[]", "QWERTYUIOP{}"},
+		{KeyA, "asdfghjkl;'`", `ASDFGHJKL:"~`},
+		{51, `\zxcvbnm,./`, `|ZXCVBNM<>?`}, // 51 is backslash, left of Z
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row.normal); i++ {
+			usKeymap.syms[int(row.first)+i] = [4]Keysym{Keysym(row.normal[i]), Keysym(row.shifted[i])}
+		}
+	}
+
+	special := map[Key]Keysym{
+		KeySpace:     ' ',
+		KeyBackspace: KeysymBackSpace,
+		KeyTab:       KeysymTab,
+		KeyEnter:     KeysymReturn,
+		KeyEscape:    KeysymEscape,
+		KeyShiftL:    KeysymShiftL,
+		KeyShiftR:    KeysymShiftR,
+		KeyCtrlL:     KeysymCtrlL,
+		KeyCtrlR:     KeysymCtrlR,
+		KeyAltL:      KeysymAltL,
+		KeyAltR:      KeysymAltR,
+		KeyLeft:      KeysymLeft,
+		KeyUp:        KeysymUp,
+		KeyRight:     KeysymRight,
+		KeyDown:      KeysymDown,
+		110:          KeysymHome,
+		112:          KeysymPageUp,
+		115:          KeysymEnd,
+		117:          KeysymPageDown,
+		118:          KeysymInsert,
+		119:          KeysymDelete,
+	}
+	fkeys := []Key{KeyF1, KeyF2, KeyF3, KeyF4, KeyF5, KeyF6, KeyF7, KeyF8, KeyF9, KeyF10, KeyF11, KeyF12}
+	for i, k := range fkeys {
+		special[k] = KeysymF1 + Keysym(i)
+	}
+	for k, sym := range special {
+		usKeymap.syms[k] = [4]Keysym{sym, sym}
+	}
+}
+
+// newKeymap builds a keymap from the first group of the server's
+// keyboard mapping, levels 3 and 4 included. As the protocol specifies,
+// a key with only one keysym at a level pair types it unshifted and
+// shifted, upper-cased if it's a letter. AltGr is taken to be Mod5, as
+// on nearly every layout, until setModifiers says otherwise.
+func newKeymap(m *x11.KeyboardMapping) *keymap {
+	km := &keymap{level3: x11.Mod5Mask}
+	for code := range km.syms {
+		for level, col := range []int{0, 4} {
+			lower := Keysym(m.Keysym(uint8(code), col))
+			upper := Keysym(m.Keysym(uint8(code), col+1))
+			if upper == x11.NoSymbol {
+				upper = lower
+				if r := lower.Rune(); unicode.IsLower(r) {
+					upper = keysymForRune(unicode.ToUpper(r))
+				}
+			}
+			km.syms[code][level*2], km.syms[code][level*2+1] = lower, upper
+		}
+	}
+	return km
+}
+
+// setModifiers finds the modifier AltGr sets in the server's modifier
+// mapping, keeping Mod5 if no key is bound to ISO_Level3_Shift.
+func (km *keymap) setModifiers(mods x11.ModifierMapping) {
+	for mod, codes := range mods {
+		for _, code := range codes {
+			if km.syms[code][0] == x11.KeysymISOLevel3Shift {
+				km.level3 = 1 << mod
+				return
+			}
+		}
+	}
+}
+
+// lookup resolves the keysym a key produces with the given X11 modifier
+// state. AltGr selects levels 3 and 4, on keys that have them, and Shift
+// the shifted symbol of the pair; Caps Lock inverts that for letters
+// only, keys whose shifted symbol is the upper case of the unshifted one.
+func (km *keymap) lookup(key Key, state uint16) Keysym {
+	syms := km.syms[key][:2]
+	if state&km.level3 != 0 && km.syms[key][2] != x11.NoSymbol {
+		syms = km.syms[key][2:]
+	}
+	shifted := state&x11.ShiftMask != 0
+	if state&x11.LockMask != 0 {
+		if r := syms[0].Rune(); unicode.IsLower(r) && syms[1].Rune() == unicode.ToUpper(r) {
+			shifted = !shifted
+		}
+	}
+	if shifted {
+		return syms[1]
+	}
+	return syms[0]
+}
+
+// keysymFor resolves a key with the built-in US layout (see
+// keymap.lookup).
+func keysymFor(key Key, state uint16) Keysym {
+	return usKeymap.lookup(key, state)
+}
+
+// fetchKeymap builds a keymap from the server's keyboard and modifier
+// mappings on conn.
+func fetchKeymap(conn *x11.Connection) (*keymap, error) {
+	m, err := conn.GetKeyboardMapping()
+	if err != nil {
+		return nil, err
+	}
+	km := newKeymap(m)
+	if mods, err := conn.GetModifierMapping(); err == nil {
+		km.setModifiers(mods)
+	}
+	return km, nil
+}
+
+// loadKeymap fetches the server's keyboard mapping on conn for the
+// window's key events. On failure the window keeps its current keymap,
+// the US layout if none was loaded.
+func (w *Window) loadKeymap(conn *x11.Connection) {
+	km, err := fetchKeymap(conn)
+	if err != nil {
+		return
+	}
+	w.keymap.Store(km)
+}
+
+// keysym resolves a key with the server's keyboard mapping, or the US
+// layout if it isn't known.
+func (w *Window) keysym(key Key, state uint16) Keysym {
+	if km := w.keymap.Load(); km != nil {
+		return km.lookup(key, state)
+	}
+	return keysymFor(key, state)
+}
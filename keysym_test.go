@@ -0,0 +1,214 @@
+package glow
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestKeysymFor(t *testing.T) {
+	tests := []struct {
+		key   Key
+		state uint16
+		want  Keysym
+	}{
+		{KeyA, 0, 'a'},
+		{KeyA, x11.ShiftMask, 'A'},
+		{KeyA, x11.LockMask, 'A'},
+		{KeyA, x11.LockMask | x11.ShiftMask, 'a'},
+		{Key1, x11.LockMask, '1'}, // Caps Lock leaves digits alone
+		{Key1, x11.ShiftMask, '!'},
+		{KeyMinus, x11.ShiftMask, '_'},
+		{51, 0, '\\'},
+		{KeyM, 0, 'm'},
+		{KeySpace, x11.ShiftMask, ' '},
+		{KeyEnter, 0, KeysymReturn},
+		{KeyF12, 0, KeysymF1 + 11},
+		{KeyUnknown, 0, KeysymNone},
+	}
+	for _, tt := range tests {
+		if got := keysymFor(tt.key, tt.state); got != tt.want {
+			t.Errorf("keysymFor(%d, %#x) = %#x, want %#x", tt.key, tt.state, got, tt.want)
+		}
+	}
+
+	if r := Keysym('q').Rune(); r != 'q' {
+		t.Errorf("Rune of 'q' = %q", r)
+	}
+	if r := KeysymReturn.Rune(); r != 0 {
+		t.Errorf("Return should not be printable, got %q", r)
+	}
+}
+
+// keyboardMappingReply builds a GetKeyboardMapping reply for keycodes
+// from first, with as many keysyms per keycode as the longest of syms
+// and at least two.
+func keyboardMappingReply(first uint8, syms map[uint8][]uint32) []byte {
+	const count = 128
+	per := 2
+	for _, s := range syms {
+		per = max(per, len(s))
+	}
+	reply := make([]byte, 32+count*per*4)
+	reply[0] = 1          // Reply
+	reply[1] = uint8(per) // Keysyms per keycode
+	binary.LittleEndian.PutUint32(reply[4:], uint32(count*per))
+	for code, s := range syms {
+		off := 32 + int(code-first)*per*4
+		for i, sym := range s {
+			binary.LittleEndian.PutUint32(reply[off+i*4:], sym)
+		}
+	}
+	return reply
+}
+
+func TestKeyboardMapping(t *testing.T) {
+	// A German (QWERTZ) layout, where Y and Z swap places
+	reply := keyboardMappingReply(8, map[uint8][]uint32{
+		29: {'z', 'Z'},
+		52: {'y', 'Y'},
+		20: {0xdf, '?'},                // ß
+		26: {0x010020ac, x11.NoSymbol}, // €
+		38: {'a', x11.NoSymbol},
+	})
+	m, err := x11.ParseKeyboardMapping(8, reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.KeysymsPerKeycode != 2 {
+		t.Fatalf("KeysymsPerKeycode = %d, want 2", m.KeysymsPerKeycode)
+	}
+	if got := m.Keysym(29, 0); got != 'z' {
+		t.Errorf("keycode 29 = %#x, want 'z'", got)
+	}
+	if got := m.Keysym(7, 0); got != x11.NoSymbol {
+		t.Errorf("keycode below range = %#x, want NoSymbol", got)
+	}
+	if _, err := x11.ParseKeyboardMapping(8, reply[:40]); err == nil {
+		t.Error("truncated reply parsed without error")
+	}
+
+	km := newKeymap(m)
+	tests := []struct {
+		key   Key
+		state uint16
+		want  Keysym
+		r     rune
+	}{
+		{KeyY, 0, 'z', 'z'},
+		{KeyZ, x11.ShiftMask, 'Y', 'Y'},
+		{KeyMinus, 0, 0xdf, 'ß'},
+		{KeyMinus, x11.ShiftMask, '?', '?'},
+		{KeyMinus, x11.LockMask, 0xdf, 'ß'},
+		{KeyE, 0, 0x010020ac, '€'},
+		{KeyA, x11.ShiftMask, 'A', 'A'}, // One keysym: shifted is upper-cased
+		{KeyA, x11.LockMask, 'A', 'A'},
+		{KeyB, 0, KeysymNone, 0},
+	}
+	for _, tt := range tests {
+		got := km.lookup(tt.key, tt.state)
+		if got != tt.want || got.Rune() != tt.r {
+			t.Errorf("lookup(%d, %#x) = %#x (%q), want %#x (%q)", tt.key, tt.state, got, got.Rune(), tt.want, tt.r)
+		}
+	}
+}
+
+func TestLegacyKeysymRune(t *testing.T) {
+	tests := []struct {
+		sym  Keysym
+		want rune
+	}{
+		{0x1b3, 'ł'},    // Latin-2 lstroke
+		{0x6c6, 'ф'},    // Cyrillic_ef
+		{0x6e6, 'Ф'},    // Cyrillic_EF
+		{0x7e1, 'α'},    // Greek_alpha
+		{0xcf9, 'ש'},    // hebrew_shin
+		{0x20ac, '€'},   // EuroSign
+		{0x6a0, 0},      // Unassigned
+		{KeysymHome, 0}, // Not a character
+	}
+	for _, tt := range tests {
+		if got := tt.sym.Rune(); got != tt.want {
+			t.Errorf("Keysym(%#x).Rune() = %q, want %q", tt.sym, got, tt.want)
+		}
+	}
+}
+
+func TestKeymapLevel3(t *testing.T) {
+	// German layout: AltGr+Q types @ and AltGr+E €; a Russian group-1
+	// key sends legacy Cyrillic keysyms
+	reply := keyboardMappingReply(8, map[uint8][]uint32{
+		24:  {'q', 'Q', 'q', 'Q', '@', 0x7d9},
+		26:  {'e', 'E', 'e', 'E', 0x20ac, 'E'},
+		38:  {0x6c6, 0x6e6},
+		108: {x11.KeysymISOLevel3Shift},
+	})
+	m, err := x11.ParseKeyboardMapping(8, reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	km := newKeymap(m)
+
+	// Bind AltGr to Mod3 rather than the usual Mod5
+	mods := make([]byte, 32+8*2)
+	mods[1] = 2 // Keycodes per modifier
+	mods[32+5*2] = 108
+	mapping, err := x11.ParseModifierMapping(mods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping[5]) != 1 || len(mapping[0]) != 0 {
+		t.Fatalf("modifier mapping %v, want only keycode 108 on Mod3", mapping)
+	}
+	km.setModifiers(mapping)
+
+	tests := []struct {
+		key   Key
+		state uint16
+		want  rune
+	}{
+		{KeyQ, 0, 'q'},
+		{KeyQ, x11.Mod3Mask, '@'},
+		{KeyQ, x11.Mod3Mask | x11.ShiftMask, 'Ω'},
+		{KeyQ, x11.Mod5Mask, 'q'}, // Mod5 isn't AltGr here
+		{KeyE, x11.Mod3Mask, '€'},
+		{KeyA, 0, 'ф'},
+		{KeyA, x11.ShiftMask, 'Ф'},
+		{KeyA, x11.LockMask, 'Ф'},
+		{KeyA, x11.Mod3Mask, 'ф'}, // No level 3: AltGr is ignored
+	}
+	for _, tt := range tests {
+		if got := km.lookup(tt.key, tt.state).Rune(); got != tt.want {
+			t.Errorf("lookup(%d, %#x) types %q, want %q", tt.key, tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestEventRune(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	m, err := x11.ParseKeyboardMapping(8, keyboardMappingReply(8, map[uint8][]uint32{29: {'z', 'Z'}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The US layout is used until the server's mapping is loaded
+	e := w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyPress, Keycode: uint8(KeyY)})
+	if e.Rune != 'y' {
+		t.Errorf("Rune with US layout = %q, want 'y'", e.Rune)
+	}
+
+	w.keymap.Store(newKeymap(m))
+	e = w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyPress, Keycode: uint8(KeyY), State: x11.ShiftMask})
+	if e.Key != KeyY || e.Keysym != 'Z' || e.Rune != 'Z' {
+		t.Errorf("got Key %d, Keysym %#x, Rune %q; want KeyY, 'Z', 'Z'", e.Key, e.Keysym, e.Rune)
+	}
+	e = w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyRelease, Keycode: uint8(KeyY)})
+	if e.Rune != 0 {
+		t.Errorf("KeyUp Rune = %q, want 0", e.Rune)
+	}
+	e = w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyPress, Keycode: uint8(KeyY), State: x11.ControlMask})
+	if e.Keysym != 'z' || e.Rune != 0 {
+		t.Errorf("Ctrl KeyDown: Keysym %#x, Rune %q; want 'z', 0", e.Keysym, e.Rune)
+	}
+}
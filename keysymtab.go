@@ -0,0 +1,149 @@
+package glow
+
+// legacyKeysyms maps the pre-Unicode keysym sets that layouts still
+// send (Latin-2 to Latin-9, Cyrillic, Greek, Hebrew, Thai and so on)
+// to their characters. It is taken from the U+ annotations in X.Org's
+// keysymdef.h.
+var legacyKeysyms = map[Keysym]rune{
+	// Latin-2
+	0x1a1: 0x0104, 0x1a2: 0x02d8, 0x1a3: 0x0141, 0x1a5: 0x013d, 0x1a6: 0x015a, 0x1a9: 0x0160,
+	0x1aa: 0x015e, 0x1ab: 0x0164, 0x1ac: 0x0179, 0x1ae: 0x017d, 0x1af: 0x017b, 0x1b1: 0x0105,
+	0x1b2: 0x02db, 0x1b3: 0x0142, 0x1b5: 0x013e, 0x1b6: 0x015b, 0x1b7: 0x02c7, 0x1b9: 0x0161,
+	0x1ba: 0x015f, 0x1bb: 0x0165, 0x1bc: 0x017a, 0x1bd: 0x02dd, 0x1be: 0x017e, 0x1bf: 0x017c,
+	0x1c0: 0x0154, 0x1c3: 0x0102, 0x1c5: 0x0139, 0x1c6: 0x0106, 0x1c8: 0x010c, 0x1ca: 0x0118,
+	0x1cc: 0x011a, 0x1cf: 0x010e, 0x1d0: 0x0110, 0x1d1: 0x0143, 0x1d2: 0x0147, 0x1d5: 0x0150,
+	0x1d8: 0x0158, 0x1d9: 0x016e, 0x1db: 0x0170, 0x1de: 0x0162, 0x1e0: 0x0155, 0x1e3: 0x0103,
+	0x1e5: 0x013a, 0x1e6: 0x0107, 0x1e8: 0x010d, 0x1ea: 0x0119, 0x1ec: 0x011b, 0x1ef: 0x010f,
+	0x1f0: 0x0111, 0x1f1: 0x0144, 0x1f2: 0x0148, 0x1f5: 0x0151, 0x1f8: 0x0159, 0x1f9: 0x016f,
+	0x1fb: 0x0171, 0x1fe: 0x0163, 0x1ff: 0x02d9,
+	// Latin-3
+	0x2a1: 0x0126, 0x2a6: 0x0124, 0x2a9: 0x0130, 0x2ab: 0x011e, 0x2ac: 0x0134, 0x2b1: 0x0127,
+	0x2b6: 0x0125, 0x2b9: 0x0131, 0x2bb: 0x011f, 0x2bc: 0x0135, 0x2c5: 0x010a, 0x2c6: 0x0108,
+	0x2d5: 0x0120, 0x2d8: 0x011c, 0x2dd: 0x016c, 0x2de: 0x015c, 0x2e5: 0x010b, 0x2e6: 0x0109,
+	0x2f5: 0x0121, 0x2f8: 0x011d, 0x2fd: 0x016d, 0x2fe: 0x015d,
+	// Latin-4
+	0x3a2: 0x0138, 0x3a3: 0x0156, 0x3a5: 0x0128, 0x3a6: 0x013b, 0x3aa: 0x0112, 0x3ab: 0x0122,
+	0x3ac: 0x0166, 0x3b3: 0x0157, 0x3b5: 0x0129, 0x3b6: 0x013c, 0x3ba: 0x0113, 0x3bb: 0x0123,
+	0x3bc: 0x0167, 0x3bd: 0x014a, 0x3bf: 0x014b, 0x3c0: 0x0100, 0x3c7: 0x012e, 0x3cc: 0x0116,
+	0x3cf: 0x012a, 0x3d1: 0x0145, 0x3d2: 0x014c, 0x3d3: 0x0136, 0x3d9: 0x0172, 0x3dd: 0x0168,
+	0x3de: 0x016a, 0x3e0: 0x0101, 0x3e7: 0x012f, 0x3ec: 0x0117, 0x3ef: 0x012b, 0x3f1: 0x0146,
+	0x3f2: 0x014d, 0x3f3: 0x0137, 0x3f9: 0x0173, 0x3fd: 0x0169, 0x3fe: 0x016b,
+	// Katakana
+	0x47e: 0x203e, 0x4a1: 0x3002, 0x4a2: 0x300c, 0x4a3: 0x300d, 0x4a4: 0x3001, 0x4a5: 0x30fb,
+	0x4a6: 0x30f2, 0x4a7: 0x30a1, 0x4a8: 0x30a3, 0x4a9: 0x30a5, 0x4aa: 0x30a7, 0x4ab: 0x30a9,
+	0x4ac: 0x30e3, 0x4ad: 0x30e5, 0x4ae: 0x30e7, 0x4af: 0x30c3, 0x4b0: 0x30fc, 0x4b1: 0x30a2,
+	0x4b2: 0x30a4, 0x4b3: 0x30a6, 0x4b4: 0x30a8, 0x4b5: 0x30aa, 0x4b6: 0x30ab, 0x4b7: 0x30ad,
+	0x4b8: 0x30af, 0x4b9: 0x30b1, 0x4ba: 0x30b3, 0x4bb: 0x30b5, 0x4bc: 0x30b7, 0x4bd: 0x30b9,
+	0x4be: 0x30bb, 0x4bf: 0x30bd, 0x4c0: 0x30bf, 0x4c1: 0x30c1, 0x4c2: 0x30c4, 0x4c3: 0x30c6,
+	0x4c4: 0x30c8, 0x4c5: 0x30ca, 0x4c6: 0x30cb, 0x4c7: 0x30cc, 0x4c8: 0x30cd, 0x4c9: 0x30ce,
+	0x4ca: 0x30cf, 0x4cb: 0x30d2, 0x4cc: 0x30d5, 0x4cd: 0x30d8, 0x4ce: 0x30db, 0x4cf: 0x30de,
+	0x4d0: 0x30df, 0x4d1: 0x30e0, 0x4d2: 0x30e1, 0x4d3: 0x30e2, 0x4d4: 0x30e4, 0x4d5: 0x30e6,
+	0x4d6: 0x30e8, 0x4d7: 0x30e9, 0x4d8: 0x30ea, 0x4d9: 0x30eb, 0x4da: 0x30ec, 0x4db: 0x30ed,
+	0x4dc: 0x30ef, 0x4dd: 0x30f3, 0x4de: 0x309b, 0x4df: 0x309c,
+	// Arabic
+	0x5ac: 0x060c, 0x5bb: 0x061b, 0x5bf: 0x061f, 0x5c1: 0x0621, 0x5c2: 0x0622, 0x5c3: 0x0623,
+	0x5c4: 0x0624, 0x5c5: 0x0625, 0x5c6: 0x0626, 0x5c7: 0x0627, 0x5c8: 0x0628, 0x5c9: 0x0629,
+	0x5ca: 0x062a, 0x5cb: 0x062b, 0x5cc: 0x062c, 0x5cd: 0x062d, 0x5ce: 0x062e, 0x5cf: 0x062f,
+	0x5d0: 0x0630, 0x5d1: 0x0631, 0x5d2: 0x0632, 0x5d3: 0x0633, 0x5d4: 0x0634, 0x5d5: 0x0635,
+	0x5d6: 0x0636, 0x5d7: 0x0637, 0x5d8: 0x0638, 0x5d9: 0x0639, 0x5da: 0x063a, 0x5e0: 0x0640,
+	0x5e1: 0x0641, 0x5e2: 0x0642, 0x5e3: 0x0643, 0x5e4: 0x0644, 0x5e5: 0x0645, 0x5e6: 0x0646,
+	0x5e7: 0x0647, 0x5e8: 0x0648, 0x5e9: 0x0649, 0x5ea: 0x064a, 0x5eb: 0x064b, 0x5ec: 0x064c,
+	0x5ed: 0x064d, 0x5ee: 0x064e, 0x5ef: 0x064f, 0x5f0: 0x0650, 0x5f1: 0x0651, 0x5f2: 0x0652,
+	// Cyrillic
+	0x6a1: 0x0452, 0x6a2: 0x0453, 0x6a3: 0x0451, 0x6a4: 0x0454, 0x6a5: 0x0455, 0x6a6: 0x0456,
+	0x6a7: 0x0457, 0x6a8: 0x0458, 0x6a9: 0x0459, 0x6aa: 0x045a, 0x6ab: 0x045b, 0x6ac: 0x045c,
+	0x6ad: 0x0491, 0x6ae: 0x045e, 0x6af: 0x045f, 0x6b0: 0x2116, 0x6b1: 0x0402, 0x6b2: 0x0403,
+	0x6b3: 0x0401, 0x6b4: 0x0404, 0x6b5: 0x0405, 0x6b6: 0x0406, 0x6b7: 0x0407, 0x6b8: 0x0408,
+	0x6b9: 0x0409, 0x6ba: 0x040a, 0x6bb: 0x040b, 0x6bc: 0x040c, 0x6bd: 0x0490, 0x6be: 0x040e,
+	0x6bf: 0x040f, 0x6c0: 0x044e, 0x6c1: 0x0430, 0x6c2: 0x0431, 0x6c3: 0x0446, 0x6c4: 0x0434,
+	0x6c5: 0x0435, 0x6c6: 0x0444, 0x6c7: 0x0433, 0x6c8: 0x0445, 0x6c9: 0x0438, 0x6ca: 0x0439,
+	0x6cb: 0x043a, 0x6cc: 0x043b, 0x6cd: 0x043c, 0x6ce: 0x043d, 0x6cf: 0x043e, 0x6d0: 0x043f,
+	0x6d1: 0x044f, 0x6d2: 0x0440, 0x6d3: 0x0441, 0x6d4: 0x0442, 0x6d5: 0x0443, 0x6d6: 0x0436,
+	0x6d7: 0x0432, 0x6d8: 0x044c, 0x6d9: 0x044b, 0x6da: 0x0437, 0x6db: 0x0448, 0x6dc: 0x044d,
+	0x6dd: 0x0449, 0x6de: 0x0447, 0x6df: 0x044a, 0x6e0: 0x042e, 0x6e1: 0x0410, 0x6e2: 0x0411,
+	0x6e3: 0x0426, 0x6e4: 0x0414, 0x6e5: 0x0415, 0x6e6: 0x0424, 0x6e7: 0x0413, 0x6e8: 0x0425,
+	0x6e9: 0x0418, 0x6ea: 0x0419, 0x6eb: 0x041a, 0x6ec: 0x041b, 0x6ed: 0x041c, 0x6ee: 0x041d,
+	0x6ef: 0x041e, 0x6f0: 0x041f, 0x6f1: 0x042f, 0x6f2: 0x0420, 0x6f3: 0x0421, 0x6f4: 0x0422,
+	0x6f5: 0x0423, 0x6f6: 0x0416, 0x6f7: 0x0412, 0x6f8: 0x042c, 0x6f9: 0x042b, 0x6fa: 0x0417,
+	0x6fb: 0x0428, 0x6fc: 0x042d, 0x6fd: 0x0429, 0x6fe: 0x0427, 0x6ff: 0x042a,
+	// Greek
+	0x7a1: 0x0386, 0x7a2: 0x0388, 0x7a3: 0x0389, 0x7a4: 0x038a, 0x7a5: 0x03aa, 0x7a7: 0x038c,
+	0x7a8: 0x038e, 0x7a9: 0x03ab, 0x7ab: 0x038f, 0x7ae: 0x0385, 0x7af: 0x2015, 0x7b1: 0x03ac,
+	0x7b2: 0x03ad, 0x7b3: 0x03ae, 0x7b4: 0x03af, 0x7b5: 0x03ca, 0x7b6: 0x0390, 0x7b7: 0x03cc,
+	0x7b8: 0x03cd, 0x7b9: 0x03cb, 0x7ba: 0x03b0, 0x7bb: 0x03ce, 0x7c1: 0x0391, 0x7c2: 0x0392,
+	0x7c3: 0x0393, 0x7c4: 0x0394, 0x7c5: 0x0395, 0x7c6: 0x0396, 0x7c7: 0x0397, 0x7c8: 0x0398,
+	0x7c9: 0x0399, 0x7ca: 0x039a, 0x7cb: 0x039b, 0x7cc: 0x039c, 0x7cd: 0x039d, 0x7ce: 0x039e,
+	0x7cf: 0x039f, 0x7d0: 0x03a0, 0x7d1: 0x03a1, 0x7d2: 0x03a3, 0x7d4: 0x03a4, 0x7d5: 0x03a5,
+	0x7d6: 0x03a6, 0x7d7: 0x03a7, 0x7d8: 0x03a8, 0x7d9: 0x03a9, 0x7e1: 0x03b1, 0x7e2: 0x03b2,
+	0x7e3: 0x03b3, 0x7e4: 0x03b4, 0x7e5: 0x03b5, 0x7e6: 0x03b6, 0x7e7: 0x03b7, 0x7e8: 0x03b8,
+	0x7e9: 0x03b9, 0x7ea: 0x03ba, 0x7eb: 0x03bb, 0x7ec: 0x03bc, 0x7ed: 0x03bd, 0x7ee: 0x03be,
+	0x7ef: 0x03bf, 0x7f0: 0x03c0, 0x7f1: 0x03c1, 0x7f2: 0x03c3, 0x7f3: 0x03c2, 0x7f4: 0x03c4,
+	0x7f5: 0x03c5, 0x7f6: 0x03c6, 0x7f7: 0x03c7, 0x7f8: 0x03c8, 0x7f9: 0x03c9,
+	// Technical
+	0x8a1: 0x23b7, 0x8a4: 0x2320, 0x8a5: 0x2321, 0x8a7: 0x23a1, 0x8a8: 0x23a3, 0x8a9: 0x23a4,
+	0x8aa: 0x23a6, 0x8ab: 0x239b, 0x8ac: 0x239d, 0x8ad: 0x239e, 0x8ae: 0x23a0, 0x8af: 0x23a8,
+	0x8b0: 0x23ac, 0x8bc: 0x2264, 0x8bd: 0x2260, 0x8be: 0x2265, 0x8bf: 0x222b, 0x8c0: 0x2234,
+	0x8c1: 0x221d, 0x8c2: 0x221e, 0x8c5: 0x2207, 0x8c8: 0x223c, 0x8c9: 0x2243, 0x8cd: 0x21d4,
+	0x8ce: 0x21d2, 0x8cf: 0x2261, 0x8d6: 0x221a, 0x8da: 0x2282, 0x8db: 0x2283, 0x8dc: 0x2229,
+	0x8dd: 0x222a, 0x8de: 0x2227, 0x8df: 0x2228, 0x8ef: 0x2202, 0x8f6: 0x0192, 0x8fb: 0x2190,
+	0x8fc: 0x2191, 0x8fd: 0x2192, 0x8fe: 0x2193,
+	// Special
+	0x9e0: 0x25c6, 0x9e1: 0x2592, 0x9e2: 0x2409, 0x9e3: 0x240c, 0x9e4: 0x240d, 0x9e5: 0x240a,
+	0x9e8: 0x2424, 0x9e9: 0x240b, 0x9ea: 0x2518, 0x9eb: 0x2510, 0x9ec: 0x250c, 0x9ed: 0x2514,
+	0x9ee: 0x253c, 0x9ef: 0x23ba, 0x9f0: 0x23bb, 0x9f1: 0x2500, 0x9f2: 0x23bc, 0x9f3: 0x23bd,
+	0x9f4: 0x251c, 0x9f5: 0x2524, 0x9f6: 0x2534, 0x9f7: 0x252c, 0x9f8: 0x2502,
+	// Publishing
+	0xaa1: 0x2003, 0xaa2: 0x2002, 0xaa3: 0x2004, 0xaa4: 0x2005, 0xaa5: 0x2007, 0xaa6: 0x2008,
+	0xaa7: 0x2009, 0xaa8: 0x200a, 0xaa9: 0x2014, 0xaaa: 0x2013, 0xaae: 0x2026, 0xaaf: 0x2025,
+	0xab0: 0x2153, 0xab1: 0x2154, 0xab2: 0x2155, 0xab3: 0x2156, 0xab4: 0x2157, 0xab5: 0x2158,
+	0xab6: 0x2159, 0xab7: 0x215a, 0xab8: 0x2105, 0xabb: 0x2012, 0xac3: 0x215b, 0xac4: 0x215c,
+	0xac5: 0x215d, 0xac6: 0x215e, 0xac9: 0x2122, 0xad0: 0x2018, 0xad1: 0x2019, 0xad2: 0x201c,
+	0xad3: 0x201d, 0xad4: 0x211e, 0xad5: 0x2030, 0xad6: 0x2032, 0xad7: 0x2033, 0xad9: 0x271d,
+	0xaec: 0x2663, 0xaed: 0x2666, 0xaee: 0x2665, 0xaf0: 0x2720, 0xaf1: 0x2020, 0xaf2: 0x2021,
+	0xaf3: 0x2713, 0xaf4: 0x2717, 0xaf5: 0x266f, 0xaf6: 0x266d, 0xaf7: 0x2642, 0xaf8: 0x2640,
+	0xaf9: 0x260e, 0xafa: 0x2315, 0xafb: 0x2117, 0xafc: 0x2038, 0xafd: 0x201a, 0xafe: 0x201e,
+	// APL
+	0xbc2: 0x22a4, 0xbc4: 0x230a, 0xbca: 0x2218, 0xbcc: 0x2395, 0xbce: 0x22a5, 0xbcf: 0x25cb,
+	0xbd3: 0x2308, 0xbdc: 0x22a3, 0xbfc: 0x22a2,
+	// Hebrew
+	0xcdf: 0x2017, 0xce0: 0x05d0, 0xce1: 0x05d1, 0xce2: 0x05d2, 0xce3: 0x05d3, 0xce4: 0x05d4,
+	0xce5: 0x05d5, 0xce6: 0x05d6, 0xce7: 0x05d7, 0xce8: 0x05d8, 0xce9: 0x05d9, 0xcea: 0x05da,
+	0xceb: 0x05db, 0xcec: 0x05dc, 0xced: 0x05dd, 0xcee: 0x05de, 0xcef: 0x05df, 0xcf0: 0x05e0,
+	0xcf1: 0x05e1, 0xcf2: 0x05e2, 0xcf3: 0x05e3, 0xcf4: 0x05e4, 0xcf5: 0x05e5, 0xcf6: 0x05e6,
+	0xcf7: 0x05e7, 0xcf8: 0x05e8, 0xcf9: 0x05e9, 0xcfa: 0x05ea,
+	// Thai
+	0xda1: 0x0e01, 0xda2: 0x0e02, 0xda3: 0x0e03, 0xda4: 0x0e04, 0xda5: 0x0e05, 0xda6: 0x0e06,
+	0xda7: 0x0e07, 0xda8: 0x0e08, 0xda9: 0x0e09, 0xdaa: 0x0e0a, 0xdab: 0x0e0b, 0xdac: 0x0e0c,
+	0xdad: 0x0e0d, 0xdae: 0x0e0e, 0xdaf: 0x0e0f, 0xdb0: 0x0e10, 0xdb1: 0x0e11, 0xdb2: 0x0e12,
+	0xdb3: 0x0e13, 0xdb4: 0x0e14, 0xdb5: 0x0e15, 0xdb6: 0x0e16, 0xdb7: 0x0e17, 0xdb8: 0x0e18,
+	0xdb9: 0x0e19, 0xdba: 0x0e1a, 0xdbb: 0x0e1b, 0xdbc: 0x0e1c, 0xdbd: 0x0e1d, 0xdbe: 0x0e1e,
+	0xdbf: 0x0e1f, 0xdc0: 0x0e20, 0xdc1: 0x0e21, 0xdc2: 0x0e22, 0xdc3: 0x0e23, 0xdc4: 0x0e24,
+	0xdc5: 0x0e25, 0xdc6: 0x0e26, 0xdc7: 0x0e27, 0xdc8: 0x0e28, 0xdc9: 0x0e29, 0xdca: 0x0e2a,
+	0xdcb: 0x0e2b, 0xdcc: 0x0e2c, 0xdcd: 0x0e2d, 0xdce: 0x0e2e, 0xdcf: 0x0e2f, 0xdd0: 0x0e30,
+	0xdd1: 0x0e31, 0xdd2: 0x0e32, 0xdd3: 0x0e33, 0xdd4: 0x0e34, 0xdd5: 0x0e35, 0xdd6: 0x0e36,
+	0xdd7: 0x0e37, 0xdd8: 0x0e38, 0xdd9: 0x0e39, 0xdda: 0x0e3a, 0xddf: 0x0e3f, 0xde0: 0x0e40,
+	0xde1: 0x0e41, 0xde2: 0x0e42, 0xde3: 0x0e43, 0xde4: 0x0e44, 0xde5: 0x0e45, 0xde6: 0x0e46,
+	0xde7: 0x0e47, 0xde8: 0x0e48, 0xde9: 0x0e49, 0xdea: 0x0e4a, 0xdeb: 0x0e4b, 0xdec: 0x0e4c,
+	0xded: 0x0e4d, 0xdf0: 0x0e50, 0xdf1: 0x0e51, 0xdf2: 0x0e52, 0xdf3: 0x0e53, 0xdf4: 0x0e54,
+	0xdf5: 0x0e55, 0xdf6: 0x0e56, 0xdf7: 0x0e57, 0xdf8: 0x0e58, 0xdf9: 0x0e59,
+	// Korean
+	0xea1: 0x3131, 0xea2: 0x3132, 0xea3: 0x3133, 0xea4: 0x3134, 0xea5: 0x3135, 0xea6: 0x3136,
+	0xea7: 0x3137, 0xea8: 0x3138, 0xea9: 0x3139, 0xeaa: 0x313a, 0xeab: 0x313b, 0xeac: 0x313c,
+	0xead: 0x313d, 0xeae: 0x313e, 0xeaf: 0x313f, 0xeb0: 0x3140, 0xeb1: 0x3141, 0xeb2: 0x3142,
+	0xeb3: 0x3143, 0xeb4: 0x3144, 0xeb5: 0x3145, 0xeb6: 0x3146, 0xeb7: 0x3147, 0xeb8: 0x3148,
+	0xeb9: 0x3149, 0xeba: 0x314a, 0xebb: 0x314b, 0xebc: 0x314c, 0xebd: 0x314d, 0xebe: 0x314e,
+	0xebf: 0x314f, 0xec0: 0x3150, 0xec1: 0x3151, 0xec2: 0x3152, 0xec3: 0x3153, 0xec4: 0x3154,
+	0xec5: 0x3155, 0xec6: 0x3156, 0xec7: 0x3157, 0xec8: 0x3158, 0xec9: 0x3159, 0xeca: 0x315a,
+	0xecb: 0x315b, 0xecc: 0x315c, 0xecd: 0x315d, 0xece: 0x315e, 0xecf: 0x315f, 0xed0: 0x3160,
+	0xed1: 0x3161, 0xed2: 0x3162, 0xed3: 0x3163, 0xed4: 0x11a8, 0xed5: 0x11a9, 0xed6: 0x11aa,
+	0xed7: 0x11ab, 0xed8: 0x11ac, 0xed9: 0x11ad, 0xeda: 0x11ae, 0xedb: 0x11af, 0xedc: 0x11b0,
+	0xedd: 0x11b1, 0xede: 0x11b2, 0xedf: 0x11b3, 0xee0: 0x11b4, 0xee1: 0x11b5, 0xee2: 0x11b6,
+	0xee3: 0x11b7, 0xee4: 0x11b8, 0xee5: 0x11b9, 0xee6: 0x11ba, 0xee7: 0x11bb, 0xee8: 0x11bc,
+	0xee9: 0x11bd, 0xeea: 0x11be, 0xeeb: 0x11bf, 0xeec: 0x11c0, 0xeed: 0x11c1, 0xeee: 0x11c2,
+	0xeef: 0x316d, 0xef0: 0x3171, 0xef1: 0x3178, 0xef2: 0x317f, 0xef3: 0x3181, 0xef4: 0x3184,
+	0xef5: 0x3186, 0xef6: 0x318d, 0xef7: 0x318e, 0xef8: 0x11eb, 0xef9: 0x11f0, 0xefa: 0x11f9,
+	// Latin-9
+	0x13bc: 0x0152, 0x13bd: 0x0153, 0x13be: 0x0178,
+	// Currency
+	0x20ac: 0x20ac,
+}
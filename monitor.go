@@ -0,0 +1,142 @@
+package glow
+
+import (
+	"errors"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// ScreenMode is a resolution and refresh rate the display hardware
+// supports.
+type ScreenMode struct {
+	Width, Height int
+	Refresh       float64 // Hz, 0 if unknown
+	Current       bool    // In use by at least one monitor
+}
+
+// Monitor is an active output, positioned in the screen's coordinate
+// space (the root window), e.g. a second monitor to the right of a
+// 1920-wide first one has X == 1920.
+type Monitor struct {
+	X, Y, Width, Height int
+	Refresh             float64 // Hz, 0 if unknown
+}
+
+// errNoMonitor is returned by CurrentMonitor when RANDR lists no active
+// outputs, as happens on some virtual servers.
+var errNoMonitor = errors.New("glow: no active monitor")
+
+// ScreenModes lists the modes the screen's outputs support, using the
+// RANDR extension. It fails if the server lacks RANDR 1.2.
+func (w *Window) ScreenModes() ([]ScreenMode, error) {
+	res, err := w.conn.GetScreenResources()
+	if err != nil {
+		return nil, err
+	}
+	monitors, err := w.monitors(res)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[uint32]bool)
+	for _, m := range monitors {
+		current[m.mode] = true
+	}
+	modes := make([]ScreenMode, 0, len(res.Modes))
+	for _, m := range res.Modes {
+		modes = append(modes, ScreenMode{
+			Width:   int(m.Width),
+			Height:  int(m.Height),
+			Refresh: m.Refresh(),
+			Current: current[m.ID],
+		})
+	}
+	return modes, nil
+}
+
+// Monitors lists the active monitors, using the RANDR extension. It
+// fails if the server lacks RANDR 1.2.
+func (w *Window) Monitors() ([]Monitor, error) {
+	res, err := w.conn.GetScreenResources()
+	if err != nil {
+		return nil, err
+	}
+	monitors, err := w.monitors(res)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Monitor, len(monitors))
+	for i, m := range monitors {
+		out[i] = m.Monitor
+	}
+	return out, nil
+}
+
+// CurrentMonitor returns the monitor showing the centre of the window,
+// or the nearest one if the centre is off every monitor. Use it to
+// place or size a window on the right screen of a multi-monitor setup.
+func (w *Window) CurrentMonitor() (Monitor, error) {
+	monitors, err := w.Monitors()
+	if err != nil {
+		return Monitor{}, err
+	}
+	if len(monitors) == 0 {
+		return Monitor{}, errNoMonitor
+	}
+
+	x, y, err := w.conn.TranslateCoordinates(w.windowID, w.conn.RootWindow, 0, 0)
+	if err != nil {
+		return Monitor{}, err
+	}
+	return nearestMonitor(monitors, int(x)+w.width/2, int(y)+w.height/2), nil
+}
+
+// nearestMonitor returns the monitor containing (x, y), or else the one
+// whose edge is closest to it.
+func nearestMonitor(monitors []Monitor, x, y int) Monitor {
+	best, bestDist := monitors[0], -1
+	for _, m := range monitors {
+		dx := max(m.X-x, 0, x-(m.X+m.Width-1))
+		dy := max(m.Y-y, 0, y-(m.Y+m.Height-1))
+		if d := dx*dx + dy*dy; bestDist < 0 || d < bestDist {
+			best, bestDist = m, d
+		}
+	}
+	return best
+}
+
+// activeMonitor is a Monitor with the RANDR mode it is scanning out.
+type activeMonitor struct {
+	Monitor
+	mode uint32
+}
+
+// monitors queries every CRTC and returns those that are switched on.
+func (w *Window) monitors(res *x11.ScreenResources) ([]activeMonitor, error) {
+	refresh := make(map[uint32]float64, len(res.Modes))
+	for _, m := range res.Modes {
+		refresh[m.ID] = m.Refresh()
+	}
+
+	var out []activeMonitor
+	for _, crtc := range res.Crtcs {
+		info, err := w.conn.GetCrtcInfo(crtc, res.ConfigTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode == 0 || info.Width == 0 || info.Height == 0 {
+			continue
+		}
+		out = append(out, activeMonitor{
+			Monitor: Monitor{
+				X:       int(info.X),
+				Y:       int(info.Y),
+				Width:   int(info.Width),
+				Height:  int(info.Height),
+				Refresh: refresh[info.Mode],
+			},
+			mode: info.Mode,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,40 @@
+package glow
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestModeRefresh(t *testing.T) {
+	// CEA 1920x1080@60: 148.5 MHz, 2200x1125 total
+	m := x11.ModeInfo{DotClock: 148500000, HTotal: 2200, VTotal: 1125}
+	if r := m.Refresh(); math.Abs(r-60) > 1e-9 {
+		t.Errorf("Refresh = %v, want 60", r)
+	}
+	m.Flags = x11.ModeInterlace
+	if r := m.Refresh(); math.Abs(r-120) > 1e-9 {
+		t.Errorf("interlaced Refresh = %v, want 120 fields/s", r)
+	}
+	if r := (x11.ModeInfo{DotClock: 1}).Refresh(); r != 0 {
+		t.Errorf("Refresh without timings = %v, want 0", r)
+	}
+}
+
+func TestNearestMonitor(t *testing.T) {
+	left := Monitor{X: 0, Y: 0, Width: 1920, Height: 1080}
+	right := Monitor{X: 1920, Y: 0, Width: 1280, Height: 1024}
+	monitors := []Monitor{left, right}
+
+	if m := nearestMonitor(monitors, 2000, 500); m != right {
+		t.Errorf("point on the right monitor picked %+v", m)
+	}
+	if m := nearestMonitor(monitors, 1919, 1079); m != left {
+		t.Errorf("bottom-right pixel of the left monitor picked %+v", m)
+	}
+	// Below the shorter right monitor, but nearer to it than the left
+	if m := nearestMonitor(monitors, 3000, 1100); m != right {
+		t.Errorf("off-screen point picked %+v", m)
+	}
+}
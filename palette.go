@@ -0,0 +1,157 @@
+package glow
+
+// Palette is a table of colors for drawing indexed sprites. Changing an
+// entry changes every pixel drawn with that index the next time the
+// sprite is drawn, which is what palette cycling animates.
+type Palette []Color
+
+// Rotate shifts entries start to start+count-1 up by one place, the
+// last moving to start, so colors flow along the range: run it on a
+// timer (see PaletteAnimator) to animate water, fire or marquees
+// without touching the sprites. A negative count rotates the other
+// way. The range is clamped to the palette.
+func (p Palette) Rotate(start, count int) {
+	down := count < 0
+	if down {
+		count = -count
+	}
+	if start < 0 {
+		count += start
+		start = 0
+	}
+	count = min(count, len(p)-start)
+	if count < 2 {
+		return
+	}
+
+	r := p[start : start+count]
+	if down {
+		first := r[0]
+		copy(r, r[1:])
+		r[count-1] = first
+	} else {
+		last := r[count-1]
+		copy(r[1:], r)
+		r[0] = last
+	}
+}
+
+// IndexedSprite is an image of palette indices, drawn with
+// Canvas.DrawSpriteWithPalette.
+type IndexedSprite struct {
+	Width, Height int
+	Pix           []uint8 // One index per pixel, row by row
+}
+
+// NewIndexedSprite returns a w×h indexed sprite, all index 0.
+func NewIndexedSprite(w, h int) *IndexedSprite {
+	w, h = max(w, 0), max(h, 0)
+	return &IndexedSprite{Width: w, Height: h, Pix: make([]uint8, w*h)}
+}
+
+// Index returns the palette index at (x, y), or 0 outside the sprite.
+func (s *IndexedSprite) Index(x, y int) uint8 {
+	if x < 0 || x >= s.Width || y < 0 || y >= s.Height {
+		return 0
+	}
+	return s.Pix[y*s.Width+x]
+}
+
+// SetIndex sets the palette index at (x, y). Points outside the sprite
+// are ignored.
+func (s *IndexedSprite) SetIndex(x, y int, i uint8) {
+	if x < 0 || x >= s.Width || y < 0 || y >= s.Height {
+		return
+	}
+	s.Pix[y*s.Width+x] = i
+}
+
+// DrawSpriteWithPalette draws s with its top-left corner at (x, y),
+// looking each pixel's color up in p. Colors with an alpha of 0, and
+// indices past the end of p, are transparent; other translucent colors
+// are blended.
+func (c *Canvas) DrawSpriteWithPalette(s *IndexedSprite, p Palette, x, y int) {
+	if s == nil || len(s.Pix) != s.Width*s.Height {
+		return
+	}
+	fy := c.flipBox(y, s.Height)
+	c.fb.SetOpacity(255) // Each color's alpha is applied below
+	for row := 0; row < s.Height; row++ {
+		indices := s.Pix[row*s.Width : (row+1)*s.Width]
+		for col, i := range indices {
+			if int(i) >= len(p) {
+				continue
+			}
+			switch color := p[i]; color.A {
+			case 0:
+			case 255:
+				c.fb.SetPixel(x+col, fy+row, color.R, color.G, color.B)
+			default:
+				c.fb.BlendPixel(x+col, fy+row, color.R, color.G, color.B, color.A)
+			}
+		}
+	}
+	c.markDirty(x, fy, s.Width, s.Height)
+}
+
+// PaletteAnimator rotates ranges of a palette on timers, for palette
+// cycling. Advance it by the frame time, then redraw the indexed
+// sprites:
+//
+//	anim := glow.NewPaletteAnimator(pal)
+//	anim.AddCycle(16, 8, 0.1) // Water: entries 16-23, 10 steps a second
+//	for running {
+//		anim.Update(clock.Tick())
+//		canvas.DrawSpriteWithPalette(waterfall, pal, 0, 0)
+//		...
+//	}
+type PaletteAnimator struct {
+	Palette Palette
+	cycles  []paletteCycle
+}
+
+// paletteCycle is one range animated by a PaletteAnimator
+type paletteCycle struct {
+	start, count int
+	interval     float64 // Seconds per step
+	elapsed      float64 // Seconds since the last step
+}
+
+// NewPaletteAnimator returns an animator for p, which it rotates in
+// place.
+func NewPaletteAnimator(p Palette) *PaletteAnimator {
+	return &PaletteAnimator{Palette: p}
+}
+
+// AddCycle rotates count entries from start (see Palette.Rotate) one
+// step every interval seconds. Ranges may have different speeds, and a
+// negative count cycles backwards.
+func (a *PaletteAnimator) AddCycle(start, count int, interval float64) {
+	if interval <= 0 {
+		return
+	}
+	a.cycles = append(a.cycles, paletteCycle{start: start, count: count, interval: interval})
+}
+
+// Update advances the timers by dt seconds, rotating each range once
+// for every interval that elapsed. It reports whether the palette
+// changed, so a scene that is otherwise still only needs redrawing
+// when it did.
+func (a *PaletteAnimator) Update(dt float64) bool {
+	changed := false
+	for i := range a.cycles {
+		cy := &a.cycles[i]
+		cy.elapsed += dt
+		steps := int(cy.elapsed / cy.interval)
+		if steps == 0 {
+			continue
+		}
+		cy.elapsed -= float64(steps) * cy.interval
+		// A full turn leaves the range as it was
+		for range steps % max(cy.count, -cy.count, 1) {
+			a.Palette.Rotate(cy.start, cy.count)
+		}
+		changed = true
+	}
+	return changed
+}
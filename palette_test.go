@@ -0,0 +1,73 @@
+package glow
+
+import "testing"
+
+func TestPaletteRotate(t *testing.T) {
+	mk := func() Palette {
+		p := make(Palette, 6)
+		for i := range p {
+			p[i] = RGB(uint8(i), 0, 0)
+		}
+		return p
+	}
+	order := func(p Palette) []uint8 {
+		var r []uint8
+		for _, c := range p {
+			r = append(r, c.R)
+		}
+		return r
+	}
+	for _, tt := range []struct {
+		start, count int
+		want         []uint8
+	}{
+		{1, 3, []uint8{0, 3, 1, 2, 4, 5}},
+		{1, -3, []uint8{0, 2, 3, 1, 4, 5}},
+		{4, 10, []uint8{0, 1, 2, 3, 5, 4}}, // Clamped to the end
+		{-1, 3, []uint8{1, 0, 2, 3, 4, 5}}, // Clamped to the start
+		{2, 1, []uint8{0, 1, 2, 3, 4, 5}},
+	} {
+		p := mk()
+		p.Rotate(tt.start, tt.count)
+		if got := order(p); string(got) != string(tt.want) {
+			t.Errorf("Rotate(%d, %d) = %v, want %v", tt.start, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestDrawSpriteWithPalette(t *testing.T) {
+	pal := Palette{RGBA(0, 0, 0, 0), Red, Blue}
+	s := NewIndexedSprite(3, 1)
+	s.SetIndex(1, 0, 1)
+	s.SetIndex(2, 0, 2)
+	s.SetIndex(5, 0, 1) // Outside: ignored
+
+	c := NewCanvas(8, 8)
+	c.Clear(White)
+	c.DrawSpriteWithPalette(s, pal, 2, 3)
+	for x, want := range []Color{White, White, White, Red, Blue, White} {
+		if got := c.GetPixel(x, 3); got != want {
+			t.Errorf("pixel %d = %v, want %v", x, got, want)
+		}
+	}
+
+	// Cycling the palette recolors the next draw
+	anim := NewPaletteAnimator(pal)
+	anim.AddCycle(1, 2, 0.1)
+	if anim.Update(0.05) {
+		t.Error("palette changed before the interval")
+	}
+	if !anim.Update(0.06) {
+		t.Error("palette didn't change after the interval")
+	}
+	c.DrawSpriteWithPalette(s, pal, 2, 3)
+	if c.GetPixel(3, 3) != Blue || c.GetPixel(4, 3) != Red {
+		t.Errorf("after cycling, pixels are %v, %v", c.GetPixel(3, 3), c.GetPixel(4, 3))
+	}
+
+	// A long pause rotates by the steps that elapsed, modulo a full turn
+	anim.Update(0.3) // 3 steps of a 2-entry range: one swap
+	if pal[1] != Red || pal[2] != Blue {
+		t.Errorf("after 3 more steps palette is %v", pal)
+	}
+}
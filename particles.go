@@ -0,0 +1,239 @@
+package glow
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// EmitterShape is where an Emitter spawns its particles.
+type EmitterShape int
+
+const (
+	// EmitPoint spawns particles at (X, Y), heading in Angle give or
+	// take Spread/2.
+	EmitPoint EmitterShape = iota
+	// EmitLine spawns particles anywhere on the segment from (X, Y) to
+	// (X2, Y2), heading in Angle give or take Spread/2: fire along a
+	// log, rain along the top of the screen.
+	EmitLine
+	// EmitRadial spawns particles on the circle of Radius around (X, Y),
+	// heading straight out from the centre: explosions and rings. A
+	// Radius of 0 spawns them all at the centre.
+	EmitRadial
+)
+
+// Emitter describes a stream of particles. Every value is in pixels and
+// seconds, and the *Var fields randomize the value before them by up to
+// that much either way. Fields can be changed between Updates, to move
+// the emitter with the mouse for example.
+type Emitter struct {
+	Shape  EmitterShape
+	X, Y   float64
+	X2, Y2 float64 // Other end of an EmitLine
+	Radius float64 // Circle of an EmitRadial
+
+	// Rate is the particles spawned per second. 0 spawns none
+	// (ParticleSystem.Burst still does).
+	Rate float64
+
+	// Angle is the direction particles head in, in radians: 0 is right
+	// and math.Pi/2 is down the canvas (up with Canvas.SetYUp). Spread is
+	// the width of the cone around it. Radial emitters ignore both.
+	Angle, Spread float64
+
+	Speed, SpeedVar float64 // Pixels per second
+	Life, LifeVar   float64 // Seconds
+
+	// Size is the glow radius of a new particle, and EndSize its radius
+	// when it dies; it changes linearly in between.
+	Size, SizeVar float64
+	EndSize       float64
+
+	// StartColor fades to EndColor over each particle's life, alpha
+	// included, so an EndColor with alpha 0 fades particles out.
+	StartColor, EndColor Color
+
+	// Gravity accelerates particles down the canvas, in pixels per
+	// second squared; negative values make them rise. Drag is the
+	// fraction of their speed they lose per second.
+	Gravity, Drag float64
+
+	pending float64 // Fraction of a particle owed by Rate
+}
+
+// particle is one live particle of a ParticleSystem
+type particle struct {
+	x, y, vx, vy  float64
+	age, life     float64
+	size, endSize float64
+	start, end    Color
+	gravity, drag float64
+}
+
+// ParticleSystem animates and draws the particles of its emitters, up to
+// a fixed number at a time:
+//
+//	ps := glow.NewParticleSystem(5000)
+//	ps.AddEmitter(&glow.Emitter{
+//		X: 400, Y: 550, Rate: 600,
+//		Angle: -math.Pi / 2, Spread: 0.5, Speed: 450, SpeedVar: 150,
+//		Life: 2, Size: 5, StartColor: glow.RGB(80, 200, 255),
+//		EndColor: glow.RGBA(80, 200, 255, 0), Gravity: 700,
+//	})
+//	for running {
+//		ps.Update(clock.Tick())
+//		canvas.Clear(glow.Black)
+//		ps.Draw(canvas)
+//		win.Present()
+//	}
+type ParticleSystem struct {
+	Emitters  []*Emitter
+	particles []particle
+	max       int
+	rng       *rand.Rand
+}
+
+// NewParticleSystem returns a system holding at most maxParticles live
+// particles; emitters pause while it is full.
+func NewParticleSystem(maxParticles int) *ParticleSystem {
+	return &ParticleSystem{
+		particles: make([]particle, 0, max(maxParticles, 0)),
+		max:       max(maxParticles, 0),
+		rng:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// SetSeed makes the random variations repeat from run to run, for tests
+// and replays.
+func (ps *ParticleSystem) SetSeed(seed uint64) {
+	ps.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// AddEmitter adds e to the system and returns it.
+func (ps *ParticleSystem) AddEmitter(e *Emitter) *Emitter {
+	ps.Emitters = append(ps.Emitters, e)
+	return e
+}
+
+// RemoveEmitter stops e spawning particles. Those already spawned live
+// out their lives.
+func (ps *ParticleSystem) RemoveEmitter(e *Emitter) {
+	for i, em := range ps.Emitters {
+		if em == e {
+			ps.Emitters = append(ps.Emitters[:i], ps.Emitters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Burst spawns n particles from e at once, whatever its Rate, as for an
+// explosion. e doesn't have to be one of the system's emitters.
+func (ps *ParticleSystem) Burst(e *Emitter, n int) {
+	for range n {
+		if !ps.spawn(e) {
+			return
+		}
+	}
+}
+
+// Count returns the number of live particles.
+func (ps *ParticleSystem) Count() int { return len(ps.particles) }
+
+// Clear removes every particle.
+func (ps *ParticleSystem) Clear() { ps.particles = ps.particles[:0] }
+
+// Update advances the system by dt seconds: particles age, move and die,
+// and emitters spawn new ones.
+func (ps *ParticleSystem) Update(dt float64) {
+	if dt <= 0 {
+		return
+	}
+
+	// Dead particles are replaced by the last one, keeping the live
+	// ones packed at the front
+	for i := 0; i < len(ps.particles); {
+		p := &ps.particles[i]
+		p.age += dt
+		if p.age >= p.life {
+			last := len(ps.particles) - 1
+			ps.particles[i] = ps.particles[last]
+			ps.particles = ps.particles[:last]
+			continue
+		}
+		p.vy += p.gravity * dt
+		if p.drag > 0 {
+			keep := max(1-p.drag*dt, 0)
+			p.vx *= keep
+			p.vy *= keep
+		}
+		p.x += p.vx * dt
+		p.y += p.vy * dt
+		i++
+	}
+
+	for _, e := range ps.Emitters {
+		e.pending += e.Rate * dt
+		n := int(e.pending)
+		e.pending -= float64(n)
+		ps.Burst(e, n)
+	}
+}
+
+// spawn adds one particle from e, returning false if the system is full.
+func (ps *ParticleSystem) spawn(e *Emitter) bool {
+	if len(ps.particles) >= ps.max {
+		return false
+	}
+
+	p := particle{
+		x: e.X, y: e.Y,
+		life:    max(ps.vary(e.Life, e.LifeVar), 0),
+		size:    max(ps.vary(e.Size, e.SizeVar), 0),
+		endSize: e.EndSize,
+		start:   e.StartColor,
+		end:     e.EndColor,
+		gravity: e.Gravity,
+		drag:    e.Drag,
+	}
+	angle := e.Angle + (ps.rng.Float64()-0.5)*e.Spread
+	switch e.Shape {
+	case EmitLine:
+		t := ps.rng.Float64()
+		p.x += (e.X2 - e.X) * t
+		p.y += (e.Y2 - e.Y) * t
+	case EmitRadial:
+		angle = ps.rng.Float64() * 2 * math.Pi
+		p.x += e.Radius * math.Cos(angle)
+		p.y += e.Radius * math.Sin(angle)
+	}
+	speed := ps.vary(e.Speed, e.SpeedVar)
+	p.vx, p.vy = speed*math.Cos(angle), speed*math.Sin(angle)
+
+	ps.particles = append(ps.particles, p)
+	return true
+}
+
+// vary returns v randomized by up to spread either way.
+func (ps *ParticleSystem) vary(v, spread float64) float64 {
+	if spread == 0 {
+		return v
+	}
+	return v + (ps.rng.Float64()*2-1)*spread
+}
+
+// Draw draws every particle as a glow (see Canvas.DrawGlow), so dense
+// clusters add up to bright cores. Draw over a dark background.
+func (ps *ParticleSystem) Draw(c *Canvas) {
+	for i := range ps.particles {
+		p := &ps.particles[i]
+		t := 0.0
+		if p.life > 0 {
+			t = p.age / p.life
+		}
+		radius := int(p.size + (p.endSize-p.size)*t + 0.5)
+		if radius < 1 {
+			continue
+		}
+		c.DrawGlow(int(math.Round(p.x)), int(math.Round(p.y)), radius, lerpColor(p.start, p.end, t))
+	}
+}
@@ -0,0 +1,117 @@
+package glow
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParticleEmission(t *testing.T) {
+	ps := NewParticleSystem(100)
+	ps.SetSeed(1)
+	ps.AddEmitter(&Emitter{X: 50, Y: 50, Rate: 30, Life: 1})
+
+	// Rate carries fractions over between frames
+	for range 16 {
+		ps.Update(1.0 / 64)
+	}
+	if n := ps.Count(); n != 7 {
+		t.Errorf("after 0.25s at 30/s, %d particles, want 7", n)
+	}
+
+	// Particles die at the end of their life while new ones arrive
+	for range 100 {
+		ps.Update(0.02)
+	}
+	if n := ps.Count(); n < 29 || n > 31 {
+		t.Errorf("steady state %d particles, want about 30", n)
+	}
+
+	// The system never holds more than its maximum
+	ps.Burst(ps.Emitters[0], 500)
+	if n := ps.Count(); n != 100 {
+		t.Errorf("after a burst, %d particles, want the maximum 100", n)
+	}
+	ps.Clear()
+	ps.RemoveEmitter(ps.Emitters[0])
+	ps.Update(1)
+	if n := ps.Count(); n != 0 {
+		t.Errorf("%d particles after Clear and RemoveEmitter", n)
+	}
+}
+
+func TestParticleMotion(t *testing.T) {
+	ps := NewParticleSystem(10)
+	e := &Emitter{X: 10, Y: 20, Angle: 0, Speed: 100, Life: 10, Gravity: 50}
+	ps.Burst(e, 1)
+
+	for range 100 {
+		ps.Update(0.01)
+	}
+	p := ps.particles[0]
+	if math.Abs(p.x-110) > 1e-9 {
+		t.Errorf("x after 1s at 100px/s = %v, want 110", p.x)
+	}
+	// Semi-implicit Euler: v grows first, so y is a little ahead of
+	// 20 + 50/2
+	if math.Abs(p.vy-50) > 1e-9 || p.y < 45 || p.y > 45.5 {
+		t.Errorf("after 1s of gravity, vy = %v and y = %v, want 50 and about 45", p.vy, p.y)
+	}
+
+	// Drag slows particles down
+	ps.Clear()
+	ps.Burst(&Emitter{Speed: 100, Life: 10, Drag: 0.5}, 1)
+	ps.Update(1)
+	if vx := ps.particles[0].vx; math.Abs(vx-50) > 1e-9 {
+		t.Errorf("vx after 1s of 0.5 drag = %v, want 50", vx)
+	}
+}
+
+func TestEmitterShapes(t *testing.T) {
+	ps := NewParticleSystem(200)
+	ps.SetSeed(7)
+
+	ps.Burst(&Emitter{Shape: EmitRadial, X: 100, Y: 100, Radius: 20, Speed: 10, Life: 1}, 50)
+	for _, p := range ps.particles {
+		dx, dy := p.x-100, p.y-100
+		if d := math.Hypot(dx, dy); math.Abs(d-20) > 1e-9 {
+			t.Fatalf("radial particle at distance %v, want 20", d)
+		}
+		// Heading straight out: velocity parallel to the offset
+		if math.Abs(dx*p.vy-dy*p.vx) > 1e-6 || dx*p.vx+dy*p.vy <= 0 {
+			t.Fatalf("radial particle at (%v, %v) heading (%v, %v)", dx, dy, p.vx, p.vy)
+		}
+	}
+
+	ps.Clear()
+	ps.Burst(&Emitter{Shape: EmitLine, X: 0, Y: 10, X2: 300, Y2: 10,
+		Angle: math.Pi / 2, Spread: 0.2, Speed: 10, Life: 1}, 50)
+	for _, p := range ps.particles {
+		if p.y != 10 || p.x < 0 || p.x > 300 {
+			t.Fatalf("line particle at (%v, %v), off the line", p.x, p.y)
+		}
+		if a := math.Atan2(p.vy, p.vx); math.Abs(a-math.Pi/2) > 0.1+1e-9 {
+			t.Fatalf("line particle heading %v, outside the spread", a)
+		}
+	}
+}
+
+func TestParticleDraw(t *testing.T) {
+	ps := NewParticleSystem(10)
+	e := &Emitter{X: 8, Y: 8, Life: 1, Size: 4, EndSize: 4,
+		StartColor: Red, EndColor: RGBA(0, 0, 255, 0)}
+	ps.Burst(e, 1)
+
+	c := NewCanvas(16, 16)
+	ps.Draw(c)
+	if got := c.GetPixel(8, 8); got.R != 255 || got.B != 0 {
+		t.Errorf("new particle's centre = %v, want red", got)
+	}
+
+	// Halfway through its life, half red and half blue at half strength
+	ps.Update(0.5)
+	c.Clear(Black)
+	ps.Draw(c)
+	if got := c.GetPixel(8, 8); got.R != 64 || got.B != 64 {
+		t.Errorf("halfway particle's centre = %v, want (64, 0, 64)", got)
+	}
+}
@@ -0,0 +1,97 @@
+package glow
+
+import "errors"
+
+// Pixmap is an image stored on the X server. Art uploaded into it once
+// can be copied to the window any number of times without sending the
+// pixels again, which suits icon caches and static backgrounds.
+//
+// Pixmaps are freed when their window closes, and are lost (not
+// recreated) if the window reconnects.
+type Pixmap struct {
+	win    *Window
+	id     uint32
+	width  int
+	height int
+}
+
+// NewPixmap creates a width×height pixmap for use with this window.
+// Its initial contents are undefined.
+func (w *Window) NewPixmap(width, height int) (*Pixmap, error) {
+	if width <= 0 || height <= 0 || width > 0xFFFF || height > 0xFFFF {
+		return nil, errors.New("glow: invalid pixmap size")
+	}
+	id, err := w.conn.CreatePixmap(w.windowID, uint16(width), uint16(height), w.Depth())
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pixmap{win: w, id: id, width: width, height: height}
+	if w.pixmaps == nil {
+		w.pixmaps = make(map[*Pixmap]struct{})
+	}
+	w.pixmaps[p] = struct{}{}
+	return p, nil
+}
+
+// Width returns the pixmap width in pixels.
+func (p *Pixmap) Width() int { return p.width }
+
+// Height returns the pixmap height in pixels.
+func (p *Pixmap) Height() int { return p.height }
+
+// PutCanvas uploads the whole canvas into the pixmap at (x, y).
+func (p *Pixmap) PutCanvas(c *Canvas, x, y int) error {
+	return p.put(c.fb.Width, c.fb.Height, x, y, c.fb.Pixels)
+}
+
+// PutSprite uploads a sprite into the pixmap at (x, y). Pixmaps have no
+// alpha channel, so the sprite's transparency is ignored.
+func (p *Pixmap) PutSprite(s *Sprite, x, y int) error {
+	return p.put(s.data.Width, s.data.Height, x, y, s.data.Pixels)
+}
+
+func (p *Pixmap) put(width, height, x, y int, pixels []byte) error {
+	if p.id == 0 {
+		return errors.New("glow: pixmap is freed")
+	}
+	if width == 0 || height == 0 {
+		return nil
+	}
+	return p.win.conn.PutImage(p.id, p.win.gcID, uint16(width), uint16(height),
+		int16(x), int16(y), p.win.Depth(), pixels)
+}
+
+// CopyTo draws the whole pixmap onto the window at (x, y). It draws on
+// the window directly, so call it after Present; the next Present
+// covers it again.
+func (p *Pixmap) CopyTo(x, y int) error {
+	return p.CopyRegionTo(0, 0, p.width, p.height, x, y)
+}
+
+// CopyRegionTo draws the width×height area at (srcX, srcY) of the pixmap
+// onto the window at (dstX, dstY).
+func (p *Pixmap) CopyRegionTo(srcX, srcY, width, height, dstX, dstY int) error {
+	if p.id == 0 {
+		return errors.New("glow: pixmap is freed")
+	}
+	return p.win.conn.CopyArea(p.id, p.win.windowID, p.win.gcID,
+		int16(srcX), int16(srcY), int16(dstX), int16(dstY), uint16(width), uint16(height))
+}
+
+// Free releases the pixmap on the server. It is safe to call twice.
+func (p *Pixmap) Free() {
+	if p.id == 0 {
+		return
+	}
+	p.win.conn.FreePixmap(p.id)
+	delete(p.win.pixmaps, p)
+	p.id = 0
+}
+
+// freePixmaps releases every pixmap still held by the window.
+func (w *Window) freePixmaps() {
+	for p := range w.pixmaps {
+		p.Free()
+	}
+}
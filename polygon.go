@@ -0,0 +1,46 @@
+package glow
+
+import "image"
+
+// DrawPolygon draws the outline of the polygon with the given vertices,
+// closed back to the first. Two points draw a line; fewer draw nothing.
+func (c *Canvas) DrawPolygon(points []image.Point, color Color) {
+	if len(points) < 2 {
+		return
+	}
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		c.DrawLine(p.X, p.Y, q.X, q.Y, color)
+		if len(points) == 2 {
+			break
+		}
+	}
+}
+
+// FillPolygon fills the polygon with the given vertices using the
+// even-odd rule, so concave shapes such as stars and arrows fill
+// correctly and self-intersecting ones leave their overlaps empty. Two
+// points draw a line; fewer draw nothing. With antialiasing on, the
+// edges are blended into the pixels just outside the fill.
+func (c *Canvas) FillPolygon(points []image.Point, color Color) {
+	if len(points) < 2 {
+		return
+	}
+	c.fb.SetOpacity(color.A)
+	flipped := make([]image.Point, len(points))
+	xy := make([]int, 0, 2*len(points))
+	for i, p := range points {
+		flipped[i] = image.Point{X: p.X, Y: c.flipY(p.Y)}
+		xy = append(xy, p.X, flipped[i].Y)
+	}
+	c.fb.FillPolygon(flipped, color.R, color.G, color.B)
+	if !c.aa {
+		c.markDirtyPoints(0, xy...)
+		return
+	}
+	for i, p := range flipped {
+		q := flipped[(i+1)%len(flipped)]
+		c.fb.DrawLineAA(p.X, p.Y, q.X, q.Y, color.R, color.G, color.B)
+	}
+	c.markDirtyPoints(1, xy...)
+}
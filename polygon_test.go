@@ -0,0 +1,101 @@
+package glow
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestFillPolygonArrow(t *testing.T) {
+	c := NewCanvas(80, 60)
+	// A right-pointing arrow: a shaft with a triangular head, concave
+	// where the two meet
+	arrow := []image.Point{{10, 20}, {40, 20}, {40, 10}, {60, 30}, {40, 50}, {40, 40}, {10, 40}}
+	c.FillPolygon(arrow, White)
+
+	probes := []struct {
+		x, y   int
+		inside bool
+	}{
+		{20, 30, true},  // Shaft
+		{10, 20, true},  // Top-left corner
+		{50, 30, true},  // Head
+		{45, 20, true},  // Head, above the shaft
+		{30, 15, false}, // Notch above the shaft
+		{30, 45, false}, // Notch below the shaft
+		{45, 12, false}, // Outside the head's upper edge
+		{5, 30, false},  // Left of the tail
+		{61, 30, false}, // Past the tip
+		{20, 40, false}, // On the bottom edge, left out
+	}
+	for _, p := range probes {
+		got := c.GetPixel(p.x, p.y) == White
+		if got != p.inside {
+			t.Errorf("pixel (%d, %d) filled = %v, want %v", p.x, p.y, got, p.inside)
+		}
+	}
+}
+
+func TestFillPolygonEvenOdd(t *testing.T) {
+	c := NewCanvas(100, 100)
+	// A pentagram drawn in one stroke; its centre is enclosed twice
+	star := make([]image.Point, 5)
+	for i := range star {
+		a := -math.Pi/2 + float64(i)*4*math.Pi/5
+		star[i] = image.Point{X: 50 + int(40*math.Cos(a)), Y: 50 + int(40*math.Sin(a))}
+	}
+	c.FillPolygon(star, White)
+	if c.GetPixel(50, 50) == White {
+		t.Error("even-odd fill filled the pentagram's centre")
+	}
+	if c.GetPixel(50, 20) != White {
+		t.Error("pentagram's top point not filled")
+	}
+}
+
+func TestFillPolygonAntialias(t *testing.T) {
+	tri := []image.Point{{10, 10}, {50, 10}, {10, 37}}
+	c := NewCanvas(60, 60)
+	c.SetAntialias(true)
+	c.FillPolygon(tri, White)
+
+	if c.GetPixel(15, 15) != White {
+		t.Error("antialiased polygon's interior not filled")
+	}
+	// Just outside the sloped edge, partly covered
+	if p := c.GetPixel(31, 23); p == White || p == Black {
+		t.Errorf("sloped edge not blended: got %v", p)
+	}
+	if c.GetPixel(40, 40) != Black {
+		t.Error("antialiased polygon drew far outside its edges")
+	}
+}
+
+func TestPolygonDegenerate(t *testing.T) {
+	c := NewCanvas(20, 20)
+	c.FillPolygon(nil, White)
+	c.FillPolygon([]image.Point{{5, 5}}, White)
+	c.DrawPolygon([]image.Point{{5, 5}}, White)
+	if c.GetPixel(5, 5) == White {
+		t.Error("a single point drew something")
+	}
+
+	c.FillPolygon([]image.Point{{2, 2}, {8, 2}}, White)
+	if c.GetPixel(5, 2) != White {
+		t.Error("two points didn't draw a line")
+	}
+
+	// Hanging off every edge
+	c.FillPolygon([]image.Point{{-10, -10}, {30, -10}, {30, 30}, {-10, 30}}, Red)
+	if c.GetPixel(0, 0) != Red || c.GetPixel(19, 19) != Red {
+		t.Error("clipped polygon didn't cover the canvas")
+	}
+
+	c.DrawPolygon([]image.Point{{2, 2}, {12, 2}, {12, 12}}, Blue)
+	if c.GetPixel(7, 2) != Blue || c.GetPixel(7, 7) != Blue || c.GetPixel(12, 7) != Blue {
+		t.Error("outline missing an edge")
+	}
+	if c.GetPixel(10, 5) != Red {
+		t.Error("outline filled its interior")
+	}
+}
@@ -0,0 +1,115 @@
+package glow
+
+import (
+	"math"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// newBlankSprite allocates a fully transparent sprite.
+func newBlankSprite(w, h int) *Sprite {
+	if w < 0 {
+		w = 0
+	}
+	if h < 0 {
+		h = 0
+	}
+	return &Sprite{
+		data: &x11.SpriteData{
+			Width:  w,
+			Height: h,
+			Pixels: make([]byte, w*h*4),
+		},
+	}
+}
+
+// setPixel writes a straight-alpha BGRA pixel into the sprite.
+func (s *Sprite) setPixel(x, y int, c Color, a uint8) {
+	off := (y*s.data.Width + x) * 4
+	s.data.Pixels[off] = c.B
+	s.data.Pixels[off+1] = c.G
+	s.data.Pixels[off+2] = c.R
+	s.data.Pixels[off+3] = a
+}
+
+// NewCheckerSprite builds an opaque w×h checkerboard of cell×cell squares
+// alternating between a (top-left) and b.
+func NewCheckerSprite(w, h, cell int, a, b Color) *Sprite {
+	if cell < 1 {
+		cell = 1
+	}
+	s := newBlankSprite(w, h)
+	for y := 0; y < s.data.Height; y++ {
+		for x := 0; x < s.data.Width; x++ {
+			c := a
+			if (x/cell+y/cell)%2 == 1 {
+				c = b
+			}
+			s.setPixel(x, y, c, 255)
+		}
+	}
+	return s
+}
+
+// NewGradientSprite builds an opaque w×h linear gradient running from
+// `from` to `to`, left to right if horizontal, otherwise top to bottom.
+func NewGradientSprite(w, h int, from, to Color, horizontal bool) *Sprite {
+	s := newBlankSprite(w, h)
+
+	steps := s.data.Height
+	if horizontal {
+		steps = s.data.Width
+	}
+
+	for y := 0; y < s.data.Height; y++ {
+		for x := 0; x < s.data.Width; x++ {
+			i := y
+			if horizontal {
+				i = x
+			}
+			t := 0.0
+			if steps > 1 {
+				t = float64(i) / float64(steps-1)
+			}
+			s.setPixel(x, y, lerpColor(from, to, t), 255)
+		}
+	}
+	return s
+}
+
+// NewCircleSprite builds a (2·radius)×(2·radius) sprite holding a filled
+// circle. Edge pixels get partial alpha for antialiasing and the corners
+// are fully transparent.
+func NewCircleSprite(radius int, c Color) *Sprite {
+	if radius < 0 {
+		radius = 0
+	}
+	size := radius * 2
+	s := newBlankSprite(size, size)
+
+	r := float64(radius)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			// Distance from the pixel centre to the circle centre
+			dx := float64(x) + 0.5 - r
+			dy := float64(y) + 0.5 - r
+			coverage := r - math.Sqrt(dx*dx+dy*dy) + 0.5
+			if coverage <= 0 {
+				continue
+			}
+			if coverage > 1 {
+				coverage = 1
+			}
+			s.setPixel(x, y, c, uint8(coverage*255+0.5))
+		}
+	}
+	return s
+}
+
+// lerpColor interpolates between two colors, t in [0, 1].
+func lerpColor(a, b Color, t float64) Color {
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return Color{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A)}
+}
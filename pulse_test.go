@@ -0,0 +1,561 @@
+package glow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/pulse"
+)
+
+func TestWriteDataChunksOnFrames(t *testing.T) {
+	client, server := net.Pipe()
+	ctx := &AudioContext{conn: pulse.NewConnection(client)}
+	ctx.SetChunkSize(1000)
+	conn := ctx.conn
+
+	data := make([]byte, 2500)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	received := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(server)
+		received <- b
+	}()
+	// 6-byte frames (S16LE, 3 channels): chunks must be 996 bytes
+	if err := conn.WriteData(7, 6, data); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	out := <-received
+
+	var payload []byte
+	var sizes []int
+	for len(out) > 0 {
+		n := int(binary.BigEndian.Uint32(out[0:]))
+		if ch := binary.BigEndian.Uint32(out[4:]); ch != 7 {
+			t.Fatalf("descriptor channel %d, want 7", ch)
+		}
+		out = out[pulse.DescriptorSize:]
+		payload = append(payload, out[:n]...)
+		sizes = append(sizes, n)
+		out = out[n:]
+	}
+	if len(sizes) != 3 || sizes[0] != 996 || sizes[1] != 996 {
+		t.Errorf("unexpected chunk sizes %v", sizes)
+	}
+	if !bytes.Equal(payload, data) {
+		t.Errorf("payload corrupted")
+	}
+}
+
+// shortConn accepts at most max bytes per Write without reporting an
+// error, like a congested socket.
+type shortConn struct {
+	net.Conn
+	max int
+}
+
+func (c shortConn) Write(b []byte) (int, error) {
+	return c.Conn.Write(b[:min(len(b), c.max)])
+}
+
+func TestWriteDataShortWrites(t *testing.T) {
+	client, server := net.Pipe()
+	conn := pulse.NewConnection(shortConn{client, 7})
+
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	received := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(server)
+		received <- b
+	}()
+	if err := conn.WriteData(5, 4, data); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+	out := <-received
+
+	want := append(pulse.BuildDescriptor(uint32(len(data)), 5), data...)
+	if !bytes.Equal(out, want) {
+		t.Errorf("frame truncated: got %d bytes, want %d", len(out), len(want))
+	}
+}
+
+// BenchmarkWriteData streams a second of 44.1 kHz stereo S16LE audio
+// over a Unix socket, as to the PulseAudio server.
+func BenchmarkWriteData(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "pulse.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		b.Skip(err)
+	}
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			io.Copy(io.Discard, c)
+		}
+	}()
+	nc, err := net.Dial("unix", path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer nc.Close()
+	conn := pulse.NewConnection(nc)
+
+	data := make([]byte, 44100*4)
+	for _, size := range []int{4096, pulse.DefaultChunkSize} {
+		b.Run(fmt.Sprintf("chunk=%d", size), func(b *testing.B) {
+			conn.SetChunkSize(size)
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if err := conn.WriteData(0, 4, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// readPulseRequest reads one command frame from server and returns its
+// command and tag, with a parser positioned at its payload. ok is false
+// once the client has gone.
+func readPulseRequest(server net.Conn) (cmd, tag uint32, tp *pulse.TagParser, ok bool) {
+	desc := make([]byte, pulse.DescriptorSize)
+	if _, err := io.ReadFull(server, desc); err != nil {
+		return 0, 0, nil, false
+	}
+	req := make([]byte, binary.BigEndian.Uint32(desc))
+	if _, err := io.ReadFull(server, req); err != nil {
+		return 0, 0, nil, false
+	}
+	tp = pulse.NewTagParser(req)
+	cmd, _ = tp.ReadU32()
+	tag, _ = tp.ReadU32()
+	return cmd, tag, tp, true
+}
+
+// writePulseReply answers the request with tag with a REPLY carrying
+// payload.
+func writePulseReply(server net.Conn, tag uint32, payload []byte) {
+	tb := pulse.NewTagBuilder()
+	tb.AddU32(pulse.CmdReply)
+	tb.AddU32(tag)
+	writePulsePacket(server, append(tb.Bytes(), payload...))
+}
+
+// writePulsePacket sends packet on the control channel.
+func writePulsePacket(server net.Conn, packet []byte) {
+	server.Write(append(pulse.BuildDescriptor(uint32(len(packet)), pulse.ControlChannel), packet...))
+}
+
+// fakePulseReply reads one command frame from server and answers it
+// with a REPLY carrying payload.
+func fakePulseReply(t *testing.T, server net.Conn, payload []byte) {
+	t.Helper()
+	_, tag, _, ok := readPulseRequest(server)
+	if !ok {
+		t.Error("no request from the client")
+		return
+	}
+	writePulseReply(server, tag, payload)
+}
+
+func TestPlaybackPosition(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := pulse.NewConnection(client)
+	spec := pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 44100}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tb := pulse.NewTagBuilder()
+		tb.AddU32(3) // stream index
+		tb.AddU32(9) // sink input index
+		tb.AddU32(0) // missing
+		fakePulseReply(t, server, tb.Bytes())
+
+		// Skip the data packets
+		for n := 0; n < 176400; {
+			desc := make([]byte, pulse.DescriptorSize)
+			io.ReadFull(server, desc)
+			size := int(binary.BigEndian.Uint32(desc))
+			io.CopyN(io.Discard, server, int64(size))
+			n += size
+		}
+
+		tb = pulse.NewTagBuilder()
+		tb.AddUsec(50 * time.Millisecond)
+		tb.AddUsec(0)
+		tb.AddBool(true)
+		tb.AddTimeval(time.Unix(100, 0))
+		tb.AddTimeval(time.Unix(100, 0))
+		tb.AddS64(176400)
+		tb.AddS64(88200)
+		fakePulseReply(t, server, tb.Bytes())
+	}()
+
+	stream, err := conn.CreatePlaybackStream(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.WriteAll(make([]byte, 176400)); err != nil {
+		t.Fatal(err)
+	}
+	lat, err := stream.Latency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if !lat.Playing || lat.Sink != 50*time.Millisecond || lat.ReadIndex != 88200 {
+		t.Fatalf("unexpected latency %+v", lat)
+	}
+
+	// One second written, half a second of it still buffered, 50 ms in
+	// the sink: 450 ms heard
+	if got := playbackPosition(stream.Written(), lat, spec); got != 450*time.Millisecond {
+		t.Errorf("position %v, want 450ms", got)
+	}
+}
+
+func TestSetChannelVolumes(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := pulse.NewConnection(client)
+	spec := pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 44100}
+	ctx := &AudioContext{conn: conn, spec: spec}
+	p := ctx.NewPlayer(nil)
+
+	if err := p.SetChannelVolumes([]float64{1}); err == nil {
+		t.Error("accepted one volume for two channels")
+	}
+	// Before Play the balance is only stored
+	if err := p.SetChannelVolumes([]float64{2, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.ChannelVolumes(); got[0] != 1 || got[1] != 0 {
+		t.Errorf("ChannelVolumes() = %v, want clamped [1 0]", got)
+	}
+
+	requests := make(chan []uint32, 1)
+	go func() {
+		tb := pulse.NewTagBuilder()
+		tb.AddU32(3) // stream index
+		tb.AddU32(9) // sink input index
+		tb.AddU32(0) // missing
+		fakePulseReply(t, server, tb.Bytes())
+
+		_, tag, tp, _ := readPulseRequest(server)
+		tp.ReadU32() // sink input
+		vols, _ := tp.ReadCVolume()
+		requests <- vols
+		writePulseReply(server, tag, nil)
+	}()
+
+	stream, err := conn.CreatePlaybackStream(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.stream = stream
+
+	// The overall volume scales both channels
+	if err := p.SetVolume(0.125); err != nil {
+		t.Fatal(err)
+	}
+	vols := <-requests
+	if len(vols) != 2 || vols[0] != pulse.VolumeNorm/2 || vols[1] != 0 {
+		t.Errorf("sent volumes %v, want [%d 0]", vols, pulse.VolumeNorm/2)
+	}
+}
+
+// writeUnderflow sends an UNDERFLOW notification for stream index 3.
+func writeUnderflow(server net.Conn) {
+	tb := pulse.NewTagBuilder()
+	tb.AddU32(pulse.CmdUnderflow)
+	tb.AddU32(0xffffffff)
+	tb.AddU32(3) // stream index
+	writePulsePacket(server, tb.Bytes())
+}
+
+func TestOnUnderrun(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := pulse.NewConnection(client)
+	conn.SetChunkSize(1000)
+	spec := pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 44100}
+	ctx := &AudioContext{conn: conn, spec: spec}
+	p := ctx.NewPlayer(nil)
+
+	go func() {
+		tb := pulse.NewTagBuilder()
+		tb.AddU32(3) // stream index
+		tb.AddU32(9) // sink input index
+		tb.AddU32(0) // missing
+		fakePulseReply(t, server, tb.Bytes())
+
+		// Run dry after the first chunk, while data is still coming
+		for i := 0; i < 2; i++ {
+			desc := make([]byte, pulse.DescriptorSize)
+			io.ReadFull(server, desc)
+			io.CopyN(io.Discard, server, int64(binary.BigEndian.Uint32(desc)))
+			if i == 0 {
+				writeUnderflow(server)
+			}
+		}
+
+		// Then again at the end of the sound, before a volume reply
+		writeUnderflow(server)
+		fakePulseReply(t, server, nil)
+	}()
+
+	stream, err := conn.CreatePlaybackStream(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.stream = stream
+	underruns := make(chan struct{}, 4)
+	p.OnUnderrun(func() { underruns <- struct{}{} })
+
+	if err := stream.WriteAll(make([]byte, 2000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetVolume(1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-underruns:
+	case <-time.After(time.Second):
+		t.Fatal("underrun not reported")
+	}
+	select {
+	case <-underruns:
+		t.Error("end of the sound reported as an underrun")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPauseResumeStop(t *testing.T) {
+	client, server := net.Pipe()
+	conn := pulse.NewConnection(client)
+	spec := pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 44100}
+	ctx := &AudioContext{conn: conn, spec: spec}
+	p := ctx.NewPlayer(nil)
+
+	type request struct {
+		cmd, channel uint32
+		cork         bool
+	}
+	requests := make(chan request, 8)
+	go func() {
+		defer close(requests)
+		tb := pulse.NewTagBuilder()
+		tb.AddU32(3) // stream index
+		tb.AddU32(9) // sink input index
+		tb.AddU32(0) // missing
+		fakePulseReply(t, server, tb.Bytes())
+
+		for {
+			cmd, tag, tp, ok := readPulseRequest(server)
+			if !ok {
+				return
+			}
+			r := request{cmd: cmd}
+			r.channel, _ = tp.ReadU32()
+			if r.cmd == pulse.CmdCorkPlaybackStream {
+				r.cork, _ = tp.ReadBool()
+			}
+			requests <- r
+			writePulseReply(server, tag, nil)
+		}
+	}()
+
+	stream, err := conn.CreatePlaybackStream(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.stream = stream
+
+	// Repeated transitions send nothing
+	for _, f := range []func() error{p.Pause, p.Pause, p.Resume, p.Resume, p.Stop, p.Stop, p.Pause} {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.WriteAll(make([]byte, 16)); !errors.Is(err, pulse.ErrStreamDeleted) {
+		t.Errorf("WriteAll after Stop = %v, want ErrStreamDeleted", err)
+	}
+	client.Close()
+
+	want := []request{
+		{pulse.CmdCorkPlaybackStream, 3, true},
+		{pulse.CmdCorkPlaybackStream, 3, false},
+		{pulse.CmdDeletePlaybackStream, 3, false},
+	}
+	var got []request
+	for r := range requests {
+		got = append(got, r)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+	if _, err := p.Position(); err != ErrNotPlaying {
+		t.Errorf("Position after Stop = %v, want ErrNotPlaying", err)
+	}
+}
+
+// writeSubscribeEvent sends a SUBSCRIBE_EVENT notification.
+func writeSubscribeEvent(server net.Conn, typ, index uint32) {
+	tb := pulse.NewTagBuilder()
+	tb.AddU32(pulse.CmdSubscribeEvent)
+	tb.AddU32(0xffffffff)
+	tb.AddU32(typ)
+	tb.AddU32(index)
+	writePulsePacket(server, tb.Bytes())
+}
+
+func TestAudioSubscribe(t *testing.T) {
+	client, server := net.Pipe()
+	ctx := &AudioContext{conn: pulse.NewConnection(client)}
+
+	masks := make(chan uint32, 1)
+	go func() {
+		_, tag, tp, _ := readPulseRequest(server)
+		mask, _ := tp.ReadU32()
+		masks <- mask
+		writePulseReply(server, tag, nil)
+
+		writeSubscribeEvent(server, pulse.SubscriptionEventSink|pulse.SubscriptionEventChange, 1)
+		writeSubscribeEvent(server, pulse.SubscriptionEventSinkInput|pulse.SubscriptionEventRemove, 42)
+		server.Close()
+	}()
+
+	events, err := ctx.Subscribe(AudioSinks | AudioSinkInputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mask := <-masks; mask != pulse.SubscriptionMaskSink|pulse.SubscriptionMaskSinkInput {
+		t.Errorf("sent mask %#x", mask)
+	}
+
+	want := []SubscribeEvent{
+		{Facility: AudioSinks, Change: AudioObjectChanged, Index: 1},
+		{Facility: AudioSinkInputs, Change: AudioObjectRemoved, Index: 42},
+	}
+	var got []SubscribeEvent
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				done = true // Closed with the connection
+				break
+			}
+			got = append(got, e)
+		case <-timeout:
+			t.Fatal("events channel not closed with the connection")
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+// fakePulsePlayback plays a PulseAudio server for players: it creates
+// streams 3, 4 and so on and answers every command, reporting what the
+// client sends: "data" for audio, or the command.
+func fakePulsePlayback(server net.Conn) <-chan string {
+	sent := make(chan string, 64)
+	go func() {
+		defer close(sent)
+		next := uint32(3)
+		for {
+			desc := make([]byte, pulse.DescriptorSize)
+			if _, err := io.ReadFull(server, desc); err != nil {
+				return
+			}
+			packet := make([]byte, binary.BigEndian.Uint32(desc))
+			if _, err := io.ReadFull(server, packet); err != nil {
+				return
+			}
+			if binary.BigEndian.Uint32(desc[4:]) != pulse.ControlChannel {
+				sent <- "data"
+				continue
+			}
+			tp := pulse.NewTagParser(packet)
+			cmd, _ := tp.ReadU32()
+			tag, _ := tp.ReadU32()
+			tb := pulse.NewTagBuilder()
+			switch cmd {
+			case pulse.CmdCreatePlaybackStream:
+				sent <- "create"
+				tb.AddU32(next)     // stream index
+				tb.AddU32(next + 6) // sink input index
+				tb.AddU32(0)        // missing
+				next++
+			case pulse.CmdDrainPlaybackStream:
+				sent <- "drain"
+			case pulse.CmdDeletePlaybackStream:
+				sent <- "delete"
+			default:
+				sent <- fmt.Sprint(cmd)
+			}
+			writePulseReply(server, tag, tb.Bytes())
+		}
+	}()
+	return sent
+}
+
+// collectPulse returns what fakePulsePlayback reports until it has seen
+// n stream deletions, or a second has passed.
+func collectPulse(sent <-chan string, n int) []string {
+	var got []string
+	timeout := time.After(time.Second)
+	for n > 0 {
+		select {
+		case s := <-sent:
+			got = append(got, s)
+			if s == "delete" {
+				n--
+			}
+		case <-timeout:
+			return got
+		}
+	}
+	return got
+}
+
+func TestPlayDeletesStream(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	conn := pulse.NewConnection(client)
+	spec := pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 44100}
+	ctx := &AudioContext{conn: conn, spec: spec}
+	sent := fakePulsePlayback(server)
+
+	p := ctx.NewPlayer(bytes.NewReader(make([]byte, 400)))
+	p.Play()
+
+	// Played out, then released on the server
+	got := collectPulse(sent, 1)
+	if want := []string{"create", "data", "drain", "delete"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("sent %v, want %v", got, want)
+	}
+	p.mu.Lock()
+	stream := p.stream
+	p.mu.Unlock()
+	if stream != nil {
+		t.Error("player still holds its deleted stream")
+	}
+}
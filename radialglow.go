@@ -0,0 +1,32 @@
+package glow
+
+import "sync"
+
+// maxCachedGlow is the largest radius whose falloff table is kept
+// between calls. Tables grow with the radius squared, and larger glows
+// are few enough per frame to build on the fly.
+const maxCachedGlow = 256
+
+// glowFalloffs caches falloff tables by radius.
+var glowFalloffs sync.Map // int -> []uint8
+
+// glowFalloff returns the intensity of a glow of the given radius at
+// each squared distance from its centre, 255 at the centre down to 0 at
+// radius, on a smooth quadratic curve. Indexing by squared distance
+// spares DrawGlow a square root per pixel.
+func glowFalloff(radius int) []uint8 {
+	if t, ok := glowFalloffs.Load(radius); ok {
+		return t.([]uint8)
+	}
+
+	rr := radius * radius
+	t := make([]uint8, rr)
+	for d2 := range t {
+		f := 1 - float64(d2)/float64(rr) // Squared below: flat at the centre, smooth at the edge
+		t[d2] = uint8(f*f*255 + 0.5)
+	}
+	if radius <= maxCachedGlow {
+		glowFalloffs.Store(radius, t)
+	}
+	return t
+}
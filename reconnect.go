@@ -0,0 +1,150 @@
+package glow
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// errConnectionLost is reported when the event goroutine saw the
+// connection drop before Present did.
+var errConnectionLost = errors.New("glow: connection to X server lost")
+
+// OnDisconnect registers fn to be called when the connection to the X
+// server is lost, for example because the server restarted. Loss is
+// detected by Present, and fn runs once per loss on the goroutine
+// calling Present, before any reconnect attempt. Pass nil to remove
+// the callback.
+func (w *Window) OnDisconnect(fn func()) {
+	w.onDisconnect = fn
+}
+
+// SetAutoReconnect controls whether Present reconnects after the
+// connection to the X server is lost. When enabled, Present opens a new
+// connection, recreates the window and its graphics context with the
+// same title and size, restores fullscreen, and re-uploads the canvas.
+//
+// The canvas contents and queued events survive a reconnect. Lost are:
+// the window's position and any window manager state other than
+// fullscreen, an in-progress drag-and-drop, and atoms returned by
+// InternAtom (intern them again, the new server may number them
+// differently).
+//
+// Windows created through a Display share its connection and never
+// reconnect.
+func (w *Window) SetAutoReconnect(enabled bool) {
+	w.autoReconnect = enabled
+}
+
+// recoverConnection handles a lost connection: it notifies the
+// OnDisconnect callback and, if enabled, reconnects. It returns cause
+// if the window stays disconnected.
+func (w *Window) recoverConnection(cause error) error {
+	if w.closed.Load() || w.display != nil {
+		return cause
+	}
+
+	w.lost.Store(true)
+	if !w.disconnected {
+		w.disconnected = true
+		if w.onDisconnect != nil {
+			w.onDisconnect()
+		}
+	}
+	if !w.autoReconnect {
+		return cause
+	}
+
+	if err := w.reconnect(); err != nil {
+		return fmt.Errorf("glow: reconnect failed: %w (after %v)", err, cause)
+	}
+	return nil
+}
+
+// reconnect replaces the window's connection, window and GC with fresh
+// ones and restarts the event goroutine.
+func (w *Window) reconnect() error {
+	conn, err := x11.Connect()
+	if err != nil {
+		return err
+	}
+
+	nw, err := createWindow(conn, w.title, w.width, w.height, w.translucent)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	// Stop the old event goroutine before its connection goes away,
+	// so it doesn't mistake the close for another disconnect, and wait
+	// for it: it reads w.conn and the state replaced below
+	close(w.quitChan)
+	w.conn.Close()
+	<-w.pollDone
+
+	w.conn = conn
+	w.windowID = nw.windowID
+	w.gcID = nw.gcID
+	w.depth, w.visual, w.colormap = nw.depth, nw.visual, nw.colormap
+	w.quitChan = make(chan struct{})
+	w.keymap.Store(nw.keymap.Load())
+	w.dnd = dndState{}
+	w.state = windowState{}
+	w.lost.Store(false)
+	w.presented = Rect{}
+	if w.shm != nil {
+		w.shm.Release()
+		w.shm = nil
+	}
+	w.shmBusy = false
+	w.shmOff = false
+	w.backBuffer = 0
+
+	// Pixmaps and GCs died with the old connection
+	for p := range w.pixmaps {
+		p.id = 0
+	}
+	w.pixmaps = nil
+	for g := range w.gcs {
+		g.id = 0
+	}
+	w.gcs = nil
+	w.disconnected = false
+
+	if w.class[1] != "" {
+		if err := w.SetClass(w.class[0], w.class[1]); err != nil {
+			return err
+		}
+	}
+	if w.propertyEvents.Load() {
+		if err := w.SetPropertyEvents(true); err != nil {
+			return err
+		}
+	}
+	if w.fullscreen {
+		if err := w.SetFullscreen(true); err != nil {
+			return err
+		}
+	}
+	// Cursors died with the old connection too
+	w.cursorIDs = nil
+	w.blankCursor = 0
+	if w.cursorHidden {
+		w.cursorHidden = false
+		if err := w.HideCursor(); err != nil {
+			return err
+		}
+	}
+	if shape := w.cursorShape; shape != CursorDefault {
+		w.cursorShape = CursorDefault
+		if err := w.SetCursor(shape); err != nil {
+			return err
+		}
+	}
+
+	w.pollDone = make(chan struct{})
+	go w.pollEvents(w.conn, w.quitChan, w.pollDone)
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package glow
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// refreshSamples is how many synced presents EstimateRefreshRate times.
+const refreshSamples = 20
+
+// Range of refresh rates real displays run at; estimates outside it
+// measure something other than the display.
+const (
+	minRefreshRate = 24
+	maxRefreshRate = 500
+)
+
+// EstimateRefreshRate returns the display's refresh rate in Hz. When
+// the server supports RANDR it is the exact rate of the monitor showing
+// the window (see CurrentMonitor). Otherwise it is estimated by
+// presenting the canvas several times, waiting for the X server to
+// finish each frame with a GetInputFocus round trip, and taking the
+// median interval; that takes a few frames. The result is remembered
+// for later calls. It returns 0 if the measurement fails.
+//
+// The X server only paces frames when a compositor or driver syncs
+// presentation to vertical blank, so on many setups the measurement
+// shows how fast frames can be pushed rather than the refresh rate.
+// Results outside 24-500 Hz should be treated as "unknown"; within it
+// they are usually within a few percent, as scheduling noise is
+// filtered out by the median.
+func (w *Window) EstimateRefreshRate() float64 {
+	if w.refreshRate > 0 {
+		return w.refreshRate
+	}
+	if m, err := w.CurrentMonitor(); err == nil && m.Refresh > 0 {
+		w.refreshRate = m.Refresh
+		return w.refreshRate
+	}
+
+	times := make([]time.Time, 0, refreshSamples+1)
+	for i := 0; i <= refreshSamples; i++ {
+		// Send the whole view each time: with nothing drawn since the
+		// last sample, a plain present would send nothing to time
+		w.fullPresent.Store(true)
+		if err := w.putCanvas(); err != nil {
+			return 0
+		}
+		if err := w.conn.Sync(); err != nil {
+			return 0
+		}
+		times = append(times, time.Now())
+	}
+	w.refreshRate = medianRate(times)
+	return w.refreshRate
+}
+
+// medianRate returns the rate, per second, of the median interval
+// between consecutive times, or 0 if there are fewer than two.
+func medianRate(times []time.Time) float64 {
+	if len(times) < 2 {
+		return 0
+	}
+	intervals := make([]time.Duration, len(times)-1)
+	for i := range intervals {
+		intervals[i] = times[i+1].Sub(times[i])
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	median := intervals[len(intervals)/2]
+	if median <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(median)
+}
+
+// defaultFPS is the frame rate Run targets when no cap is set: the
+// estimated refresh rate if it is plausible, else runDefaultFPS.
+func (w *Window) defaultFPS() int {
+	rate := w.EstimateRefreshRate()
+	if rate < minRefreshRate || rate > maxRefreshRate {
+		return runDefaultFPS
+	}
+	return int(math.Round(rate))
+}
@@ -0,0 +1,88 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestMedianRate(t *testing.T) {
+	start := time.Unix(0, 0)
+	var times []time.Time
+	for i, gap := range []time.Duration{0, 16, 17, 40, 16, 17, 1} {
+		if i == 0 {
+			times = append(times, start)
+			continue
+		}
+		times = append(times, times[i-1].Add(gap*time.Millisecond))
+	}
+	// Intervals sorted: 1 16 16 17 17 40 -> median 17 ms
+	if got := medianRate(times); got < 58.8 || got > 58.9 {
+		t.Errorf("medianRate = %v, want ~58.8", got)
+	}
+	if medianRate(times[:1]) != 0 {
+		t.Error("a single sample has no rate")
+	}
+}
+
+func TestEstimateRefreshRateSendsFrames(t *testing.T) {
+	client, server := net.Pipe()
+	conn := x11.NewConnection(client)
+	conn.ResourceIDBase = 0x200000
+	conn.ResourceIDMask = 0xFFFF
+	conn.RootDepth = 24
+	defer conn.Close()
+	w := &Window{
+		conn: conn, windowID: 0x100, gcID: 0x101,
+		canvas: &Canvas{fb: x11.NewFramebuffer(40, 30)}, width: 40, height: 30,
+		shmOff: true,
+	}
+
+	// A server without RANDR that counts the frame requests between
+	// GetInputFocus round trips
+	frames := make(chan int, refreshSamples+1)
+	go func() {
+		seq, sent := uint16(0), 0
+		for {
+			hdr := make([]byte, 4)
+			if _, err := io.ReadFull(server, hdr); err != nil {
+				return
+			}
+			req := make([]byte, int(binary.LittleEndian.Uint16(hdr[2:]))*4)
+			if _, err := io.ReadFull(server, req[4:]); err != nil {
+				return
+			}
+			seq++
+			switch hdr[0] {
+			case x11.OpPutImage, x11.OpCopyArea:
+				sent++
+			case x11.OpGetInputFocus:
+				frames <- sent
+				sent = 0
+				fallthrough
+			case x11.OpQueryExtension:
+				reply := make([]byte, 32) // Extension absent
+				reply[0] = 1
+				binary.LittleEndian.PutUint16(reply[2:], seq)
+				server.Write(reply)
+			}
+		}
+	}()
+
+	w.EstimateRefreshRate()
+	close(frames)
+	n := 0
+	for sent := range frames {
+		if sent == 0 {
+			t.Errorf("sample %d sent no frame", n)
+		}
+		n++
+	}
+	if n != refreshSamples+1 {
+		t.Errorf("%d samples, want %d", n, refreshSamples+1)
+	}
+}
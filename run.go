@@ -0,0 +1,68 @@
+package glow
+
+import "time"
+
+// runDefaultFPS is the frame rate Run targets when no cap is set and
+// the refresh rate can't be determined
+const runDefaultFPS = 60
+
+// Run drives the window's main loop until update returns false, the
+// window's close button is pressed or the window is closed:
+//
+//	win.Run(func(dt float64, c *glow.Canvas) bool {
+//		if win.Keyboard().IsDown(glow.KeyEscape) {
+//			return false
+//		}
+//		x += speed * dt
+//		c.Clear(glow.Black)
+//		c.FillCircle(int(x), 100, 10, glow.White)
+//		return true
+//	})
+//
+// Each frame it reads all pending events into the Keyboard and Mouse
+// state (see UpdateInput), calls update with the seconds elapsed since
+// the previous frame, presents the canvas and sleeps to hold the frame
+// rate set with SetMaxFPS, or the display's refresh rate if none is set
+// (see EstimateRefreshRate; 60 FPS when it is unknown). Shortcuts
+// registered with OnShortcut still fire. The first frame gets a dt of 0.
+//
+// Run returns the first Present error, or nil once the loop ends.
+// Apps that need the individual events should write their own loop with
+// PollEvent instead.
+func (w *Window) Run(update func(dt float64, c *Canvas) bool) error {
+	defaultFPS := 0 // Measured when first needed
+	last := time.Now()
+	first := true
+
+	for !w.closed.Load() {
+		w.UpdateInput()
+		for e := w.PollEvent(); e != nil; e = w.PollEvent() {
+			if e.Type == EventQuit {
+				return nil
+			}
+		}
+
+		now := time.Now()
+		dt := now.Sub(last).Seconds()
+		last = now
+		if first {
+			dt, first = 0, false
+		}
+
+		if !update(dt, w.canvas) {
+			return nil
+		}
+
+		fps := w.maxFPS
+		if fps == 0 {
+			if defaultFPS == 0 {
+				defaultFPS = w.defaultFPS()
+			}
+			fps = defaultFPS
+		}
+		if _, err := w.PresentThrottled(fps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
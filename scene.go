@@ -0,0 +1,174 @@
+package glow
+
+import (
+	"math"
+	"slices"
+)
+
+// A Scene is an optional retained-mode layer on top of Canvas: instead
+// of issuing drawing calls every frame, build a tree of nodes once,
+// update their fields as the app runs and call Draw. It uses only the
+// public Canvas API, so scenes and immediate-mode drawing mix freely.
+//
+//	scene := &glow.Scene{}
+//	hud := &glow.GroupNode{Transform: glow.Transform{X: 10, Y: 10}}
+//	hud.Add(&glow.RectNode{Width: 100, Height: 20, Color: glow.Gray})
+//	scene.Add(hud, &glow.SpriteNode{Sprite: player})
+//	...
+//	scene.Draw(win.Canvas())
+type Scene struct {
+	Root GroupNode
+}
+
+// Add appends nodes to the scene's root group.
+func (s *Scene) Add(nodes ...Node) { s.Root.Add(nodes...) }
+
+// Draw draws every visible node of the scene onto c.
+func (s *Scene) Draw(c *Canvas) { s.Root.Draw(c, Transform{}) }
+
+// Transform places a node relative to its parent: coordinates are
+// scaled by ScaleX, ScaleY, then offset by X, Y. A zero scale counts
+// as 1, so the zero Transform leaves coordinates unchanged. Scales
+// should be positive.
+type Transform struct {
+	X, Y           float64
+	ScaleX, ScaleY float64
+}
+
+// scale returns the transform's scale with zero treated as 1.
+func (t Transform) scale() (sx, sy float64) {
+	sx, sy = t.ScaleX, t.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	return sx, sy
+}
+
+// Apply maps the point (x, y) through the transform.
+func (t Transform) Apply(x, y float64) (float64, float64) {
+	sx, sy := t.scale()
+	return t.X + x*sx, t.Y + y*sy
+}
+
+// Then returns the transform that applies child first and then t, as
+// for a node inside a group.
+func (t Transform) Then(child Transform) Transform {
+	sx, sy := t.scale()
+	csx, csy := child.scale()
+	x, y := t.Apply(child.X, child.Y)
+	return Transform{X: x, Y: y, ScaleX: sx * csx, ScaleY: sy * csy}
+}
+
+// applyRect maps a rectangle through the transform, rounded to pixels.
+func (t Transform) applyRect(x, y, width, height int) (int, int, int, int) {
+	x0, y0 := t.Apply(float64(x), float64(y))
+	x1, y1 := t.Apply(float64(x+width), float64(y+height))
+	rx, ry := int(math.Round(x0)), int(math.Round(y0))
+	return rx, ry, int(math.Round(x1)) - rx, int(math.Round(y1)) - ry
+}
+
+// Node is an element of a Scene. Besides the nodes in this package, any
+// type embedding NodeBase and implementing Draw can be added to a group.
+type Node interface {
+	// Draw draws the node onto c, with t mapping the node's
+	// coordinates to the canvas.
+	Draw(c *Canvas, t Transform)
+	base() *NodeBase
+}
+
+// NodeBase holds the properties every node has. Embed it in custom
+// node types.
+type NodeBase struct {
+	Hidden bool // Not drawn, nor are its children
+	Z      int  // Drawing order within the parent group, lowest first
+}
+
+func (b *NodeBase) base() *NodeBase { return b }
+
+// GroupNode draws its children with its Transform applied on top of
+// the parent's. Children are drawn in order of Z; those with equal Z in
+// the order they were added.
+type GroupNode struct {
+	NodeBase
+	Transform Transform
+	Children  []Node
+
+	sorted []Node // Children ordered by Z, reused between draws
+}
+
+// Add appends nodes to the group.
+func (g *GroupNode) Add(nodes ...Node) {
+	g.Children = append(g.Children, nodes...)
+}
+
+// Remove removes n from the group, reporting whether it was there.
+func (g *GroupNode) Remove(n Node) bool {
+	i := slices.Index(g.Children, n)
+	if i < 0 {
+		return false
+	}
+	g.Children = slices.Delete(g.Children, i, i+1)
+	return true
+}
+
+// Draw implements Node.
+func (g *GroupNode) Draw(c *Canvas, t Transform) {
+	t = t.Then(g.Transform)
+	g.sorted = append(g.sorted[:0], g.Children...)
+	slices.SortStableFunc(g.sorted, func(a, b Node) int {
+		return a.base().Z - b.base().Z
+	})
+	for _, n := range g.sorted {
+		if !n.base().Hidden {
+			n.Draw(c, t)
+		}
+	}
+	clear(g.sorted) // Don't keep removed nodes alive
+}
+
+// RectNode is a filled or outlined rectangle with its top-left corner
+// at (X, Y).
+type RectNode struct {
+	NodeBase
+	X, Y, Width, Height int
+	Color               Color
+	Outline             bool // Draw only the outline
+}
+
+// Draw implements Node.
+func (r *RectNode) Draw(c *Canvas, t Transform) {
+	x, y, w, h := t.applyRect(r.X, r.Y, r.Width, r.Height)
+	if r.Outline {
+		c.DrawRectOutline(x, y, w, h, r.Color)
+	} else {
+		c.DrawRect(x, y, w, h, r.Color)
+	}
+}
+
+// SpriteNode draws a sprite with its anchor at (X, Y), scaled by the
+// transforms of the groups it is in.
+type SpriteNode struct {
+	NodeBase
+	Sprite *Sprite
+	X, Y   int
+}
+
+// Draw implements Node.
+func (s *SpriteNode) Draw(c *Canvas, t Transform) {
+	if s.Sprite == nil {
+		return
+	}
+	sx, sy := t.scale()
+	fx, fy := t.Apply(float64(s.X), float64(s.Y))
+	x, y := int(math.Round(fx)), int(math.Round(fy))
+	if sx == 1 && sy == 1 {
+		c.DrawSprite(s.Sprite, x, y)
+		return
+	}
+	w := int(math.Round(float64(s.Sprite.Width()) * sx))
+	h := int(math.Round(float64(s.Sprite.Height()) * sy))
+	c.DrawSpriteScaled(s.Sprite, x, y, w, h)
+}
@@ -0,0 +1,55 @@
+package glow
+
+import "testing"
+
+func TestSceneTransforms(t *testing.T) {
+	c := NewCanvas(32, 32)
+	scene := &Scene{}
+	group := &GroupNode{Transform: Transform{X: 10, Y: 10, ScaleX: 2, ScaleY: 2}}
+	group.Add(&RectNode{X: 1, Y: 1, Width: 2, Height: 2, Color: Red})
+	inner := &GroupNode{Transform: Transform{X: 5}}
+	inner.Add(&SpriteNode{Sprite: makeOpaqueRedSprite(1, 1), X: 1, Y: 0})
+	group.Add(inner)
+	scene.Add(group)
+	scene.Draw(c)
+
+	// Rect (1,1)-(3,3) scaled by 2 and moved by 10
+	assertFBPixel(t, c.fb, 12, 12, 255, 0, 0)
+	assertFBPixel(t, c.fb, 15, 15, 255, 0, 0)
+	assertFBPixel(t, c.fb, 11, 11, 0, 0, 0)
+	assertFBPixel(t, c.fb, 16, 16, 0, 0, 0)
+
+	// Sprite at 10 + (5+1)*2 = 22, drawn 2x2
+	assertFBPixel(t, c.fb, 22, 10, 255, 0, 0)
+	assertFBPixel(t, c.fb, 23, 11, 255, 0, 0)
+	assertFBPixel(t, c.fb, 24, 10, 0, 0, 0)
+}
+
+func TestSceneVisibilityAndZ(t *testing.T) {
+	c := NewCanvas(4, 4)
+	scene := &Scene{}
+	top := &RectNode{Width: 4, Height: 4, Color: Green, NodeBase: NodeBase{Z: 1}}
+	bottom := &RectNode{Width: 4, Height: 4, Color: Red}
+	scene.Add(top, bottom)
+	scene.Draw(c)
+	assertFBPixel(t, c.fb, 0, 0, 0, 255, 0)
+
+	// Hiding the top node reveals the one below
+	top.Hidden = true
+	scene.Draw(c)
+	assertFBPixel(t, c.fb, 0, 0, 255, 0, 0)
+
+	// Hidden groups hide their children
+	group := &GroupNode{NodeBase: NodeBase{Hidden: true, Z: 2}}
+	group.Add(&RectNode{Width: 1, Height: 1, Color: Blue})
+	scene.Add(group)
+	scene.Draw(c)
+	assertFBPixel(t, c.fb, 0, 0, 255, 0, 0)
+
+	if !scene.Root.Remove(bottom) || scene.Root.Remove(bottom) {
+		t.Error("Remove should succeed once")
+	}
+	if len(scene.Root.Children) != 2 {
+		t.Errorf("%d children left, want 2", len(scene.Root.Children))
+	}
+}
@@ -0,0 +1,86 @@
+package glow
+
+import "github.com/AchrafSoltani/glow/internal/x11"
+
+// Present sends pixels through a MIT-SHM shared memory segment when the
+// X server can reach one, so a frame costs a memory copy instead of a
+// trip through the socket. The segment mirrors the canvas's layout: each
+// dirty region is copied to the same place and drawn with ShmPutImage.
+
+// SharedMemory reports whether Present is sending pixels through shared
+// memory. It is false until the first Present, and for good when the
+// server lacks MIT-SHM or runs on another machine or in another
+// container; Present then sends the pixels over the connection.
+func (w *Window) SharedMemory() bool {
+	return w.shm != nil
+}
+
+// shmSegment returns a segment large enough for the canvas, creating it
+// on first use or after a resize, or nil if shared memory is unavailable.
+func (w *Window) shmSegment() *x11.ShmSegment {
+	if w.shmOff {
+		return nil
+	}
+	size := len(w.canvas.fb.Pixels)
+	if w.shm != nil && len(w.shm.Data) >= size {
+		return w.shm
+	}
+
+	w.freeShm()
+	seg, err := w.conn.NewShmSegment(size, w.windowID)
+	if err != nil {
+		w.shmOff = true
+		return nil
+	}
+	w.shm = seg
+	return seg
+}
+
+// waitShm waits until the server has drawn the last frame sent through
+// the segment, so the next one can be written over it.
+func (w *Window) waitShm() error {
+	if !w.shmBusy {
+		return nil
+	}
+	w.shmBusy = false
+	return w.conn.Sync()
+}
+
+// putRegion uploads the canvas area r to (dstX, dstY) in dst, the
+// window or its back buffer.
+func (w *Window) putRegion(dst uint32, r Rect, dstX, dstY int) error {
+	seg := w.shmSegment()
+	if seg == nil {
+		return w.conn.PutImage(dst, w.gcID,
+			uint16(r.Width), uint16(r.Height), int16(dstX), int16(dstY),
+			w.Depth(), w.viewPixels(r))
+	}
+
+	fb := w.canvas.fb
+	shmCopy(seg.Data, fb, r)
+	w.shmBusy = true
+	return w.conn.ShmPutImage(dst, w.gcID, seg,
+		uint16(fb.Width), uint16(fb.Height), uint16(r.X), uint16(r.Y),
+		uint16(r.Width), uint16(r.Height), int16(dstX), int16(dstY),
+		w.Depth())
+}
+
+// shmCopy copies the area r of fb to the same place in dst, an image
+// laid out like fb.
+func shmCopy(dst []byte, fb *x11.Framebuffer, r Rect) {
+	rowBytes := r.Width * 4
+	for row := r.Y; row < r.Y+r.Height; row++ {
+		off := (row*fb.Width + r.X) * 4
+		copy(dst[off:off+rowBytes], fb.Pixels[off:off+rowBytes])
+	}
+}
+
+// freeShm detaches the shared memory segment, if any.
+func (w *Window) freeShm() {
+	if w.shm == nil {
+		return
+	}
+	w.conn.FreeShmSegment(w.shm)
+	w.shm = nil
+	w.shmBusy = false
+}
@@ -0,0 +1,63 @@
+package glow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestShmCopy(t *testing.T) {
+	fb := x11.NewFramebuffer(8, 6)
+	for i := range fb.Pixels {
+		fb.Pixels[i] = byte(i)
+	}
+	dst := make([]byte, len(fb.Pixels))
+	shmCopy(dst, fb, Rect{X: 2, Y: 1, Width: 3, Height: 2})
+
+	for y := 0; y < fb.Height; y++ {
+		for x := 0; x < fb.Width; x++ {
+			off := (y*fb.Width + x) * 4
+			inside := x >= 2 && x < 5 && y >= 1 && y < 3
+			if got := bytes.Equal(dst[off:off+4], fb.Pixels[off:off+4]); got != inside {
+				t.Errorf("pixel (%d, %d) copied = %v, want %v", x, y, got, inside)
+			}
+		}
+	}
+}
+
+// BenchmarkPresentSHM compares full-frame Presents with and without
+// shared memory. It needs an X server and skips without one.
+func BenchmarkPresentSHM(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		shm  bool
+	}{{"socket", false}, {"shm", true}} {
+		b.Run(bm.name, func(b *testing.B) {
+			w, err := NewWindow("glow benchmark", 640, 480)
+			if err != nil {
+				b.Skip(err)
+			}
+			defer w.Close()
+			w.shmOff = !bm.shm
+			if err := w.Present(); err != nil {
+				b.Fatal(err)
+			}
+			if bm.shm && !w.SharedMemory() {
+				b.Skip("MIT-SHM unavailable")
+			}
+
+			b.SetBytes(640 * 480 * 4)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w.canvas.Clear(RGB(uint8(i), 0, 0))
+				if err := w.Present(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := w.Sync(); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
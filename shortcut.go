@@ -0,0 +1,54 @@
+package glow
+
+// shortcut identifies a key combo registered with OnShortcut
+type shortcut struct {
+	mods Modifier
+	key  Key
+}
+
+// IsCombo reports whether e is a key press of key with exactly the
+// modifiers mods held. Ctrl+S does not match Ctrl+Shift+S; Caps Lock
+// and Num Lock are ignored.
+func (e *Event) IsCombo(key Key, mods Modifier) bool {
+	return e.Type == EventKeyDown && e.Key == key && e.Mods == mods
+}
+
+// OnShortcut registers fn to run when key is pressed with exactly the
+// modifiers mods held:
+//
+//	win.OnShortcut(glow.ModCtrl, glow.KeyS, save)
+//	win.OnShortcut(glow.ModCtrl, glow.KeyZ, undo)
+//	win.OnShortcut(glow.ModCtrl|glow.ModShift, glow.KeyZ, redo)
+//
+// Shortcuts are dispatched by PollEvent and WaitEvent (or the Display's
+// equivalents), on the goroutine calling them. A key press that matches
+// a shortcut is consumed and never returned as an EventKeyDown; its
+// EventKeyUp is still delivered. Key repeat fires fn again.
+//
+// Registering the same combo again replaces its handler; a nil fn
+// removes it. Call OnShortcut from the goroutine that reads events.
+func (w *Window) OnShortcut(mods Modifier, key Key, fn func()) {
+	sc := shortcut{mods: mods, key: key}
+	if fn == nil {
+		delete(w.shortcuts, sc)
+		return
+	}
+	if w.shortcuts == nil {
+		w.shortcuts = make(map[shortcut]func())
+	}
+	w.shortcuts[sc] = fn
+}
+
+// dispatchShortcut runs the shortcut matching e, if any, and reports
+// whether e was consumed.
+func (w *Window) dispatchShortcut(e *Event) bool {
+	if e.Type != EventKeyDown || len(w.shortcuts) == 0 {
+		return false
+	}
+	fn, ok := w.shortcuts[shortcut{mods: e.Mods, key: e.Key}]
+	if !ok {
+		return false
+	}
+	fn()
+	return true
+}
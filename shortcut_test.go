@@ -0,0 +1,59 @@
+package glow
+
+import (
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestModsFromState(t *testing.T) {
+	state := uint16(x11.ControlMask | x11.ShiftMask | x11.LockMask | x11.Mod2Mask)
+	if got := modsFromState(state); got != ModCtrl|ModShift {
+		t.Errorf("expected Ctrl+Shift, got %b", got)
+	}
+}
+
+func TestEventMods(t *testing.T) {
+	w := &Window{}
+	shift, ctrlAlt := uint16(x11.ShiftMask), uint16(x11.ControlMask|x11.Mod1Mask)
+
+	key := w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyPress, Keycode: uint8(KeyA), State: shift})
+	if key.Mods != ModShift {
+		t.Errorf("key Mods = %b, want Shift", key.Mods)
+	}
+	click := w.convertEvent(x11.ButtonEvent{EventType: x11.EventButtonPress, Button: 1, State: ctrlAlt})
+	if !click.Mods.Has(ModCtrl|ModAlt) || click.Mods.Has(ModShift) {
+		t.Errorf("click Mods = %b, want Ctrl+Alt", click.Mods)
+	}
+	drag := w.convertEvent(x11.MotionEvent{State: shift | x11.Button1Mask | x11.Button3Mask})
+	if drag.Mods != ModShift || drag.Buttons != MouseLeftMask|MouseRightMask {
+		t.Errorf("drag Mods = %b, Buttons = %b; want Shift with left and right held", drag.Mods, drag.Buttons)
+	}
+}
+
+func TestShortcutConsumesKeyDown(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	saved := 0
+	w.OnShortcut(ModCtrl, KeyS, func() { saved++ })
+
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyS, Mods: ModCtrl}
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyS, Mods: ModCtrl | ModShift}
+	w.eventChan <- Event{Type: EventKeyUp, Key: KeyS, Mods: ModCtrl}
+
+	e := w.PollEvent()
+	if saved != 1 {
+		t.Fatalf("expected shortcut to fire once, fired %d times", saved)
+	}
+	if e == nil || !e.IsCombo(KeyS, ModCtrl|ModShift) {
+		t.Fatalf("expected Ctrl+Shift+S to pass through, got %+v", e)
+	}
+	if e := w.PollEvent(); e == nil || e.Type != EventKeyUp {
+		t.Fatalf("expected key up to pass through, got %+v", e)
+	}
+
+	w.OnShortcut(ModCtrl, KeyS, nil)
+	w.eventChan <- Event{Type: EventKeyDown, Key: KeyS, Mods: ModCtrl}
+	if e := w.PollEvent(); e == nil || saved != 1 {
+		t.Errorf("removed shortcut still consumed the key")
+	}
+}
@@ -0,0 +1,61 @@
+package glow
+
+import (
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/pulse"
+)
+
+// Sound is audio decoded once into memory and played as often as
+// needed, as for game sound effects:
+//
+//	jump, err := glow.LoadSound("jump.wav")
+//	...
+//	jump.Play()
+//
+// Each Play opens its own stream on a shared audio context and the
+// server mixes them, so a sound can overlap itself. The stream plays in
+// the sound's own format; the server resamples as needed.
+type Sound struct {
+	spec pulse.SampleSpec
+	pcm  []byte
+}
+
+// LoadSound loads a WAV file as a sound (see LoadWAV).
+func LoadSound(path string) (*Sound, error) {
+	clip, err := LoadWAV(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSound(clip), nil
+}
+
+// NewSound makes a sound of clip. The clip's data is shared, not copied.
+func NewSound(clip *AudioClip) *Sound {
+	return &Sound{spec: clip.spec(), pcm: clip.Data}
+}
+
+// Duration returns how long the sound plays for.
+func (s *Sound) Duration() time.Duration {
+	bytesPerSec := s.spec.FrameSize() * int(s.spec.Rate)
+	if bytesPerSec == 0 {
+		return 0
+	}
+	return time.Duration(len(s.pcm)) * time.Second / time.Duration(bytesPerSec)
+}
+
+// Play starts the sound and returns its player, to stop it or change
+// its volume. The first call opens the shared audio context, returning
+// its error if audio is unavailable. The decoded data is shared, not
+// copied, between plays.
+func (s *Sound) Play() (*AudioPlayer, error) {
+	shared, err := sharedAudio()
+	if err != nil {
+		return nil, err
+	}
+	ctx := &AudioContext{conn: shared.conn, spec: s.spec}
+	p := ctx.NewPlayer(nil)
+	p.pcm = s.pcm
+	p.Play()
+	return p, nil
+}
@@ -0,0 +1,37 @@
+package glow
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/pulse"
+)
+
+func TestLoadSound(t *testing.T) {
+	pcm := make([]byte, 44100*4/10) // 100 ms of 16-bit stereo
+	for i := range pcm {
+		pcm[i] = byte(i)
+	}
+	path := filepath.Join(t.TempDir(), "blip.wav")
+	if err := os.WriteFile(path, makeTestWAV(wavFormatPCM, 2, 44100, 16, pcm), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSound(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pulse.SampleSpec{Format: pulse.SampleS16LE, Channels: 2, Rate: 44100}
+	if s.spec != want {
+		t.Errorf("spec %+v, want %+v", s.spec, want)
+	}
+	if !bytes.Equal(s.pcm, pcm) {
+		t.Errorf("PCM data differs")
+	}
+	if d := s.Duration(); d != 100*time.Millisecond {
+		t.Errorf("Duration() = %v, want 100ms", d)
+	}
+}
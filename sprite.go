@@ -1,17 +1,26 @@
 package glow
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	_ "image/png"
 	"io"
+	"math"
 	"os"
 
 	"github.com/AchrafSoltani/glow/internal/x11"
 )
 
+// ErrEmptyImage is returned when loading an image with no pixels.
+var ErrEmptyImage = errors.New("glow: image has no pixels")
+
 // Sprite holds pre-converted BGRA pixel data ready for fast blitting.
 type Sprite struct {
 	data *x11.SpriteData
+
+	// Anchor, as a fraction of the size (see SetAnchor)
+	anchorX, anchorY float64
 }
 
 // Width returns the sprite width in pixels.
@@ -20,6 +29,32 @@ func (s *Sprite) Width() int { return s.data.Width }
 // Height returns the sprite height in pixels.
 func (s *Sprite) Height() int { return s.data.Height }
 
+// IsEmpty reports whether the sprite has nothing to draw: it is nil, has
+// zero width or height, or its pixel data doesn't match its size.
+func (s *Sprite) IsEmpty() bool {
+	return s == nil || !s.data.Valid() || s.data.Width == 0 || s.data.Height == 0
+}
+
+// SetAnchor sets the point of the sprite that DrawSprite places at the
+// given position, as a fraction of its width and height: (0, 0) is the
+// top-left corner (the default), (0.5, 0.5) the centre and (1, 1) the
+// bottom-right corner. On a canvas using SetYUp, ay is measured up from
+// the bottom edge instead. DrawSpriteRegion ignores the anchor.
+func (s *Sprite) SetAnchor(ax, ay float64) {
+	s.anchorX, s.anchorY = ax, ay
+}
+
+// Anchor returns the sprite's anchor set with SetAnchor.
+func (s *Sprite) Anchor() (ax, ay float64) {
+	return s.anchorX, s.anchorY
+}
+
+// anchorOffset returns the anchor in pixels from the sprite's origin.
+func (s *Sprite) anchorOffset() (int, int) {
+	return int(math.Round(s.anchorX * float64(s.data.Width))),
+		int(math.Round(s.anchorY * float64(s.data.Height)))
+}
+
 // LoadPNG loads a PNG file from disk and returns a Sprite.
 func LoadPNG(path string) (*Sprite, error) {
 	f, err := os.Open(path)
@@ -31,18 +66,31 @@ func LoadPNG(path string) (*Sprite, error) {
 }
 
 // LoadPNGFromReader decodes a PNG from a reader and returns a Sprite.
+// An image with zero width or height returns ErrEmptyImage.
 func LoadPNGFromReader(r io.Reader) (*Sprite, error) {
 	img, _, err := image.Decode(r)
 	if err != nil {
 		return nil, err
 	}
+	if b := img.Bounds(); b.Empty() {
+		return nil, fmt.Errorf("%w (%dx%d)", ErrEmptyImage, b.Dx(), b.Dy())
+	}
 	return NewSpriteFromImage(img), nil
 }
 
 // NewSpriteFromImage converts any image.Image to a Sprite with BGRA pixel data.
-// It uses straight (non-premultiplied) alpha.
+// It uses straight (non-premultiplied) alpha. An image with no pixels
+// gives an empty sprite (see IsEmpty), which draws nothing.
 func NewSpriteFromImage(img image.Image) *Sprite {
-	bounds := img.Bounds()
+	return NewSpriteFromImageRegion(img, img.Bounds())
+}
+
+// NewSpriteFromImageRegion converts only the part of img inside r to a
+// Sprite, for carving sprites out of a large image without converting all
+// of it. r is in img's coordinate space and is clipped to img's bounds;
+// a region outside the image gives an empty sprite.
+func NewSpriteFromImageRegion(img image.Image, r image.Rectangle) *Sprite {
+	bounds := r.Intersect(img.Bounds())
 	w := bounds.Dx()
 	h := bounds.Dy()
 	pixels := make([]byte, w*h*4)
@@ -98,13 +146,57 @@ func NewSpriteFromImage(img image.Image) *Sprite {
 	}
 }
 
-// DrawSprite draws an entire sprite at (x, y) on the canvas with alpha blending.
+// DrawSprite draws an entire sprite on the canvas with alpha blending,
+// with its anchor (the top-left corner by default) at (x, y).
 func (c *Canvas) DrawSprite(s *Sprite, x, y int) {
-	c.fb.BlitSprite(s.data, x, y)
+	if s.IsEmpty() {
+		return
+	}
+	ox, oy := s.anchorOffset()
+	x, y = x-ox, y-oy
+	fy := c.flipBox(y, s.data.Height)
+	c.fb.BlitSprite(s.data, x, fy)
+	c.markDirty(x, fy, s.data.Width, s.data.Height)
+}
+
+// DrawSpriteUnchecked is DrawSprite without clipping, for hot loops
+// drawing many sprites known to be on screen, such as particles. The
+// whole sprite must lie within the canvas and its clip rectangle;
+// otherwise it draws garbage or panics. Build with -tags glowdebug to
+// have it check and panic with a clear message instead.
+func (c *Canvas) DrawSpriteUnchecked(s *Sprite, x, y int) {
+	if s.IsEmpty() {
+		return
+	}
+	ox, oy := s.anchorOffset()
+	x, y = x-ox, y-oy
+	fy := c.flipBox(y, s.data.Height)
+	c.fb.BlitSpriteFast(s.data, x, fy)
+	c.markDirty(x, fy, s.data.Width, s.data.Height)
+}
+
+// DrawSpriteScaled draws a whole sprite stretched or shrunk to
+// dstW×dstH pixels, using nearest-neighbour sampling so pixel art stays
+// crisp. The anchor is placed at (x, y) as in DrawSprite, measured on
+// the scaled size.
+func (c *Canvas) DrawSpriteScaled(s *Sprite, x, y, dstW, dstH int) {
+	if s.IsEmpty() {
+		return
+	}
+	x -= int(math.Round(s.anchorX * float64(dstW)))
+	y -= int(math.Round(s.anchorY * float64(dstH)))
+	fy := c.flipBox(y, dstH)
+	c.fb.BlitSpriteScaled(s.data, x, fy, dstW, dstH)
+	c.markDirty(x, fy, dstW, dstH)
 }
 
 // DrawSpriteRegion draws a sub-region of a sprite at (x, y) on the canvas.
 // The source region is defined by (srcX, srcY, srcW, srcH) within the sprite.
 func (c *Canvas) DrawSpriteRegion(s *Sprite, x, y, srcX, srcY, srcW, srcH int) {
-	c.fb.BlitSpriteRegion(s.data, x, y, srcX, srcY, srcW, srcH)
+	if s.IsEmpty() {
+		return
+	}
+	fy := c.flipBox(y, srcH)
+	c.fb.BlitSpriteRegion(s.data, x, fy, srcX, srcY, srcW, srcH)
+	c.markDirty(x, fy, srcW, srcH)
 }
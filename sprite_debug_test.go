@@ -0,0 +1,14 @@
+//go:build glowdebug
+
+package glow
+
+import "testing"
+
+func TestDrawSpriteUncheckedBounds(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("off-canvas DrawSpriteUnchecked didn't panic in a glowdebug build")
+		}
+	}()
+	NewCanvas(16, 16).DrawSpriteUnchecked(makeOpaqueRedSprite(4, 4), 14, 2)
+}
@@ -2,10 +2,14 @@ package glow
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"image"
 	"image/color"
 	"image/png"
+	"io"
 	"testing"
+	"testing/fstest"
 
 	"github.com/AchrafSoltani/glow/internal/x11"
 )
@@ -40,6 +44,42 @@ func makeTestPNG() []byte {
 	return buf.Bytes()
 }
 
+// makeTestBMP returns a 24-bit BMP header for a width×height image,
+// followed by pixels bytes of pixel data.
+func makeTestBMP(width, height int32, pixels int) []byte {
+	b := make([]byte, 54+pixels)
+	b[0], b[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(b[10:], 54) // Pixel data offset
+	binary.LittleEndian.PutUint32(b[14:], 40) // BITMAPINFOHEADER
+	binary.LittleEndian.PutUint32(b[18:], uint32(width))
+	binary.LittleEndian.PutUint32(b[22:], uint32(height))
+	binary.LittleEndian.PutUint16(b[28:], 24)
+	return b
+}
+
+func TestDecodeBMPBounds(t *testing.T) {
+	// 2×2 pixels, rows padded to 8 bytes
+	if img, err := decodeBMP(bytes.NewReader(makeTestBMP(2, 2, 16))); err != nil || img.Bounds().Dx() != 2 {
+		t.Fatalf("valid BMP: %v", err)
+	}
+
+	huge := makeTestBMP(1<<30, 1<<30, 16)
+	offsetPastEnd := makeTestBMP(2, 2, 16)
+	binary.LittleEndian.PutUint32(offsetPastEnd[10:], 1000)
+	offsetNegative := makeTestBMP(2, 2, 16)
+	binary.LittleEndian.PutUint32(offsetNegative[10:], 0xFFFFFFF0)
+	for name, data := range map[string][]byte{
+		"huge":            huge,
+		"truncated":       makeTestBMP(2, 2, 15),
+		"offset past end": offsetPastEnd,
+		"negative offset": offsetNegative,
+	} {
+		if _, err := decodeBMP(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s BMP decoded without error", name)
+		}
+	}
+}
+
 func TestLoadPNGFromReader(t *testing.T) {
 	data := makeTestPNG()
 	sprite, err := LoadPNGFromReader(bytes.NewReader(data))
@@ -101,6 +141,30 @@ func TestNewSpriteFromImage_Generic(t *testing.T) {
 	assertPixel(t, sprite, 1, 1, 255, 0, 0, 255)
 }
 
+func TestNewSpriteFromImageRegion(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.SetNRGBA(2, 1, color.NRGBA{255, 0, 0, 255})
+	img.SetNRGBA(3, 3, color.NRGBA{0, 0, 255, 255})
+
+	// Region hanging off the bottom-right edge is clipped to 2x3
+	sprite := NewSpriteFromImageRegion(img, image.Rect(2, 1, 10, 10))
+	if sprite.Width() != 2 || sprite.Height() != 3 {
+		t.Fatalf("expected 2x3, got %dx%d", sprite.Width(), sprite.Height())
+	}
+	assertPixel(t, sprite, 0, 0, 0, 0, 255, 255)
+	assertPixel(t, sprite, 1, 2, 255, 0, 0, 255)
+
+	// Generic path on a sub-image
+	sub := NewSpriteFromImageRegion(image.NewRGBA(image.Rect(0, 0, 4, 4)), image.Rect(1, 1, 3, 2))
+	if sub.Width() != 2 || sub.Height() != 1 {
+		t.Errorf("expected 2x1, got %dx%d", sub.Width(), sub.Height())
+	}
+
+	if empty := NewSpriteFromImageRegion(img, image.Rect(8, 8, 9, 9)); empty.Width() != 0 {
+		t.Errorf("region outside image should be empty, got width %d", empty.Width())
+	}
+}
+
 func TestBlitSprite_FullyOnScreen(t *testing.T) {
 	fb := x11.NewFramebuffer(8, 8)
 	fb.Clear(0, 0, 0) // black background
@@ -223,6 +287,42 @@ func TestAlphaBlending(t *testing.T) {
 	}
 }
 
+func TestLoadSprites(t *testing.T) {
+	// 2x1 bottom-up 24-bit BMP: red, blue (rows padded to 8 bytes)
+	bmp := make([]byte, 54+8)
+	copy(bmp, "BM")
+	bmp[10] = 54 // pixel data offset
+	bmp[14] = 40 // BITMAPINFOHEADER
+	bmp[18] = 2  // width
+	bmp[22] = 1  // height
+	bmp[26] = 1  // planes
+	bmp[28] = 24 // bits per pixel
+	copy(bmp[54:], []byte{0, 0, 255, 255, 0, 0})
+
+	fsys := fstest.MapFS{
+		"assets/player.png": {Data: makeTestPNG()},
+		"assets/tile.bmp":   {Data: bmp},
+		"assets/notes.txt":  {Data: []byte("not an image")},
+	}
+
+	sprites, err := LoadSprites(fsys, "assets/*")
+	if err != nil {
+		t.Fatalf("LoadSprites failed: %v", err)
+	}
+	if len(sprites) != 2 {
+		t.Fatalf("expected 2 sprites, got %d", len(sprites))
+	}
+	if s := sprites["player"]; s == nil || s.Width() != 4 {
+		t.Errorf("player sprite missing or wrong size")
+	}
+	tile := sprites["tile"]
+	if tile == nil || tile.Width() != 2 || tile.Height() != 1 {
+		t.Fatalf("tile sprite missing or wrong size")
+	}
+	assertPixel(t, tile, 0, 0, 0, 0, 255, 255) // red
+	assertPixel(t, tile, 1, 0, 255, 0, 0, 255) // blue
+}
+
 // --- Helpers ---
 
 func makeOpaqueRedSprite(w, h int) *Sprite {
@@ -258,3 +358,298 @@ func assertFBPixel(t *testing.T, fb *x11.Framebuffer, x, y int, er, eg, eb uint8
 			x, y, er, eg, eb, r, g, b)
 	}
 }
+
+func TestNewCheckerSprite(t *testing.T) {
+	s := NewCheckerSprite(4, 4, 2, White, Black)
+	if s.Width() != 4 || s.Height() != 4 {
+		t.Fatalf("expected 4x4, got %dx%d", s.Width(), s.Height())
+	}
+	assertPixel(t, s, 0, 0, 255, 255, 255, 255) // a
+	assertPixel(t, s, 1, 1, 255, 255, 255, 255) // same cell
+	assertPixel(t, s, 2, 0, 0, 0, 0, 255)       // b
+	assertPixel(t, s, 2, 2, 255, 255, 255, 255) // a again
+}
+
+func TestNewGradientSprite(t *testing.T) {
+	s := NewGradientSprite(3, 2, Black, White, true)
+	assertPixel(t, s, 0, 1, 0, 0, 0, 255)
+	assertPixel(t, s, 1, 1, 128, 128, 128, 255)
+	assertPixel(t, s, 2, 1, 255, 255, 255, 255)
+
+	v := NewGradientSprite(2, 3, Red, Blue, false)
+	assertPixel(t, v, 1, 0, 0, 0, 255, 255)
+	assertPixel(t, v, 1, 2, 255, 0, 0, 255)
+}
+
+func TestNewCircleSprite(t *testing.T) {
+	s := NewCircleSprite(8, Green)
+	if s.Width() != 16 || s.Height() != 16 {
+		t.Fatalf("expected 16x16, got %dx%d", s.Width(), s.Height())
+	}
+	// Centre is opaque, corners are transparent
+	assertPixel(t, s, 8, 8, 0, 255, 0, 255)
+	if a := pixelAt(s, 0, 0)[3]; a != 0 {
+		t.Errorf("corner alpha: expected 0, got %d", a)
+	}
+	// Somewhere on the edge there must be partial coverage
+	partial := false
+	for x := 0; x < 16; x++ {
+		if a := pixelAt(s, x, 1)[3]; a > 0 && a < 255 {
+			partial = true
+		}
+	}
+	if !partial {
+		t.Errorf("expected antialiased edge pixels")
+	}
+}
+
+func TestDrawSpriteAnchor(t *testing.T) {
+	fb := x11.NewFramebuffer(10, 10)
+	c := &Canvas{fb: fb}
+
+	sprite := makeOpaqueRedSprite(4, 4)
+	sprite.SetAnchor(0.5, 0.5)
+	c.DrawSprite(sprite, 5, 5)
+
+	// Centred on (5, 5): covers 3..6
+	assertFBPixel(t, fb, 3, 3, 255, 0, 0)
+	assertFBPixel(t, fb, 6, 6, 255, 0, 0)
+	assertFBPixel(t, fb, 2, 2, 0, 0, 0)
+	assertFBPixel(t, fb, 7, 7, 0, 0, 0)
+}
+
+func TestSpriteEncodeRoundTrip(t *testing.T) {
+	loaded, err := LoadPNGFromReader(bytes.NewReader(makeTestPNG()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every pixel different, to exercise literal packets
+	noisy := newBlankSprite(5, 3)
+	for i := range noisy.data.Pixels {
+		noisy.data.Pixels[i] = byte(i * 7)
+	}
+
+	sprites := map[string]*Sprite{
+		"png":     loaded,
+		"red":     makeOpaqueRedSprite(20, 10),
+		"blank":   newBlankSprite(300, 2),
+		"checker": NewCheckerSprite(16, 16, 4, Red, Blue),
+		"noisy":   noisy,
+		"empty":   newBlankSprite(0, 0),
+	}
+	for name, s := range sprites {
+		var buf bytes.Buffer
+		if err := s.Encode(&buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := DecodeSprite(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.Width() != s.Width() || got.Height() != s.Height() || !bytes.Equal(got.data.Pixels, s.data.Pixels) {
+			t.Errorf("%s: round trip changed the sprite", name)
+		}
+	}
+
+	// Large transparent areas compress well
+	var buf bytes.Buffer
+	sprites["blank"].Encode(&buf)
+	if buf.Len() > 100 {
+		t.Errorf("blank 300x2 sprite encoded to %d bytes", buf.Len())
+	}
+}
+
+func TestDecodeSpriteBackToBack(t *testing.T) {
+	first := makeOpaqueRedSprite(20, 10) // RLE
+	second := NewCheckerSprite(3, 3, 1, Red, Blue)
+	for i := range second.data.Pixels {
+		second.data.Pixels[i] = byte(i * 7) // Raw
+	}
+
+	var buf bytes.Buffer
+	first.Encode(&buf)
+	second.Encode(&buf)
+	data := buf.Bytes()
+
+	readers := map[string]func() io.Reader{
+		"byte reader": func() io.Reader { return bytes.NewReader(data) },
+		"plain reader": func() io.Reader {
+			return struct{ io.Reader }{bytes.NewReader(data)}
+		},
+	}
+	for name, newReader := range readers {
+		r := newReader()
+		for i, want := range []*Sprite{first, second} {
+			got, err := DecodeSprite(r)
+			if err != nil {
+				t.Fatalf("%s: sprite %d: %v", name, i, err)
+			}
+			if !bytes.Equal(got.data.Pixels, want.data.Pixels) {
+				t.Errorf("%s: sprite %d decoded wrong", name, i)
+			}
+		}
+	}
+}
+
+func TestDecodeSpriteInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	makeOpaqueRedSprite(4, 4).Encode(&buf)
+	good := buf.Bytes()
+
+	cases := map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XXXX"), good[4:]...),
+		"truncated": good[:len(good)-2],
+		"overrun":   append(append([]byte{}, good[:10]...), 0xff, 0, 0, 255, 255),
+	}
+	for name, data := range cases {
+		if _, err := DecodeSprite(bytes.NewReader(data)); !errors.Is(err, ErrBadSprite) {
+			t.Errorf("%s: got %v, want ErrBadSprite", name, err)
+		}
+	}
+}
+
+// quadSprite returns a 2x2 opaque sprite: red, green / blue, white
+func quadSprite() *Sprite {
+	s := newBlankSprite(2, 2)
+	s.setPixel(0, 0, Red, 255)
+	s.setPixel(1, 0, Green, 255)
+	s.setPixel(0, 1, Blue, 255)
+	s.setPixel(1, 1, White, 255)
+	return s
+}
+
+func TestBlitSpriteScaledUp(t *testing.T) {
+	fb := x11.NewFramebuffer(6, 6)
+	fb.BlitSpriteScaled(quadSprite().data, 1, 1, 4, 4)
+
+	want := [2][2][3]uint8{{{255, 0, 0}, {0, 255, 0}}, {{0, 0, 255}, {255, 255, 255}}}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c := want[y/2][x/2]
+			assertFBPixel(t, fb, 1+x, 1+y, c[0], c[1], c[2])
+		}
+	}
+	for i := 0; i < 6; i++ {
+		assertFBPixel(t, fb, i, 0, 0, 0, 0)
+		assertFBPixel(t, fb, 5, i, 0, 0, 0)
+	}
+}
+
+func TestBlitSpriteScaledDownAndClipped(t *testing.T) {
+	// 4x4 down to 2x2 samples every other pixel
+	big := newBlankSprite(4, 4)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			big.setPixel(x, y, RGB(uint8(x*10), uint8(y*10), 0), 255)
+		}
+	}
+	fb := x11.NewFramebuffer(2, 2)
+	fb.BlitSpriteScaled(big.data, 0, 0, 2, 2)
+	assertFBPixel(t, fb, 1, 1, 20, 20, 0)
+
+	// Hanging off the top-left: only the white quarter lands
+	fb = x11.NewFramebuffer(4, 4)
+	fb.BlitSpriteScaled(quadSprite().data, -2, -2, 4, 4)
+	assertFBPixel(t, fb, 0, 0, 255, 255, 255)
+	assertFBPixel(t, fb, 1, 1, 255, 255, 255)
+	assertFBPixel(t, fb, 2, 2, 0, 0, 0)
+
+	// And off the bottom-right
+	fb.BlitSpriteScaled(quadSprite().data, 2, 2, 6, 6)
+	assertFBPixel(t, fb, 3, 3, 255, 0, 0)
+	fb.BlitSpriteScaled(quadSprite().data, 4, 0, 4, 4) // fully outside
+}
+
+func TestDrawSpriteScaledBlends(t *testing.T) {
+	c := NewCanvas(4, 4)
+	c.Clear(White)
+	s := newBlankSprite(1, 1)
+	s.setPixel(0, 0, Red, 128)
+	c.DrawSpriteScaled(s, 0, 0, 3, 3)
+	r, g, b := c.fb.GetPixel(2, 2)
+	if r != 255 || g < 126 || g > 128 || b < 126 || b > 128 {
+		t.Errorf("blended pixel = (%d,%d,%d), want ~(255,127,127)", r, g, b)
+	}
+	assertFBPixel(t, c.fb, 3, 3, 255, 255, 255)
+}
+
+func TestEmptySprite(t *testing.T) {
+	s := NewSpriteFromImage(image.NewNRGBA(image.Rect(0, 0, 0, 0)))
+	if !s.IsEmpty() || s.Width() != 0 || s.Height() != 0 {
+		t.Fatalf("0x0 image: IsEmpty %v, size %dx%d", s.IsEmpty(), s.Width(), s.Height())
+	}
+	if makeOpaqueRedSprite(2, 2).IsEmpty() {
+		t.Error("2x2 sprite reported empty")
+	}
+	var nilSprite *Sprite
+	if !nilSprite.IsEmpty() {
+		t.Error("nil sprite not empty")
+	}
+
+	c := NewCanvas(8, 8)
+	c.DrawSprite(s, 2, 2)
+	c.DrawSpriteScaled(s, 2, 2, 4, 4)
+	c.DrawSpriteRegion(s, 2, 2, 0, 0, 1, 1)
+	if got := c.GetPixel(2, 2); got != Black {
+		t.Errorf("empty sprite drew %v", got)
+	}
+}
+
+func TestMalformedSprite(t *testing.T) {
+	// Claims 4x4 but holds only two pixels
+	bad := &x11.SpriteData{Width: 4, Height: 4, Pixels: bytes.Repeat([]byte{0, 0, 255, 255}, 2)}
+	if bad.Valid() {
+		t.Fatal("mismatched sprite reported valid")
+	}
+	if !(&Sprite{data: bad}).IsEmpty() {
+		t.Error("mismatched sprite not reported empty")
+	}
+
+	fb := x11.NewFramebuffer(8, 8)
+	fb.BlitSprite(bad, 0, 0)
+	fb.BlitSpriteRegion(bad, 0, 0, 0, 0, 4, 4)
+	fb.BlitSpriteScaled(bad, 0, 0, 8, 8)
+	assertFBPixel(t, fb, 0, 0, 0, 0, 0)
+	assertFBPixel(t, fb, 3, 3, 0, 0, 0)
+}
+
+func TestDrawSpriteUnchecked(t *testing.T) {
+	s := makeOpaqueRedSprite(4, 3)
+	s.data.Pixels[3] = 0       // Transparent corner
+	s.data.Pixels[4*5+3] = 128 // Translucent middle pixel
+	s.SetAnchor(0.5, 0.5)
+
+	want, got := NewCanvas(16, 16), NewCanvas(16, 16)
+	want.Clear(Blue)
+	got.Clear(Blue)
+	want.ResetDirty()
+	got.ResetDirty()
+	want.DrawSprite(s, 7, 9)
+	got.DrawSpriteUnchecked(s, 7, 9)
+
+	if !bytes.Equal(got.fb.Pixels, want.fb.Pixels) {
+		t.Error("DrawSpriteUnchecked drew differently from DrawSprite")
+	}
+	if g, w := got.DirtyRegions(), want.DirtyRegions(); len(g) != 1 || g[0] != w[0] {
+		t.Errorf("dirty regions = %v, want %v", g, w)
+	}
+}
+
+func BenchmarkDrawSprite(b *testing.B) {
+	s := makeOpaqueRedSprite(8, 8)
+	c := NewCanvas(640, 480)
+	for _, bm := range []struct {
+		name string
+		draw func(s *Sprite, x, y int)
+	}{
+		{"clipped", c.DrawSprite},
+		{"unchecked", c.DrawSpriteUnchecked},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bm.draw(s, i%600, i%440)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package glow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// Sprite file layout, all integers little-endian:
+//
+//	magic    [4]byte "GLSP"
+//	version  uint8   1
+//	encoding uint8   spriteRaw or spriteRLE
+//	width    uint16
+//	height   uint16
+//	pixels           BGRA, straight alpha, row by row
+//
+// RLE pixels are a series of packets, each a header byte n followed by
+// either n+1 literal pixels (n < 128) or one pixel repeated n-127 times
+// (n >= 128).
+const (
+	spriteMagic   = "GLSP"
+	spriteVersion = 1
+	spriteRaw     = 0
+	spriteRLE     = 1
+
+	spriteHeaderSize = 10
+	maxSpriteSize    = 16384 // Largest width or height DecodeSprite accepts
+	maxRLEPacket     = 128   // Pixels in one RLE packet
+)
+
+// ErrBadSprite is returned (wrapped) by DecodeSprite for data that isn't
+// a valid sprite file.
+var ErrBadSprite = errors.New("glow: invalid sprite data")
+
+// Encode writes the sprite in glow's own binary format, which
+// DecodeSprite loads without any image decoding or pixel conversion.
+// Tools can pre-convert PNG assets to it for faster startup. Pixels are
+// run-length encoded when that makes the file smaller, as it does for
+// sprites with large transparent or solid areas. The anchor is not
+// stored.
+func (s *Sprite) Encode(w io.Writer) error {
+	if s.data.Width > maxSpriteSize || s.data.Height > maxSpriteSize {
+		return fmt.Errorf("glow: sprite %dx%d too large to encode", s.data.Width, s.data.Height)
+	}
+
+	pixels := s.data.Pixels
+	encoding := byte(spriteRaw)
+	if rle := encodeSpriteRLE(pixels); len(rle) < len(pixels) {
+		pixels, encoding = rle, spriteRLE
+	}
+
+	header := make([]byte, spriteHeaderSize)
+	copy(header, spriteMagic)
+	header[4] = spriteVersion
+	header[5] = encoding
+	binary.LittleEndian.PutUint16(header[6:], uint16(s.data.Width))
+	binary.LittleEndian.PutUint16(header[8:], uint16(s.data.Height))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(pixels)
+	return err
+}
+
+// spriteReader is what decodeSpriteRLE needs: whole pixels and single
+// packet headers.
+type spriteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// byteReader reads single bytes straight from r, without buffering, so
+// no byte past the sprite is consumed.
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
+// DecodeSprite reads a sprite written by Sprite.Encode. It reads no
+// further than the end of the sprite, so several sprites can be decoded
+// back to back from one reader.
+func DecodeSprite(r io.Reader) (*Sprite, error) {
+	br, ok := r.(spriteReader)
+	if !ok {
+		br = byteReader{r}
+	}
+	header := make([]byte, spriteHeaderSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrBadSprite, err)
+	}
+	if string(header[:4]) != spriteMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrBadSprite)
+	}
+	if header[4] != spriteVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadSprite, header[4])
+	}
+	w := int(binary.LittleEndian.Uint16(header[6:]))
+	h := int(binary.LittleEndian.Uint16(header[8:]))
+	if w > maxSpriteSize || h > maxSpriteSize {
+		return nil, fmt.Errorf("%w: size %dx%d too large", ErrBadSprite, w, h)
+	}
+
+	pixels := make([]byte, w*h*4)
+	switch header[5] {
+	case spriteRaw:
+		if _, err := io.ReadFull(br, pixels); err != nil {
+			return nil, fmt.Errorf("%w: pixels: %v", ErrBadSprite, err)
+		}
+	case spriteRLE:
+		if err := decodeSpriteRLE(br, pixels); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown encoding %d", ErrBadSprite, header[5])
+	}
+
+	return &Sprite{data: &x11.SpriteData{Width: w, Height: h, Pixels: pixels}}, nil
+}
+
+// encodeSpriteRLE run-length encodes BGRA pixels into packets.
+func encodeSpriteRLE(pix []byte) []byte {
+	var out []byte
+	for i := 0; i < len(pix); {
+		run := 1
+		for run < maxRLEPacket && i+run*4 < len(pix) && bytes.Equal(pix[i:i+4], pix[i+run*4:i+run*4+4]) {
+			run++
+		}
+		if run > 1 {
+			out = append(out, byte(maxRLEPacket+run-1))
+			out = append(out, pix[i:i+4]...)
+			i += run * 4
+			continue
+		}
+
+		// Literal pixels, up to where the next run starts
+		start, n := i, 0
+		for n < maxRLEPacket && i < len(pix) {
+			if i+8 <= len(pix) && bytes.Equal(pix[i:i+4], pix[i+4:i+8]) {
+				break
+			}
+			i += 4
+			n++
+		}
+		out = append(out, byte(n-1))
+		out = append(out, pix[start:i]...)
+	}
+	return out
+}
+
+// decodeSpriteRLE fills pix from RLE packets read from r.
+func decodeSpriteRLE(r spriteReader, pix []byte) error {
+	for off := 0; off < len(pix); {
+		n, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("%w: pixels: %v", ErrBadSprite, io.ErrUnexpectedEOF)
+		}
+		count := int(n) + 1
+		if n >= maxRLEPacket {
+			count = int(n) - maxRLEPacket + 1
+		}
+		if off+count*4 > len(pix) {
+			return fmt.Errorf("%w: RLE packet overruns the image", ErrBadSprite)
+		}
+
+		if n < maxRLEPacket {
+			if _, err := io.ReadFull(r, pix[off:off+count*4]); err != nil {
+				return fmt.Errorf("%w: pixels: %v", ErrBadSprite, err)
+			}
+		} else {
+			if _, err := io.ReadFull(r, pix[off:off+4]); err != nil {
+				return fmt.Errorf("%w: pixels: %v", ErrBadSprite, err)
+			}
+			for i := 1; i < count; i++ {
+				copy(pix[off+i*4:], pix[off:off+4])
+			}
+		}
+		off += count * 4
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package glow
+
+import (
+	"bytes"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// SwapMode selects what the canvas holds after SwapBuffers.
+type SwapMode int
+
+const (
+	SwapClear  SwapMode = iota // Canvas starts each frame cleared to black
+	SwapRetain                 // Canvas keeps the frame just presented
+)
+
+// swapState is the double-buffering state behind SwapBuffers.
+type swapState struct {
+	mode             SwapMode
+	front            *x11.Framebuffer // Last frame SwapBuffers presented
+	scrollX, scrollY int              // Scroll offset front was shown at
+}
+
+// SetSwapMode selects whether SwapBuffers hands back a cleared canvas
+// (SwapClear, the default) or one still holding the presented frame
+// (SwapRetain).
+func (w *Window) SetSwapMode(mode SwapMode) {
+	w.swap.mode = mode
+}
+
+// SwapBuffers presents the canvas and starts a new frame, for apps that
+// draw every frame in full. The canvas is double buffered: the frame
+// just presented becomes the front buffer, and the canvas becomes a back
+// buffer that is cleared or keeps its contents according to SetSwapMode,
+// so no separate Clear is needed.
+//
+// Only the area that differs from the previous front buffer is sent to
+// the X server, which makes mostly static frames cheap. Like Present, it
+// honours SetMaxFPS and recovers a lost connection.
+func (w *Window) SwapBuffers() error {
+	err := w.presentSwap()
+	w.flipBuffers()
+	w.pace(w.maxFPS)
+	return err
+}
+
+// presentSwap uploads what changed since the previous SwapBuffers,
+// falling back to a full Present when the front buffer can't be trusted.
+func (w *Window) presentSwap() error {
+	r, full := w.swapDamage()
+	if full || w.lost.Load() || w.backBufferStale(w.visibleRegion()) {
+		w.fullPresent.Store(true)
+		return w.present()
+	}
+	if r.Empty() {
+		return nil
+	}
+
+	if err := w.putRegions([]Rect{r}, w.visibleRegion()); err != nil {
+		w.fullPresent.Store(true)
+		return w.present()
+	}
+	w.canvas.ResetDirty()
+	return nil
+}
+
+// swapDamage returns the visible area where the canvas differs from the
+// front buffer. full is true if the whole view must be uploaded: on the
+// first swap, after a resize or scroll, or under a software cursor,
+// which Present composites over the whole view.
+func (w *Window) swapDamage() (r Rect, full bool) {
+	fb, front := w.canvas.fb, w.swap.front
+	if front == nil || front.Width != fb.Width || front.Height != fb.Height ||
+		w.swap.scrollX != w.scrollX || w.swap.scrollY != w.scrollY || w.cursor != nil {
+		return Rect{}, true
+	}
+
+	view := w.visibleRegion()
+	x0, y0, x1, y1 := view.X+view.Width, -1, -1, -1
+	for y := view.Y; y < view.Y+view.Height; y++ {
+		start := (y*fb.Width + view.X) * 4
+		a := fb.Pixels[start : start+view.Width*4]
+		b := front.Pixels[start : start+view.Width*4]
+		if bytes.Equal(a, b) {
+			continue
+		}
+		if y0 < 0 {
+			y0 = y
+		}
+		y1 = y
+		// Narrow the columns from both ends of the row
+		first, last := 0, view.Width-1
+		for bytes.Equal(a[first*4:first*4+4], b[first*4:first*4+4]) {
+			first++
+		}
+		for bytes.Equal(a[last*4:last*4+4], b[last*4:last*4+4]) {
+			last--
+		}
+		x0, x1 = min(x0, view.X+first), max(x1, view.X+last)
+	}
+	if y0 < 0 {
+		return Rect{}, false
+	}
+	return Rect{X: x0, Y: y0, Width: x1 - x0 + 1, Height: y1 - y0 + 1}, false
+}
+
+// flipBuffers makes the canvas the front buffer and gives the canvas a
+// back buffer prepared according to the swap mode.
+func (w *Window) flipBuffers() {
+	fb := w.canvas.fb
+	front := w.swap.front
+	if front == nil || front.Width != fb.Width || front.Height != fb.Height {
+		front = x11.NewFramebuffer(fb.Width, fb.Height)
+	}
+	w.swap.scrollX, w.swap.scrollY = w.scrollX, w.scrollY
+
+	if w.swap.mode == SwapRetain {
+		copy(front.Pixels, fb.Pixels)
+		w.swap.front = front
+		return
+	}
+	w.swap.front = fb
+	w.canvas.fb = front
+	// The clip belongs to the canvas, not the buffer behind it
+	front.CopyClip(fb)
+	front.ClearAlpha(0, 0, 0, 255)
+	w.canvas.markAllDirty()
+}
@@ -0,0 +1,58 @@
+package glow
+
+import (
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestSwapDamage(t *testing.T) {
+	w := &Window{canvas: &Canvas{fb: x11.NewFramebuffer(20, 10)}, width: 20, height: 10}
+	if _, full := w.swapDamage(); !full {
+		t.Fatal("first swap should upload everything")
+	}
+
+	w.canvas.Clear(Red)
+	w.flipBuffers()
+	if r, g, b := w.canvas.fb.GetPixel(3, 3); r != 0 || g != 0 || b != 0 {
+		t.Fatalf("SwapClear back buffer should be black, got %d,%d,%d", r, g, b)
+	}
+
+	// Redraw the same frame but for two pixels
+	w.canvas.Clear(Red)
+	w.canvas.SetPixel(4, 2, Blue)
+	w.canvas.SetPixel(9, 6, Blue)
+	r, full := w.swapDamage()
+	if full || r != (Rect{X: 4, Y: 2, Width: 6, Height: 5}) {
+		t.Fatalf("damage %+v (full %v), want the two changed pixels' box", r, full)
+	}
+
+	w.SetSwapMode(SwapRetain)
+	w.flipBuffers()
+	if r, _, _ := w.canvas.fb.GetPixel(0, 0); r != 255 {
+		t.Fatal("SwapRetain back buffer should keep the presented frame")
+	}
+	if r, full := w.swapDamage(); full || !r.Empty() {
+		t.Fatalf("unchanged frame should have no damage, got %+v", r)
+	}
+}
+
+func TestSwapKeepsClip(t *testing.T) {
+	w := &Window{canvas: &Canvas{fb: x11.NewFramebuffer(20, 10)}, width: 20, height: 10}
+	w.canvas.SetClipRect(0, 0, 5, 5)
+
+	// Two frames, so both buffers of the pair are drawn on
+	for frame := range 2 {
+		w.canvas.DrawRect(0, 0, 20, 10, Red)
+		if r, _, _ := w.canvas.fb.GetPixel(10, 8); r != 0 {
+			t.Errorf("frame %d drew outside the clip", frame)
+		}
+		if r, _, _ := w.canvas.fb.GetPixel(2, 2); r != 255 {
+			t.Errorf("frame %d didn't draw inside the clip", frame)
+		}
+		w.flipBuffers()
+	}
+	if got := w.canvas.ClipRect(); got != (Rect{Width: 5, Height: 5}) {
+		t.Errorf("ClipRect() after swapping = %+v", got)
+	}
+}
@@ -0,0 +1,259 @@
+package glow
+
+import (
+	"math"
+	"strings"
+)
+
+// Metrics of the bundled DefaultFont at scale 1. The text methods draw
+// in DefaultFont, so they hold unless it is replaced.
+const (
+	GlyphWidth  = bitmapFontSize // Pixels per glyph, horizontally
+	GlyphHeight = bitmapFontSize // Pixels per glyph, vertically
+	CharAdvance = bitmapFontSize // Horizontal distance between glyph origins
+	LineHeight  = bitmapFontSize // Vertical distance between lines of text
+	TabWidth    = 4              // Tab stops every TabWidth characters
+)
+
+// DrawText draws text with its top-left corner at (x, y) in DefaultFont,
+// each font pixel drawn as a scale×scale square. '\n' starts a new line
+// and '\t' advances to the next tab stop.
+func (c *Canvas) DrawText(x, y int, text string, color Color, scale int) {
+	if scale < 1 {
+		scale = 1
+	}
+	if c.yUp {
+		_, h := MeasureText(text, scale)
+		y = c.flipBox(y, h)
+	}
+	f := DefaultFont
+	for i, line := range strings.Split(text, "\n") {
+		c.drawTextLine(f, x, y+i*f.lineHeight*scale, expandTabs(line), color, scale)
+	}
+}
+
+// MeasureText returns the size in pixels DrawText would cover for text
+// at the given scale.
+func MeasureText(text string, scale int) (width, height int) {
+	if scale < 1 {
+		scale = 1
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	width, height = DefaultFont.Measure(strings.Join(lines, "\n"))
+	return width * scale, height * scale
+}
+
+// DrawTextWrapped draws text at scale 1 inside r, breaking lines at
+// spaces so they fit r's width. Words longer than a line are broken
+// wherever the line is full, '\n' forces a new line and '\t' advances to
+// the next tab stop. Lines that would extend below r are not drawn.
+// It returns the number of lines drawn and the height in pixels they use.
+func (c *Canvas) DrawTextWrapped(r Rect, text string, color Color) (lines, height int) {
+	if r.Width <= 0 {
+		return 0, 0
+	}
+	f := DefaultFont
+	textHeight := f.ascent + f.descent
+	r.Y = c.flipBox(r.Y, r.Height)
+
+	measure := func(line []rune) int {
+		w, _ := f.Measure(string(line))
+		return w
+	}
+	for _, line := range wrapText(text, r.Width, measure) {
+		top := r.Y + lines*f.lineHeight
+		if top+textHeight > r.Y+r.Height {
+			break
+		}
+		c.drawTextLine(f, r.X, top, line, color, 1)
+		lines++
+	}
+
+	if lines > 0 {
+		height = (lines-1)*f.lineHeight + textHeight
+	}
+	return lines, height
+}
+
+// DrawTextOutlined draws text in fill surrounded by a one-font-pixel
+// outline, keeping it readable over busy backgrounds. The outline is
+// drawn by offsetting the text in all eight directions.
+func (c *Canvas) DrawTextOutlined(x, y int, text string, fill, outline Color, scale int) {
+	if scale < 1 {
+		scale = 1
+	}
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if dx != 0 || dy != 0 {
+				c.DrawText(x+dx*scale, y+dy*scale, text, outline, scale)
+			}
+		}
+	}
+	c.DrawText(x, y, text, fill, scale)
+}
+
+// DrawTextGradient draws text filled with a vertical gradient running
+// from top to bottom across the height of each line of glyphs.
+func (c *Canvas) DrawTextGradient(x, y int, text string, top, bottom Color, scale int) {
+	if scale < 1 {
+		scale = 1
+	}
+	f := DefaultFont
+	rows := (f.ascent+f.descent)*scale - 1
+	colorAt := func(py int) Color {
+		if rows <= 0 {
+			return top
+		}
+		return lerpColor(top, bottom, math.Min(float64(py)/float64(rows), 1))
+	}
+	if c.yUp {
+		_, h := MeasureText(text, scale)
+		y = c.flipBox(y, h)
+	}
+	for i, line := range strings.Split(text, "\n") {
+		c.drawGlyphs(f, x, y+i*f.lineHeight*scale, expandTabs(line), scale, colorAt)
+	}
+}
+
+// drawTextLine draws a single line that contains no '\n' or '\t'.
+func (c *Canvas) drawTextLine(f *Font, x, y int, line string, color Color, scale int) {
+	c.drawGlyphs(f, x, y, line, scale, func(int) Color { return color })
+}
+
+// drawGlyphs draws a single line of glyphs in f with its top-left at
+// (x, y), each font pixel as a scale×scale square. colorAt gives the
+// color for each pixel row, counted from the top of the line.
+func (c *Canvas) drawGlyphs(f *Font, x, y int, line string, scale int, colorAt func(py int) Color) {
+	if w, h := f.Measure(line); w > 0 {
+		c.markDirty(x, y, w*scale, h*scale)
+	}
+	pen := 0.0
+	for _, r := range line {
+		f.cache.mu.Lock()
+		g := f.glyph(r)
+		gx := int(math.Round(pen)) + g.offX
+		gy := f.ascent + g.offY // Glyph top, from the top of the line
+		for row := 0; row < g.height; row++ {
+			for col := 0; col < g.width; col++ {
+				a := g.mask[row*g.stride+col]
+				if a == 0 {
+					continue
+				}
+				px := x + (gx+col)*scale
+				for sy := 0; sy < scale; sy++ {
+					py := (gy+row)*scale + sy
+					color := colorAt(py)
+					c.fb.SetOpacity(color.A)
+					if a == 255 {
+						c.fb.DrawRect(px, y+py, scale, 1, color.R, color.G, color.B)
+						continue
+					}
+					for sx := 0; sx < scale; sx++ {
+						c.fb.BlendPixel(px+sx, y+py, color.R, color.G, color.B, a)
+					}
+				}
+			}
+		}
+		pen += g.advance
+		f.cache.mu.Unlock()
+	}
+}
+
+// expandTabs replaces each tab with spaces up to the next tab stop.
+func expandTabs(line string) string {
+	if !strings.ContainsRune(line, '\t') {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := TabWidth - col%TabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
+// wrapText breaks text into lines no wider than maxWidth, as measured by
+// width, with tabs expanded. Lines are broken at spaces where possible;
+// whitespace at a wrap point is dropped, indentation after '\n' is kept.
+// A character wider than maxWidth gets a line of its own.
+func wrapText(text string, maxWidth int, width func(line []rune) int) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		var line []rune
+		wrapped := false
+
+		flush := func() {
+			lines = append(lines, strings.TrimRight(string(line), " "))
+			line = line[:0]
+			wrapped = true
+		}
+
+		for _, tok := range splitWords(para) {
+			if tok[0] == ' ' || tok[0] == '\t' {
+				if wrapped && len(line) == 0 {
+					continue // no leading whitespace on wrapped lines
+				}
+				for _, r := range tok {
+					n := 1
+					if r == '\t' {
+						n = TabWidth - len(line)%TabWidth
+					}
+					for i := 0; i < n; i++ {
+						line = append(line, ' ')
+					}
+				}
+				if width(line) > maxWidth {
+					flush()
+				}
+				continue
+			}
+
+			word := []rune(tok)
+			if len(line) > 0 && width(append(line[:len(line):len(line)], word...)) > maxWidth {
+				flush()
+			}
+			// Hard-break words that can't fit on a line of their own
+			for len(word) > 1 && width(word) > maxWidth {
+				n := 1
+				for n < len(word) && width(word[:n+1]) <= maxWidth {
+					n++
+				}
+				lines = append(lines, string(word[:n]))
+				word = word[n:]
+				wrapped = true
+			}
+			line = append(line, word...)
+		}
+
+		if len(line) > 0 || !wrapped {
+			lines = append(lines, strings.TrimRight(string(line), " "))
+		}
+	}
+	return lines
+}
+
+// splitWords splits s into alternating runs of whitespace (spaces and
+// tabs) and non-whitespace.
+func splitWords(s string) []string {
+	var toks []string
+	start := 0
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || isBlank(s[i]) != isBlank(s[start]) {
+			toks = append(toks, s[start:i])
+			start = i
+		}
+	}
+	return toks
+}
+
+func isBlank(b byte) bool { return b == ' ' || b == '\t' }
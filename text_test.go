@@ -0,0 +1,65 @@
+package glow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text    string
+		maxCols int
+		want    []string
+	}{
+		{"hello world", 20, []string{"hello world"}},
+		{"hello world", 8, []string{"hello", "world"}},
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"abcdefghijkl", 5, []string{"abcde", "fghij", "kl"}},
+		{"a\tb", 10, []string{"a   b"}},
+		{"line one\n  indented", 20, []string{"line one", "  indented"}},
+		{"first\n\nthird", 20, []string{"first", "", "third"}},
+	}
+
+	for _, tt := range tests {
+		got := wrapText(tt.text, tt.maxCols, func(line []rune) int { return len(line) })
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d): expected %q, got %q", tt.text, tt.maxCols, tt.want, got)
+		}
+	}
+}
+
+func TestMeasureText(t *testing.T) {
+	w, h := MeasureText("AB", 1)
+	if w != 2*CharAdvance || h != GlyphHeight {
+		t.Errorf("MeasureText(AB, 1): expected %dx%d, got %dx%d", 2*CharAdvance, GlyphHeight, w, h)
+	}
+	w, h = MeasureText("A\nBC\tD", 2)
+	if w != (TabWidth+1)*CharAdvance*2 || h != (LineHeight+GlyphHeight)*2 {
+		t.Errorf("MeasureText two lines at scale 2: got %dx%d", w, h)
+	}
+}
+
+func TestDrawTextUsesDefaultFont(t *testing.T) {
+	a, b := NewCanvas(40, 20), NewCanvas(40, 20)
+	a.DrawText(0, 0, "Hi?\nok", White, 1)
+	b.DrawTextFont(nil, 0, 0, "Hi?\nok", White)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if a.GetPixel(x, y) != b.GetPixel(x, y) {
+				t.Fatalf("DrawText and DrawTextFont(nil) differ at (%d, %d)", x, y)
+			}
+		}
+	}
+
+	// At scale 2 each font pixel is a 2×2 square: 'A' starts ..##....
+	c := NewCanvas(16, 16)
+	c.DrawText(0, 0, "A", White, 2)
+	for x := 0; x < 16; x++ {
+		want := x >= 4 && x < 8
+		for y := 0; y < 2; y++ {
+			if got := c.GetPixel(x, y) == White; got != want {
+				t.Errorf("scaled 'A' pixel (%d, %d) drawn = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package glow
+
+import "strings"
+
+// Dead keysyms: accent keys that type nothing themselves but modify the
+// next letter
+const (
+	keysymDeadGrave      Keysym = 0xfe50
+	keysymDeadAcute      Keysym = 0xfe51
+	keysymDeadCircumflex Keysym = 0xfe52
+	keysymDeadTilde      Keysym = 0xfe53
+	keysymDeadDiaeresis  Keysym = 0xfe57
+	keysymDeadAboveRing  Keysym = 0xfe58
+	keysymDeadCedilla    Keysym = 0xfe5b
+)
+
+// deadKey describes how a dead key composes: ASCII base letters in the
+// order of their accented forms, and the accent typed on its own.
+type deadKey struct {
+	spacing  rune
+	base     string
+	composed string
+}
+
+var deadKeys = map[Keysym]deadKey{
+	keysymDeadGrave:      {'`', "aeiouAEIOU", "àèìòùÀÈÌÒÙ"},
+	keysymDeadAcute:      {'´', "aeiouyAEIOUY", "áéíóúýÁÉÍÓÚÝ"},
+	keysymDeadCircumflex: {'^', "aeiouAEIOU", "âêîôûÂÊÎÔÛ"},
+	keysymDeadTilde:      {'~', "anoANO", "ãñõÃÑÕ"},
+	keysymDeadDiaeresis:  {'¨', "aeiouyAEIOU", "äëïöüÿÄËÏÖÜ"},
+	keysymDeadAboveRing:  {'°', "aA", "åÅ"},
+	keysymDeadCedilla:    {'¸', "cC", "çÇ"},
+}
+
+// compose returns what typing r after the dead key types: the accented
+// letter, the accent alone for a space, or else the accent followed by r.
+func (d deadKey) compose(r rune) string {
+	if r == ' ' {
+		return string(d.spacing)
+	}
+	if i := strings.IndexRune(d.base, r); i >= 0 {
+		return string([]rune(d.composed)[i])
+	}
+	return string(d.spacing) + string(r)
+}
+
+// SetTextInput turns EventTextInput on or off (off by default). When on,
+// every EventKeyDown that types something is followed by an
+// EventTextInput carrying the text, with Shift, Caps Lock and the
+// keyboard layout applied and dead keys composed (´ then e types é).
+// Keys that edit rather than type, such as Backspace, Enter and the
+// arrows, only produce EventKeyDown. Turn it on while a text field has
+// the focus.
+func (w *Window) SetTextInput(enabled bool) {
+	w.textInput.Store(enabled)
+}
+
+// textInputEvent returns the EventTextInput following the key event e,
+// or nil if it types nothing. It runs on the event goroutine, which owns
+// the pending dead key.
+func (w *Window) textInputEvent(e *Event) *Event {
+	if e.Type != EventKeyDown || !w.textInput.Load() {
+		return nil
+	}
+
+	if _, ok := deadKeys[e.Keysym]; ok && e.Mods&ModCtrl == 0 {
+		if w.deadKey == e.Keysym {
+			// Pressed twice: type the accent itself
+			w.deadKey = 0
+			return &Event{Type: EventTextInput, Text: string(deadKeys[e.Keysym].spacing)}
+		}
+		w.deadKey = e.Keysym
+		return nil
+	}
+
+	if e.Rune == 0 {
+		// Modifiers leave a pending accent for the next letter;
+		// anything else cancels it
+		if !isModifierKeysym(e.Keysym) {
+			w.deadKey = 0
+		}
+		return nil
+	}
+
+	text := string(e.Rune)
+	if w.deadKey != 0 {
+		text = deadKeys[w.deadKey].compose(e.Rune)
+		w.deadKey = 0
+	}
+	return &Event{Type: EventTextInput, Text: text}
+}
+
+// isModifierKeysym reports whether k is a modifier key (Shift, Ctrl,
+// Caps Lock, Alt, AltGr and the like).
+func isModifierKeysym(k Keysym) bool {
+	switch {
+	case k >= 0xffe1 && k <= 0xffee: // Shift_L to Hyper_R
+		return true
+	case k == 0xff7e, k == 0xfe03: // Mode_switch, ISO_Level3_Shift (AltGr)
+		return true
+	}
+	return false
+}
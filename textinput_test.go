@@ -0,0 +1,98 @@
+package glow
+
+import (
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// typeKey converts a key press through w and returns the text it types.
+func typeKey(w *Window, key Key, state uint16) string {
+	e := w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyPress, Keycode: uint8(key), State: state})
+	if text := w.textInputEvent(e); text != nil {
+		return text.Text
+	}
+	return ""
+}
+
+func TestTextInput(t *testing.T) {
+	w := &Window{eventChan: make(chan Event, 8)}
+	if got := typeKey(w, KeyA, 0); got != "" {
+		t.Errorf("text input is off by default, got %q", got)
+	}
+	w.SetTextInput(true)
+
+	tests := []struct {
+		key   Key
+		state uint16
+		want  string
+	}{
+		{KeyA, 0, "a"},
+		{KeyA, x11.ShiftMask, "A"},
+		{KeyA, x11.LockMask, "A"},
+		{Key1, 0, "1"},
+		{Key1, x11.ShiftMask, "!"},
+		{KeyMinus, x11.ShiftMask, "_"},
+		{KeySpace, 0, " "},
+		{KeyA, x11.ControlMask, ""},
+		{KeyBackspace, 0, ""},
+		{KeyEnter, 0, ""},
+		{KeyShiftL, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := typeKey(w, tt.key, tt.state); got != tt.want {
+			t.Errorf("key %d with state %#x typed %q, want %q", tt.key, tt.state, got, tt.want)
+		}
+	}
+
+	// Key releases type nothing
+	e := w.convertEvent(x11.KeyEvent{EventType: x11.EventKeyRelease, Keycode: uint8(KeyA)})
+	if text := w.textInputEvent(e); text != nil {
+		t.Errorf("KeyUp typed %q", text.Text)
+	}
+}
+
+func TestTextInputDeadKeys(t *testing.T) {
+	// Keycode 34 (right of P) is a dead acute accent, shifted a dead
+	// diaeresis
+	m, err := x11.ParseKeyboardMapping(8, keyboardMappingReply(8, map[uint8][]uint32{
+		34: {uint32(keysymDeadAcute), uint32(keysymDeadDiaeresis)},
+		26: {'e', 'E'},
+		33: {'p', 'P'},
+		65: {' ', ' '},
+		50: {0xffe1, 0xffe1}, // Shift_L
+		22: {0xff08, 0xff08}, // BackSpace
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Window{eventChan: make(chan Event, 8)}
+	w.keymap.Store(newKeymap(m))
+	w.SetTextInput(true)
+
+	type press struct {
+		key   Key
+		state uint16
+	}
+	tests := []struct {
+		keys []press
+		want string
+	}{
+		{[]press{{34, 0}, {26, 0}}, "é"},
+		{[]press{{34, 0}, {50, 0}, {26, x11.ShiftMask}}, "É"}, // Shift between keeps the accent
+		{[]press{{34, x11.ShiftMask}, {26, 0}}, "ë"},
+		{[]press{{34, 0}, {65, 0}}, "´"},
+		{[]press{{34, 0}, {34, 0}}, "´"},
+		{[]press{{34, 0}, {33, 0}}, "´p"},
+		{[]press{{34, 0}, {22, 0}, {26, 0}}, "e"}, // Backspace cancels the accent
+	}
+	for _, tt := range tests {
+		got := ""
+		for _, p := range tt.keys {
+			got += typeKey(w, p.key, p.state)
+		}
+		if got != tt.want {
+			t.Errorf("%v typed %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,730 @@
+package glow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// Font is a TrueType font rasterized at a fixed size. Glyphs are
+// rendered with antialiased edges the first time they are drawn and
+// kept in an atlas for later use (see CacheStats).
+//
+// Only the glyph outlines are used: hinting, kerning and OpenType
+// layout are not applied, and CFF-flavoured (.otf) fonts aren't
+// supported.
+//
+// DefaultFont is a Font too, drawn from bitmaps instead of outlines.
+type Font struct {
+	size       float64
+	scale      float64 // Pixels per font unit
+	ascent     int     // Pixels above the baseline
+	descent    int     // Pixels below the baseline
+	lineHeight int
+
+	glyf, loca  []byte
+	hmtx        []byte
+	longLoca    bool
+	numGlyphs   int
+	numHMetrics int
+	glyphIndex  func(r rune) int
+
+	cache *glyphCache
+
+	bitmap map[rune][8]uint8 // Glyphs of a bitmap font; nil for TrueType
+}
+
+// fontGlyph is a rasterized glyph: a coverage mask and where it sits
+// relative to the pen position on the baseline. Row y of the mask
+// starts at mask[y*stride], as cached glyphs live in a shared atlas.
+type fontGlyph struct {
+	mask          []uint8
+	stride        int
+	width, height int
+	offX, offY    int
+	advance       float64
+}
+
+// LoadTTF loads a TrueType font from a .ttf file, rendered with an em
+// size of size pixels.
+func LoadTTF(path string, size float64) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("glow: load font: %w", err)
+	}
+	return ParseTTF(data, size)
+}
+
+// ParseTTF parses TrueType font data, rendered with an em size of size
+// pixels.
+func ParseTTF(data []byte, size float64) (*Font, error) {
+	if size <= 0 {
+		return nil, errors.New("glow: font size must be positive")
+	}
+	tables, err := ttfTables(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "maxp", "hhea", "hmtx", "cmap", "loca", "glyf"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("glow: font has no %s table", tag)
+		}
+	}
+	head, maxp, hhea := tables["head"], tables["maxp"], tables["hhea"]
+	if len(head) < 54 || len(maxp) < 6 || len(hhea) < 36 {
+		return nil, errors.New("glow: font tables are truncated")
+	}
+
+	unitsPerEm := binary.BigEndian.Uint16(head[18:])
+	if unitsPerEm == 0 {
+		return nil, errors.New("glow: font has zero units per em")
+	}
+	f := &Font{
+		size:        size,
+		scale:       size / float64(unitsPerEm),
+		glyf:        tables["glyf"],
+		loca:        tables["loca"],
+		hmtx:        tables["hmtx"],
+		longLoca:    int16(binary.BigEndian.Uint16(head[50:])) != 0,
+		numGlyphs:   int(binary.BigEndian.Uint16(maxp[4:])),
+		numHMetrics: int(binary.BigEndian.Uint16(hhea[34:])),
+	}
+	if f.numHMetrics == 0 || len(f.hmtx) < f.numHMetrics*4 {
+		return nil, errors.New("glow: font has no horizontal metrics")
+	}
+
+	ascender := int16(binary.BigEndian.Uint16(hhea[4:]))
+	descender := int16(binary.BigEndian.Uint16(hhea[6:]))
+	lineGap := int16(binary.BigEndian.Uint16(hhea[8:]))
+	f.ascent = int(math.Ceil(float64(ascender) * f.scale))
+	f.descent = int(math.Ceil(float64(-descender) * f.scale))
+	f.lineHeight = f.ascent + f.descent + int(math.Round(float64(lineGap)*f.scale))
+
+	if f.glyphIndex, err = ttfCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+
+	// Atlas cells fit the font's bounding box, plus the pixel the
+	// rasterizer may round out to on each side
+	xMin := int16(binary.BigEndian.Uint16(head[36:]))
+	yMin := int16(binary.BigEndian.Uint16(head[38:]))
+	xMax := int16(binary.BigEndian.Uint16(head[40:]))
+	yMax := int16(binary.BigEndian.Uint16(head[42:]))
+	if xMax < xMin || yMax < yMin {
+		return nil, errors.New("glow: font bounding box is invalid")
+	}
+	cellW := int(math.Ceil(float64(int(xMax)-int(xMin))*f.scale)) + 2
+	cellH := int(math.Ceil(float64(int(yMax)-int(yMin))*f.scale)) + 2
+	if cellW > maxGlyphSize || cellH > maxGlyphSize {
+		return nil, fmt.Errorf("glow: font glyphs too large at size %v", size)
+	}
+	capacity := (glyphAtlasSize / max(cellW, 1)) * (glyphAtlasSize / max(cellH, 1))
+	f.cache = newGlyphCache(cellW, cellH, min(capacity, f.numGlyphs))
+	return f, nil
+}
+
+// Size returns the em size in pixels the font was loaded with.
+func (f *Font) Size() float64 { return f.size }
+
+// Ascent returns how many pixels the font rises above its baseline.
+func (f *Font) Ascent() int { return f.ascent }
+
+// LineHeight returns the distance in pixels between lines of text.
+func (f *Font) LineHeight() int { return f.lineHeight }
+
+// Measure returns the size in pixels DrawTextFont would cover for text.
+func (f *Font) Measure(text string) (width, height int) {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		pen := 0.0
+		for _, r := range line {
+			pen += f.runeAdvance(r)
+		}
+		width = max(width, int(math.Ceil(pen)))
+	}
+	height = (len(lines)-1)*f.lineHeight + f.ascent + f.descent
+	return width, height
+}
+
+// DrawTextFont draws text in f with the top-left of its first line at
+// (x, y); the baseline lies Ascent pixels below y. A nil f draws in
+// DefaultFont. '\n' starts a new line. Glyph edges are alpha-blended
+// into the canvas.
+func (c *Canvas) DrawTextFont(f *Font, x, y int, text string, color Color) {
+	if f == nil {
+		f = DefaultFont
+	}
+	w, h := f.Measure(text)
+	y = c.flipBox(y, h)
+	c.markDirty(x, y, w, h)
+	c.fb.SetOpacity(color.A)
+
+	for i, line := range strings.Split(text, "\n") {
+		baseline := y + i*f.lineHeight + f.ascent
+		pen := float64(x)
+		for _, r := range line {
+			f.cache.mu.Lock()
+			g := f.glyph(r)
+			gx := int(math.Round(pen)) + g.offX
+			gy := baseline + g.offY
+			for row := 0; row < g.height; row++ {
+				for col := 0; col < g.width; col++ {
+					if a := g.mask[row*g.stride+col]; a > 0 {
+						c.fb.BlendPixel(gx+col, gy+row, color.R, color.G, color.B, a)
+					}
+				}
+			}
+			pen += g.advance
+			f.cache.mu.Unlock()
+		}
+	}
+}
+
+// glyph returns the rasterized glyph for r, rendering it on first use.
+// Runes the font lacks use its missing-glyph shape (glyph 0). The caller
+// holds f.cache.mu while it uses the glyph.
+func (f *Font) glyph(r rune) *fontGlyph {
+	if g, ok := f.cache.lookup(r); ok {
+		return g
+	}
+	if f.bitmap != nil {
+		return f.cache.insert(r, f.bitmapGlyph(r))
+	}
+	id := f.lookupIndex(r)
+	g := f.rasterize(f.outline(id, 0))
+	g.advance = float64(f.advanceWidth(id)) * f.scale
+	return f.cache.insert(r, g)
+}
+
+// runeAdvance returns how far r moves the pen, in pixels.
+func (f *Font) runeAdvance(r rune) float64 {
+	if f.bitmap != nil {
+		return bitmapFontSize
+	}
+	return float64(f.advanceWidth(f.lookupIndex(r))) * f.scale
+}
+
+// lookupIndex maps r to a glyph index, 0 if the font lacks it.
+func (f *Font) lookupIndex(r rune) int {
+	id := f.glyphIndex(r)
+	if id >= f.numGlyphs {
+		id = 0
+	}
+	return id
+}
+
+// advanceWidth returns a glyph's advance in font units.
+func (f *Font) advanceWidth(id int) int {
+	if id >= f.numHMetrics {
+		id = f.numHMetrics - 1
+	}
+	return int(binary.BigEndian.Uint16(f.hmtx[id*4:]))
+}
+
+// ttfTables maps table tags to their data.
+func ttfTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errors.New("glow: font data too short")
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // 1.0, "true"
+	default:
+		return nil, errors.New("glow: not a TrueType font")
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+n*16 {
+		return nil, errors.New("glow: font table directory truncated")
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		rec := data[12+i*16:]
+		off := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		if off < 0 || length < 0 || off+length > len(data) {
+			return nil, fmt.Errorf("glow: font table %q out of bounds", rec[:4])
+		}
+		tables[string(rec[:4])] = data[off : off+length]
+	}
+	return tables, nil
+}
+
+// ttfCmap picks a Unicode subtable from the cmap and returns a lookup
+// from runes to glyph indices. Formats 4 (BMP) and 12 (full Unicode)
+// are supported.
+func ttfCmap(cmap []byte) (func(rune) int, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("glow: font cmap truncated")
+	}
+	var best []byte
+	bestRank := 0
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n && 4+i*8+8 <= len(cmap); i++ {
+		rec := cmap[4+i*8:]
+		platform, encoding := binary.BigEndian.Uint16(rec), binary.BigEndian.Uint16(rec[2:])
+		off := int(binary.BigEndian.Uint32(rec[4:]))
+		if off+4 > len(cmap) {
+			continue
+		}
+		rank := 0
+		switch {
+		case platform == 3 && encoding == 10, platform == 0 && encoding >= 4:
+			rank = 2 // Full Unicode
+		case platform == 3 && encoding == 1, platform == 0:
+			rank = 1 // BMP only
+		}
+		if rank > bestRank {
+			best, bestRank = cmap[off:], rank
+		}
+	}
+	if best == nil {
+		return nil, errors.New("glow: font has no Unicode cmap")
+	}
+
+	switch binary.BigEndian.Uint16(best) {
+	case 4:
+		return cmapFormat4(best)
+	case 12:
+		return cmapFormat12(best)
+	}
+	return nil, fmt.Errorf("glow: unsupported cmap format %d", binary.BigEndian.Uint16(best))
+}
+
+func cmapFormat4(t []byte) (func(rune) int, error) {
+	if len(t) < 14 {
+		return nil, errors.New("glow: cmap format 4 truncated")
+	}
+	segX2 := int(binary.BigEndian.Uint16(t[6:]))
+	ends := 14
+	starts := ends + segX2 + 2
+	deltas := starts + segX2
+	ranges := deltas + segX2
+	if len(t) < ranges+segX2 {
+		return nil, errors.New("glow: cmap format 4 truncated")
+	}
+	u16 := func(off int) int {
+		if off+2 > len(t) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(t[off:]))
+	}
+
+	return func(r rune) int {
+		if r > 0xFFFF {
+			return 0
+		}
+		c := int(r)
+		for i := 0; i < segX2; i += 2 {
+			if c > u16(ends+i) {
+				continue
+			}
+			start := u16(starts + i)
+			if c < start {
+				return 0
+			}
+			delta, rangeOff := u16(deltas+i), u16(ranges+i)
+			if rangeOff == 0 {
+				return (c + delta) & 0xFFFF
+			}
+			id := u16(ranges + i + rangeOff + (c-start)*2)
+			if id == 0 {
+				return 0
+			}
+			return (id + delta) & 0xFFFF
+		}
+		return 0
+	}, nil
+}
+
+func cmapFormat12(t []byte) (func(rune) int, error) {
+	if len(t) < 16 {
+		return nil, errors.New("glow: cmap format 12 truncated")
+	}
+	n := int(binary.BigEndian.Uint32(t[12:]))
+	if len(t) < 16+n*12 {
+		return nil, errors.New("glow: cmap format 12 truncated")
+	}
+	return func(r rune) int {
+		c := uint32(r)
+		for i := 0; i < n; i++ {
+			g := t[16+i*12:]
+			start, end := binary.BigEndian.Uint32(g), binary.BigEndian.Uint32(g[4:])
+			if c >= start && c <= end {
+				return int(binary.BigEndian.Uint32(g[8:]) + c - start)
+			}
+		}
+		return 0
+	}, nil
+}
+
+// fontPoint is an outline point in font units, y up.
+type fontPoint struct {
+	x, y    float64
+	onCurve bool
+}
+
+// outline returns the contours of glyph id. depth guards against
+// composite glyphs that reference themselves.
+func (f *Font) outline(id, depth int) [][]fontPoint {
+	if depth > 8 {
+		return nil
+	}
+	var start, end int
+	if f.longLoca {
+		if (id+2)*4 > len(f.loca) {
+			return nil
+		}
+		start = int(binary.BigEndian.Uint32(f.loca[id*4:]))
+		end = int(binary.BigEndian.Uint32(f.loca[id*4+4:]))
+	} else {
+		if (id+2)*2 > len(f.loca) {
+			return nil
+		}
+		start = int(binary.BigEndian.Uint16(f.loca[id*2:])) * 2
+		end = int(binary.BigEndian.Uint16(f.loca[id*2+2:])) * 2
+	}
+	if end <= start || end > len(f.glyf) || end-start < 10 {
+		return nil // Empty glyph, e.g. space
+	}
+	g := f.glyf[start:end]
+
+	n := int(int16(binary.BigEndian.Uint16(g)))
+	if n < 0 {
+		return f.compositeOutline(g[10:], depth)
+	}
+	return simpleOutline(g[10:], n)
+}
+
+// simpleOutline decodes the points of a simple glyph with n contours.
+// It returns nil if the data is malformed.
+func simpleOutline(g []byte, n int) [][]fontPoint {
+	if len(g) < n*2+2 {
+		return nil
+	}
+	ends := make([]int, n)
+	for i := range ends {
+		ends[i] = int(binary.BigEndian.Uint16(g[i*2:]))
+	}
+	numPoints := 0
+	if n > 0 {
+		numPoints = ends[n-1] + 1
+	}
+	p := n*2 + 2 + int(binary.BigEndian.Uint16(g[n*2:])) // skip instructions
+
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		if p >= len(g) {
+			return nil
+		}
+		fl := g[p]
+		p++
+		flags = append(flags, fl)
+		if fl&8 != 0 { // repeat
+			if p >= len(g) {
+				return nil
+			}
+			for r := int(g[p]); r > 0 && len(flags) < numPoints; r-- {
+				flags = append(flags, fl)
+			}
+			p++
+		}
+	}
+
+	points := make([]fontPoint, numPoints)
+	// Coordinates are deltas; bit "short" selects a byte, whose sign
+	// comes from bit "same", else "same" repeats the previous value
+	readCoords := func(short, same byte, set func(i int, v float64)) bool {
+		v := 0
+		for i, fl := range flags {
+			switch {
+			case fl&short != 0:
+				if p >= len(g) {
+					return false
+				}
+				d := int(g[p])
+				p++
+				if fl&same == 0 {
+					d = -d
+				}
+				v += d
+			case fl&same == 0:
+				if p+2 > len(g) {
+					return false
+				}
+				v += int(int16(binary.BigEndian.Uint16(g[p:])))
+				p += 2
+			}
+			set(i, float64(v))
+		}
+		return true
+	}
+	if !readCoords(2, 16, func(i int, v float64) { points[i].x = v }) ||
+		!readCoords(4, 32, func(i int, v float64) { points[i].y = v }) {
+		return nil
+	}
+	for i, fl := range flags {
+		points[i].onCurve = fl&1 != 0
+	}
+
+	contours := make([][]fontPoint, 0, n)
+	first := 0
+	for _, e := range ends {
+		if e < first || e >= numPoints {
+			return nil
+		}
+		contours = append(contours, points[first:e+1])
+		first = e + 1
+	}
+	return contours
+}
+
+// compositeOutline assembles a glyph made of transformed copies of
+// other glyphs. Components positioned by point matching are placed at
+// the origin.
+func (f *Font) compositeOutline(g []byte, depth int) [][]fontPoint {
+	var contours [][]fontPoint
+	p := 0
+	for {
+		if p+4 > len(g) {
+			return contours
+		}
+		flags := binary.BigEndian.Uint16(g[p:])
+		id := int(binary.BigEndian.Uint16(g[p+2:]))
+		p += 4
+
+		var dx, dy float64
+		if flags&1 != 0 { // ARG_1_AND_2_ARE_WORDS
+			if p+4 > len(g) {
+				return contours
+			}
+			dx = float64(int16(binary.BigEndian.Uint16(g[p:])))
+			dy = float64(int16(binary.BigEndian.Uint16(g[p+2:])))
+			p += 4
+		} else {
+			if p+2 > len(g) {
+				return contours
+			}
+			dx, dy = float64(int8(g[p])), float64(int8(g[p+1]))
+			p += 2
+		}
+		if flags&2 == 0 { // not ARGS_ARE_XY_VALUES
+			dx, dy = 0, 0
+		}
+
+		// 2.14 fixed-point transform
+		f2dot14 := func() float64 {
+			if p+2 > len(g) {
+				return 1
+			}
+			v := float64(int16(binary.BigEndian.Uint16(g[p:]))) / 16384
+			p += 2
+			return v
+		}
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		switch {
+		case flags&8 != 0: // WE_HAVE_A_SCALE
+			a = f2dot14()
+			d = a
+		case flags&0x40 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			a, d = f2dot14(), f2dot14()
+		case flags&0x80 != 0: // WE_HAVE_A_TWO_BY_TWO
+			a, b, c, d = f2dot14(), f2dot14(), f2dot14(), f2dot14()
+		}
+
+		for _, contour := range f.outline(id, depth+1) {
+			moved := make([]fontPoint, len(contour))
+			for i, pt := range contour {
+				moved[i] = fontPoint{
+					x:       a*pt.x + c*pt.y + dx,
+					y:       b*pt.x + d*pt.y + dy,
+					onCurve: pt.onCurve,
+				}
+			}
+			contours = append(contours, moved)
+		}
+
+		if flags&0x20 == 0 { // no MORE_COMPONENTS
+			return contours
+		}
+	}
+}
+
+// rasterize renders contours into a coverage mask at the font's scale.
+func (f *Font) rasterize(contours [][]fontPoint) *fontGlyph {
+	// Flatten to line segments in pixels, y down
+	var segs [][4]float64
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	addLine := func(x0, y0, x1, y1 float64) {
+		segs = append(segs, [4]float64{x0, y0, x1, y1})
+		minX, maxX = math.Min(minX, math.Min(x0, x1)), math.Max(maxX, math.Max(x0, x1))
+		minY, maxY = math.Min(minY, math.Min(y0, y1)), math.Max(maxY, math.Max(y0, y1))
+	}
+	for _, contour := range contours {
+		flattenContour(contour, f.scale, addLine)
+	}
+	if len(segs) == 0 {
+		return &fontGlyph{}
+	}
+
+	offX, offY := int(math.Floor(minX)), int(math.Floor(minY))
+	w, h := int(math.Ceil(maxX))-offX, int(math.Ceil(maxY))-offY
+	if w <= 0 || h <= 0 {
+		return &fontGlyph{}
+	}
+	// A glyph far outside the font's bounding box is malformed; drawing
+	// it would take more memory than any real glyph needs
+	if w > 2*f.cache.cellW || h > 2*f.cache.cellH {
+		return &fontGlyph{}
+	}
+
+	// Signed-area accumulation: each edge deposits how much it covers
+	// of every pixel it crosses, and a running sum along each row turns
+	// that into coverage
+	stride := w + 2
+	acc := make([]float64, stride*h)
+	for _, s := range segs {
+		accumulateLine(acc, stride, w, h,
+			s[0]-float64(offX), s[1]-float64(offY), s[2]-float64(offX), s[3]-float64(offY))
+	}
+
+	mask := make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		sum := 0.0
+		for x := 0; x < w; x++ {
+			sum += acc[y*stride+x]
+			mask[y*w+x] = uint8(math.Min(math.Abs(sum), 1)*255 + 0.5)
+		}
+	}
+	return &fontGlyph{mask: mask, stride: w, width: w, height: h, offX: offX, offY: offY}
+}
+
+// flattenContour converts a TrueType contour of on- and off-curve
+// points into line segments, scaled to pixels with y pointing down.
+// Consecutive off-curve points imply an on-curve point between them.
+func flattenContour(c []fontPoint, scale float64, line func(x0, y0, x1, y1 float64)) {
+	if len(c) < 2 {
+		return
+	}
+	pts := make([]fontPoint, 0, len(c)+1)
+	for _, p := range c {
+		pts = append(pts, fontPoint{p.x * scale, -p.y * scale, p.onCurve})
+	}
+	mid := func(a, b fontPoint) fontPoint {
+		return fontPoint{(a.x + b.x) / 2, (a.y + b.y) / 2, true}
+	}
+
+	// Rotate so the contour starts on the curve, adding the implied
+	// point if every point is off it
+	start := -1
+	for i, p := range pts {
+		if p.onCurve {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		pts = append([]fontPoint{mid(pts[len(pts)-1], pts[0])}, pts...)
+	} else {
+		pts = append(pts[start:], pts[:start]...)
+	}
+
+	n := len(pts)
+	cur := pts[0]
+	for i := 1; i <= n; i++ {
+		p := pts[i%n]
+		if p.onCurve {
+			line(cur.x, cur.y, p.x, p.y)
+			cur = p
+			continue
+		}
+		end := pts[(i+1)%n]
+		if end.onCurve {
+			i++
+		} else {
+			end = mid(p, end)
+		}
+		quadTo(cur.x, cur.y, p.x, p.y, end.x, end.y, line)
+		cur = end
+	}
+}
+
+// quadTo flattens a quadratic Bézier into enough lines that the error
+// stays well under a pixel.
+func quadTo(x0, y0, cx, cy, x1, y1 float64, line func(x0, y0, x1, y1 float64)) {
+	dev := math.Hypot(x0-2*cx+x1, y0-2*cy+y1)
+	steps := max(1, int(math.Ceil(math.Sqrt(dev*2))))
+	px, py := x0, y0
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		u := 1 - t
+		nx := u*u*x0 + 2*u*t*cx + t*t*x1
+		ny := u*u*y0 + 2*u*t*cy + t*t*y1
+		line(px, py, nx, ny)
+		px, py = nx, ny
+	}
+}
+
+// accumulateLine adds the signed area a line segment covers to acc, a
+// w×h grid stored with the given stride.
+func accumulateLine(acc []float64, stride, w, h int, x0, y0, x1, y1 float64) {
+	if y0 == y1 {
+		return
+	}
+	dir := 1.0
+	if y0 > y1 {
+		dir = -1
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	clampX := func(x float64) float64 { return math.Max(0, math.Min(float64(w), x)) }
+	dxdy := (x1 - x0) / (y1 - y0)
+	x := x0
+	if y0 < 0 {
+		x -= y0 * dxdy
+	}
+
+	for y := max(0, int(y0)); y < min(h, int(math.Ceil(y1))); y++ {
+		row := y * stride
+		dy := math.Min(float64(y+1), y1) - math.Max(float64(y), y0)
+		xnext := x + dxdy*dy
+		d := dy * dir
+
+		xa, xb := clampX(x), clampX(xnext)
+		if xa > xb {
+			xa, xb = xb, xa
+		}
+		xaFloor := math.Floor(xa)
+		xai := int(xaFloor)
+		xbCeil := math.Ceil(xb)
+		xbi := int(xbCeil)
+
+		if xbi <= xai+1 {
+			// The edge stays within one pixel column
+			xmf := 0.5*(xa+xb) - xaFloor
+			acc[row+xai] += d - d*xmf
+			acc[row+xai+1] += d * xmf
+		} else {
+			s := 1 / (xb - xa)
+			xaf := xa - xaFloor
+			a0 := 0.5 * s * (1 - xaf) * (1 - xaf)
+			xbf := xb - xbCeil + 1
+			am := 0.5 * s * xbf * xbf
+			acc[row+xai] += d * a0
+			if xbi == xai+2 {
+				acc[row+xai+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - xaf)
+				acc[row+xai+1] += d * (a1 - a0)
+				for xi := xai + 2; xi < xbi-1; xi++ {
+					acc[row+xi] += d * s
+				}
+				a2 := a1 + float64(xbi-xai-3)*s
+				acc[row+xbi-1] += d * (1 - a2 - am)
+			}
+			acc[row+xbi] += d * am
+		}
+		x = xnext
+	}
+}
@@ -0,0 +1,206 @@
+package glow
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// testTTF builds a minimal TrueType font with 1000 units per em whose
+// only glyph, for 'A', is a square from (100, 0) to (900, 800).
+func testTTF() []byte {
+	return buildTestTTF([4]int{100, 0, 900, 800}, 800)
+}
+
+// buildTestTTF builds testTTF's font with the given head bounding box
+// (xMin, yMin, xMax, yMax) and an 'A' square side units across.
+func buildTestTTF(bbox [4]int, side int) []byte {
+	be := binary.BigEndian
+	u16 := func(vs ...int) []byte {
+		b := make([]byte, len(vs)*2)
+		for i, v := range vs {
+			be.PutUint16(b[i*2:], uint16(v))
+		}
+		return b
+	}
+
+	head := make([]byte, 54)
+	be.PutUint16(head[18:], 1000) // unitsPerEm; short loca
+	copy(head[36:], u16(bbox[0], bbox[1], bbox[2], bbox[3]))
+	maxp := u16(0, 0x5000, 2) // version 0.5, 2 glyphs
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0x10000-200))
+	be.PutUint16(hhea[34:], 2)
+	hmtx := u16(500, 0, 1000, 0)
+
+	// Format 4 subtable mapping 'A' to glyph 1
+	sub := u16(4, 32, 0, 4, 4, 1, 0,
+		'A', 0xFFFF, 0,
+		'A', 0xFFFF,
+		1-'A', 1,
+		0, 0)
+	cmap := append(u16(0, 1, 3, 1, 0, 12), sub...)
+
+	glyph := append(u16(1, 100, 0, 900, 800, 3, 0), 1, 1, 1, 1)
+	glyph = append(glyph, u16(100, side, 0, 0x10000-side)...) // x deltas
+	glyph = append(glyph, u16(0, 0, side, 0)...)              // y deltas
+	loca := u16(0, 0, len(glyph)/2)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{
+		{"cmap", cmap}, {"glyf", glyph}, {"head", head}, {"hhea", hhea},
+		{"hmtx", hmtx}, {"loca", loca}, {"maxp", maxp},
+	}
+	font := u16(1, 0, len(tables), 0, 0, 0)
+	off := len(font) + len(tables)*16
+	var body []byte
+	for _, t := range tables {
+		rec := make([]byte, 16)
+		copy(rec, t.tag)
+		be.PutUint32(rec[8:], uint32(off+len(body)))
+		be.PutUint32(rec[12:], uint32(len(t.data)))
+		font = append(font, rec...)
+		body = append(body, t.data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(font, body...)
+}
+
+func TestDrawTextFont(t *testing.T) {
+	f, err := ParseTTF(testTTF(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Ascent() != 8 || f.LineHeight() != 10 {
+		t.Fatalf("ascent %d, line height %d; want 8, 10", f.Ascent(), f.LineHeight())
+	}
+	if w, h := f.Measure("AA\nA"); w != 20 || h != 20 {
+		t.Fatalf("Measure = %dx%d, want 20x20", w, h)
+	}
+
+	c := &Canvas{fb: x11.NewFramebuffer(30, 12)}
+	c.DrawTextFont(f, 0, 0, "AA", White)
+	// The square covers x 1-8 and the 8 rows above the baseline
+	assertFBPixel(t, c.fb, 4, 4, 255, 255, 255)
+	assertFBPixel(t, c.fb, 1, 0, 255, 255, 255)
+	assertFBPixel(t, c.fb, 8, 7, 255, 255, 255)
+	assertFBPixel(t, c.fb, 0, 4, 0, 0, 0)
+	assertFBPixel(t, c.fb, 9, 4, 0, 0, 0)
+	assertFBPixel(t, c.fb, 4, 8, 0, 0, 0)
+	// The second glyph starts one advance (10 px) along
+	assertFBPixel(t, c.fb, 11, 4, 255, 255, 255)
+
+	// Runes the font lacks fall back to the empty glyph 0
+	c.DrawTextFont(f, 20, 0, "Z", White)
+	assertFBPixel(t, c.fb, 24, 4, 0, 0, 0)
+}
+
+func TestFontHugeGlyph(t *testing.T) {
+	// Glyph outline far outside the font's bounding box
+	f, err := ParseTTF(buildTestTTF([4]int{100, 0, 900, 800}, 30000), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCanvas(40, 40)
+	c.DrawTextFont(f, 0, 0, "A", White)
+	if c.GetPixel(20, 20) != Black {
+		t.Error("oversized glyph was drawn")
+	}
+
+	// Bounding boxes too big for the size, or upside down
+	bad := map[string][]byte{
+		"huge":     buildTestTTF([4]int{0x10000 - 32768, 0x10000 - 32768, 32767, 32767}, 800),
+		"inverted": buildTestTTF([4]int{900, 0, 100, 800}, 800),
+	}
+	for name, data := range bad {
+		if _, err := ParseTTF(data, 1000); err == nil {
+			t.Errorf("%s bounding box: no error", name)
+		}
+	}
+}
+
+func TestFontGlyphCache(t *testing.T) {
+	f, err := ParseTTF(testTTF(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Canvas{fb: x11.NewFramebuffer(30, 12)}
+
+	c.DrawTextFont(f, 0, 0, "AA", White)
+	if s := f.CacheStats(); s.Misses != 1 || s.Hits != 1 || s.Glyphs != 1 || s.Capacity != 2 {
+		t.Fatalf("stats after AA: %+v", s)
+	}
+
+	// With room for one glyph, alternating runes evict each other
+	f.cache = newGlyphCache(10, 10, 1)
+	c.DrawTextFont(f, 0, 0, "AZ", White)
+	c.Clear(Black)
+	c.DrawTextFont(f, 10, 0, "A", White)
+	if s := f.CacheStats(); s.Misses != 3 || s.Evictions != 2 || s.Glyphs != 1 {
+		t.Fatalf("stats after eviction: %+v", s)
+	}
+	// The glyph redrawn from its new cell is intact
+	assertFBPixel(t, c.fb, 11, 0, 255, 255, 255)
+	assertFBPixel(t, c.fb, 18, 7, 255, 255, 255)
+	assertFBPixel(t, c.fb, 19, 4, 0, 0, 0)
+}
+
+func TestFontGlyphCacheConcurrent(t *testing.T) {
+	f, err := ParseTTF(testTTF(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One cell, so the goroutines keep evicting each other's glyphs
+	f.cache = newGlyphCache(10, 10, 1)
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := &Canvas{fb: x11.NewFramebuffer(30, 12)}
+			for range 50 {
+				c.DrawTextFont(f, 0, 0, "AZ"[i%2:], White)
+				f.CacheStats()
+			}
+		}()
+	}
+	wg.Wait()
+	if s := f.CacheStats(); s.Hits+s.Misses != 4*50*3/2 {
+		t.Errorf("%d lookups counted, want %d", s.Hits+s.Misses, 4*50*3/2)
+	}
+}
+
+func TestDefaultFont(t *testing.T) {
+	if w, h := DefaultFont.Measure("ab\ncd"); w != 16 || h != 16 {
+		t.Errorf("Measure = %dx%d, want 16x16", w, h)
+	}
+
+	c := NewCanvas(24, 8)
+	c.DrawTextFont(nil, 0, 0, "A─é", White)
+	// Top row of 'A' is ..##....
+	for x := 0; x < 8; x++ {
+		want := Black
+		if x == 2 || x == 3 {
+			want = White
+		}
+		if got := c.GetPixel(x, 0); got != want {
+			t.Errorf("'A' pixel (%d, 0) = %+v, want %+v", x, got, want)
+		}
+	}
+	// The box-drawing line spans its whole cell
+	if c.GetPixel(8, 3) != White || c.GetPixel(15, 4) != White || c.GetPixel(8, 2) != Black {
+		t.Error("'─' doesn't fill rows 3 and 4 of its cell")
+	}
+	// Missing characters draw as '?', whose top row is .####...
+	if c.GetPixel(17, 0) != White || c.GetPixel(16, 0) != Black {
+		t.Error("missing character not drawn as '?'")
+	}
+}
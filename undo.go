@@ -0,0 +1,96 @@
+package glow
+
+// CanvasState is a copy of a canvas's pixels taken by Canvas.Snapshot.
+// Each snapshot costs width × height × 4 bytes (about 1.9 MB at 800×600).
+type CanvasState struct {
+	width, height int
+	pixels        []byte
+}
+
+// Width returns the width of the canvas when the snapshot was taken.
+func (s *CanvasState) Width() int { return s.width }
+
+// Height returns the height of the canvas when the snapshot was taken.
+func (s *CanvasState) Height() int { return s.height }
+
+// Snapshot copies the canvas's current pixels.
+func (c *Canvas) Snapshot() *CanvasState {
+	pixels := make([]byte, len(c.fb.Pixels))
+	copy(pixels, c.fb.Pixels)
+	return &CanvasState{
+		width:  c.fb.Width,
+		height: c.fb.Height,
+		pixels: pixels,
+	}
+}
+
+// Restore copies a snapshot back onto the canvas. If the canvas has been
+// resized since, only the area both sizes share is restored; the rest of
+// the canvas is left as is.
+func (c *Canvas) Restore(s *CanvasState) {
+	if s == nil {
+		return
+	}
+	c.markDirty(0, 0, s.width, s.height)
+	if s.width == c.fb.Width && s.height == c.fb.Height {
+		copy(c.fb.Pixels, s.pixels)
+		return
+	}
+
+	rowBytes := min(s.width, c.fb.Width) * 4
+	for y := 0; y < min(s.height, c.fb.Height); y++ {
+		copy(c.fb.Pixels[y*c.fb.Width*4:][:rowBytes], s.pixels[y*s.width*4:])
+	}
+}
+
+// UndoStack holds canvas snapshots for undo, newest on top. With a limit
+// set, pushing onto a full stack discards the oldest snapshot, capping
+// memory at limit snapshots.
+//
+//	undo := glow.NewUndoStack(20)
+//	// before each stroke:
+//	undo.Push(canvas.Snapshot())
+//	// on Ctrl+Z:
+//	if s := undo.Pop(); s != nil {
+//		canvas.Restore(s)
+//	}
+type UndoStack struct {
+	states []*CanvasState
+	limit  int
+}
+
+// NewUndoStack creates an undo stack holding at most limit snapshots.
+// A limit of 0 or less means unlimited.
+func NewUndoStack(limit int) *UndoStack {
+	return &UndoStack{limit: limit}
+}
+
+// Push adds a snapshot on top of the stack.
+func (u *UndoStack) Push(s *CanvasState) {
+	if u.limit > 0 && len(u.states) >= u.limit {
+		// Drop the oldest, releasing its pixels
+		copy(u.states, u.states[1:])
+		u.states[len(u.states)-1] = nil
+		u.states = u.states[:len(u.states)-1]
+	}
+	u.states = append(u.states, s)
+}
+
+// Pop removes and returns the newest snapshot, or nil if the stack is empty.
+func (u *UndoStack) Pop() *CanvasState {
+	if len(u.states) == 0 {
+		return nil
+	}
+	s := u.states[len(u.states)-1]
+	u.states[len(u.states)-1] = nil
+	u.states = u.states[:len(u.states)-1]
+	return s
+}
+
+// Len returns the number of snapshots on the stack.
+func (u *UndoStack) Len() int { return len(u.states) }
+
+// Clear discards every snapshot.
+func (u *UndoStack) Clear() {
+	u.states = nil
+}
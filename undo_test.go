@@ -0,0 +1,54 @@
+package glow
+
+import (
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	c := &Canvas{fb: x11.NewFramebuffer(4, 4)}
+	c.Clear(Red)
+	snap := c.Snapshot()
+
+	c.Clear(Blue)
+	c.Restore(snap)
+	if got := c.GetPixel(2, 2); got != Red {
+		t.Errorf("after restore: expected red, got %v", got)
+	}
+
+	// The snapshot must not alias the canvas
+	c.SetPixel(0, 0, Green)
+	c.Restore(snap)
+	if got := c.GetPixel(0, 0); got != Red {
+		t.Errorf("snapshot aliased canvas: got %v", got)
+	}
+
+	// Restoring onto a larger canvas only touches the shared area
+	c.Resize(6, 6)
+	c.Clear(Blue)
+	c.Restore(snap)
+	if got := c.GetPixel(3, 3); got != Red {
+		t.Errorf("shared area: expected red, got %v", got)
+	}
+	if got := c.GetPixel(5, 5); got != Blue {
+		t.Errorf("outside snapshot: expected blue, got %v", got)
+	}
+}
+
+func TestUndoStackLimit(t *testing.T) {
+	u := NewUndoStack(2)
+	a := &CanvasState{width: 1}
+	b := &CanvasState{width: 2}
+	c := &CanvasState{width: 3}
+	u.Push(a)
+	u.Push(b)
+	u.Push(c) // discards a
+
+	if u.Len() != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", u.Len())
+	}
+	if u.Pop() != c || u.Pop() != b || u.Pop() != nil {
+		t.Errorf("unexpected pop order")
+	}
+}
@@ -0,0 +1,77 @@
+package glow
+
+// SetVirtualSize makes the canvas width×height pixels regardless of the
+// window's size, so a scene larger than the window can be drawn once and
+// panned with SetScroll. Present uploads only the window-sized area at
+// the scroll offset. The canvas keeps this size when the window is
+// resized. SetVirtualSize(0, 0) goes back to a canvas that follows the
+// window size.
+func (w *Window) SetVirtualSize(width, height int) {
+	if width <= 0 || height <= 0 {
+		w.virtualW, w.virtualH = 0, 0
+		w.canvas.Resize(w.width, w.height)
+	} else {
+		w.virtualW, w.virtualH = width, height
+		w.canvas.Resize(width, height)
+	}
+	w.SetScroll(w.scrollX, w.scrollY)
+}
+
+// VirtualSize returns the canvas size set with SetVirtualSize, or 0, 0
+// if the canvas follows the window size.
+func (w *Window) VirtualSize() (width, height int) {
+	return w.virtualW, w.virtualH
+}
+
+// SetScroll sets the canvas position shown at the window's top-left
+// corner. It is clamped so the window never shows past the canvas edges.
+func (w *Window) SetScroll(x, y int) {
+	w.scrollX = clampInt(x, 0, max(w.canvas.Width()-w.width, 0))
+	w.scrollY = clampInt(y, 0, max(w.canvas.Height()-w.height, 0))
+}
+
+// Scroll returns the current scroll offset.
+func (w *Window) Scroll() (x, y int) {
+	return w.scrollX, w.scrollY
+}
+
+// visibleRegion returns the area of the canvas the window shows.
+func (w *Window) visibleRegion() Rect {
+	fb := w.canvas.fb
+	return Rect{
+		X:      w.scrollX,
+		Y:      w.scrollY,
+		Width:  min(w.width, fb.Width-w.scrollX),
+		Height: min(w.height, fb.Height-w.scrollY),
+	}
+}
+
+// viewPixels returns the pixels of the visible region, packed row after
+// row. When the whole canvas is visible it is returned without copying.
+func (w *Window) viewPixels(r Rect) []byte {
+	fb := w.canvas.fb
+	if r.X == 0 && r.Y == 0 && r.Width == fb.Width && r.Height == fb.Height {
+		return fb.Pixels
+	}
+
+	rowBytes := r.Width * 4
+	if cap(w.viewBuf) < rowBytes*r.Height {
+		w.viewBuf = make([]byte, rowBytes*r.Height)
+	}
+	buf := w.viewBuf[:rowBytes*r.Height]
+	for row := 0; row < r.Height; row++ {
+		off := ((r.Y+row)*fb.Width + r.X) * 4
+		copy(buf[row*rowBytes:(row+1)*rowBytes], fb.Pixels[off:])
+	}
+	return buf
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
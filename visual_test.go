@@ -0,0 +1,105 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func TestWindowHasAlpha(t *testing.T) {
+	rgb := x11.VisualInfo{ID: 0x21, Depth: 24, Class: x11.VisualTrueColor,
+		RedMask: 0xFF0000, GreenMask: 0xFF00, BlueMask: 0xFF}
+	argb := rgb
+	argb.ID, argb.Depth = 0x22, 32
+
+	for _, tt := range []struct {
+		name   string
+		depth  uint8
+		visual uint32
+		want   bool
+	}{
+		{"rgb", 24, 0x21, false},
+		{"argb", 32, 0x22, true},
+		{"unknown visual", 32, 0x99, false},
+	} {
+		conn := &x11.Connection{RootDepth: tt.depth, RootVisual: tt.visual,
+			Visuals: []x11.VisualInfo{rgb, argb}}
+		w := &Window{conn: conn}
+		if got := w.HasAlpha(); got != tt.want {
+			t.Errorf("%s: HasAlpha = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := w.Depth(); got != tt.depth {
+			t.Errorf("%s: Depth = %d, want %d", tt.name, got, tt.depth)
+		}
+	}
+}
+
+func TestTranslucentWindowVisual(t *testing.T) {
+	client, server := net.Pipe()
+	conn := x11.NewConnection(client)
+	conn.ResourceIDBase = 0x200000
+	conn.ResourceIDMask = 0xFFFF
+	conn.RootWindow, conn.RootDepth, conn.RootVisual = 0x100, 24, 0x21
+	conn.Visuals = []x11.VisualInfo{
+		{ID: 0x21, Depth: 24, Class: x11.VisualTrueColor, RedMask: 0xFF0000, GreenMask: 0xFF00, BlueMask: 0xFF},
+		{ID: 0x22, Depth: 32, Class: x11.VisualTrueColor, RedMask: 0xFF0000, GreenMask: 0xFF00, BlueMask: 0xFF},
+	}
+	defer conn.Close()
+
+	// Record requests, answering InternAtom and failing the keymap
+	// queries so the window falls back to the US layout
+	reqs := make(chan []byte, 64)
+	go func() {
+		seq := uint16(0)
+		for {
+			hdr := make([]byte, 4)
+			if _, err := io.ReadFull(server, hdr); err != nil {
+				return
+			}
+			req := make([]byte, int(binary.LittleEndian.Uint16(hdr[2:]))*4)
+			copy(req, hdr)
+			if _, err := io.ReadFull(server, req[4:]); err != nil {
+				return
+			}
+			seq++
+			reqs <- req
+			reply := make([]byte, 32)
+			switch req[0] {
+			case x11.OpInternAtom:
+				reply[0] = 1
+				binary.LittleEndian.PutUint32(reply[8:], 0x300+uint32(seq))
+			case x11.OpGetKeyboardMapping, x11.OpGetModifierMapping:
+				reply[1] = 2 // BadValue
+			default:
+				continue
+			}
+			binary.LittleEndian.PutUint16(reply[2:], seq)
+			server.Write(reply)
+		}
+	}()
+
+	w, err := createWindow(conn, "t", 40, 30, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.HasAlpha() || w.Depth() != 32 {
+		t.Errorf("HasAlpha = %v, Depth = %d; want true, 32", w.HasAlpha(), w.Depth())
+	}
+
+	colormap := nextRequest(t, reqs, x11.OpCreateColormap)
+	if v := binary.LittleEndian.Uint32(colormap[12:]); v != 0x22 {
+		t.Errorf("colormap for visual %#x, want 0x22", v)
+	}
+	create := nextRequest(t, reqs, x11.OpCreateWindow)
+	if create[1] != 32 || binary.LittleEndian.Uint32(create[24:]) != 0x22 {
+		t.Errorf("window of depth %d on visual %#x, want 32 on 0x22", create[1], binary.LittleEndian.Uint32(create[24:]))
+	}
+	mask := binary.LittleEndian.Uint32(create[28:])
+	if mask&x11.CWColormap == 0 || mask&x11.CWBorderPixel == 0 ||
+		binary.LittleEndian.Uint32(create[44:]) != binary.LittleEndian.Uint32(colormap[4:]) {
+		t.Errorf("window created without its colormap: mask %#x", mask)
+	}
+}
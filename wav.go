@@ -0,0 +1,194 @@
+package glow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/AchrafSoltani/glow/internal/pulse"
+)
+
+// WAVE format tags from the fmt chunk
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xfffe
+)
+
+// maxWAVData is the largest data chunk decodeWAV accepts, to fail
+// cleanly on corrupt sizes instead of attempting a huge allocation.
+const maxWAVData = 1 << 30
+
+// AudioClip is PCM audio loaded from a WAV file.
+type AudioClip struct {
+	SampleRate int    // In Hz
+	Channels   int    // Interleaved in Data
+	BitDepth   int    // Bits per sample: 8 (unsigned) or 16 (signed)
+	Data       []byte // PCM samples, little-endian, a whole number of frames
+}
+
+// LoadWAV loads a WAV file holding 8-bit unsigned or 16-bit signed PCM.
+// Compressed and float WAV files return an error.
+func LoadWAV(path string) (*AudioClip, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	clip, err := LoadWAVFromReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	return clip, nil
+}
+
+// LoadWAVFromReader is LoadWAV reading the file from r.
+func LoadWAVFromReader(r io.Reader) (*AudioClip, error) {
+	spec, pcm, err := decodeWAV(r)
+	if err != nil {
+		return nil, err
+	}
+	return &AudioClip{
+		SampleRate: int(spec.Rate),
+		Channels:   int(spec.Channels),
+		BitDepth:   spec.SampleSize() * 8,
+		Data:       pcm,
+	}, nil
+}
+
+// Format returns the clip's sample format.
+func (c *AudioClip) Format() SampleFormat {
+	if c.BitDepth == 8 {
+		return SampleU8
+	}
+	return SampleS16LE
+}
+
+// Duration returns how long the clip plays for.
+func (c *AudioClip) Duration() time.Duration {
+	bytesPerSec := c.SampleRate * c.Channels * c.BitDepth / 8
+	if bytesPerSec <= 0 {
+		return 0
+	}
+	return time.Duration(len(c.Data)) * time.Second / time.Duration(bytesPerSec)
+}
+
+// NewAudioContext creates an audio context in the clip's sample rate,
+// channel count and format, so its data plays as is:
+//
+//	clip, err := glow.LoadWAV("music.wav")
+//	...
+//	ctx, err := clip.NewAudioContext()
+//	...
+//	ctx.NewPlayer(bytes.NewReader(clip.Data)).Play()
+func (c *AudioClip) NewAudioContext() (*AudioContext, error) {
+	return NewAudioContextFmt(c.SampleRate, c.Channels, c.Format())
+}
+
+// spec returns the clip's format as a pulse sample spec.
+func (c *AudioClip) spec() pulse.SampleSpec {
+	format, _ := c.Format().pulseFormat()
+	return pulse.SampleSpec{Format: format, Channels: uint8(c.Channels), Rate: uint32(c.SampleRate)}
+}
+
+// decodeWAV reads a RIFF/WAVE file holding integer PCM, returning its
+// sample spec and PCM data. Samples are stored little-endian, so the
+// data plays as is.
+func decodeWAV(r io.Reader) (pulse.SampleSpec, []byte, error) {
+	var spec pulse.SampleSpec
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return spec, nil, fmt.Errorf("glow audio: wav header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return spec, nil, errors.New("glow audio: not a WAV file")
+	}
+
+	haveFmt := false
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return spec, nil, fmt.Errorf("glow audio: wav: no data chunk: %w", err)
+		}
+		id := string(hdr[0:4])
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 || size > 1024 {
+				return spec, nil, fmt.Errorf("glow audio: wav: bad fmt chunk size %d", size)
+			}
+			b := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return spec, nil, fmt.Errorf("glow audio: wav fmt chunk: %w", err)
+			}
+			var err error
+			if spec, err = parseWAVFormat(b[:size]); err != nil {
+				return spec, nil, err
+			}
+			haveFmt = true
+
+		case "data":
+			if !haveFmt {
+				return spec, nil, errors.New("glow audio: wav: data chunk before fmt chunk")
+			}
+			if size > maxWAVData {
+				return spec, nil, fmt.Errorf("glow audio: wav: data chunk of %d bytes too large", size)
+			}
+			pcm := make([]byte, size)
+			if _, err := io.ReadFull(r, pcm); err != nil {
+				return spec, nil, fmt.Errorf("glow audio: wav data chunk: %w", err)
+			}
+			// Drop a trailing partial frame
+			return spec, pcm[:len(pcm)-len(pcm)%spec.FrameSize()], nil
+
+		default:
+			// Chunks are padded to an even size
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return spec, nil, fmt.Errorf("glow audio: wav %q chunk: %w", id, err)
+			}
+		}
+	}
+}
+
+// parseWAVFormat converts the body of a fmt chunk to a sample spec.
+func parseWAVFormat(b []byte) (pulse.SampleSpec, error) {
+	var spec pulse.SampleSpec
+	tag := binary.LittleEndian.Uint16(b[0:])
+	channels := binary.LittleEndian.Uint16(b[2:])
+	rate := binary.LittleEndian.Uint32(b[4:])
+	bits := binary.LittleEndian.Uint16(b[14:])
+
+	// WAVE_FORMAT_EXTENSIBLE keeps the real tag at the start of the
+	// sub-format GUID
+	if tag == wavFormatExtensible && len(b) >= 26 {
+		tag = binary.LittleEndian.Uint16(b[24:])
+	}
+	switch tag {
+	case wavFormatPCM:
+	case wavFormatFloat:
+		return spec, errors.New("glow audio: wav: float samples are not supported")
+	default:
+		return spec, fmt.Errorf("glow audio: wav: compressed format %#x is not supported", tag)
+	}
+
+	switch bits {
+	case 8:
+		spec.Format = pulse.SampleU8
+	case 16:
+		spec.Format = pulse.SampleS16LE
+	default:
+		return spec, fmt.Errorf("glow audio: wav: %d-bit samples are not supported", bits)
+	}
+	if channels == 0 || channels > 32 || rate == 0 {
+		return spec, fmt.Errorf("glow audio: wav: bad format (%d channels at %d Hz)", channels, rate)
+	}
+	spec.Channels = uint8(channels)
+	spec.Rate = rate
+	return spec, nil
+}
@@ -0,0 +1,80 @@
+package glow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// makeTestWAV builds a WAV file with the given format tag, a LIST chunk
+// before the data to be skipped, and pcm as its data.
+func makeTestWAV(tag uint16, channels uint16, rate uint32, bits uint16, pcm []byte) []byte {
+	var b bytes.Buffer
+	le := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(4 + 8 + 16 + 8 + 3 + 1 + 8 + len(pcm)))
+	b.WriteString("WAVE")
+	b.WriteString("fmt ")
+	le(uint32(16))
+	le(tag)
+	le(channels)
+	le(rate)
+	le(rate * uint32(channels) * uint32(bits/8)) // byte rate
+	le(channels * (bits / 8))                    // block align
+	le(bits)
+	b.WriteString("LIST")
+	le(uint32(3))
+	b.WriteString("abc\x00") // Odd size, padded
+	b.WriteString("data")
+	le(uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
+
+func TestLoadWAVFromReader(t *testing.T) {
+	pcm := []byte{0x80, 0x90, 0xa0, 0xb0, 0xc0} // 8-bit mono
+	clip, err := LoadWAVFromReader(bytes.NewReader(makeTestWAV(wavFormatPCM, 1, 22050, 8, pcm)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clip.SampleRate != 22050 || clip.Channels != 1 || clip.BitDepth != 8 {
+		t.Errorf("got %d Hz, %d channels, %d bits; want 22050, 1, 8", clip.SampleRate, clip.Channels, clip.BitDepth)
+	}
+	if clip.Format() != SampleU8 {
+		t.Errorf("Format() = %v, want SampleU8", clip.Format())
+	}
+	if !bytes.Equal(clip.Data, pcm) {
+		t.Errorf("Data = %v, want the %d-byte data chunk", clip.Data, len(pcm))
+	}
+
+	// A trailing partial frame is dropped
+	clip, err = LoadWAVFromReader(bytes.NewReader(makeTestWAV(wavFormatPCM, 2, 44100, 16, make([]byte, 10))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clip.Data) != 8 || clip.Format() != SampleS16LE {
+		t.Errorf("got %d bytes of %v, want 8 of SampleS16LE", len(clip.Data), clip.Format())
+	}
+}
+
+func TestDecodeWAVErrors(t *testing.T) {
+	tests := []struct {
+		name, want string
+		data       []byte
+	}{
+		{"not riff", "not a WAV file", []byte("OggS0000WAVE")},
+		{"float", "float", makeTestWAV(wavFormatFloat, 1, 8000, 32, nil)},
+		{"compressed", "compressed", makeTestWAV(2, 1, 8000, 4, nil)},
+		{"24-bit", "24-bit", makeTestWAV(wavFormatPCM, 1, 8000, 24, nil)},
+		{"truncated", "data chunk", makeTestWAV(wavFormatPCM, 1, 8000, 8, []byte{1, 2, 3})[:57]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeWAV(bytes.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
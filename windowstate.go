@@ -0,0 +1,112 @@
+package glow
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+// wmStateIconic is the WM_STATE value of an iconified window (ICCCM
+// 4.1.3.1)
+const wmStateIconic = 3
+
+// windowState caches what the window manager says about the window,
+// rather than querying it every frame. Focus follows FocusIn and
+// FocusOut events; the rest is re-read from the WM_STATE and
+// _NET_WM_STATE properties (see refreshState). It is written by the
+// event goroutine and read by the app, hence atomic.
+type windowState struct {
+	minimized atomic.Bool
+	maximized atomic.Bool
+	focused   atomic.Bool
+}
+
+// IsMinimized reports whether the window is minimized (iconified). Apps
+// can pause rendering and game logic while it is.
+func (w *Window) IsMinimized() bool { return w.state.minimized.Load() }
+
+// IsMaximized reports whether the window manager has maximized the
+// window both horizontally and vertically.
+func (w *Window) IsMaximized() bool { return w.state.maximized.Load() }
+
+// IsFocused reports whether the window has the keyboard focus.
+func (w *Window) IsFocused() bool { return w.state.focused.Load() }
+
+// SetInputFocus asks for the keyboard focus, as after mapping a tool
+// window or to take the focus back from another of the app's windows.
+// The window must be mapped. Window managers have the last word on
+// focus: most let an app move the focus between its own windows, but
+// focus stealing prevention may refuse it or flash the window's taskbar
+// entry instead when another app has the focus, and the window manager
+// can move the focus elsewhere at any time. IsFocused changes once the
+// FocusIn event arrives.
+func (w *Window) SetInputFocus() error {
+	return w.conn.SetInputFocus(w.windowID, x11.RevertToParent)
+}
+
+// HasInputFocus asks the X server whether the window has the keyboard
+// focus now, rather than reading the state kept from focus events like
+// IsFocused. It costs a round trip.
+func (w *Window) HasInputFocus() (bool, error) {
+	focus, err := w.conn.GetInputFocus()
+	if err != nil {
+		return false, err
+	}
+	return focus.Window == w.windowID, nil
+}
+
+// trackFocus updates the focus state from a FocusIn or FocusOut event.
+func (w *Window) trackFocus(e x11.FocusEvent) {
+	// Keyboard grabs (a window manager's Alt+Tab) report focus moving
+	// without it leaving us, and NotifyPointer events concern the window
+	// under the pointer rather than the focus window
+	if e.Mode == x11.NotifyGrab || e.Mode == x11.NotifyUngrab || e.Detail == x11.NotifyPointer {
+		return
+	}
+	w.state.focused.Store(e.EventType == x11.EventFocusIn)
+}
+
+// refreshState re-reads WM_STATE and _NET_WM_STATE after one of them
+// may have changed. Without PropertyChangeMask the window hears of
+// no property changes, so this also runs on the map, unmap and
+// configure events that minimizing, restoring and maximizing cause.
+// Errors leave the cached state as it was.
+func (w *Window) refreshState() {
+	wmState, err := w.conn.GetProperty(w.windowID, x11.AtomWMState, 0, false)
+	if err != nil {
+		return
+	}
+	netState, err := w.conn.GetProperty(w.windowID, x11.AtomNetWMState, x11.AtomAtom, false)
+	if err != nil {
+		return
+	}
+	w.setState(wmState, netState)
+}
+
+// setState decodes the WM_STATE and _NET_WM_STATE property values.
+// Either may be missing (Type 0), as with window managers that don't
+// set it.
+func (w *Window) setState(wmState, netState *x11.Property) {
+	iconic := false
+	if wmState.Format == 32 && len(wmState.Value) >= 4 {
+		iconic = binary.LittleEndian.Uint32(wmState.Value) == wmStateIconic
+	}
+
+	var hidden, maxVert, maxHorz bool
+	if netState.Format == 32 {
+		for i := 0; i+4 <= len(netState.Value); i += 4 {
+			switch x11.Atom(binary.LittleEndian.Uint32(netState.Value[i:])) {
+			case x11.AtomNetWMStateHidden:
+				hidden = true
+			case x11.AtomNetWMStateMaxVert:
+				maxVert = true
+			case x11.AtomNetWMStateMaxHorz:
+				maxHorz = true
+			}
+		}
+	}
+
+	w.state.minimized.Store(iconic || hidden)
+	w.state.maximized.Store(maxVert && maxHorz)
+}
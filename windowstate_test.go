@@ -0,0 +1,204 @@
+package glow
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/AchrafSoltani/glow/internal/x11"
+)
+
+func atomList(atoms ...x11.Atom) *x11.Property {
+	p := &x11.Property{Type: x11.AtomAtom, Format: 32}
+	for _, a := range atoms {
+		p.Value = binary.LittleEndian.AppendUint32(p.Value, uint32(a))
+	}
+	return p
+}
+
+func TestWindowStateProperties(t *testing.T) {
+	// Distinct stand-ins, as no server interns them in tests
+	saved := [3]x11.Atom{x11.AtomNetWMStateHidden, x11.AtomNetWMStateMaxVert, x11.AtomNetWMStateMaxHorz}
+	t.Cleanup(func() {
+		x11.AtomNetWMStateHidden, x11.AtomNetWMStateMaxVert, x11.AtomNetWMStateMaxHorz = saved[0], saved[1], saved[2]
+	})
+	x11.AtomNetWMStateHidden, x11.AtomNetWMStateMaxVert, x11.AtomNetWMStateMaxHorz = 901, 902, 903
+	iconic := &x11.Property{Format: 32, Value: []byte{wmStateIconic, 0, 0, 0, 0, 0, 0, 0}}
+	normal := &x11.Property{Format: 32, Value: []byte{1, 0, 0, 0, 0, 0, 0, 0}}
+	missing := &x11.Property{}
+
+	w := &Window{}
+	w.setState(normal, atomList(x11.AtomNetWMStateMaxVert, x11.AtomNetWMStateMaxHorz))
+	if w.IsMinimized() || !w.IsMaximized() {
+		t.Errorf("maximized window: minimized=%v maximized=%v", w.IsMinimized(), w.IsMaximized())
+	}
+
+	// Maximized in one direction only isn't maximized
+	w.setState(normal, atomList(x11.AtomNetWMStateMaxVert))
+	if w.IsMaximized() {
+		t.Error("half-maximized window reported maximized")
+	}
+
+	// Either property can say the window is minimized
+	w.setState(iconic, missing)
+	if !w.IsMinimized() {
+		t.Error("iconic WM_STATE not minimized")
+	}
+	w.setState(missing, atomList(x11.AtomNetWMStateHidden))
+	if !w.IsMinimized() {
+		t.Error("_NET_WM_STATE_HIDDEN not minimized")
+	}
+	w.setState(missing, missing)
+	if w.IsMinimized() || w.IsMaximized() {
+		t.Error("state left over after the properties went away")
+	}
+}
+
+func TestWindowFocusEvents(t *testing.T) {
+	w := &Window{}
+	in := x11.FocusEvent{EventType: x11.EventFocusIn, Mode: x11.NotifyNormal}
+	out := x11.FocusEvent{EventType: x11.EventFocusOut, Mode: x11.NotifyNormal}
+
+	if e := w.convertEvent(in); e != nil || !w.IsFocused() {
+		t.Fatalf("FocusIn: event %v, focused %v", e, w.IsFocused())
+	}
+	// A keyboard grab doesn't take the focus away
+	grab := out
+	grab.Mode = x11.NotifyGrab
+	w.convertEvent(grab)
+	if !w.IsFocused() {
+		t.Error("grab cleared the focus")
+	}
+	w.convertEvent(out)
+	if w.IsFocused() {
+		t.Error("FocusOut left the window focused")
+	}
+}
+
+func TestPropertyChangedEvents(t *testing.T) {
+	conn, reqs := recordRequests(t)
+	w := &Window{conn: conn, windowID: 0x100}
+	e := x11.PropertyNotifyEvent{Atom: 1234, State: x11.PropertyNewValue}
+	if got := w.convertEvent(e); got != nil {
+		t.Fatalf("property event delivered while off: %+v", got)
+	}
+
+	// checkMask checks a ChangeWindowAttributes selecting mask
+	checkMask := func(mask uint32) {
+		t.Helper()
+		req := nextRequest(t, reqs, x11.OpChangeWindowAttributes)
+		if binary.LittleEndian.Uint32(req[4:]) != 0x100 ||
+			binary.LittleEndian.Uint32(req[8:]) != x11.CWEventMask ||
+			binary.LittleEndian.Uint32(req[12:]) != mask {
+			t.Errorf("ChangeWindowAttributes % x, want event mask %#x", req, mask)
+		}
+	}
+	if err := w.SetPropertyEvents(true); err != nil {
+		t.Fatal(err)
+	}
+	checkMask(x11.WindowEventMask | x11.PropertyChangeMask)
+	got := w.convertEvent(e)
+	if got == nil || got.Type != EventPropertyChanged || got.Property != 1234 || got.PropertyDeleted {
+		t.Fatalf("unexpected event %+v", got)
+	}
+	e.State = x11.PropertyDelete
+	if got := w.convertEvent(e); got == nil || !got.PropertyDeleted {
+		t.Errorf("deletion not reported: %+v", got)
+	}
+
+	if err := w.SetPropertyEvents(false); err != nil {
+		t.Fatal(err)
+	}
+	checkMask(x11.WindowEventMask)
+	if x11.WindowEventMask&x11.PropertyChangeMask != 0 {
+		t.Error("windows select PropertyChangeMask by default")
+	}
+}
+
+func TestWindowStateOnUnmap(t *testing.T) {
+	client, server := net.Pipe()
+	conn := x11.NewConnection(client)
+	defer conn.Close()
+	w := &Window{conn: conn, windowID: 0x400001}
+
+	// Answer the GetProperty of WM_STATE with Iconic, and that of
+	// _NET_WM_STATE with a missing property
+	go func() {
+		for seq := uint16(1); seq <= 2; seq++ {
+			req := make([]byte, 24)
+			if _, err := io.ReadFull(server, req); err != nil || req[0] != x11.OpGetProperty {
+				t.Errorf("request %v (%v), want GetProperty", req, err)
+				return
+			}
+			reply := make([]byte, 32)
+			reply[0] = 1
+			binary.LittleEndian.PutUint16(reply[2:], seq)
+			if x11.Atom(binary.LittleEndian.Uint32(req[8:])) == x11.AtomWMState {
+				reply[1] = 32
+				binary.LittleEndian.PutUint32(reply[4:], 2)
+				binary.LittleEndian.PutUint32(reply[16:], 2)
+				reply = append(reply, wmStateIconic, 0, 0, 0, 0, 0, 0, 0)
+			}
+			server.Write(reply)
+		}
+	}()
+
+	// Minimizing unmaps the window; no PropertyNotify arrives as
+	// PropertyChangeMask is off
+	if e := w.convertEvent(x11.MapEvent{EventType: x11.EventUnmapNotify, Window: 0x400001}); e != nil {
+		t.Errorf("unmap delivered %+v", e)
+	}
+	if !w.IsMinimized() {
+		t.Error("window not minimized after unmap")
+	}
+}
+
+func TestSetInputFocus(t *testing.T) {
+	conn, reqs := recordRequests(t)
+	w := &Window{conn: conn, windowID: 0x100}
+	if err := w.SetInputFocus(); err != nil {
+		t.Fatal(err)
+	}
+	req := nextRequest(t, reqs, x11.OpSetInputFocus)
+	if len(req) != 12 || req[1] != x11.RevertToParent ||
+		binary.LittleEndian.Uint32(req[4:]) != 0x100 || binary.LittleEndian.Uint32(req[8:]) != 0 {
+		t.Errorf("SetInputFocus request % x", req)
+	}
+}
+
+func TestHasInputFocus(t *testing.T) {
+	client, server := net.Pipe()
+	conn := x11.NewConnection(client)
+	defer conn.Close()
+	w := &Window{conn: conn, windowID: 0x400001}
+
+	// answer reads a GetInputFocus and replies with focus on window
+	answer := func(seq uint16, window uint32) {
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(server, req); err != nil || req[0] != x11.OpGetInputFocus {
+			t.Errorf("request %v (%v), want GetInputFocus", req, err)
+			return
+		}
+		reply := make([]byte, 32)
+		reply[0] = 1
+		reply[1] = x11.RevertToPointerRoot
+		binary.LittleEndian.PutUint16(reply[2:], seq)
+		binary.LittleEndian.PutUint32(reply[8:], window)
+		server.Write(reply)
+	}
+
+	go answer(1, 0x400001)
+	if ok, err := w.HasInputFocus(); err != nil || !ok {
+		t.Errorf("HasInputFocus = %v, %v with focus on the window", ok, err)
+	}
+	go answer(2, 0x600002)
+	if ok, err := w.HasInputFocus(); err != nil || ok {
+		t.Errorf("HasInputFocus = %v, %v with focus elsewhere", ok, err)
+	}
+	go answer(3, x11.FocusPointerRoot)
+	focus, err := conn.GetInputFocus()
+	if err != nil || focus.Window != x11.FocusPointerRoot || focus.RevertTo != x11.RevertToPointerRoot {
+		t.Errorf("GetInputFocus = %+v, %v", focus, err)
+	}
+}